@@ -6,7 +6,9 @@ import (
 	"musicapp/internal/db"
 	"musicapp/internal/handlers"
 	"musicapp/internal/logging"
+	"musicapp/internal/mail"
 	"musicapp/internal/middleware"
+	"musicapp/internal/oidc"
 	"musicapp/internal/repository"
 	"musicapp/internal/service"
 	"musicapp/internal/storage"
@@ -15,34 +17,68 @@ import (
 // Dependencies holds all application dependencies
 type Dependencies struct {
 	// Infrastructure
-	Database         *db.DB
-	Redis            *cache.Cache
-	S3               *storage.S3Client
-	Logger           *logging.Logger
+	Database           *db.DB
+	Redis              *cache.Cache
+	S3                 *storage.S3Client
+	Logger             *logging.Logger
 	TransactionManager *db.TransactionManager
 
 	// Repositories
-	UserRepo   *repository.UserRepository
-	BandRepo   *repository.BandRepository
-	PostRepo   *repository.PostRepository
-	FollowRepo *repository.FollowRepository
+	UserRepo         *repository.UserRepository
+	BandRepo         *repository.BandRepository
+	PostRepo         *repository.PostRepository
+	FollowRepo       *repository.FollowRepository
+	CommentRepo      *repository.CommentRepository
+	MessageRepo      *repository.MessageRepository
+	ConversationRepo *repository.ConversationRepository
+	RefreshTokenRepo *repository.RefreshTokenRepository
+	SessionRepo      *repository.SessionRepository
+	AccountTokenRepo *repository.AccountTokenRepository
+	MFARepo          *repository.MFARepository
+	IdentityRepo     *repository.IdentityRepository
+	APIKeyRepo       *repository.APIKeyRepository
+	DataExportRepo   *repository.DataExportRepository
+	AuditLogRepo     *repository.AuditLogRepository
+	MembershipRepo   *repository.BandMembershipRepository
 
 	// Services
-	AuthService   *service.AuthService
-	UserService   *service.UserService
-	BandService   *service.BandService
-	PostService   *service.PostService
-	FollowService *service.FollowService
+	AuthService          *service.AuthService
+	AccountService       *service.AccountService
+	MFAService           *service.MFAService
+	OIDCService          *service.OIDCService
+	APIKeyService        *service.APIKeyService
+	DataExportService    *service.DataExportService
+	AdminService         *service.AdminService
+	UserService          *service.UserService
+	BandService          *service.BandService
+	MembershipService    *service.BandMembershipService
+	PostService          *service.PostService
+	FollowService        *service.FollowService
+	CommentService       *service.CommentService
+	ChatService          *service.ChatService
+	DirectMessageService *service.DirectMessageService
 
 	// Handlers
-	AuthHandler   *handlers.AuthHandler
-	UserHandler   *handlers.UserHandler
-	BandHandler   *handlers.BandHandler
-	PostHandler   *handlers.PostHandler
-	FollowHandler *handlers.FollowHandler
+	AuthHandler          *handlers.AuthHandler
+	AccountHandler       *handlers.AccountHandler
+	MFAHandler           *handlers.MFAHandler
+	OIDCHandler          *handlers.OIDCHandler
+	APIKeyHandler        *handlers.APIKeyHandler
+	DataExportHandler    *handlers.DataExportHandler
+	AdminHandler         *handlers.AdminHandler
+	JWKSHandler          *handlers.JWKSHandler
+	UserHandler          *handlers.UserHandler
+	BandHandler          *handlers.BandHandler
+	MembershipHandler    *handlers.BandMembershipHandler
+	PostHandler          *handlers.PostHandler
+	FollowHandler        *handlers.FollowHandler
+	CommentHandler       *handlers.CommentHandler
+	ChatHandler          *handlers.ChatHandler
+	DirectMessageHandler *handlers.DirectMessageHandler
 
 	// Middleware
 	AuthMiddleware    *middleware.AuthMiddleware
+	EmailVerification *middleware.EmailVerification
 	LoggingMiddleware *middleware.LoggingMiddleware
 }
 
@@ -51,7 +87,7 @@ func setupDependencies(cfg *config.Config) (*Dependencies, error) {
 	// Initialize logger first
 	var logger *logging.Logger
 	var err error
-	
+
 	if cfg.Environment == "production" {
 		logger, err = logging.NewProduction()
 	} else {
@@ -87,8 +123,28 @@ func setupDependencies(cfg *config.Config) (*Dependencies, error) {
 		}
 	}
 
+	// Initialize mailer: SMTP when configured, otherwise files for development
+	var mailer mail.Mailer
+	if cfg.SMTPHost != "" {
+		mailer = mail.NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUsername, cfg.SMTPPassword, cfg.MailFrom)
+	} else {
+		fileMailer, err := mail.NewFileMailer(cfg.MailDir, cfg.MailFrom)
+		if err != nil {
+			return nil, err
+		}
+		logger.WithField("mail_dir", cfg.MailDir).Info("SMTP not configured, writing outgoing mail to files")
+		mailer = fileMailer
+	}
+
 	// Initialize middleware
-	authMiddleware := middleware.NewAuthMiddleware(cfg.JWTSecret, redisCache)
+	var jwtKeys *middleware.KeySet
+	if cfg.JWTSigningKey != nil || len(cfg.JWTVerificationKeys) > 0 {
+		jwtKeys, err = middleware.NewKeySet(cfg.JWTSigningKey, cfg.JWTVerificationKeys)
+		if err != nil {
+			return nil, err
+		}
+	}
+	authMiddleware := middleware.NewAuthMiddlewareWithKeys(cfg.JWTSecret, jwtKeys, redisCache)
 	loggingMiddleware := middleware.NewLoggingMiddleware(logger)
 
 	// Initialize repositories
@@ -96,20 +152,70 @@ func setupDependencies(cfg *config.Config) (*Dependencies, error) {
 	bandRepo := repository.NewBandRepository(database)
 	postRepo := repository.NewPostRepository(database)
 	followRepo := repository.NewFollowRepository(database)
+	commentRepo := repository.NewCommentRepository(database)
+	messageRepo := repository.NewMessageRepository(database)
+	conversationRepo := repository.NewConversationRepository(database)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(database)
+	sessionRepo := repository.NewSessionRepository(database)
+	accountTokenRepo := repository.NewAccountTokenRepository(database)
+	mfaRepo := repository.NewMFARepository(database)
+	identityRepo := repository.NewIdentityRepository(database)
+	apiKeyRepo := repository.NewAPIKeyRepository(database)
+	dataExportRepo := repository.NewDataExportRepository(database)
+	auditLogRepo := repository.NewAuditLogRepository(database)
+	membershipRepo := repository.NewBandMembershipRepository(database)
+
+	// Email verification checks need the user repository, and personal API
+	// keys are looked up by the API key service
+	emailVerification := middleware.NewEmailVerification(userRepo, middleware.UnverifiedAccess(cfg.UnverifiedAccess))
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo, logger)
+	authMiddleware.SetAPIKeyVerifier(apiKeyService)
 
 	// Initialize services
-	authService := service.NewAuthService(userRepo, redisCache, authMiddleware)
+	mfaService := service.NewMFAService(mfaRepo, userRepo, redisCache, logger)
+	loginThrottle := service.NewLoginThrottle(redisCache, mailer, logger)
+	authService := service.NewAuthService(userRepo, redisCache, authMiddleware, refreshTokenRepo, sessionRepo, mfaService, loginThrottle, logger)
+	oidcProviders := make([]service.OIDCProvider, 0, len(cfg.OIDCProviders))
+	for _, providerConfig := range cfg.OIDCProviders {
+		oidcProviders = append(oidcProviders, oidc.NewProvider(providerConfig))
+	}
+	oidcService := service.NewOIDCService(oidcProviders, identityRepo, userRepo, redisCache, authService, logger)
+	var mediaStore service.MediaStore
+	var exportStore service.ExportStore
+	if s3Client != nil {
+		mediaStore = s3Client
+		exportStore = s3Client
+	}
+	accountService := service.NewAccountService(userRepo, accountTokenRepo, authService, apiKeyService, mediaStore, mailer, redisCache, cfg.AppURL, logger)
+	dataExportService := service.NewDataExportService(dataExportRepo, exportStore, logger)
+	adminService := service.NewAdminService(userRepo, postRepo, commentRepo, auditLogRepo, authService, logger)
 	userService := service.NewUserService(userRepo, redisCache, s3Client, logger)
 	bandService := service.NewBandService(bandRepo, userRepo, redisCache, s3Client)
+	authMiddleware.SetBandAuthorizer(bandService)
+	membershipService := service.NewBandMembershipService(membershipRepo, bandRepo, userRepo, mailer, cfg.AppURL, logger)
 	postService := service.NewPostService(postRepo, userRepo, bandRepo, redisCache, s3Client)
 	followService := service.NewFollowService(followRepo, userRepo, bandRepo, redisCache)
+	commentService := service.NewCommentService(commentRepo, postRepo, bandRepo, cfg.CommentMaxDepth)
+	chatService := service.NewChatService(messageRepo, bandRepo, redisCache)
+	directMessageService := service.NewDirectMessageService(conversationRepo, userRepo)
 
 	// Initialize handlers
-	authHandler := handlers.NewAuthHandler(authService)
+	authHandler := handlers.NewAuthHandler(authService, accountService)
+	accountHandler := handlers.NewAccountHandler(accountService)
+	mfaHandler := handlers.NewMFAHandler(mfaService)
+	oidcHandler := handlers.NewOIDCHandler(oidcService)
+	apiKeyHandler := handlers.NewAPIKeyHandler(apiKeyService)
+	dataExportHandler := handlers.NewDataExportHandler(dataExportService)
+	adminHandler := handlers.NewAdminHandler(adminService)
+	jwksHandler := handlers.NewJWKSHandler(authMiddleware.Keys())
 	userHandler := handlers.NewUserHandler(userService, bandService)
 	bandHandler := handlers.NewBandHandler(bandService)
+	membershipHandler := handlers.NewBandMembershipHandler(membershipService)
 	postHandler := handlers.NewPostHandler(postService)
 	followHandler := handlers.NewFollowHandler(followService)
+	commentHandler := handlers.NewCommentHandler(commentService)
+	chatHandler := handlers.NewChatHandler(chatService, authMiddleware)
+	directMessageHandler := handlers.NewDirectMessageHandler(directMessageService)
 
 	return &Dependencies{
 		// Infrastructure
@@ -120,27 +226,61 @@ func setupDependencies(cfg *config.Config) (*Dependencies, error) {
 		TransactionManager: txManager,
 
 		// Repositories
-		UserRepo:   userRepo,
-		BandRepo:   bandRepo,
-		PostRepo:   postRepo,
-		FollowRepo: followRepo,
+		UserRepo:         userRepo,
+		BandRepo:         bandRepo,
+		PostRepo:         postRepo,
+		FollowRepo:       followRepo,
+		CommentRepo:      commentRepo,
+		MessageRepo:      messageRepo,
+		ConversationRepo: conversationRepo,
+		RefreshTokenRepo: refreshTokenRepo,
+		SessionRepo:      sessionRepo,
+		AccountTokenRepo: accountTokenRepo,
+		MFARepo:          mfaRepo,
+		IdentityRepo:     identityRepo,
+		APIKeyRepo:       apiKeyRepo,
+		DataExportRepo:   dataExportRepo,
+		AuditLogRepo:     auditLogRepo,
+		MembershipRepo:   membershipRepo,
 
 		// Services
-		AuthService:   authService,
-		UserService:   userService,
-		BandService:   bandService,
-		PostService:   postService,
-		FollowService: followService,
+		AuthService:          authService,
+		AccountService:       accountService,
+		MFAService:           mfaService,
+		OIDCService:          oidcService,
+		APIKeyService:        apiKeyService,
+		DataExportService:    dataExportService,
+		AdminService:         adminService,
+		UserService:          userService,
+		BandService:          bandService,
+		MembershipService:    membershipService,
+		PostService:          postService,
+		FollowService:        followService,
+		CommentService:       commentService,
+		ChatService:          chatService,
+		DirectMessageService: directMessageService,
 
 		// Handlers
-		AuthHandler:   authHandler,
-		UserHandler:   userHandler,
-		BandHandler:   bandHandler,
-		PostHandler:   postHandler,
-		FollowHandler: followHandler,
+		AuthHandler:          authHandler,
+		AccountHandler:       accountHandler,
+		MFAHandler:           mfaHandler,
+		OIDCHandler:          oidcHandler,
+		APIKeyHandler:        apiKeyHandler,
+		DataExportHandler:    dataExportHandler,
+		AdminHandler:         adminHandler,
+		JWKSHandler:          jwksHandler,
+		UserHandler:          userHandler,
+		BandHandler:          bandHandler,
+		MembershipHandler:    membershipHandler,
+		PostHandler:          postHandler,
+		FollowHandler:        followHandler,
+		CommentHandler:       commentHandler,
+		ChatHandler:          chatHandler,
+		DirectMessageHandler: directMessageHandler,
 
 		// Middleware
 		AuthMiddleware:    authMiddleware,
+		EmailVerification: emailVerification,
 		LoggingMiddleware: loggingMiddleware,
 	}, nil
 }
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"musicapp/internal/middleware"
+	"musicapp/internal/models"
 
 	"github.com/gorilla/mux"
 	httpSwagger "github.com/swaggo/http-swagger"
@@ -22,17 +23,23 @@ func setupRoutes(deps *Dependencies) *mux.Router {
 	// Health check endpoints
 	setupHealthRoutes(router, deps)
 
+	// Token verification keys
+	router.HandleFunc("/.well-known/jwks.json", deps.JWKSHandler.JWKS).Methods("GET")
+
 	// Swagger documentation
 	router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
-	// API routes
+	// API routes. Routes that personal API keys may call use RequireScope
+	// instead of RequireAuth.
 	api := router.PathPrefix("/api").Subrouter()
 	setupAuthRoutes(api, deps)
 	setupUserRoutes(api, deps)
 	setupBandRoutes(api, deps)
 	setupPostRoutes(api, deps)
+	setupCommentRoutes(api, deps)
 	setupFollowRoutes(api, deps)
 	setupFeedRoutes(api, deps)
+	setupAdminRoutes(api, deps)
 
 	return router
 }
@@ -70,62 +77,168 @@ func setupAuthRoutes(api *mux.Router, deps *Dependencies) {
 	auth := api.PathPrefix("/auth").Subrouter()
 	auth.HandleFunc("/register", deps.AuthHandler.Register).Methods("POST")
 	auth.HandleFunc("/login", deps.AuthHandler.Login).Methods("POST")
+	auth.HandleFunc("/refresh", deps.AuthHandler.Refresh).Methods("POST")
+	auth.HandleFunc("/forgot-password", deps.AccountHandler.ForgotPassword).Methods("POST")
+	auth.HandleFunc("/reset-password", deps.AccountHandler.ResetPassword).Methods("POST")
+	auth.HandleFunc("/verify-email", deps.AccountHandler.VerifyEmail).Methods("POST")
+	auth.Handle("/resend-verification", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.AccountHandler.ResendVerification))).Methods("POST")
+	auth.HandleFunc("/mfa/verify", deps.AuthHandler.VerifyMFA).Methods("POST")
+	auth.Handle("/mfa/totp", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.MFAHandler.EnrollTOTP))).Methods("POST")
+	auth.Handle("/mfa/totp/confirm", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.MFAHandler.ConfirmTOTP))).Methods("POST")
+	auth.Handle("/mfa/totp/disable", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.MFAHandler.DisableTOTP))).Methods("POST")
 	auth.Handle("/logout", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.AuthHandler.Logout))).Methods("POST")
+
+	// Sign-in with external identity providers
+	auth.HandleFunc("/oidc/providers", deps.OIDCHandler.ListProviders).Methods("GET")
+	auth.HandleFunc("/oidc/{provider}/authorize", deps.OIDCHandler.Authorize).Methods("POST")
+	auth.HandleFunc("/oidc/{provider}/callback", deps.OIDCHandler.Callback).Methods("POST")
+	auth.Handle("/oidc/{provider}/link/authorize", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.OIDCHandler.AuthorizeLink))).Methods("POST")
+	auth.Handle("/oidc/{provider}/link", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.OIDCHandler.Link))).Methods("POST")
 }
 
 // setupUserRoutes configures user routes
 func setupUserRoutes(api *mux.Router, deps *Dependencies) {
 	users := api.PathPrefix("/users").Subrouter()
+
+	// The current user, their account and their signed-in devices; registered
+	// before the /{id} routes. Credential and account changes need an
+	// interactive login.
+	users.Handle("/me", deps.AuthMiddleware.RequireScope(middleware.ScopeProfileRead, http.HandlerFunc(deps.UserHandler.GetCurrentUser))).Methods("GET")
+	users.Handle("/me", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.AccountHandler.DeleteAccount))).Methods("DELETE")
+	users.Handle("/me/restore", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.AccountHandler.RestoreAccount))).Methods("POST")
+	users.Handle("/me/password", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.AccountHandler.ChangePassword))).Methods("PUT")
+	users.Handle("/me/email", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.AccountHandler.ChangeEmail))).Methods("PUT")
+	users.Handle("/me/sessions", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.AuthHandler.ListSessions))).Methods("GET")
+	users.Handle("/me/sessions", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.AuthHandler.RevokeOtherSessions))).Methods("DELETE")
+	users.Handle("/me/sessions/{id}", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.AuthHandler.RevokeSession))).Methods("DELETE")
+
+	// Linked identity provider accounts; registered before the /{id} routes
+	users.Handle("/me/identities", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.OIDCHandler.ListIdentities))).Methods("GET")
+	users.Handle("/me/identities/{id}", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.OIDCHandler.Unlink))).Methods("DELETE")
+
+	// Personal API keys can only be managed from an interactive login
+	users.Handle("/me/api-keys", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.APIKeyHandler.ListAPIKeys))).Methods("GET")
+	users.Handle("/me/api-keys", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.APIKeyHandler.CreateAPIKey))).Methods("POST")
+	users.Handle("/me/api-keys/{id}", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.APIKeyHandler.RevokeAPIKey))).Methods("DELETE")
+
+	// Personal data exports can only be requested from an interactive login
+	users.Handle("/me/exports", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.DataExportHandler.ListExports))).Methods("GET")
+	users.Handle("/me/exports", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.DataExportHandler.RequestExport))).Methods("POST")
+	users.Handle("/me/exports/{id}", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.DataExportHandler.GetExport))).Methods("GET")
+
+	// Band invitations and join requests of the current user; registered
+	// before the /{id} routes
+	users.Handle("/me/band-invitations", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsRead, http.HandlerFunc(deps.MembershipHandler.ListInvitations))).Methods("GET")
+	users.Handle("/me/band-invitations/{id}/accept", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsWrite, deps.EmailVerification.RequireVerified(http.HandlerFunc(deps.MembershipHandler.AcceptInvitation)))).Methods("POST")
+	users.Handle("/me/band-invitations/{id}/decline", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.MembershipHandler.DeclineInvitation)))).Methods("POST")
+	users.Handle("/me/join-requests", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsRead, http.HandlerFunc(deps.MembershipHandler.ListJoinRequests))).Methods("GET")
+	users.Handle("/me/join-requests/{id}", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.MembershipHandler.CancelJoinRequest)))).Methods("DELETE")
+
+	// Direct messages for the current user; registered before the /{id} routes
+	users.Handle("/me/conversations", deps.AuthMiddleware.RequireScope(middleware.ScopeMessagesRead, http.HandlerFunc(deps.DirectMessageHandler.GetConversations))).Methods("GET")
+	users.Handle("/me/conversations/unread", deps.AuthMiddleware.RequireScope(middleware.ScopeMessagesRead, http.HandlerFunc(deps.DirectMessageHandler.GetUnreadCount))).Methods("GET")
+	users.Handle("/me/conversations/{id}/messages", deps.AuthMiddleware.RequireScope(middleware.ScopeMessagesRead, http.HandlerFunc(deps.DirectMessageHandler.GetMessages))).Methods("GET")
+	users.Handle("/me/conversations/{id}/read", deps.AuthMiddleware.RequireScope(middleware.ScopeMessagesWrite, http.HandlerFunc(deps.DirectMessageHandler.MarkRead))).Methods("POST")
+	users.Handle("/me/messages/{id}", deps.AuthMiddleware.RequireScope(middleware.ScopeMessagesWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.DirectMessageHandler.DeleteMessage)))).Methods("DELETE")
+
 	users.HandleFunc("", deps.UserHandler.GetAllUsers).Methods("GET")
-	users.HandleFunc("/{id}", deps.UserHandler.GetUser).Methods("GET")
-	users.Handle("/{id}", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.UserHandler.UpdateUser))).Methods("PUT")
-	users.HandleFunc("/{id}/posts", deps.UserHandler.GetUserPosts).Methods("GET")
+	users.Handle("/{id}", deps.AuthMiddleware.OptionalAuth(http.HandlerFunc(deps.UserHandler.GetUser))).Methods("GET")
+	users.Handle("/{id}", deps.AuthMiddleware.RequireScope(middleware.ScopeProfileWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.UserHandler.UpdateUser)))).Methods("PUT")
+	users.Handle("/{id}/posts", deps.AuthMiddleware.OptionalAuth(http.HandlerFunc(deps.PostHandler.GetUserPosts))).Methods("GET")
 	users.HandleFunc("/{id}/followers", deps.UserHandler.GetFollowers).Methods("GET")
 	users.HandleFunc("/{id}/following", deps.UserHandler.GetFollowing).Methods("GET")
 	users.HandleFunc("/{id}/bands", deps.UserHandler.GetUserBands).Methods("GET")
 	users.HandleFunc("/nearby", deps.UserHandler.GetNearbyUsers).Methods("GET")
-	users.Handle("/{id}/profile-picture", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.UserHandler.UploadProfilePicture))).Methods("POST")
+	users.Handle("/{id}/profile-picture", deps.AuthMiddleware.RequireScope(middleware.ScopeProfileWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.UserHandler.UploadProfilePicture)))).Methods("POST")
+	users.Handle("/{id}/messages", deps.AuthMiddleware.RequireScope(middleware.ScopeMessagesWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.DirectMessageHandler.SendMessage)))).Methods("POST")
 }
 
 // setupBandRoutes configures band routes
 func setupBandRoutes(api *mux.Router, deps *Dependencies) {
 	bands := api.PathPrefix("/bands").Subrouter()
 	bands.HandleFunc("", deps.BandHandler.GetAllBands).Methods("GET")
-	bands.Handle("", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.BandHandler.CreateBand))).Methods("POST")
+	bands.Handle("", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.BandHandler.CreateBand)))).Methods("POST")
 	bands.HandleFunc("/{id}", deps.BandHandler.GetBand).Methods("GET")
-	bands.Handle("/{id}", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.BandHandler.UpdateBand))).Methods("PUT")
-	bands.Handle("/{id}", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.BandHandler.DeleteBand))).Methods("DELETE")
-	bands.Handle("/{id}/join", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.BandHandler.JoinBand))).Methods("POST")
-	bands.Handle("/{id}/leave", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.BandHandler.LeaveBand))).Methods("POST")
+	bands.Handle("/{id}", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsWrite, deps.EmailVerification.RequireVerifiedForWrites(deps.AuthMiddleware.RequireBandPermission(models.BandPermissionEditProfile, http.HandlerFunc(deps.BandHandler.UpdateBand))))).Methods("PUT")
+	bands.Handle("/{id}", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsWrite, deps.EmailVerification.RequireVerifiedForWrites(deps.AuthMiddleware.RequireBandPermission(models.BandPermissionDeleteBand, http.HandlerFunc(deps.BandHandler.DeleteBand))))).Methods("DELETE")
+	bands.Handle("/{id}/join", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsWrite, deps.EmailVerification.RequireVerified(http.HandlerFunc(deps.MembershipHandler.RequestToJoin)))).Methods("POST")
+	bands.Handle("/{id}/leave", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.BandHandler.LeaveBand)))).Methods("POST")
 	bands.HandleFunc("/{id}/members", deps.BandHandler.GetBandMembers).Methods("GET")
+	bands.Handle("/{id}/members/{userId}", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsWrite, deps.EmailVerification.RequireVerifiedForWrites(deps.AuthMiddleware.RequireBandAdmin(http.HandlerFunc(deps.BandHandler.RemoveMember))))).Methods("DELETE")
+	bands.Handle("/{id}/members/{userId}/role", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsWrite, deps.EmailVerification.RequireVerifiedForWrites(deps.AuthMiddleware.RequireBandAdmin(http.HandlerFunc(deps.BandHandler.SetMemberRole))))).Methods("PUT")
+	bands.Handle("/{id}/members/{userId}/posting", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsWrite, deps.EmailVerification.RequireVerifiedForWrites(deps.AuthMiddleware.RequireBandAdmin(http.HandlerFunc(deps.BandHandler.SetMemberPostingPermission))))).Methods("PUT")
+	bands.Handle("/{id}/invitations", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsRead, deps.AuthMiddleware.RequireBandAdmin(http.HandlerFunc(deps.MembershipHandler.ListBandInvitations)))).Methods("GET")
+	bands.Handle("/{id}/invitations", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsWrite, deps.EmailVerification.RequireVerified(deps.AuthMiddleware.RequireBandAdmin(http.HandlerFunc(deps.MembershipHandler.Invite))))).Methods("POST")
+	bands.Handle("/{id}/invitations/{requestId}", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsWrite, deps.EmailVerification.RequireVerifiedForWrites(deps.AuthMiddleware.RequireBandAdmin(http.HandlerFunc(deps.MembershipHandler.CancelInvitation))))).Methods("DELETE")
+	bands.Handle("/{id}/join-requests", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsRead, deps.AuthMiddleware.RequireBandAdmin(http.HandlerFunc(deps.MembershipHandler.ListBandJoinRequests)))).Methods("GET")
+	bands.Handle("/{id}/join-requests/{requestId}/approve", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsWrite, deps.EmailVerification.RequireVerifiedForWrites(deps.AuthMiddleware.RequireBandAdmin(http.HandlerFunc(deps.MembershipHandler.ApproveJoinRequest))))).Methods("POST")
+	bands.Handle("/{id}/join-requests/{requestId}/reject", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsWrite, deps.EmailVerification.RequireVerifiedForWrites(deps.AuthMiddleware.RequireBandAdmin(http.HandlerFunc(deps.MembershipHandler.RejectJoinRequest))))).Methods("POST")
+	bands.Handle("/{id}/posts", deps.AuthMiddleware.OptionalAuth(http.HandlerFunc(deps.PostHandler.GetBandPosts))).Methods("GET")
+	bands.Handle("/{id}/posts", deps.AuthMiddleware.RequireScope(middleware.ScopePostsWrite, deps.EmailVerification.RequireVerified(http.HandlerFunc(deps.PostHandler.CreateBandPost)))).Methods("POST")
 	bands.HandleFunc("/nearby", deps.BandHandler.GetNearbyBands).Methods("GET")
-	bands.Handle("/{id}/profile-picture", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.BandHandler.UploadProfilePicture))).Methods("POST")
+	bands.Handle("/{id}/profile-picture", deps.AuthMiddleware.RequireScope(middleware.ScopeBandsWrite, deps.EmailVerification.RequireVerifiedForWrites(deps.AuthMiddleware.RequireBandPermission(models.BandPermissionEditProfile, http.HandlerFunc(deps.BandHandler.UploadProfilePicture))))).Methods("POST")
+	bands.Handle("/{id}/messages", deps.AuthMiddleware.RequireScope(middleware.ScopeMessagesRead, http.HandlerFunc(deps.ChatHandler.GetMessages))).Methods("GET")
+	bands.Handle("/{id}/chat", deps.AuthMiddleware.RequireAuth(deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.ChatHandler.Chat)))).Methods("GET")
 }
 
 // setupPostRoutes configures post routes
 func setupPostRoutes(api *mux.Router, deps *Dependencies) {
 	posts := api.PathPrefix("/posts").Subrouter()
-	posts.HandleFunc("", deps.PostHandler.GetAllPosts).Methods("GET")
-	posts.Handle("", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.PostHandler.CreatePost))).Methods("POST")
-	posts.HandleFunc("/{id}", deps.PostHandler.GetPost).Methods("GET")
-	posts.Handle("/{id}", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.PostHandler.UpdatePost))).Methods("PUT")
-	posts.Handle("/{id}", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.PostHandler.DeletePost))).Methods("DELETE")
-	posts.Handle("/{id}/like", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.PostHandler.LikePost))).Methods("POST")
-	posts.Handle("/{id}/like", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.PostHandler.UnlikePost))).Methods("DELETE")
-	posts.Handle("/{id}/repost", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.PostHandler.Repost))).Methods("POST")
-	posts.Handle("/{id}/media", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.PostHandler.UploadMedia))).Methods("POST")
+	posts.Handle("", deps.AuthMiddleware.OptionalAuth(http.HandlerFunc(deps.PostHandler.GetAllPosts))).Methods("GET")
+	posts.Handle("", deps.AuthMiddleware.RequireScope(middleware.ScopePostsWrite, deps.EmailVerification.RequireVerified(http.HandlerFunc(deps.PostHandler.CreatePost)))).Methods("POST")
+	posts.Handle("/{id}", deps.AuthMiddleware.OptionalAuth(http.HandlerFunc(deps.PostHandler.GetPost))).Methods("GET")
+	posts.Handle("/{id}", deps.AuthMiddleware.RequireScope(middleware.ScopePostsWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.PostHandler.UpdatePost)))).Methods("PUT")
+	posts.Handle("/{id}", deps.AuthMiddleware.RequireScope(middleware.ScopePostsWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.PostHandler.DeletePost)))).Methods("DELETE")
+	posts.Handle("/{id}/like", deps.AuthMiddleware.RequireScope(middleware.ScopePostsWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.PostHandler.LikePost)))).Methods("POST")
+	posts.Handle("/{id}/like", deps.AuthMiddleware.RequireScope(middleware.ScopePostsWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.PostHandler.UnlikePost)))).Methods("DELETE")
+	posts.Handle("/{id}/repost", deps.AuthMiddleware.RequireScope(middleware.ScopePostsWrite, deps.EmailVerification.RequireVerified(http.HandlerFunc(deps.PostHandler.Repost)))).Methods("POST")
+	posts.Handle("/{id}/repost", deps.AuthMiddleware.RequireScope(middleware.ScopePostsWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.PostHandler.Unrepost)))).Methods("DELETE")
+	posts.Handle("/{id}/media", deps.AuthMiddleware.RequireScope(middleware.ScopePostsWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.PostHandler.UploadMedia)))).Methods("POST")
+	posts.Handle("/{id}/comments", deps.AuthMiddleware.OptionalAuth(http.HandlerFunc(deps.CommentHandler.GetPostComments))).Methods("GET")
+	posts.Handle("/{id}/comments", deps.AuthMiddleware.RequireScope(middleware.ScopePostsWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.CommentHandler.CreateComment)))).Methods("POST")
+}
+
+// setupCommentRoutes configures comment routes
+func setupCommentRoutes(api *mux.Router, deps *Dependencies) {
+	comments := api.PathPrefix("/comments").Subrouter()
+	comments.Handle("/{id}", deps.AuthMiddleware.RequireScope(middleware.ScopePostsWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.CommentHandler.DeleteComment)))).Methods("DELETE")
+	comments.Handle("/{id}/replies", deps.AuthMiddleware.OptionalAuth(http.HandlerFunc(deps.CommentHandler.GetCommentReplies))).Methods("GET")
+	comments.Handle("/{id}/like", deps.AuthMiddleware.RequireScope(middleware.ScopePostsWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.CommentHandler.LikeComment)))).Methods("POST")
+	comments.Handle("/{id}/like", deps.AuthMiddleware.RequireScope(middleware.ScopePostsWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.CommentHandler.UnlikeComment)))).Methods("DELETE")
 }
 
 // setupFollowRoutes configures follow routes
 func setupFollowRoutes(api *mux.Router, deps *Dependencies) {
 	follows := api.PathPrefix("/follow").Subrouter()
-	follows.Handle("", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.FollowHandler.Follow))).Methods("POST")
-	follows.Handle("", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.FollowHandler.Unfollow))).Methods("DELETE")
+	follows.Handle("", deps.AuthMiddleware.RequireScope(middleware.ScopeFollowsWrite, deps.EmailVerification.RequireVerified(http.HandlerFunc(deps.FollowHandler.Follow)))).Methods("POST")
+	follows.Handle("", deps.AuthMiddleware.RequireScope(middleware.ScopeFollowsWrite, deps.EmailVerification.RequireVerifiedForWrites(http.HandlerFunc(deps.FollowHandler.Unfollow)))).Methods("DELETE")
 }
 
 // setupFeedRoutes configures feed routes
 func setupFeedRoutes(api *mux.Router, deps *Dependencies) {
 	feed := api.PathPrefix("/feed").Subrouter()
-	feed.Handle("", deps.AuthMiddleware.RequireAuth(http.HandlerFunc(deps.PostHandler.GetFeed))).Methods("GET")
-	feed.HandleFunc("/explore", deps.PostHandler.GetExploreFeed).Methods("GET")
+	feed.Handle("", deps.AuthMiddleware.RequireScope(middleware.ScopePostsRead, http.HandlerFunc(deps.PostHandler.GetFeed))).Methods("GET")
+	feed.Handle("/explore", deps.AuthMiddleware.OptionalAuth(http.HandlerFunc(deps.PostHandler.GetExploreFeed))).Methods("GET")
+}
+
+// setupAdminRoutes configures the staff API. Moderators can act on regular
+// users and content; role changes and the audit log are for admins.
+func setupAdminRoutes(api *mux.Router, deps *Dependencies) {
+	admin := api.PathPrefix("/admin").Subrouter()
+	moderator := func(handler http.HandlerFunc) http.Handler {
+		return deps.AuthMiddleware.RequireRole(middleware.RoleModerator, handler)
+	}
+	adminOnly := func(handler http.HandlerFunc) http.Handler {
+		return deps.AuthMiddleware.RequireRole(middleware.RoleAdmin, handler)
+	}
+
+	admin.Handle("/users", moderator(deps.AdminHandler.SearchUsers)).Methods("GET")
+	admin.Handle("/users/{id}", moderator(deps.AdminHandler.GetUser)).Methods("GET")
+	admin.Handle("/users/{id}/suspend", moderator(deps.AdminHandler.SuspendUser)).Methods("POST")
+	admin.Handle("/users/{id}/unsuspend", moderator(deps.AdminHandler.UnsuspendUser)).Methods("POST")
+	admin.Handle("/users/{id}/logout", moderator(deps.AdminHandler.ForceLogout)).Methods("POST")
+	admin.Handle("/users/{id}/role", adminOnly(deps.AdminHandler.SetRole)).Methods("PUT")
+	admin.Handle("/posts/{id}", moderator(deps.AdminHandler.DeletePost)).Methods("DELETE")
+	admin.Handle("/comments/{id}", moderator(deps.AdminHandler.DeleteComment)).Methods("DELETE")
+	admin.Handle("/audit-log", adminOnly(deps.AdminHandler.ListAuditLog)).Methods("GET")
 }
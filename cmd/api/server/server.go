@@ -53,6 +53,15 @@ func New(cfg *config.Config) (*Server, error) {
 //
 //go:noinline
 func (s *Server) Start() error {
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	// Permanently delete accounts whose deletion grace period has passed
+	go s.purgeDeletedAccounts(jobsCtx)
+
+	// Build data exports interrupted by a restart and delete expired ones
+	go s.processDataExports(jobsCtx)
+
 	// Start server in a goroutine
 	go func() {
 		log.Printf("Server starting on port %d", s.config.Port)
@@ -80,6 +89,63 @@ func (s *Server) Start() error {
 	return nil
 }
 
+// accountPurgeInterval is how often accounts due for deletion are purged
+const accountPurgeInterval = time.Hour
+
+// purgeDeletedAccounts runs AccountService.PurgeDeletedAccounts now and
+// then every accountPurgeInterval until ctx is cancelled
+func (s *Server) purgeDeletedAccounts(ctx context.Context) {
+	ticker := time.NewTicker(accountPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := s.deps.AccountService.PurgeDeletedAccounts(ctx)
+		if err != nil {
+			s.deps.Logger.WithError(err).Error("Failed to purge deleted accounts")
+		} else if purged > 0 {
+			s.deps.Logger.WithField("accounts", purged).Info("Purged deleted accounts")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dataExportInterval is how often pending and expired data exports are
+// processed; exports are normally built as soon as they are requested
+const dataExportInterval = 5 * time.Minute
+
+// processDataExports runs DataExportService.ProcessPendingExports and
+// DeleteExpiredExports now and then every dataExportInterval until ctx is
+// cancelled
+func (s *Server) processDataExports(ctx context.Context) {
+	ticker := time.NewTicker(dataExportInterval)
+	defer ticker.Stop()
+
+	for {
+		if processed, err := s.deps.DataExportService.ProcessPendingExports(ctx); err != nil {
+			s.deps.Logger.WithError(err).Error("Failed to process pending data exports")
+		} else if processed > 0 {
+			s.deps.Logger.WithField("exports", processed).Info("Processed pending data exports")
+		}
+
+		if deleted, err := s.deps.DataExportService.DeleteExpiredExports(ctx); err != nil {
+			s.deps.Logger.WithError(err).Error("Failed to delete expired data exports")
+		} else if deleted > 0 {
+			s.deps.Logger.WithField("exports", deleted).Info("Deleted expired data exports")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
 // Close closes all dependencies
 func (s *Server) Close() {
 	if s.deps != nil {
@@ -52,19 +52,6 @@ func (c *Cache) IsBlacklisted(ctx context.Context, jti string) (bool, error) {
 	return exists > 0, err
 }
 
-// Session operations
-func (c *Cache) SetSession(ctx context.Context, userID string, data interface{}, expiration time.Duration) error {
-	return c.Client.Set(ctx, fmt.Sprintf("session:%s", userID), data, expiration).Err()
-}
-
-func (c *Cache) GetSession(ctx context.Context, userID string) (string, error) {
-	return c.Client.Get(ctx, fmt.Sprintf("session:%s", userID)).Result()
-}
-
-func (c *Cache) DeleteSession(ctx context.Context, userID string) error {
-	return c.Client.Del(ctx, fmt.Sprintf("session:%s", userID)).Err()
-}
-
 // Feed caching
 func (c *Cache) SetUserFeed(ctx context.Context, userID string, feed interface{}, expiration time.Duration) error {
 	return c.Client.Set(ctx, fmt.Sprintf("feed:user:%s", userID), feed, expiration).Err()
@@ -95,3 +82,102 @@ func (c *Cache) CheckRateLimit(ctx context.Context, key string, limit int, windo
 
 	return count <= int64(limit), nil
 }
+
+// Login throttling. RecordLoginFailure counts a failed login against key and
+// returns the count so far; each failure keeps the count alive for window.
+func (c *Cache) RecordLoginFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	pipe := c.Client.Pipeline()
+	incr := pipe.Incr(ctx, fmt.Sprintf("auth:login_failures:%s", key))
+	pipe.Expire(ctx, fmt.Sprintf("auth:login_failures:%s", key), window)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return 0, err
+	}
+	return incr.Result()
+}
+
+func (c *Cache) ClearLoginFailures(ctx context.Context, key string) error {
+	return c.Client.Del(ctx, fmt.Sprintf("auth:login_failures:%s", key)).Err()
+}
+
+func (c *Cache) LockLogin(ctx context.Context, key string, duration time.Duration) error {
+	return c.Client.Set(ctx, fmt.Sprintf("auth:login_lock:%s", key), 1, duration).Err()
+}
+
+// LoginLockRemaining returns how long logins for key stay locked, or zero
+// if they are not locked
+func (c *Cache) LoginLockRemaining(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.Client.PTTL(ctx, fmt.Sprintf("auth:login_lock:%s", key)).Result()
+	if err != nil || ttl < 0 {
+		return 0, err
+	}
+	return ttl, nil
+}
+
+// MFA login challenges, keyed by the hash of the challenge token
+func (c *Cache) SetMFAChallenge(ctx context.Context, tokenHash, userID string, expiration time.Duration) error {
+	return c.Client.Set(ctx, fmt.Sprintf("auth:mfa:%s", tokenHash), userID, expiration).Err()
+}
+
+// GetMFAChallenge returns the user a challenge was issued to, or an empty
+// string if it does not exist or has expired
+func (c *Cache) GetMFAChallenge(ctx context.Context, tokenHash string) (string, error) {
+	userID, err := c.Client.Get(ctx, fmt.Sprintf("auth:mfa:%s", tokenHash)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return userID, err
+}
+
+// DeleteMFAChallenge removes a challenge. It reports false if the challenge
+// was already gone, so only one request can complete it.
+func (c *Cache) DeleteMFAChallenge(ctx context.Context, tokenHash string) (bool, error) {
+	deleted, err := c.Client.Del(ctx, fmt.Sprintf("auth:mfa:%s", tokenHash)).Result()
+	return deleted > 0, err
+}
+
+// OIDC sign-in state, keyed by the hash of the state parameter
+func (c *Cache) SetOIDCState(ctx context.Context, stateHash, value string, expiration time.Duration) error {
+	return c.Client.Set(ctx, fmt.Sprintf("auth:oidc:%s", stateHash), value, expiration).Err()
+}
+
+// TakeOIDCState returns and deletes a sign-in state, so it can be used only
+// once. It returns an empty string if the state does not exist or expired.
+func (c *Cache) TakeOIDCState(ctx context.Context, stateHash string) (string, error) {
+	value, err := c.Client.GetDel(ctx, fmt.Sprintf("auth:oidc:%s", stateHash)).Result()
+	if err == redis.Nil {
+		return "", nil
+	}
+	return value, err
+}
+
+// Band chat pub/sub
+func (c *Cache) PublishBandMessage(ctx context.Context, bandID string, payload []byte) error {
+	return c.Client.Publish(ctx, fmt.Sprintf("chat:band:%s", bandID), payload).Err()
+}
+
+// SubscribeBandMessages subscribes to a band's chat channel. Payloads are
+// delivered on the returned channel until the close function is called or
+// ctx is cancelled.
+func (c *Cache) SubscribeBandMessages(ctx context.Context, bandID string) (<-chan []byte, func() error, error) {
+	pubsub := c.Client.Subscribe(ctx, fmt.Sprintf("chat:band:%s", bandID))
+
+	// Wait for the subscription to be confirmed before returning
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, nil, err
+	}
+
+	messages := make(chan []byte)
+	go func() {
+		defer close(messages)
+		for msg := range pubsub.Channel() {
+			select {
+			case messages <- []byte(msg.Payload):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return messages, pubsub.Close, nil
+}
@@ -26,6 +26,7 @@ func TestLoad(t *testing.T) {
 				AWSSecretAccessKey: "",
 				S3BucketName:       "",
 				S3CDNURL:           "",
+				CommentMaxDepth:    3,
 				Port:               8080,
 				Environment:        "development",
 			},
@@ -41,6 +42,7 @@ func TestLoad(t *testing.T) {
 				"AWS_SECRET_ACCESS_KEY":  "custom-secret-key",
 				"S3_BUCKET_NAME":         "custom-bucket",
 				"S3_CDN_URL":             "https://custom-cdn.com",
+				"COMMENT_MAX_DEPTH":      "5",
 				"PORT":                   "3000",
 				"ENVIRONMENT":            "production",
 			},
@@ -53,6 +55,7 @@ func TestLoad(t *testing.T) {
 				AWSSecretAccessKey: "custom-secret-key",
 				S3BucketName:       "custom-bucket",
 				S3CDNURL:           "https://custom-cdn.com",
+				CommentMaxDepth:    5,
 				Port:               3000,
 				Environment:        "production",
 			},
@@ -74,6 +77,7 @@ func TestLoad(t *testing.T) {
 				AWSSecretAccessKey: "",
 				S3BucketName:       "",
 				S3CDNURL:           "",
+				CommentMaxDepth:    3,
 				Port:               9000,
 				Environment:        "staging",
 			},
@@ -115,6 +119,9 @@ func TestLoad(t *testing.T) {
 			if config.S3CDNURL != tt.expectedConfig.S3CDNURL {
 				t.Errorf("Expected S3CDNURL %s, got %s", tt.expectedConfig.S3CDNURL, config.S3CDNURL)
 			}
+			if config.CommentMaxDepth != tt.expectedConfig.CommentMaxDepth {
+				t.Errorf("Expected CommentMaxDepth %d, got %d", tt.expectedConfig.CommentMaxDepth, config.CommentMaxDepth)
+			}
 			if config.Port != tt.expectedConfig.Port {
 				t.Errorf("Expected Port %d, got %d", tt.expectedConfig.Port, config.Port)
 			}
@@ -278,6 +285,51 @@ func TestGetEnvAsInt(t *testing.T) {
 	}
 }
 
+func TestGetOIDCProviders(t *testing.T) {
+	envVars := map[string]string{
+		"OIDC_PROVIDERS":            "google, spotify,broken",
+		"OIDC_GOOGLE_ISSUER":        "https://accounts.google.com",
+		"OIDC_GOOGLE_CLIENT_ID":     "google-client",
+		"OIDC_GOOGLE_CLIENT_SECRET": "google-secret",
+		"OIDC_SPOTIFY_CLIENT_ID":    "spotify-client",
+		"OIDC_SPOTIFY_SCOPES":       "user-read-email,user-read-private",
+		"OIDC_SPOTIFY_AUTH_URL":     "https://accounts.spotify.com/authorize",
+		"OIDC_SPOTIFY_TOKEN_URL":    "https://accounts.spotify.com/api/token",
+		"OIDC_SPOTIFY_USERINFO_URL": "https://api.spotify.com/v1/me",
+		"OIDC_SPOTIFY_REDIRECT_URL": "https://musicapp.example.com/auth/spotify",
+		"OIDC_BROKEN_CLIENT_ID":     "no-issuer",
+	}
+	for key, value := range envVars {
+		os.Setenv(key, value)
+	}
+	defer func() {
+		for key := range envVars {
+			os.Unsetenv(key)
+		}
+	}()
+
+	providers := getOIDCProviders("http://localhost:3000/")
+	if len(providers) != 2 {
+		t.Fatalf("Expected 2 providers, got %d", len(providers))
+	}
+
+	google := providers[0]
+	if google.Name != "google" || google.Issuer != "https://accounts.google.com" || google.ClientSecret != "google-secret" {
+		t.Errorf("Unexpected google provider: %+v", google)
+	}
+	if google.RedirectURL != "http://localhost:3000/oauth/google/callback" {
+		t.Errorf("Expected default redirect URL, got %s", google.RedirectURL)
+	}
+
+	spotify := providers[1]
+	if spotify.RedirectURL != "https://musicapp.example.com/auth/spotify" {
+		t.Errorf("Expected configured redirect URL, got %s", spotify.RedirectURL)
+	}
+	if len(spotify.Scopes) != 2 || spotify.Scopes[1] != "user-read-private" {
+		t.Errorf("Unexpected scopes: %v", spotify.Scopes)
+	}
+}
+
 // Helper function to clear environment variables
 func clearEnvVars() {
 	envVars := []string{
@@ -289,6 +341,7 @@ func clearEnvVars() {
 		"AWS_SECRET_ACCESS_KEY",
 		"S3_BUCKET_NAME",
 		"S3_CDN_URL",
+		"COMMENT_MAX_DEPTH",
 		"PORT",
 		"ENVIRONMENT",
 	}
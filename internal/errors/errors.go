@@ -3,6 +3,7 @@ package errors
 import (
 	"fmt"
 	"net/http"
+	"time"
 )
 
 // ErrorCode represents a specific error type
@@ -52,6 +53,9 @@ type AppError struct {
 	Details    string    `json:"details,omitempty"`
 	HTTPStatus int       `json:"-"`
 	Cause      error     `json:"-"`
+
+	// RetryAfter tells rate-limited clients how long to wait, if known
+	RetryAfter time.Duration `json:"-"`
 }
 
 // Error implements the error interface
@@ -182,6 +186,12 @@ func NewValidationError(field, reason string) *AppError {
 	return NewWithDetails(ErrCodeValidationFailed, "Validation failed", fmt.Sprintf("Field '%s': %s", field, reason))
 }
 
+func NewRateLimited(message string, retryAfter time.Duration) *AppError {
+	err := New(ErrCodeRateLimited, message)
+	err.RetryAfter = retryAfter
+	return err
+}
+
 // IsAppError checks if an error is an AppError
 func IsAppError(err error) bool {
 	_, ok := err.(*AppError)
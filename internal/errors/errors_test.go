@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	apperrors "musicapp/internal/errors"
 )
@@ -164,6 +165,12 @@ func TestSpecificErrorFunctions(t *testing.T) {
 	if validationErr.Code != apperrors.ErrCodeValidationFailed {
 		t.Errorf("Expected code %s, got %s", apperrors.ErrCodeValidationFailed, validationErr.Code)
 	}
+	
+	// Test NewRateLimited
+	rateLimitedErr := apperrors.NewRateLimited("Slow down", time.Minute)
+	if rateLimitedErr.HTTPStatus != http.StatusTooManyRequests || rateLimitedErr.RetryAfter != time.Minute {
+		t.Errorf("Expected 429 with a one-minute retry, got %d and %v", rateLimitedErr.HTTPStatus, rateLimitedErr.RetryAfter)
+	}
 }
 
 func TestPredefinedErrors(t *testing.T) {
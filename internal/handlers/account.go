@@ -0,0 +1,355 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"musicapp/internal/middleware"
+	"musicapp/internal/service"
+	"musicapp/internal/validation"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+type AccountHandler struct {
+	accountService *service.AccountService
+	validator      *validation.Validator
+}
+
+func NewAccountHandler(accountService *service.AccountService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+		validator:      validation.New(),
+	}
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// The password confirmations below are left optional in validation so that
+// accounts without a password get the service's explanation instead
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password" validate:"required,password"`
+}
+
+type ChangeEmailRequest struct {
+	Email    string `json:"email" validate:"required,email"`
+	Password string `json:"password"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"`
+}
+
+type DeleteAccountResponse struct {
+	DeletionScheduledAt time.Time `json:"deletion_scheduled_at"`
+}
+
+// @Summary Request a password reset
+// @Description Email a single-use password reset link valid for one hour. The response is the same whether or not the email has an account.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body ForgotPasswordRequest true "Account email"
+// @Success 200 {object} map[string]interface{} "Reset link sent if the account exists"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Router /auth/forgot-password [post]
+func (h *AccountHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var req ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := h.validator.ValidateStruct(req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Validation failed")
+		return
+	}
+
+	if err := h.accountService.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		writeServiceError(w, err, "Failed to request password reset")
+		return
+	}
+
+	utils.WriteSuccess(w, "If an account exists for that email, a reset link has been sent", nil)
+}
+
+// @Summary Reset password
+// @Description Set a new password with a token from a reset email. Signs the account out of every session.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} map[string]interface{} "Password reset"
+// @Failure 400 {object} map[string]interface{} "Invalid or expired reset token"
+// @Router /auth/reset-password [post]
+func (h *AccountHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var req ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := h.validator.ValidateStruct(req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Validation failed")
+		return
+	}
+
+	if err := h.accountService.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		writeServiceError(w, err, "Failed to reset password")
+		return
+	}
+
+	utils.WriteSuccess(w, "Password reset successfully", nil)
+}
+
+// @Summary Verify email address
+// @Description Confirm the account's email address with a token from a verification email
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body VerifyEmailRequest true "Verification token"
+// @Success 200 {object} map[string]interface{} "Email verified"
+// @Failure 400 {object} map[string]interface{} "Invalid or expired verification token"
+// @Router /auth/verify-email [post]
+func (h *AccountHandler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	var req VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := h.validator.ValidateStruct(req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Validation failed")
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(r.Context(), req.Token); err != nil {
+		writeServiceError(w, err, "Failed to verify email")
+		return
+	}
+
+	utils.WriteSuccess(w, "Email verified successfully", nil)
+}
+
+// @Summary Resend verification email
+// @Description Email a new verification link to the current user, replacing any earlier one. Limited to 3 requests per hour.
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Verification email sent"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "Email is already verified"
+// @Failure 429 {object} map[string]interface{} "Too many verification emails requested"
+// @Router /auth/resend-verification [post]
+func (h *AccountHandler) ResendVerification(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.accountService.ResendVerificationEmail(r.Context(), userID); err != nil {
+		writeServiceError(w, err, "Failed to send verification email")
+		return
+	}
+
+	utils.WriteSuccess(w, "Verification email sent", nil)
+}
+
+// @Summary Change password
+// @Description Set a new password for the current user after confirming the current one. Signs out every other session; the current one stays signed in.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body ChangePasswordRequest true "Current and new password"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Password changed"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 401 {object} map[string]interface{} "Unauthorized or current password is incorrect"
+// @Failure 429 {object} map[string]interface{} "Too many password confirmations"
+// @Router /users/me/password [put]
+func (h *AccountHandler) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	var req ChangePasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := h.validator.ValidateStruct(req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Validation failed")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	sessionID, _ := middleware.GetSessionIDFromContext(r.Context())
+
+	if err := h.accountService.ChangePassword(r.Context(), userID, sessionID, req.CurrentPassword, req.NewPassword); err != nil {
+		writeServiceError(w, err, "Failed to change password")
+		return
+	}
+
+	utils.WriteSuccess(w, "Password changed successfully", nil)
+}
+
+// @Summary Change email address
+// @Description Move the current user to a new email address after confirming their password. The new address must be verified again, so a verification link is mailed to it.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body ChangeEmailRequest true "New email and current password"
+// @Security BearerAuth
+// @Success 200 {object} models.UserResponse "Email changed"
+// @Failure 400 {object} map[string]interface{} "Invalid request body or unchanged email"
+// @Failure 401 {object} map[string]interface{} "Unauthorized or password is incorrect"
+// @Failure 409 {object} map[string]interface{} "Email already in use"
+// @Failure 429 {object} map[string]interface{} "Too many password confirmations"
+// @Router /users/me/email [put]
+func (h *AccountHandler) ChangeEmail(w http.ResponseWriter, r *http.Request) {
+	var req ChangeEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := h.validator.ValidateStruct(req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Validation failed")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	user, err := h.accountService.ChangeEmail(r.Context(), userID, req.Email, req.Password)
+	if err != nil {
+		writeServiceError(w, err, "Failed to change email")
+		return
+	}
+
+	utils.WriteSuccess(w, "Email changed; check your inbox to verify the new address", user.ToResponse())
+}
+
+// @Summary Delete account
+// @Description Schedule the current user's account for permanent deletion in 30 days, after confirming their password. Every session and API key is revoked. Logging in and restoring the account before then cancels the deletion; afterwards the account, its content and its uploaded files are deleted.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body DeleteAccountRequest true "Current password"
+// @Security BearerAuth
+// @Success 200 {object} DeleteAccountResponse "Account scheduled for deletion"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 401 {object} map[string]interface{} "Unauthorized or password is incorrect"
+// @Failure 409 {object} map[string]interface{} "Account is already scheduled for deletion"
+// @Failure 429 {object} map[string]interface{} "Too many password confirmations"
+// @Router /users/me [delete]
+func (h *AccountHandler) DeleteAccount(w http.ResponseWriter, r *http.Request) {
+	var req DeleteAccountRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := h.validator.ValidateStruct(req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Validation failed")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	deleteAt, err := h.accountService.DeleteAccount(r.Context(), userID, req.Password)
+	if err != nil {
+		writeServiceError(w, err, "Failed to delete account")
+		return
+	}
+
+	utils.WriteSuccess(w, "Account scheduled for deletion", DeleteAccountResponse{DeletionScheduledAt: deleteAt})
+}
+
+// @Summary Restore account
+// @Description Cancel the scheduled deletion of the current user's account
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Account restored"
+// @Failure 400 {object} map[string]interface{} "Account is not scheduled for deletion"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /users/me/restore [post]
+func (h *AccountHandler) RestoreAccount(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.accountService.RestoreAccount(r.Context(), userID); err != nil {
+		writeServiceError(w, err, "Failed to restore account")
+		return
+	}
+
+	utils.WriteSuccess(w, "Account restored", nil)
+}
@@ -0,0 +1,425 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"musicapp/internal/middleware"
+	"musicapp/internal/models"
+	"musicapp/internal/pagination"
+	"musicapp/internal/service"
+	"musicapp/internal/validation"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type AdminHandler struct {
+	adminService *service.AdminService
+	validator    *validation.Validator
+}
+
+func NewAdminHandler(adminService *service.AdminService) *AdminHandler {
+	return &AdminHandler{
+		adminService: adminService,
+		validator:    validation.New(),
+	}
+}
+
+type SuspendUserRequest struct {
+	Reason string `json:"reason" validate:"required,min=1,max=500"`
+}
+
+type SetRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user moderator admin"`
+}
+
+// @Summary Search users
+// @Description Find users by part of their username, email address or display name, newest first. Includes account state such as suspensions. Moderators and admins only.
+// @Tags Admin
+// @Produce json
+// @Param q query string false "Text to look for in the username, email or display name"
+// @Param role query string false "Only users with this role (user, moderator or admin)"
+// @Param suspended query bool false "Only suspended (true) or active (false) accounts"
+// @Param limit query int false "Number of users to return (default: 20, max: 100)" default(20)
+// @Param cursor query string false "Opaque cursor from a previous next_cursor"
+// @Security BearerAuth
+// @Success 200 {array} models.AdminUserResponse "Users retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid filter or cursor"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Router /admin/users [get]
+func (h *AdminHandler) SearchUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.UserSearchFilter{
+		Query: query.Get("q"),
+		Role:  query.Get("role"),
+	}
+	if suspendedStr := query.Get("suspended"); suspendedStr != "" {
+		suspended, err := strconv.ParseBool(suspendedStr)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "Invalid suspended filter")
+			return
+		}
+		filter.Suspended = &suspended
+	}
+
+	limit, after, ok := adminPage(w, r)
+	if !ok {
+		return
+	}
+
+	users, err := h.adminService.SearchUsers(r.Context(), filter, limit, after)
+	if err != nil {
+		writeServiceError(w, err, "Failed to search users")
+		return
+	}
+
+	userResponses := make([]*models.AdminUserResponse, 0, len(users))
+	for _, user := range users {
+		userResponses = append(userResponses, user.ToAdminResponse())
+	}
+
+	utils.WritePaginated(w, "Users retrieved successfully", userResponses, pagination.Next(users, limit, userPosition))
+}
+
+// @Summary Get a user
+// @Description Get any user, including account state such as suspensions. Moderators and admins only.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Security BearerAuth
+// @Success 200 {object} models.AdminUserResponse "User retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Router /admin/users/{id} [get]
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	user, err := h.adminService.GetUser(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to get user")
+		return
+	}
+
+	utils.WriteSuccess(w, "User retrieved successfully", user.ToAdminResponse())
+}
+
+// @Summary Suspend a user
+// @Description Suspend an account with a lower role than yours and sign it out everywhere. It cannot sign in and its API keys stop working until the suspension is lifted. Moderators and admins only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body SuspendUserRequest true "Reason for the suspension"
+// @Security BearerAuth
+// @Success 200 {object} models.AdminUserResponse "Account suspended"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden, or the user's role is not lower than yours"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 409 {object} map[string]interface{} "Account is already suspended"
+// @Router /admin/users/{id}/suspend [post]
+func (h *AdminHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	var req SuspendUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := h.validator.ValidateStruct(req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Validation failed")
+		return
+	}
+
+	actor, ok := adminActor(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	user, err := h.adminService.SuspendUser(r.Context(), actor, userID, req.Reason)
+	if err != nil {
+		writeServiceError(w, err, "Failed to suspend account")
+		return
+	}
+
+	utils.WriteSuccess(w, "Account suspended", user.ToAdminResponse())
+}
+
+// @Summary Lift a suspension
+// @Description Let a suspended account with a lower role than yours sign in again. Moderators and admins only.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Security BearerAuth
+// @Success 200 {object} models.AdminUserResponse "Suspension lifted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden, or the user's role is not lower than yours"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 409 {object} map[string]interface{} "Account is not suspended"
+// @Router /admin/users/{id}/unsuspend [post]
+func (h *AdminHandler) UnsuspendUser(w http.ResponseWriter, r *http.Request) {
+	actor, ok := adminActor(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	user, err := h.adminService.UnsuspendUser(r.Context(), actor, userID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to lift suspension")
+		return
+	}
+
+	utils.WriteSuccess(w, "Suspension lifted", user.ToAdminResponse())
+}
+
+// @Summary Sign a user out everywhere
+// @Description Revoke every session of a user with a lower role than yours. Moderators and admins only.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "User ID"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "User signed out"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden, or the user's role is not lower than yours"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Router /admin/users/{id}/logout [post]
+func (h *AdminHandler) ForceLogout(w http.ResponseWriter, r *http.Request) {
+	actor, ok := adminActor(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.adminService.ForceLogout(r.Context(), actor, userID); err != nil {
+		writeServiceError(w, err, "Failed to sign user out")
+		return
+	}
+
+	utils.WriteSuccess(w, "User signed out", nil)
+}
+
+// @Summary Change a user's role
+// @Description Make a user a regular user, moderator or admin. You cannot change your own role; demoted users are signed out. Admins only.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body SetRoleRequest true "New role"
+// @Security BearerAuth
+// @Success 200 {object} models.AdminUserResponse "Role changed"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 422 {object} map[string]interface{} "Cannot change your own role"
+// @Router /admin/users/{id}/role [put]
+func (h *AdminHandler) SetRole(w http.ResponseWriter, r *http.Request) {
+	var req SetRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := h.validator.ValidateStruct(req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Validation failed")
+		return
+	}
+
+	actor, ok := adminActor(w, r)
+	if !ok {
+		return
+	}
+
+	userID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	user, err := h.adminService.SetRole(r.Context(), actor, userID, req.Role)
+	if err != nil {
+		writeServiceError(w, err, "Failed to change role")
+		return
+	}
+
+	utils.WriteSuccess(w, "Role changed", user.ToAdminResponse())
+}
+
+// @Summary Delete a post
+// @Description Delete any post. Its author and content are kept in the audit log. Moderators and admins only.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param reason query string false "Why the post was removed"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Post deleted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Post not found"
+// @Router /admin/posts/{id} [delete]
+func (h *AdminHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
+	actor, ok := adminActor(w, r)
+	if !ok {
+		return
+	}
+
+	postID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	if err := h.adminService.DeletePost(r.Context(), actor, postID, r.URL.Query().Get("reason")); err != nil {
+		writeServiceError(w, err, "Failed to delete post")
+		return
+	}
+
+	utils.WriteSuccess(w, "Post deleted", nil)
+}
+
+// @Summary Delete a comment
+// @Description Delete any comment along with its replies. Its author and content are kept in the audit log. Moderators and admins only.
+// @Tags Admin
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Param reason query string false "Why the comment was removed"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Comment deleted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Failure 404 {object} map[string]interface{} "Comment not found"
+// @Router /admin/comments/{id} [delete]
+func (h *AdminHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	actor, ok := adminActor(w, r)
+	if !ok {
+		return
+	}
+
+	commentID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid comment ID")
+		return
+	}
+
+	if err := h.adminService.DeleteComment(r.Context(), actor, commentID, r.URL.Query().Get("reason")); err != nil {
+		writeServiceError(w, err, "Failed to delete comment")
+		return
+	}
+
+	utils.WriteSuccess(w, "Comment deleted", nil)
+}
+
+// @Summary List the audit log
+// @Description List staff actions, newest first. Admins only.
+// @Tags Admin
+// @Produce json
+// @Param actor_id query string false "Only actions by this member of staff"
+// @Param target_id query string false "Only actions on this user, post or comment"
+// @Param action query string false "Only this action, e.g. user.suspended"
+// @Param limit query int false "Number of entries to return (default: 20, max: 100)" default(20)
+// @Param cursor query string false "Opaque cursor from a previous next_cursor"
+// @Security BearerAuth
+// @Success 200 {array} models.AuditLogEntry "Audit log retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid filter or cursor"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden"
+// @Router /admin/audit-log [get]
+func (h *AdminHandler) ListAuditLog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.AuditLogFilter{Action: query.Get("action")}
+	if actorID := query.Get("actor_id"); actorID != "" {
+		id, err := uuid.Parse(actorID)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "Invalid actor ID")
+			return
+		}
+		filter.ActorID = &id
+	}
+	if targetID := query.Get("target_id"); targetID != "" {
+		id, err := uuid.Parse(targetID)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "Invalid target ID")
+			return
+		}
+		filter.TargetID = &id
+	}
+
+	limit, after, ok := adminPage(w, r)
+	if !ok {
+		return
+	}
+
+	entries, err := h.adminService.ListAuditLog(r.Context(), filter, limit, after)
+	if err != nil {
+		writeServiceError(w, err, "Failed to list audit log")
+		return
+	}
+	if entries == nil {
+		entries = []*models.AuditLogEntry{}
+	}
+
+	utils.WritePaginated(w, "Audit log retrieved successfully", entries, pagination.Next(entries, limit, auditPosition))
+}
+
+// adminActor identifies the member of staff making the request. It writes
+// the error response itself when the request must not go on.
+func adminActor(w http.ResponseWriter, r *http.Request) (service.AdminActor, bool) {
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return service.AdminActor{}, false
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return service.AdminActor{}, false
+	}
+
+	role, _ := middleware.GetRoleFromContext(r.Context())
+	return service.AdminActor{ID: userID, Role: role, IPAddress: deviceInfo(r, "").IPAddress}, true
+}
+
+// adminPage reads the limit and cursor of a staff listing. It writes the
+// error response itself when they are invalid.
+func adminPage(w http.ResponseWriter, r *http.Request) (int, *pagination.Cursor, bool) {
+	limit := 20 // default
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = min(l, 100)
+	}
+
+	after, err := parseCursor(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid cursor")
+		return 0, nil, false
+	}
+
+	return limit, after, true
+}
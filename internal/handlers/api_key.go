@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"musicapp/internal/middleware"
+	"musicapp/internal/models"
+	"musicapp/internal/service"
+	"musicapp/internal/validation"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+	validator     *validation.Validator
+}
+
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{
+		apiKeyService: apiKeyService,
+		validator:     validation.New(),
+	}
+}
+
+// @Summary Create an API key
+// @Description Create a personal API key for scripts and integrations, sent as a Bearer token like an access token. It can only call endpoints covered by its scopes: profile:read, profile:write, posts:read, posts:write, bands:read, bands:write, follows:write, messages:read and messages:write. The token is only shown in this response.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.CreateAPIKeyRequest true "Key name, scopes and lifetime"
+// @Security BearerAuth
+// @Success 201 {object} models.CreatedAPIKeyResponse "API key created"
+// @Failure 400 {object} map[string]interface{} "Invalid request body or unknown scope"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 422 {object} map[string]interface{} "Too many active API keys"
+// @Router /users/me/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := h.validator.ValidateStruct(req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Validation failed")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	key, token, err := h.apiKeyService.Create(r.Context(), userID, &req)
+	if err != nil {
+		writeServiceError(w, err, "Failed to create API key")
+		return
+	}
+
+	utils.WriteCreated(w, "API key created; copy the token now, it will not be shown again", models.CreatedAPIKeyResponse{
+		APIKeyResponse: key.ToResponse(),
+		Token:          token,
+	})
+}
+
+// @Summary List API keys
+// @Description List the current user's API keys that have not been revoked, including expired ones
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.APIKeyResponse "API keys retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /users/me/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	keys, err := h.apiKeyService.List(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to list API keys")
+		return
+	}
+
+	keyResponses := make([]*models.APIKeyResponse, 0, len(keys))
+	for _, key := range keys {
+		keyResponses = append(keyResponses, key.ToResponse())
+	}
+
+	utils.WriteSuccess(w, "API keys retrieved successfully", keyResponses)
+}
+
+// @Summary Revoke an API key
+// @Description Stop one of the current user's API keys from working
+// @Tags Authentication
+// @Produce json
+// @Param id path string true "API key ID"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "API key revoked"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "API key not found"
+// @Router /users/me/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	keyID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid API key ID")
+		return
+	}
+
+	if err := h.apiKeyService.Revoke(r.Context(), userID, keyID); err != nil {
+		writeServiceError(w, err, "Failed to revoke API key")
+		return
+	}
+
+	utils.WriteSuccess(w, "API key revoked", nil)
+}
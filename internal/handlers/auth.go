@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
 
 	"musicapp/internal/errors"
@@ -10,17 +11,22 @@ import (
 	"musicapp/internal/service"
 	"musicapp/internal/validation"
 	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 )
 
 type AuthHandler struct {
-	authService *service.AuthService
-	validator   *validation.Validator
+	authService    *service.AuthService
+	accountService *service.AccountService
+	validator      *validation.Validator
 }
 
-func NewAuthHandler(authService *service.AuthService) *AuthHandler {
+func NewAuthHandler(authService *service.AuthService, accountService *service.AccountService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
-		validator:   validation.New(),
+		authService:    authService,
+		accountService: accountService,
+		validator:      validation.New(),
 	}
 }
 
@@ -31,20 +37,62 @@ type RegisterRequest struct {
 	Location *models.Location `json:"location,omitempty"`
 	City     *string          `json:"city,omitempty" validate:"omitempty,min=1,max=100"`
 	Country  *string          `json:"country,omitempty" validate:"omitempty,min=1,max=100"`
+
+	// Optional label shown in the session list, e.g. "Alex's iPhone"
+	DeviceName string `json:"device_name,omitempty" validate:"omitempty,max=100"`
 }
 
 type LoginRequest struct {
-	Email    string `json:"email" validate:"required,email"`
-	Password string `json:"password" validate:"required"`
+	Email      string `json:"email" validate:"required,email"`
+	Password   string `json:"password" validate:"required"`
+	DeviceName string `json:"device_name,omitempty" validate:"omitempty,max=100"`
+}
+
+type MFAVerifyRequest struct {
+	MFAToken   string `json:"mfa_token" validate:"required"`
+	Code       string `json:"code" validate:"required,max=32"`
+	DeviceName string `json:"device_name,omitempty" validate:"omitempty,max=100"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
 type AuthResponse struct {
-	Token string               `json:"token"`
-	User  *models.UserResponse `json:"user"`
+	Token        string               `json:"token"`
+	RefreshToken string               `json:"refresh_token"`
+	ExpiresIn    int                  `json:"expires_in"`
+	User         *models.UserResponse `json:"user"`
+}
+
+// MFAChallengeResponse is returned by login instead of tokens when the
+// account has two-factor authentication enabled
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// deviceInfo describes the client making the request for its session record
+func deviceInfo(r *http.Request, name string) models.DeviceInfo {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		ip = host
+	}
+	return models.DeviceInfo{Name: name, UserAgent: r.UserAgent(), IPAddress: ip}
+}
+
+func newAuthResponse(user *models.User, tokens *models.TokenPair) AuthResponse {
+	return AuthResponse{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+		User:         user.ToResponse(),
+	}
 }
 
 // @Summary Register a new user
-// @Description Create a new user account with profile information
+// @Description Create a new user account with profile information and email a link to verify the address
 // @Tags Authentication
 // @Accept json
 // @Produce json
@@ -77,7 +125,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Use service to register user
-	user, token, err := h.authService.RegisterUser(r.Context(), createReq)
+	user, tokens, err := h.authService.RegisterUser(r.Context(), createReq, deviceInfo(r, req.DeviceName))
 	if err != nil {
 		appErr := errors.GetAppError(err)
 		if appErr != nil {
@@ -88,23 +136,24 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := AuthResponse{
-		Token: token,
-		User:  user.ToResponse(),
-	}
+	// The account exists either way; if the email cannot be sent the user
+	// can ask for another one
+	_ = h.accountService.SendVerificationEmail(r.Context(), user)
 
-	utils.WriteCreated(w, "User registered successfully", response)
+	utils.WriteCreated(w, "User registered successfully", newAuthResponse(user, tokens))
 }
 
 // @Summary Login user
-// @Description Authenticate user and return JWT token
+// @Description Authenticate user and return a short-lived access token and a refresh token. Accounts with two-factor authentication get an mfa_token instead, to be exchanged at /auth/mfa/verify.
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param credentials body LoginRequest true "Login credentials"
 // @Success 200 {object} AuthResponse "Login successful"
+// @Success 200 {object} MFAChallengeResponse "Two-factor authentication required"
 // @Failure 400 {object} map[string]interface{} "Invalid request body"
 // @Failure 401 {object} map[string]interface{} "Invalid credentials"
+// @Failure 429 {object} map[string]interface{} "Too many failed attempts; see the Retry-After header"
 // @Router /auth/login [post]
 func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	var req LoginRequest
@@ -120,7 +169,7 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Use service to login user
-	user, token, err := h.authService.LoginUser(r.Context(), req.Email, req.Password)
+	user, tokens, challenge, err := h.authService.LoginUser(r.Context(), req.Email, req.Password, deviceInfo(r, req.DeviceName))
 	if err != nil {
 		appErr := errors.GetAppError(err)
 		if appErr != nil {
@@ -131,16 +180,89 @@ func (h *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	response := AuthResponse{
-		Token: token,
-		User:  user.ToResponse(),
+	if challenge != nil {
+		utils.WriteSuccess(w, "Two-factor authentication required", MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    challenge.Token,
+			ExpiresIn:   challenge.ExpiresIn,
+		})
+		return
+	}
+
+	utils.WriteSuccess(w, "Login successful", newAuthResponse(user, tokens))
+}
+
+// @Summary Complete two-factor login
+// @Description Exchange the mfa_token from login and a code from the authenticator app, or an unused recovery code, for an access token and refresh token
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body MFAVerifyRequest true "MFA token and code"
+// @Success 200 {object} AuthResponse "Login successful"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 401 {object} map[string]interface{} "Invalid code or expired MFA token"
+// @Router /auth/mfa/verify [post]
+func (h *AuthHandler) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var req MFAVerifyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := h.validator.ValidateStruct(req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Validation failed")
+		return
+	}
+
+	user, tokens, err := h.authService.VerifyMFA(r.Context(), req.MFAToken, req.Code, deviceInfo(r, req.DeviceName))
+	if err != nil {
+		writeServiceError(w, err, "Failed to verify code")
+		return
+	}
+
+	utils.WriteSuccess(w, "Login successful", newAuthResponse(user, tokens))
+}
+
+// @Summary Refresh tokens
+// @Description Exchange a refresh token for a new access token and refresh token. Each refresh token can be used once; reusing one revokes the whole session.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param refresh body RefreshRequest true "Refresh token"
+// @Success 200 {object} AuthResponse "Tokens refreshed"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 401 {object} map[string]interface{} "Invalid or expired refresh token"
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var req RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := h.validator.ValidateStruct(req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Validation failed")
+		return
+	}
+
+	user, tokens, err := h.authService.RefreshTokens(r.Context(), req.RefreshToken, deviceInfo(r, ""))
+	if err != nil {
+		appErr := errors.GetAppError(err)
+		if appErr != nil {
+			utils.WriteError(w, appErr.HTTPStatus, appErr.Message)
+			return
+		}
+		utils.WriteError(w, http.StatusUnauthorized, "Invalid refresh token")
+		return
 	}
 
-	utils.WriteSuccess(w, "Login successful", response)
+	utils.WriteSuccess(w, "Tokens refreshed", newAuthResponse(user, tokens))
 }
 
 // @Summary Logout user
-// @Description Logout user and invalidate JWT token
+// @Description Logout user, invalidate the JWT token and revoke the session's refresh tokens
 // @Tags Authentication
 // @Accept json
 // @Produce json
@@ -157,17 +279,125 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	userID, ok := middleware.GetUserIDFromContext(r.Context())
-	if !ok {
-		utils.WriteError(w, http.StatusUnauthorized, "Invalid token")
-		return
-	}
+	// Tokens issued before refresh tokens existed have no session
+	sessionID, _ := middleware.GetSessionIDFromContext(r.Context())
 
 	// Use service to logout user
-	if err := h.authService.LogoutUser(r.Context(), jti, userID); err != nil {
+	if err := h.authService.LogoutUser(r.Context(), jti, sessionID); err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, "Failed to logout")
 		return
 	}
 
 	utils.WriteSuccess(w, "Logout successful", nil)
 }
+
+// @Summary List sessions
+// @Description List the devices signed in to the current user's account, most recently used first
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.SessionResponse "Sessions retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /users/me/sessions [get]
+func (h *AuthHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	sessionID, _ := middleware.GetSessionIDFromContext(r.Context())
+
+	sessions, err := h.authService.ListSessions(r.Context(), userID, sessionID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to list sessions")
+		return
+	}
+
+	sessionResponses := make([]*models.SessionResponse, 0, len(sessions))
+	for _, session := range sessions {
+		sessionResponses = append(sessionResponses, session.ToResponse())
+	}
+
+	utils.WriteSuccess(w, "Sessions retrieved successfully", sessionResponses)
+}
+
+// @Summary Revoke a session
+// @Description Sign out one of the current user's devices. Its refresh token stops working and its access tokens are revoked.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param id path string true "Session ID"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Session revoked"
+// @Failure 400 {object} map[string]interface{} "Invalid session ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Session not found"
+// @Router /users/me/sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	sessionID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid session ID")
+		return
+	}
+
+	if err := h.authService.RevokeSession(r.Context(), userID, sessionID); err != nil {
+		writeServiceError(w, err, "Failed to revoke session")
+		return
+	}
+
+	utils.WriteSuccess(w, "Session revoked", nil)
+}
+
+// @Summary Revoke other sessions
+// @Description Sign out every device except the one making the request
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Other sessions revoked"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /users/me/sessions [delete]
+func (h *AuthHandler) RevokeOtherSessions(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+	sessionID, _ := middleware.GetSessionIDFromContext(r.Context())
+
+	revoked, err := h.authService.RevokeOtherSessions(r.Context(), userID, sessionID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to revoke sessions")
+		return
+	}
+
+	utils.WriteSuccess(w, "Other sessions revoked", map[string]int{"revoked": revoked})
+}
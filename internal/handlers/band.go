@@ -8,6 +8,7 @@ import (
 
 	"musicapp/internal/middleware"
 	"musicapp/internal/models"
+	"musicapp/internal/pagination"
 	"musicapp/internal/service"
 	"musicapp/pkg/utils"
 
@@ -59,7 +60,7 @@ func (h *BandHandler) CreateBand(w http.ResponseWriter, r *http.Request) {
 	// Use service to create band
 	band, err := h.bandService.CreateBand(r.Context(), userID, &req)
 	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		writeRequestError(w, err)
 		return
 	}
 
@@ -127,7 +128,7 @@ func (h *BandHandler) UpdateBand(w http.ResponseWriter, r *http.Request) {
 	// Use service to update band
 	band, err := h.bandService.UpdateBand(r.Context(), bandID, userID, &req)
 	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		writeRequestError(w, err)
 		return
 	}
 
@@ -159,45 +160,13 @@ func (h *BandHandler) DeleteBand(w http.ResponseWriter, r *http.Request) {
 
 	// Use service to delete band
 	if err := h.bandService.DeleteBand(r.Context(), bandID, userID); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		writeRequestError(w, err)
 		return
 	}
 
 	utils.WriteSuccess(w, "Band deleted successfully", nil)
 }
 
-func (h *BandHandler) JoinBand(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	bandIDStr := vars["id"]
-
-	bandID, err := uuid.Parse(bandIDStr)
-	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "Invalid band ID")
-		return
-	}
-
-	// Get current user
-	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
-	if !ok {
-		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
-		return
-	}
-
-	userID, err := uuid.Parse(currentUserID)
-	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
-		return
-	}
-
-	// Use service to join band
-	if err := h.bandService.JoinBand(r.Context(), bandID, userID); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	utils.WriteSuccess(w, "Successfully joined band", nil)
-}
-
 func (h *BandHandler) LeaveBand(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	bandIDStr := vars["id"]
@@ -223,7 +192,7 @@ func (h *BandHandler) LeaveBand(w http.ResponseWriter, r *http.Request) {
 
 	// Use service to leave band
 	if err := h.bandService.LeaveBand(r.Context(), bandID, userID); err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		writeRequestError(w, err)
 		return
 	}
 
@@ -300,7 +269,7 @@ func (h *BandHandler) GetNearbyBands(w http.ResponseWriter, r *http.Request) {
 	// Use service to get nearby bands
 	bands, err := h.bandService.GetNearbyBands(r.Context(), lat, lng, radius, limit)
 	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		writeRequestError(w, err)
 		return
 	}
 
@@ -360,7 +329,7 @@ func (h *BandHandler) UploadProfilePicture(w http.ResponseWriter, r *http.Reques
 	// Use service to upload profile picture
 	profilePictureURL, err := h.bandService.UploadProfilePicture(r.Context(), bandID, userID, handler.Filename, fileData)
 	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		writeRequestError(w, err)
 		return
 	}
 
@@ -378,6 +347,7 @@ func (h *BandHandler) UploadProfilePicture(w http.ResponseWriter, r *http.Reques
 // @Produce json
 // @Param limit query int false "Number of bands to return (default: 20, max: 100)" default(20)
 // @Param offset query int false "Number of bands to skip (default: 0)" default(0)
+// @Param cursor query string false "Opaque cursor from a previous next_cursor; takes precedence over offset"
 // @Success 200 {object} map[string]interface{} "Bands retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid pagination parameters"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
@@ -395,6 +365,9 @@ func (h *BandHandler) GetAllBands(w http.ResponseWriter, r *http.Request) {
 			limit = l
 		}
 	}
+	if limit > 100 {
+		limit = 100 // Matches the service cap so next_cursor is computed on full pages
+	}
 
 	if offsetStr != "" {
 		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
@@ -402,7 +375,16 @@ func (h *BandHandler) GetAllBands(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	bands, err := h.bandService.GetAllBands(r.Context(), limit, offset)
+	after, err := parseCursor(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	if after != nil {
+		offset = 0
+	}
+
+	bands, err := h.bandService.GetAllBands(r.Context(), limit, offset, after)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, "Failed to retrieve bands")
 		return
@@ -421,5 +403,175 @@ func (h *BandHandler) GetAllBands(w http.ResponseWriter, r *http.Request) {
 		"count":  len(bandResponses),
 	}
 
-	utils.WriteSuccess(w, "Bands retrieved successfully", response)
+	utils.WritePaginated(w, "Bands retrieved successfully", response, pagination.Next(bands, limit, bandPosition))
+}
+
+// @Summary Set member posting permission
+// @Description Allow or stop a band member from publishing posts as the band. Only band admins can change this.
+// @Tags Bands
+// @Accept json
+// @Produce json
+// @Param id path string true "Band ID"
+// @Param userId path string true "Member user ID"
+// @Param permission body models.UpdateMemberPostingRequest true "Posting permission"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Posting permission updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not a band admin"
+// @Failure 404 {object} map[string]interface{} "Band member not found"
+// @Router /bands/{id}/members/{userId}/posting [put]
+func (h *BandHandler) SetMemberPostingPermission(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bandIDStr := vars["id"]
+	memberIDStr := vars["userId"]
+
+	bandID, err := uuid.Parse(bandIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid band ID")
+		return
+	}
+
+	memberID, err := uuid.Parse(memberIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid member ID")
+		return
+	}
+
+	var req models.UpdateMemberPostingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Use service to update posting permission
+	if err := h.bandService.SetMemberPostingPermission(r.Context(), bandID, userID, memberID, req.CanPostAsBand); err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	utils.WriteSuccess(w, "Posting permission updated successfully", nil)
+}
+
+// @Summary Set member role
+// @Description Change a member's band role to admin or member, or hand the band over by setting owner. Members can only be managed by someone with a higher band role, so only the owner appoints admins.
+// @Tags Bands
+// @Accept json
+// @Produce json
+// @Param id path string true "Band ID"
+// @Param userId path string true "Member user ID"
+// @Param role body models.UpdateMemberRoleRequest true "Band role: owner, admin or member"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Band role updated successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not allowed to manage this member"
+// @Failure 404 {object} map[string]interface{} "Band member not found"
+// @Router /bands/{id}/members/{userId}/role [put]
+func (h *BandHandler) SetMemberRole(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bandIDStr := vars["id"]
+	memberIDStr := vars["userId"]
+
+	bandID, err := uuid.Parse(bandIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid band ID")
+		return
+	}
+
+	memberID, err := uuid.Parse(memberIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid member ID")
+		return
+	}
+
+	var req models.UpdateMemberRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.bandService.SetMemberRole(r.Context(), bandID, userID, memberID, req.Role); err != nil {
+		writeServiceError(w, err, "Failed to update band role")
+		return
+	}
+
+	utils.WriteSuccess(w, "Band role updated successfully", nil)
+}
+
+// @Summary Remove band member
+// @Description Remove a member from a band. Members can only be removed by someone with a higher band role.
+// @Tags Bands
+// @Produce json
+// @Param id path string true "Band ID"
+// @Param userId path string true "Member user ID"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Band member removed successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not allowed to manage this member"
+// @Failure 404 {object} map[string]interface{} "Band member not found"
+// @Router /bands/{id}/members/{userId} [delete]
+func (h *BandHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bandIDStr := vars["id"]
+	memberIDStr := vars["userId"]
+
+	bandID, err := uuid.Parse(bandIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid band ID")
+		return
+	}
+
+	memberID, err := uuid.Parse(memberIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid member ID")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.bandService.RemoveMember(r.Context(), bandID, userID, memberID); err != nil {
+		writeServiceError(w, err, "Failed to remove band member")
+		return
+	}
+
+	utils.WriteSuccess(w, "Band member removed successfully", nil)
 }
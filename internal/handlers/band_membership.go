@@ -0,0 +1,426 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"musicapp/internal/middleware"
+	"musicapp/internal/models"
+	"musicapp/internal/service"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type BandMembershipHandler struct {
+	membershipService *service.BandMembershipService
+}
+
+func NewBandMembershipHandler(membershipService *service.BandMembershipService) *BandMembershipHandler {
+	return &BandMembershipHandler{
+		membershipService: membershipService,
+	}
+}
+
+// @Summary Ask to join a band
+// @Description Ask to join a band, optionally with a message and as an admin rather than a member. The band's admins are emailed and can approve or reject the request within 30 days.
+// @Tags Bands
+// @Accept json
+// @Produce json
+// @Param id path string true "Band ID"
+// @Param request body models.JoinBandRequest false "Message and band role asked for"
+// @Security BearerAuth
+// @Success 201 {object} models.BandMembershipRequest "Join request sent"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Band not found"
+// @Failure 409 {object} map[string]interface{} "Already a member, invited or waiting for an answer"
+// @Router /bands/{id}/join [post]
+func (h *BandMembershipHandler) RequestToJoin(w http.ResponseWriter, r *http.Request) {
+	bandID, ok := pathUUID(w, r, "id", "Invalid band ID")
+	if !ok {
+		return
+	}
+
+	// The body is optional
+	var req models.JoinBandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userID, ok := currentUserUUID(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := h.membershipService.RequestToJoin(r.Context(), bandID, userID, &req)
+	if err != nil {
+		writeServiceError(w, err, "Failed to send join request")
+		return
+	}
+
+	utils.WriteCreated(w, "Join request sent; the band's admins will review it", request)
+}
+
+// @Summary Invite a user to a band
+// @Description Invite a user to join a band as a member, or as an admin if you own the band. The user is emailed and can accept or decline within 14 days.
+// @Tags Bands
+// @Accept json
+// @Produce json
+// @Param id path string true "Band ID"
+// @Param invitation body models.InviteToBandRequest true "User to invite, band role and message"
+// @Security BearerAuth
+// @Success 201 {object} models.BandMembershipRequest "Invitation sent"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not allowed to invite to this role"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Failure 409 {object} map[string]interface{} "Already a member, invited or asking to join"
+// @Router /bands/{id}/invitations [post]
+func (h *BandMembershipHandler) Invite(w http.ResponseWriter, r *http.Request) {
+	bandID, ok := pathUUID(w, r, "id", "Invalid band ID")
+	if !ok {
+		return
+	}
+
+	var req models.InviteToBandRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.UserID == uuid.Nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userID, ok := currentUserUUID(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := h.membershipService.Invite(r.Context(), bandID, userID, &req)
+	if err != nil {
+		writeServiceError(w, err, "Failed to send invitation")
+		return
+	}
+
+	utils.WriteCreated(w, "Invitation sent", request)
+}
+
+// @Summary List band invitations
+// @Description List the band's pending invitations, oldest first
+// @Tags Bands
+// @Produce json
+// @Param id path string true "Band ID"
+// @Security BearerAuth
+// @Success 200 {array} models.BandMembershipRequest "Invitations retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Only band admins can manage members"
+// @Router /bands/{id}/invitations [get]
+func (h *BandMembershipHandler) ListBandInvitations(w http.ResponseWriter, r *http.Request) {
+	h.listBandRequests(w, r, h.membershipService.ListBandInvitations, "Invitations retrieved successfully")
+}
+
+// @Summary Cancel a band invitation
+// @Description Withdraw a pending invitation
+// @Tags Bands
+// @Produce json
+// @Param id path string true "Band ID"
+// @Param requestId path string true "Invitation ID"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Invitation cancelled"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Only band admins can manage members"
+// @Failure 404 {object} map[string]interface{} "Invitation not found"
+// @Failure 409 {object} map[string]interface{} "Invitation is no longer pending"
+// @Router /bands/{id}/invitations/{requestId} [delete]
+func (h *BandMembershipHandler) CancelInvitation(w http.ResponseWriter, r *http.Request) {
+	bandID, ok := pathUUID(w, r, "id", "Invalid band ID")
+	if !ok {
+		return
+	}
+	requestID, ok := pathUUID(w, r, "requestId", "Invalid invitation ID")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserUUID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.membershipService.CancelInvitation(r.Context(), bandID, userID, requestID); err != nil {
+		writeServiceError(w, err, "Failed to cancel invitation")
+		return
+	}
+
+	utils.WriteSuccess(w, "Invitation cancelled", nil)
+}
+
+// @Summary List join requests
+// @Description List the band's pending join requests, oldest first
+// @Tags Bands
+// @Produce json
+// @Param id path string true "Band ID"
+// @Security BearerAuth
+// @Success 200 {array} models.BandMembershipRequest "Join requests retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Only band admins can manage members"
+// @Router /bands/{id}/join-requests [get]
+func (h *BandMembershipHandler) ListBandJoinRequests(w http.ResponseWriter, r *http.Request) {
+	h.listBandRequests(w, r, h.membershipService.ListBandJoinRequests, "Join requests retrieved successfully")
+}
+
+// @Summary Approve a join request
+// @Description Add the user who asked to the band, with the band role they asked for unless another is given. You can only approve users for a band role below yours.
+// @Tags Bands
+// @Accept json
+// @Produce json
+// @Param id path string true "Band ID"
+// @Param requestId path string true "Join request ID"
+// @Param approval body models.ApproveJoinRequest false "Band role to give instead"
+// @Security BearerAuth
+// @Success 200 {object} models.BandMembershipRequest "Join request approved"
+// @Failure 400 {object} map[string]interface{} "Invalid request"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not allowed to approve this role"
+// @Failure 404 {object} map[string]interface{} "Join request not found"
+// @Failure 409 {object} map[string]interface{} "Join request is no longer pending"
+// @Router /bands/{id}/join-requests/{requestId}/approve [post]
+func (h *BandMembershipHandler) ApproveJoinRequest(w http.ResponseWriter, r *http.Request) {
+	bandID, ok := pathUUID(w, r, "id", "Invalid band ID")
+	if !ok {
+		return
+	}
+	requestID, ok := pathUUID(w, r, "requestId", "Invalid join request ID")
+	if !ok {
+		return
+	}
+
+	// The body is optional
+	var req models.ApproveJoinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	userID, ok := currentUserUUID(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := h.membershipService.ApproveJoinRequest(r.Context(), bandID, userID, requestID, req.Role)
+	if err != nil {
+		writeServiceError(w, err, "Failed to approve join request")
+		return
+	}
+
+	utils.WriteSuccess(w, "Join request approved", request)
+}
+
+// @Summary Reject a join request
+// @Description Reject a pending join request; the user who asked is emailed
+// @Tags Bands
+// @Produce json
+// @Param id path string true "Band ID"
+// @Param requestId path string true "Join request ID"
+// @Security BearerAuth
+// @Success 200 {object} models.BandMembershipRequest "Join request rejected"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Only band admins can manage members"
+// @Failure 404 {object} map[string]interface{} "Join request not found"
+// @Failure 409 {object} map[string]interface{} "Join request is no longer pending"
+// @Router /bands/{id}/join-requests/{requestId}/reject [post]
+func (h *BandMembershipHandler) RejectJoinRequest(w http.ResponseWriter, r *http.Request) {
+	bandID, ok := pathUUID(w, r, "id", "Invalid band ID")
+	if !ok {
+		return
+	}
+	requestID, ok := pathUUID(w, r, "requestId", "Invalid join request ID")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserUUID(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := h.membershipService.RejectJoinRequest(r.Context(), bandID, userID, requestID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to reject join request")
+		return
+	}
+
+	utils.WriteSuccess(w, "Join request rejected", request)
+}
+
+// @Summary List my band invitations
+// @Description List the band invitations the current user has received, in any status, most recent first
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.BandMembershipRequest "Invitations retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /users/me/band-invitations [get]
+func (h *BandMembershipHandler) ListInvitations(w http.ResponseWriter, r *http.Request) {
+	h.listUserRequests(w, r, h.membershipService.ListInvitations, "Invitations retrieved successfully")
+}
+
+// @Summary Accept a band invitation
+// @Description Accept an invitation, joining the band with the band role offered
+// @Tags Users
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Security BearerAuth
+// @Success 200 {object} models.BandMembershipRequest "Invitation accepted"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Invitation not found"
+// @Failure 409 {object} map[string]interface{} "Invitation is no longer pending"
+// @Router /users/me/band-invitations/{id}/accept [post]
+func (h *BandMembershipHandler) AcceptInvitation(w http.ResponseWriter, r *http.Request) {
+	h.answerInvitation(w, r, h.membershipService.AcceptInvitation, "Invitation accepted", "Failed to accept invitation")
+}
+
+// @Summary Decline a band invitation
+// @Description Decline an invitation; whoever sent it is emailed
+// @Tags Users
+// @Produce json
+// @Param id path string true "Invitation ID"
+// @Security BearerAuth
+// @Success 200 {object} models.BandMembershipRequest "Invitation declined"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Invitation not found"
+// @Failure 409 {object} map[string]interface{} "Invitation is no longer pending"
+// @Router /users/me/band-invitations/{id}/decline [post]
+func (h *BandMembershipHandler) DeclineInvitation(w http.ResponseWriter, r *http.Request) {
+	h.answerInvitation(w, r, h.membershipService.DeclineInvitation, "Invitation declined", "Failed to decline invitation")
+}
+
+// @Summary List my join requests
+// @Description List the current user's join requests, in any status, most recent first
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.BandMembershipRequest "Join requests retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /users/me/join-requests [get]
+func (h *BandMembershipHandler) ListJoinRequests(w http.ResponseWriter, r *http.Request) {
+	h.listUserRequests(w, r, h.membershipService.ListJoinRequests, "Join requests retrieved successfully")
+}
+
+// @Summary Cancel a join request
+// @Description Withdraw one of the current user's pending join requests
+// @Tags Users
+// @Produce json
+// @Param id path string true "Join request ID"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Join request cancelled"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Join request not found"
+// @Failure 409 {object} map[string]interface{} "Join request is no longer pending"
+// @Router /users/me/join-requests/{id} [delete]
+func (h *BandMembershipHandler) CancelJoinRequest(w http.ResponseWriter, r *http.Request) {
+	requestID, ok := pathUUID(w, r, "id", "Invalid join request ID")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserUUID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.membershipService.CancelJoinRequest(r.Context(), userID, requestID); err != nil {
+		writeServiceError(w, err, "Failed to cancel join request")
+		return
+	}
+
+	utils.WriteSuccess(w, "Join request cancelled", nil)
+}
+
+func (h *BandMembershipHandler) listBandRequests(w http.ResponseWriter, r *http.Request, list func(ctx context.Context, bandID, actorID uuid.UUID) ([]*models.BandMembershipRequest, error), message string) {
+	bandID, ok := pathUUID(w, r, "id", "Invalid band ID")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserUUID(w, r)
+	if !ok {
+		return
+	}
+
+	requests, err := list(r.Context(), bandID, userID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to list requests")
+		return
+	}
+	if requests == nil {
+		requests = []*models.BandMembershipRequest{}
+	}
+
+	utils.WriteSuccess(w, message, requests)
+}
+
+func (h *BandMembershipHandler) listUserRequests(w http.ResponseWriter, r *http.Request, list func(ctx context.Context, userID uuid.UUID) ([]*models.BandMembershipRequest, error), message string) {
+	userID, ok := currentUserUUID(w, r)
+	if !ok {
+		return
+	}
+
+	requests, err := list(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to list requests")
+		return
+	}
+	if requests == nil {
+		requests = []*models.BandMembershipRequest{}
+	}
+
+	utils.WriteSuccess(w, message, requests)
+}
+
+func (h *BandMembershipHandler) answerInvitation(w http.ResponseWriter, r *http.Request, answer func(ctx context.Context, userID, requestID uuid.UUID) (*models.BandMembershipRequest, error), message, fallback string) {
+	requestID, ok := pathUUID(w, r, "id", "Invalid invitation ID")
+	if !ok {
+		return
+	}
+	userID, ok := currentUserUUID(w, r)
+	if !ok {
+		return
+	}
+
+	request, err := answer(r.Context(), userID, requestID)
+	if err != nil {
+		writeServiceError(w, err, fallback)
+		return
+	}
+
+	utils.WriteSuccess(w, message, request)
+}
+
+// currentUserUUID identifies the user making the request. It writes the
+// error response itself when the request must not go on.
+func currentUserUUID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return uuid.Nil, false
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return uuid.Nil, false
+	}
+
+	return userID, true
+}
+
+// pathUUID reads an ID from the URL path. It writes the error response
+// itself when the ID is invalid.
+func pathUUID(w http.ResponseWriter, r *http.Request, name, invalid string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(mux.Vars(r)[name])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, invalid)
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/middleware"
+	"musicapp/internal/models"
+	"musicapp/internal/service"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// Time allowed to write a frame to the client
+	chatWriteWait = 10 * time.Second
+
+	// Time allowed between pongs before the connection is considered dead
+	chatPongWait = 60 * time.Second
+
+	// Pings are sent at this interval; must be shorter than chatPongWait
+	chatPingPeriod = (chatPongWait * 9) / 10
+
+	// Largest frame accepted from a client
+	chatMaxFrameSize = 8192
+
+	// How often an open connection re-checks that its user is still a band
+	// member and still signed in
+	chatAccessCheckPeriod = time.Minute
+)
+
+// chatError is sent to a websocket client when one of its messages is rejected
+type chatError struct {
+	Error string `json:"error"`
+}
+
+type ChatHandler struct {
+	chatService    *service.ChatService
+	authMiddleware *middleware.AuthMiddleware
+	upgrader       websocket.Upgrader
+}
+
+func NewChatHandler(chatService *service.ChatService, authMiddleware *middleware.AuthMiddleware) *ChatHandler {
+	return &ChatHandler{
+		chatService:    chatService,
+		authMiddleware: authMiddleware,
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// Matches the CORS policy, which allows any origin; access is
+			// controlled by the bearer token and band membership
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// @Summary Get band chat history
+// @Description Get a page of a band's chat messages, newest first. Pass the ID of the oldest message already loaded as `before` to fetch earlier messages. Only band members can read the chat.
+// @Tags Chat
+// @Accept json
+// @Produce json
+// @Param id path string true "Band ID"
+// @Param before query string false "Return messages older than this message ID"
+// @Param limit query int false "Maximum number of messages to return" example(50)
+// @Security BearerAuth
+// @Success 200 {array} models.MessageResponse "Messages retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid band ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not a band member"
+// @Router /bands/{id}/messages [get]
+func (h *ChatHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bandIDStr := vars["id"]
+
+	bandID, err := uuid.Parse(bandIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid band ID")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Parse pagination parameters
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	var before *uuid.UUID
+	if beforeStr := r.URL.Query().Get("before"); beforeStr != "" {
+		beforeID, err := uuid.Parse(beforeStr)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "Invalid before cursor")
+			return
+		}
+		before = &beforeID
+	}
+
+	messages, err := h.chatService.GetMessages(r.Context(), bandID, userID, before, limit)
+	if err != nil {
+		writeServiceError(w, err, "Failed to get messages")
+		return
+	}
+
+	// Convert to response format
+	messageResponses := make([]*models.MessageResponse, 0, len(messages))
+	for _, message := range messages {
+		messageResponses = append(messageResponses, message.ToResponse())
+	}
+
+	utils.WriteSuccess(w, "Messages retrieved successfully", messageResponses)
+}
+
+// @Summary Join band chat
+// @Description Upgrade to a WebSocket connected to the band's chat room. Send `{"content": "..."}` frames to post; every message posted to the room is pushed as a MessageResponse. Browsers may pass the token as the `access_token` query parameter. Only band members can join; the connection is closed once the user leaves the band, their session is revoked or their access token expires.
+// @Tags Chat
+// @Param id path string true "Band ID"
+// @Param access_token query string false "JWT, for clients that cannot set the Authorization header"
+// @Security BearerAuth
+// @Success 101 {string} string "Switching Protocols"
+// @Failure 400 {object} map[string]interface{} "Invalid band ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not a band member"
+// @Router /bands/{id}/chat [get]
+func (h *ChatHandler) Chat(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bandIDStr := vars["id"]
+
+	bandID, err := uuid.Parse(bandIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid band ID")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	// Join before upgrading so non-members get a plain HTTP error
+	room, leave, err := h.chatService.JoinRoom(ctx, bandID, userID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to join chat")
+		return
+	}
+	defer leave()
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already written an error response
+		return
+	}
+	defer conn.Close()
+
+	rejections := make(chan chatError, 8)
+	go h.writePump(ctx, cancel, conn, bandID, userID, room, rejections)
+
+	conn.SetReadLimit(chatMaxFrameSize)
+	conn.SetReadDeadline(time.Now().Add(chatPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(chatPongWait))
+	})
+
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			// Client went away, the write pump closed the connection, or
+			// the read deadline passed without a pong
+			return
+		}
+
+		var req models.CreateMessageRequest
+		if err := json.Unmarshal(data, &req); err != nil {
+			select {
+			case rejections <- chatError{Error: "Invalid message format"}:
+			default:
+			}
+			continue
+		}
+
+		// Accepted messages reach this client through the room subscription
+		if _, err := h.chatService.SendMessage(ctx, bandID, userID, &req); err != nil {
+			message := "Failed to send message"
+			if appErr := errors.GetAppError(err); appErr != nil {
+				message = appErr.Message
+			}
+			select {
+			case rejections <- chatError{Error: message}:
+			default:
+			}
+		}
+	}
+}
+
+// writePump is the only goroutine that writes to conn. It forwards room
+// messages and rejections, keeps the connection alive with pings and closes
+// it once the user may no longer be in the room.
+func (h *ChatHandler) writePump(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn, bandID, userID uuid.UUID, room <-chan []byte, rejections <-chan chatError) {
+	ticker := time.NewTicker(chatPingPeriod)
+	accessTicker := time.NewTicker(chatAccessCheckPeriod)
+	defer func() {
+		ticker.Stop()
+		accessTicker.Stop()
+		cancel()
+		// Unblocks the read loop
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			conn.SetWriteDeadline(time.Now().Add(chatWriteWait))
+			conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+			return
+		case payload, ok := <-room:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(chatWriteWait))
+			if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+		case rejection := <-rejections:
+			conn.SetWriteDeadline(time.Now().Add(chatWriteWait))
+			if err := conn.WriteJSON(rejection); err != nil {
+				return
+			}
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(chatWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		case <-accessTicker.C:
+			if reason := h.accessLost(ctx, bandID, userID); reason != "" {
+				conn.SetWriteDeadline(time.Now().Add(chatWriteWait))
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, reason))
+				return
+			}
+		}
+	}
+}
+
+// accessLost re-checks the user's token and band membership, returning why
+// the connection must be closed, or "" while the user may stay. A check that
+// fails outright is retried at the next tick rather than dropping the user.
+func (h *ChatHandler) accessLost(ctx context.Context, bandID, userID uuid.UUID) string {
+	if authorized, err := h.authMiddleware.StillAuthorized(ctx); err == nil && !authorized {
+		return "Session expired or revoked"
+	}
+
+	err := h.chatService.CheckMembership(ctx, bandID, userID)
+	if appErr := errors.GetAppError(err); appErr != nil && appErr.Code == errors.ErrCodeForbidden {
+		return "No longer a band member"
+	}
+	return ""
+}
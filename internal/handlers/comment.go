@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"musicapp/internal/middleware"
+	"musicapp/internal/models"
+	"musicapp/internal/pagination"
+	"musicapp/internal/service"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type CommentHandler struct {
+	commentService *service.CommentService
+}
+
+func NewCommentHandler(commentService *service.CommentService) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+	}
+}
+
+// @Summary Comment on a post
+// @Description Add a comment to an existing post, or reply to a comment by setting parent_comment_id
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param comment body models.CreateCommentRequest true "Comment data"
+// @Security BearerAuth
+// @Success 201 {object} models.CommentResponse "Comment created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /posts/{id}/comments [post]
+func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postIDStr := vars["id"]
+
+	postID, err := uuid.Parse(postIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	var req models.CreateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Use service to create comment
+	comment, err := h.commentService.CreateComment(r.Context(), postID, userID, &req)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteCreated(w, "Comment created successfully", comment.ToResponse())
+}
+
+// @Summary Get post comments
+// @Description Get top-level comments on a post, oldest first, with the first replies of each thread embedded
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Post ID"
+// @Param limit query int false "Maximum number of comments to return" example(20)
+// @Param offset query int false "Number of comments to skip" example(0)
+// @Param cursor query string false "Opaque cursor from a previous next_cursor; takes precedence over offset"
+// @Success 200 {array} models.CommentResponse "Comments retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid post ID"
+// @Failure 404 {object} map[string]interface{} "Post not found"
+// @Router /posts/{id}/comments [get]
+func (h *CommentHandler) GetPostComments(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postIDStr := vars["id"]
+
+	postID, err := uuid.Parse(postIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	// Parse pagination parameters
+	limit := 20
+	offset := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	after, err := parseCursor(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	if after != nil {
+		offset = 0
+	}
+
+	// Get current user ID if available
+	var currentUserID *uuid.UUID
+	if userIDStr, ok := middleware.GetUserIDFromContext(r.Context()); ok {
+		if userID, err := uuid.Parse(userIDStr); err == nil {
+			currentUserID = &userID
+		}
+	}
+
+	comments, err := h.commentService.GetPostComments(r.Context(), postID, limit, offset, after, currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, "Post not found")
+		return
+	}
+
+	// Convert to response format
+	var commentResponses []*models.CommentResponse
+	for _, comment := range comments {
+		commentResponses = append(commentResponses, comment.ToResponse())
+	}
+
+	utils.WritePaginated(w, "Comments retrieved successfully", commentResponses, pagination.Next(comments, limit, commentPosition))
+}
+
+// @Summary Delete a comment
+// @Description Delete a comment. Allowed for the comment author and the owner of the post.
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Comment deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid comment ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /comments/{id} [delete]
+func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	commentIDStr := vars["id"]
+
+	commentID, err := uuid.Parse(commentIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid comment ID")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Use service to delete comment
+	if err := h.commentService.DeleteComment(r.Context(), commentID, userID); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteSuccess(w, "Comment deleted successfully", nil)
+}
+
+// @Summary Get comment replies
+// @Description Get a page of direct replies to a comment, oldest first
+// @Tags Comments
+// @Accept json
+// @Produce json
+// @Param id path string true "Comment ID"
+// @Param limit query int false "Maximum number of replies to return" example(20)
+// @Param offset query int false "Number of replies to skip" example(0)
+// @Param cursor query string false "Opaque cursor from a previous next_cursor; takes precedence over offset"
+// @Success 200 {array} models.CommentResponse "Replies retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid comment ID"
+// @Failure 404 {object} map[string]interface{} "Comment not found"
+// @Router /comments/{id}/replies [get]
+func (h *CommentHandler) GetCommentReplies(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	commentIDStr := vars["id"]
+
+	commentID, err := uuid.Parse(commentIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid comment ID")
+		return
+	}
+
+	// Parse pagination parameters
+	limit := 20
+	offset := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	after, err := parseCursor(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	if after != nil {
+		offset = 0
+	}
+
+	// Get current user ID if available
+	var currentUserID *uuid.UUID
+	if userIDStr, ok := middleware.GetUserIDFromContext(r.Context()); ok {
+		if userID, err := uuid.Parse(userIDStr); err == nil {
+			currentUserID = &userID
+		}
+	}
+
+	replies, err := h.commentService.GetCommentReplies(r.Context(), commentID, limit, offset, after, currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, "Comment not found")
+		return
+	}
+
+	// Convert to response format
+	var replyResponses []*models.CommentResponse
+	for _, reply := range replies {
+		replyResponses = append(replyResponses, reply.ToResponse())
+	}
+
+	utils.WritePaginated(w, "Replies retrieved successfully", replyResponses, pagination.Next(replies, limit, commentPosition))
+}
+
+func (h *CommentHandler) LikeComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	commentIDStr := vars["id"]
+
+	commentID, err := uuid.Parse(commentIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid comment ID")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Use service to like comment
+	if err := h.commentService.LikeComment(r.Context(), userID, commentID); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteSuccess(w, "Comment liked successfully", nil)
+}
+
+func (h *CommentHandler) UnlikeComment(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	commentIDStr := vars["id"]
+
+	commentID, err := uuid.Parse(commentIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid comment ID")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Use service to unlike comment
+	if err := h.commentService.UnlikeComment(r.Context(), userID, commentID); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteSuccess(w, "Comment unliked successfully", nil)
+}
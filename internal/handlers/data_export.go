@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"net/http"
+
+	"musicapp/internal/middleware"
+	"musicapp/internal/models"
+	"musicapp/internal/service"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type DataExportHandler struct {
+	dataExportService *service.DataExportService
+}
+
+func NewDataExportHandler(dataExportService *service.DataExportService) *DataExportHandler {
+	return &DataExportHandler{
+		dataExportService: dataExportService,
+	}
+}
+
+// @Summary Request a data export
+// @Description Start preparing a ZIP of the current user's personal data: profile, posts, comments, likes, reposts, follows, band memberships, band invitations and join requests, and uploaded media references, as JSON files. The export is built in the background; poll it until its status is completed. One export can be requested per day.
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} models.DataExportResponse "Data export requested"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "An export is already being prepared"
+// @Failure 429 {object} map[string]interface{} "An export was already requested today"
+// @Failure 503 {object} map[string]interface{} "Data exports are not available"
+// @Router /users/me/exports [post]
+func (h *DataExportHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	export, err := h.dataExportService.RequestExport(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to request data export")
+		return
+	}
+
+	utils.WriteAccepted(w, "Data export requested; it will be ready to download shortly", export.ToResponse())
+}
+
+// @Summary List data exports
+// @Description List the current user's 10 most recent data exports
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.DataExportResponse "Data exports retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /users/me/exports [get]
+func (h *DataExportHandler) ListExports(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	exports, err := h.dataExportService.ListExports(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to list data exports")
+		return
+	}
+
+	exportResponses := make([]*models.DataExportResponse, 0, len(exports))
+	for _, export := range exports {
+		exportResponses = append(exportResponses, export.ToResponse())
+	}
+
+	utils.WriteSuccess(w, "Data exports retrieved successfully", exportResponses)
+}
+
+// @Summary Get a data export
+// @Description Get one of the current user's data exports. Once it is completed, download_url links to the ZIP for 15 minutes; fetch the export again for a fresh link until the archive expires after 7 days.
+// @Tags Users
+// @Produce json
+// @Param id path string true "Data export ID"
+// @Security BearerAuth
+// @Success 200 {object} models.DataExportResponse "Data export retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Data export not found"
+// @Router /users/me/exports/{id} [get]
+func (h *DataExportHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	exportID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid data export ID")
+		return
+	}
+
+	export, downloadURL, err := h.dataExportService.GetExport(r.Context(), userID, exportID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to get data export")
+		return
+	}
+
+	response := export.ToResponse()
+	response.DownloadURL = downloadURL
+	utils.WriteSuccess(w, "Data export retrieved successfully", response)
+}
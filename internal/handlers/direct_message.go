@@ -0,0 +1,330 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"musicapp/internal/middleware"
+	"musicapp/internal/models"
+	"musicapp/internal/pagination"
+	"musicapp/internal/service"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type DirectMessageHandler struct {
+	directMessageService *service.DirectMessageService
+}
+
+func NewDirectMessageHandler(directMessageService *service.DirectMessageService) *DirectMessageHandler {
+	return &DirectMessageHandler{
+		directMessageService: directMessageService,
+	}
+}
+
+// @Summary Send a direct message
+// @Description Send a private message to a user. The conversation between the two users is created on the first message.
+// @Tags Direct Messages
+// @Accept json
+// @Produce json
+// @Param id path string true "Recipient user ID"
+// @Param message body models.SendDirectMessageRequest true "Message data"
+// @Security BearerAuth
+// @Success 201 {object} models.DirectMessageResponse "Message sent successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Recipient not found"
+// @Router /users/{id}/messages [post]
+func (h *DirectMessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	recipientIDStr := vars["id"]
+
+	recipientID, err := uuid.Parse(recipientIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	var req models.SendDirectMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	message, err := h.directMessageService.SendMessage(r.Context(), userID, recipientID, &req)
+	if err != nil {
+		writeServiceError(w, err, "Failed to send message")
+		return
+	}
+
+	utils.WriteCreated(w, "Message sent successfully", message.ToResponse())
+}
+
+// @Summary List conversations
+// @Description List the current user's conversations, most recently active first, with unread counts
+// @Tags Direct Messages
+// @Accept json
+// @Produce json
+// @Param limit query int false "Maximum number of conversations to return" example(20)
+// @Param offset query int false "Number of conversations to skip" example(0)
+// @Param cursor query string false "Opaque cursor from a previous next_cursor; takes precedence over offset"
+// @Security BearerAuth
+// @Success 200 {array} models.ConversationResponse "Conversations retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /users/me/conversations [get]
+func (h *DirectMessageHandler) GetConversations(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Parse pagination parameters
+	limit := 20
+	offset := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	after, err := parseCursor(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	if after != nil {
+		offset = 0
+	}
+
+	conversations, err := h.directMessageService.GetConversations(r.Context(), userID, limit, offset, after)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to get conversations")
+		return
+	}
+
+	// Convert to response format
+	conversationResponses := make([]*models.ConversationResponse, 0, len(conversations))
+	for _, conversation := range conversations {
+		conversationResponses = append(conversationResponses, conversation.ToResponse())
+	}
+
+	utils.WritePaginated(w, "Conversations retrieved successfully", conversationResponses, pagination.Next(conversations, limit, conversationPosition))
+}
+
+// @Summary Get unread message count
+// @Description Get the number of unread direct messages across all of the current user's conversations
+// @Tags Direct Messages
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Unread count retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /users/me/conversations/unread [get]
+func (h *DirectMessageHandler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	count, err := h.directMessageService.GetUnreadCount(r.Context(), userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to get unread count")
+		return
+	}
+
+	utils.WriteSuccess(w, "Unread count retrieved successfully", map[string]int{"unread_count": count})
+}
+
+// @Summary Get conversation messages
+// @Description Get a page of messages in a conversation, newest first. Pass the ID of the oldest message already loaded as `before` to fetch earlier messages.
+// @Tags Direct Messages
+// @Accept json
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Param before query string false "Return messages older than this message ID"
+// @Param limit query int false "Maximum number of messages to return" example(50)
+// @Security BearerAuth
+// @Success 200 {array} models.DirectMessageResponse "Messages retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid conversation ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Conversation not found"
+// @Router /users/me/conversations/{id}/messages [get]
+func (h *DirectMessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	conversationIDStr := vars["id"]
+
+	conversationID, err := uuid.Parse(conversationIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid conversation ID")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Parse pagination parameters
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+
+	var before *uuid.UUID
+	if beforeStr := r.URL.Query().Get("before"); beforeStr != "" {
+		beforeID, err := uuid.Parse(beforeStr)
+		if err != nil {
+			utils.WriteError(w, http.StatusBadRequest, "Invalid before cursor")
+			return
+		}
+		before = &beforeID
+	}
+
+	messages, err := h.directMessageService.GetMessages(r.Context(), conversationID, userID, before, limit)
+	if err != nil {
+		writeServiceError(w, err, "Failed to get messages")
+		return
+	}
+
+	// Convert to response format
+	messageResponses := make([]*models.DirectMessageResponse, 0, len(messages))
+	for _, message := range messages {
+		messageResponses = append(messageResponses, message.ToResponse())
+	}
+
+	utils.WriteSuccess(w, "Messages retrieved successfully", messageResponses)
+}
+
+// @Summary Mark conversation as read
+// @Description Move the current user's read marker in a conversation to now
+// @Tags Direct Messages
+// @Accept json
+// @Produce json
+// @Param id path string true "Conversation ID"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Conversation marked as read"
+// @Failure 400 {object} map[string]interface{} "Invalid conversation ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Conversation not found"
+// @Router /users/me/conversations/{id}/read [post]
+func (h *DirectMessageHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	conversationIDStr := vars["id"]
+
+	conversationID, err := uuid.Parse(conversationIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid conversation ID")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.directMessageService.MarkRead(r.Context(), conversationID, userID); err != nil {
+		writeServiceError(w, err, "Failed to mark conversation as read")
+		return
+	}
+
+	utils.WriteSuccess(w, "Conversation marked as read", nil)
+}
+
+// @Summary Delete a direct message
+// @Description Delete a direct message. Only the sender can delete it.
+// @Tags Direct Messages
+// @Accept json
+// @Produce json
+// @Param id path string true "Message ID"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Message deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid message ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not the sender"
+// @Failure 404 {object} map[string]interface{} "Message not found"
+// @Router /users/me/messages/{id} [delete]
+func (h *DirectMessageHandler) DeleteMessage(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	messageIDStr := vars["id"]
+
+	messageID, err := uuid.Parse(messageIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid message ID")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.directMessageService.DeleteMessage(r.Context(), messageID, userID); err != nil {
+		writeServiceError(w, err, "Failed to delete message")
+		return
+	}
+
+	utils.WriteSuccess(w, "Message deleted successfully", nil)
+}
@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+
+	"musicapp/internal/errors"
+	"musicapp/pkg/utils"
+)
+
+// writeServiceError writes an AppError with its own status and message, and
+// falls back to a 500 with the given message for any other error
+func writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	if appErr := errors.GetAppError(err); appErr != nil {
+		if appErr.RetryAfter > 0 {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+		}
+		utils.WriteError(w, appErr.HTTPStatus, appErr.Message)
+		return
+	}
+	utils.WriteError(w, http.StatusInternalServerError, fallback)
+}
+
+// writeRequestError writes an AppError with its own status and message, and
+// any other error as a 400 with its message, for services that still report
+// invalid input as plain errors
+func writeRequestError(w http.ResponseWriter, err error) {
+	if errors.GetAppError(err) != nil {
+		writeServiceError(w, err, "")
+		return
+	}
+	utils.WriteError(w, http.StatusBadRequest, err.Error())
+}
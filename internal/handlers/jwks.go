@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"musicapp/internal/middleware"
+	"musicapp/pkg/utils"
+)
+
+type JWKSHandler struct {
+	keys *middleware.KeySet
+}
+
+func NewJWKSHandler(keys *middleware.KeySet) *JWKSHandler {
+	return &JWKSHandler{keys: keys}
+}
+
+// @Summary JSON Web Key Set
+// @Description Public keys that verify access tokens, keyed by the kid token header. Empty while tokens are signed with HS256 only.
+// @Tags Authentication
+// @Produce json
+// @Success 200 {object} middleware.JWKS "Key set"
+// @Router /.well-known/jwks.json [get]
+func (h *JWKSHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	// Short cache so verifiers pick up rotated keys promptly
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	utils.WriteJSON(w, http.StatusOK, h.keys.JWKS())
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"musicapp/internal/middleware"
+	"musicapp/internal/models"
+	"musicapp/internal/service"
+	"musicapp/internal/validation"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+type MFAHandler struct {
+	mfaService *service.MFAService
+	validator  *validation.Validator
+}
+
+func NewMFAHandler(mfaService *service.MFAService) *MFAHandler {
+	return &MFAHandler{
+		mfaService: mfaService,
+		validator:  validation.New(),
+	}
+}
+
+type MFACodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+type DisableMFARequest struct {
+	Password string `json:"password"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+// @Summary Start TOTP enrollment
+// @Description Generate an authenticator secret and an otpauth:// provisioning URI to show as a QR code. Two-factor login starts once the secret is confirmed.
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.TOTPEnrollment "Enrollment started"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "Two-factor authentication is already enabled"
+// @Router /auth/mfa/totp [post]
+func (h *MFAHandler) EnrollTOTP(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	enrollment, err := h.mfaService.EnrollTOTP(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to start enrollment")
+		return
+	}
+
+	utils.WriteSuccess(w, "Scan the QR code with your authenticator app, then confirm with a code", enrollment)
+}
+
+// @Summary Confirm TOTP enrollment
+// @Description Turn on two-factor login with a code from the authenticator app. Returns one-time recovery codes, which are only shown once.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body MFACodeRequest true "Authenticator code"
+// @Security BearerAuth
+// @Success 200 {object} models.RecoveryCodesResponse "Two-factor authentication enabled"
+// @Failure 400 {object} map[string]interface{} "Enrollment not started"
+// @Failure 401 {object} map[string]interface{} "Invalid authentication code"
+// @Failure 429 {object} map[string]interface{} "Too many attempts"
+// @Router /auth/mfa/totp/confirm [post]
+func (h *MFAHandler) ConfirmTOTP(w http.ResponseWriter, r *http.Request) {
+	var req MFACodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := h.validator.ValidateStruct(req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Validation failed")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmTOTP(r.Context(), userID, req.Code)
+	if err != nil {
+		writeServiceError(w, err, "Failed to confirm enrollment")
+		return
+	}
+
+	utils.WriteSuccess(w, "Two-factor authentication enabled", models.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// @Summary Disable TOTP
+// @Description Turn off two-factor login. Requires the current password, unless the account has none, and a current authenticator code or an unused recovery code.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body DisableMFARequest true "Current password and authenticator or recovery code"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Two-factor authentication disabled"
+// @Failure 400 {object} map[string]interface{} "Two-factor authentication is not enabled"
+// @Failure 401 {object} map[string]interface{} "Incorrect password or invalid authentication code"
+// @Failure 429 {object} map[string]interface{} "Too many attempts"
+// @Router /auth/mfa/totp/disable [post]
+func (h *MFAHandler) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	var req DisableMFARequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := h.validator.ValidateStruct(req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Validation failed")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	if err := h.mfaService.DisableTOTP(r.Context(), userID, req.Password, req.Code); err != nil {
+		writeServiceError(w, err, "Failed to disable two-factor authentication")
+		return
+	}
+
+	utils.WriteSuccess(w, "Two-factor authentication disabled", nil)
+}
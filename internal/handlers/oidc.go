@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"musicapp/internal/middleware"
+	"musicapp/internal/models"
+	"musicapp/internal/service"
+	"musicapp/internal/validation"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type OIDCHandler struct {
+	oidcService *service.OIDCService
+	validator   *validation.Validator
+}
+
+func NewOIDCHandler(oidcService *service.OIDCService) *OIDCHandler {
+	return &OIDCHandler{
+		oidcService: oidcService,
+		validator:   validation.New(),
+	}
+}
+
+// OIDCCallbackRequest carries what the provider redirected back with
+type OIDCCallbackRequest struct {
+	Code       string `json:"code" validate:"required,max=2048"`
+	State      string `json:"state" validate:"required,max=256"`
+	DeviceName string `json:"device_name,omitempty" validate:"omitempty,max=100"`
+}
+
+// @Summary List identity providers
+// @Description List the external identity providers users can sign in with
+// @Tags Authentication
+// @Produce json
+// @Success 200 {array} string "Providers retrieved successfully"
+// @Router /auth/oidc/providers [get]
+func (h *OIDCHandler) ListProviders(w http.ResponseWriter, r *http.Request) {
+	utils.WriteSuccess(w, "Providers retrieved successfully", h.oidcService.Providers())
+}
+
+// @Summary Start provider sign-in
+// @Description Get the URL to send the user to for signing in at an identity provider. The provider redirects back to the configured redirect URL with a code and state for /auth/oidc/{provider}/callback.
+// @Tags Authentication
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Success 200 {object} models.OIDCAuthorization "Sign-in started"
+// @Failure 404 {object} map[string]interface{} "Unknown identity provider"
+// @Failure 503 {object} map[string]interface{} "Identity provider is unavailable"
+// @Router /auth/oidc/{provider}/authorize [post]
+func (h *OIDCHandler) Authorize(w http.ResponseWriter, r *http.Request) {
+	authorization, err := h.oidcService.StartLogin(r.Context(), mux.Vars(r)["provider"])
+	if err != nil {
+		writeServiceError(w, err, "Failed to start sign-in")
+		return
+	}
+
+	utils.WriteSuccess(w, "Sign-in started", authorization)
+}
+
+// @Summary Complete provider sign-in
+// @Description Exchange the code and state from the provider's redirect for an access token and refresh token. A provider account signing in for the first time creates a new user; if its email belongs to an existing user, that user must log in and link the provider instead. Accounts with two-factor authentication get an mfa_token, to be exchanged at /auth/mfa/verify.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param request body OIDCCallbackRequest true "Code and state from the provider"
+// @Success 200 {object} AuthResponse "Login successful"
+// @Success 200 {object} MFAChallengeResponse "Two-factor authentication required"
+// @Failure 400 {object} map[string]interface{} "Invalid or expired sign-in state"
+// @Failure 401 {object} map[string]interface{} "Sign-in with the identity provider failed"
+// @Failure 409 {object} map[string]interface{} "An account with this email already exists"
+// @Router /auth/oidc/{provider}/callback [post]
+func (h *OIDCHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	var req OIDCCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := h.validator.ValidateStruct(req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Validation failed")
+		return
+	}
+
+	user, tokens, challenge, err := h.oidcService.CompleteLogin(r.Context(), mux.Vars(r)["provider"], req.Code, req.State, deviceInfo(r, req.DeviceName))
+	if err != nil {
+		writeServiceError(w, err, "Failed to sign in")
+		return
+	}
+
+	if challenge != nil {
+		utils.WriteSuccess(w, "Two-factor authentication required", MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    challenge.Token,
+			ExpiresIn:   challenge.ExpiresIn,
+		})
+		return
+	}
+
+	utils.WriteSuccess(w, "Login successful", newAuthResponse(user, tokens))
+}
+
+// @Summary Start linking a provider
+// @Description Get the URL to send the current user to for linking their account at an identity provider. The provider redirects back with a code and state for /auth/oidc/{provider}/link.
+// @Tags Authentication
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Security BearerAuth
+// @Success 200 {object} models.OIDCAuthorization "Linking started"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Unknown identity provider"
+// @Failure 503 {object} map[string]interface{} "Identity provider is unavailable"
+// @Router /auth/oidc/{provider}/link/authorize [post]
+func (h *OIDCHandler) AuthorizeLink(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	authorization, err := h.oidcService.StartLink(r.Context(), mux.Vars(r)["provider"], userID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to start linking")
+		return
+	}
+
+	utils.WriteSuccess(w, "Linking started", authorization)
+}
+
+// @Summary Link a provider
+// @Description Link the provider account from the redirect to the current user, so they can sign in with it
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param provider path string true "Provider name"
+// @Param request body OIDCCallbackRequest true "Code and state from the provider"
+// @Security BearerAuth
+// @Success 201 {object} models.IdentityResponse "Provider linked"
+// @Failure 400 {object} map[string]interface{} "Invalid or expired sign-in state"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 409 {object} map[string]interface{} "Provider account already linked"
+// @Router /auth/oidc/{provider}/link [post]
+func (h *OIDCHandler) Link(w http.ResponseWriter, r *http.Request) {
+	var req OIDCCallbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Validate request
+	if err := h.validator.ValidateStruct(req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Validation failed")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	identity, err := h.oidcService.CompleteLink(r.Context(), mux.Vars(r)["provider"], req.Code, req.State, userID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to link provider")
+		return
+	}
+
+	utils.WriteCreated(w, "Provider linked", identity.ToResponse())
+}
+
+// @Summary List linked providers
+// @Description List the identity provider accounts linked to the current user
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} models.IdentityResponse "Identities retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /users/me/identities [get]
+func (h *OIDCHandler) ListIdentities(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	identities, err := h.oidcService.ListIdentities(r.Context(), userID)
+	if err != nil {
+		writeServiceError(w, err, "Failed to list identities")
+		return
+	}
+
+	identityResponses := make([]*models.IdentityResponse, 0, len(identities))
+	for _, identity := range identities {
+		identityResponses = append(identityResponses, identity.ToResponse())
+	}
+
+	utils.WriteSuccess(w, "Identities retrieved successfully", identityResponses)
+}
+
+// @Summary Unlink a provider
+// @Description Remove a linked identity provider account. Users without a password cannot remove their last one.
+// @Tags Authentication
+// @Produce json
+// @Param id path string true "Identity ID"
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Provider unlinked"
+// @Failure 400 {object} map[string]interface{} "Cannot unlink the only sign-in method"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Identity not found"
+// @Router /users/me/identities/{id} [delete]
+func (h *OIDCHandler) Unlink(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	identityID, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid identity ID")
+		return
+	}
+
+	if err := h.oidcService.Unlink(r.Context(), userID, identityID); err != nil {
+		writeServiceError(w, err, "Failed to unlink provider")
+		return
+	}
+
+	utils.WriteSuccess(w, "Provider unlinked", nil)
+}
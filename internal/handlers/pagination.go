@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+
+	"musicapp/internal/models"
+	"musicapp/internal/pagination"
+)
+
+// parseCursor reads the opaque keyset cursor from the "cursor" query
+// parameter. It returns nil when the client is not using cursors; offset
+// paging keeps working for those clients during the transition.
+func parseCursor(r *http.Request) (*pagination.Cursor, error) {
+	token := r.URL.Query().Get("cursor")
+	if token == "" {
+		return nil, nil
+	}
+	return pagination.Decode(token)
+}
+
+// postPosition is the keyset position of a post in post listings
+func postPosition(post *models.Post) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: post.CreatedAt, ID: post.ID}
+}
+
+// feedPosition is the keyset position of a personalized feed item: when it
+// was reposted into the feed, or when it was posted
+func feedPosition(post *models.Post) pagination.Cursor {
+	if post.RepostedAt != nil {
+		return pagination.Cursor{CreatedAt: *post.RepostedAt, ID: post.ID}
+	}
+	return postPosition(post)
+}
+
+// commentPosition is the keyset position of a comment in a thread
+func commentPosition(comment *models.Comment) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: comment.CreatedAt, ID: comment.ID}
+}
+
+// userPosition is the keyset position of a user in the user directory
+func userPosition(user *models.User) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: user.CreatedAt, ID: user.ID}
+}
+
+// followPosition is the keyset position of a user in a follower or
+// following list, which is ordered by when the follow happened
+func followPosition(user *models.User) pagination.Cursor {
+	if user.FollowedAt != nil {
+		return pagination.Cursor{CreatedAt: *user.FollowedAt, ID: user.ID}
+	}
+	return userPosition(user)
+}
+
+// bandPosition is the keyset position of a band in the band directory
+func bandPosition(band *models.Band) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: band.CreatedAt, ID: band.ID}
+}
+
+// conversationPosition is the keyset position of a conversation in the
+// inbox, which is ordered by latest activity
+func conversationPosition(conversation *models.Conversation) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: conversation.LastMessageAt, ID: conversation.ID}
+}
+
+// auditPosition is the keyset position of an entry in the audit log
+func auditPosition(entry *models.AuditLogEntry) pagination.Cursor {
+	return pagination.Cursor{CreatedAt: entry.CreatedAt, ID: entry.ID}
+}
@@ -9,6 +9,7 @@ import (
 
 	"musicapp/internal/middleware"
 	"musicapp/internal/models"
+	"musicapp/internal/pagination"
 	"musicapp/internal/service"
 	"musicapp/pkg/utils"
 
@@ -27,7 +28,7 @@ func NewPostHandler(postService *service.PostService) *PostHandler {
 }
 
 // @Summary Create a new post
-// @Description Create a new post (user or band post)
+// @Description Create a new post (user or band post). Set quoted_post_id to quote another post with your own commentary.
 // @Tags Posts
 // @Accept json
 // @Produce json
@@ -262,13 +263,46 @@ func (h *PostHandler) Repost(w http.ResponseWriter, r *http.Request) {
 	utils.WriteSuccess(w, "Post reposted successfully", nil)
 }
 
+func (h *PostHandler) Unrepost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	postIDStr := vars["id"]
+
+	postID, err := uuid.Parse(postIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid post ID")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Use service to remove repost
+	if err := h.postService.Unrepost(r.Context(), userID, postID); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	utils.WriteSuccess(w, "Repost removed successfully", nil)
+}
+
 // @Summary Get user feed
-// @Description Get personalized feed for the authenticated user
+// @Description Get personalized feed for the authenticated user. Posts reposted by followed users are merged in by repost time; a post reposted by several followees appears once, attributed to the latest reposter.
 // @Tags Posts
 // @Accept json
 // @Produce json
 // @Param limit query int false "Maximum number of posts to return" example(20)
 // @Param offset query int false "Number of posts to skip" example(0)
+// @Param cursor query string false "Opaque cursor from a previous next_cursor; takes precedence over offset"
 // @Security BearerAuth
 // @Success 200 {array} models.PostResponse "Feed retrieved successfully"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
@@ -301,8 +335,17 @@ func (h *PostHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	after, err := parseCursor(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	if after != nil {
+		offset = 0
+	}
+
 	// Use service to get feed
-	posts, err := h.postService.GetFeed(r.Context(), userID, limit, offset)
+	posts, err := h.postService.GetFeed(r.Context(), userID, limit, offset, after)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, "Failed to retrieve feed")
 		return
@@ -314,7 +357,7 @@ func (h *PostHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
 		postResponses = append(postResponses, post.ToResponse())
 	}
 
-	utils.WriteSuccess(w, "Feed retrieved successfully", postResponses)
+	utils.WritePaginated(w, "Feed retrieved successfully", postResponses, pagination.Next(posts, limit, feedPosition))
 }
 
 func (h *PostHandler) GetExploreFeed(w http.ResponseWriter, r *http.Request) {
@@ -332,8 +375,25 @@ func (h *PostHandler) GetExploreFeed(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	after, err := parseCursor(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	if after != nil {
+		offset = 0
+	}
+
+	// Get current user ID if available
+	var currentUserID *uuid.UUID
+	if userIDStr, ok := middleware.GetUserIDFromContext(r.Context()); ok {
+		if userID, err := uuid.Parse(userIDStr); err == nil {
+			currentUserID = &userID
+		}
+	}
+
 	// Use service to get explore feed
-	posts, err := h.postService.GetExploreFeed(r.Context(), limit, offset)
+	posts, err := h.postService.GetExploreFeed(r.Context(), limit, offset, after, currentUserID)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, "Failed to retrieve explore feed")
 		return
@@ -345,7 +405,7 @@ func (h *PostHandler) GetExploreFeed(w http.ResponseWriter, r *http.Request) {
 		postResponses = append(postResponses, post.ToResponse())
 	}
 
-	utils.WriteSuccess(w, "Explore feed retrieved successfully", postResponses)
+	utils.WritePaginated(w, "Explore feed retrieved successfully", postResponses, pagination.Next(posts, limit, postPosition))
 }
 
 // @Summary Upload media to post
@@ -457,6 +517,7 @@ func (h *PostHandler) UploadMedia(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param limit query int false "Number of posts to return (default: 20, max: 100)" default(20)
 // @Param offset query int false "Number of posts to skip (default: 0)" default(0)
+// @Param cursor query string false "Opaque cursor from a previous next_cursor; takes precedence over offset"
 // @Success 200 {object} map[string]interface{} "Posts retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid pagination parameters"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
@@ -474,6 +535,9 @@ func (h *PostHandler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
 			limit = l
 		}
 	}
+	if limit > 100 {
+		limit = 100 // Matches the service cap so next_cursor is computed on full pages
+	}
 
 	if offsetStr != "" {
 		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
@@ -481,7 +545,24 @@ func (h *PostHandler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	posts, err := h.postService.GetAllPosts(r.Context(), limit, offset)
+	after, err := parseCursor(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	if after != nil {
+		offset = 0
+	}
+
+	// Get current user ID if available
+	var currentUserID *uuid.UUID
+	if userIDStr, ok := middleware.GetUserIDFromContext(r.Context()); ok {
+		if userID, err := uuid.Parse(userIDStr); err == nil {
+			currentUserID = &userID
+		}
+	}
+
+	posts, err := h.postService.GetAllPosts(r.Context(), limit, offset, after, currentUserID)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, "Failed to retrieve posts")
 		return
@@ -500,5 +581,195 @@ func (h *PostHandler) GetAllPosts(w http.ResponseWriter, r *http.Request) {
 		"count":  len(postResponses),
 	}
 
-	utils.WriteSuccess(w, "Posts retrieved successfully", response)
+	utils.WritePaginated(w, "Posts retrieved successfully", response, pagination.Next(posts, limit, postPosition))
+}
+
+// @Summary Create a band post
+// @Description Publish a post as a band. Allowed for band admins and members designated to post as the band.
+// @Tags Posts
+// @Accept json
+// @Produce json
+// @Param id path string true "Band ID"
+// @Param post body models.CreatePostRequest true "Post creation data"
+// @Security BearerAuth
+// @Success 201 {object} models.PostResponse "Post created successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid request body"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Not allowed to post as the band"
+// @Router /bands/{id}/posts [post]
+func (h *PostHandler) CreateBandPost(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bandIDStr := vars["id"]
+
+	bandID, err := uuid.Parse(bandIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid band ID")
+		return
+	}
+
+	var req models.CreatePostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Use service to create band post
+	post, err := h.postService.CreateBandPost(r.Context(), bandID, userID, &req)
+	if err != nil {
+		writeRequestError(w, err)
+		return
+	}
+
+	utils.WriteCreated(w, "Post created successfully", post.ToResponse())
+}
+
+// @Summary Get band posts
+// @Description Get posts published as a band, newest first
+// @Tags Posts
+// @Accept json
+// @Produce json
+// @Param id path string true "Band ID"
+// @Param limit query int false "Maximum number of posts to return" example(20)
+// @Param offset query int false "Number of posts to skip" example(0)
+// @Param cursor query string false "Opaque cursor from a previous next_cursor; takes precedence over offset"
+// @Success 200 {array} models.PostResponse "Band posts retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid band ID"
+// @Failure 404 {object} map[string]interface{} "Band not found"
+// @Router /bands/{id}/posts [get]
+func (h *PostHandler) GetBandPosts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	bandIDStr := vars["id"]
+
+	bandID, err := uuid.Parse(bandIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid band ID")
+		return
+	}
+
+	// Parse pagination parameters
+	limit := 20
+	offset := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	after, err := parseCursor(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	if after != nil {
+		offset = 0
+	}
+
+	// Get current user ID if available
+	var currentUserID *uuid.UUID
+	if userIDStr, ok := middleware.GetUserIDFromContext(r.Context()); ok {
+		if userID, err := uuid.Parse(userIDStr); err == nil {
+			currentUserID = &userID
+		}
+	}
+
+	posts, err := h.postService.GetBandPosts(r.Context(), bandID, limit, offset, after, currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, "Band not found")
+		return
+	}
+
+	// Convert to response format
+	var postResponses []*models.PostResponse
+	for _, post := range posts {
+		postResponses = append(postResponses, post.ToResponse())
+	}
+
+	utils.WritePaginated(w, "Band posts retrieved successfully", postResponses, pagination.Next(posts, limit, postPosition))
+}
+
+// @Summary Get user posts
+// @Description Get posts written by a user, newest first
+// @Tags Posts
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param limit query int false "Maximum number of posts to return" example(20)
+// @Param offset query int false "Number of posts to skip" example(0)
+// @Param cursor query string false "Opaque cursor from a previous next_cursor; takes precedence over offset"
+// @Success 200 {array} models.PostResponse "User posts retrieved successfully"
+// @Failure 400 {object} map[string]interface{} "Invalid user ID"
+// @Failure 500 {object} map[string]interface{} "Failed to retrieve posts"
+// @Router /users/{id}/posts [get]
+func (h *PostHandler) GetUserPosts(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	userIDStr := vars["id"]
+
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	// Parse pagination parameters
+	limit := 20
+	offset := 0
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
+			limit = l
+		}
+	}
+	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	after, err := parseCursor(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	if after != nil {
+		offset = 0
+	}
+
+	// Get current user ID if available
+	var currentUserID *uuid.UUID
+	if userIDStr, ok := middleware.GetUserIDFromContext(r.Context()); ok {
+		if viewerID, err := uuid.Parse(userIDStr); err == nil {
+			currentUserID = &viewerID
+		}
+	}
+
+	posts, err := h.postService.GetUserPosts(r.Context(), userID, limit, offset, after, currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusInternalServerError, "Failed to retrieve posts")
+		return
+	}
+
+	// Convert to response format
+	var postResponses []*models.PostResponse
+	for _, post := range posts {
+		postResponses = append(postResponses, post.ToResponse())
+	}
+
+	utils.WritePaginated(w, "User posts retrieved successfully", postResponses, pagination.Next(posts, limit, postPosition))
 }
@@ -8,6 +8,7 @@ import (
 
 	"musicapp/internal/middleware"
 	"musicapp/internal/models"
+	"musicapp/internal/pagination"
 	"musicapp/internal/service"
 	"musicapp/pkg/utils"
 
@@ -28,7 +29,7 @@ func NewUserHandler(userService *service.UserService, bandService *service.BandS
 }
 
 // @Summary Get user profile
-// @Description Get user profile by ID
+// @Description Get user profile by ID. Accounts scheduled for deletion are hidden from everyone but their owner.
 // @Tags Users
 // @Accept json
 // @Produce json
@@ -47,7 +48,15 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userService.GetUser(r.Context(), userID)
+	// Get current user ID if available
+	var currentUserID *uuid.UUID
+	if currentUserIDStr, ok := middleware.GetUserIDFromContext(r.Context()); ok {
+		if id, err := uuid.Parse(currentUserIDStr); err == nil {
+			currentUserID = &id
+		}
+	}
+
+	user, err := h.userService.GetProfile(r.Context(), userID, currentUserID)
 	if err != nil {
 		utils.WriteError(w, http.StatusNotFound, "User not found")
 		return
@@ -56,6 +65,38 @@ func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	utils.WriteSuccess(w, "User retrieved successfully", user.ToResponse())
 }
 
+// @Summary Get current user
+// @Description Get the profile of the authenticated user
+// @Tags Users
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.CurrentUserResponse "User retrieved successfully"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "User not found"
+// @Router /users/me [get]
+func (h *UserHandler) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	// Get current user
+	currentUserID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		utils.WriteError(w, http.StatusUnauthorized, "Unauthorized")
+		return
+	}
+
+	userID, err := uuid.Parse(currentUserID)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		return
+	}
+
+	user, err := h.userService.GetUser(r.Context(), userID)
+	if err != nil {
+		utils.WriteError(w, http.StatusNotFound, "User not found")
+		return
+	}
+
+	utils.WriteSuccess(w, "User retrieved successfully", user.ToCurrentUserResponse())
+}
+
 // @Summary Update user profile
 // @Description Update user profile information
 // @Tags Users
@@ -106,7 +147,7 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	utils.WriteSuccess(w, "User updated successfully", user.ToResponse())
 }
 
-func (h *UserHandler) GetUserPosts(w http.ResponseWriter, r *http.Request) {
+func (h *UserHandler) GetFollowers(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	userIDStr := vars["id"]
 
@@ -130,46 +171,16 @@ func (h *UserHandler) GetUserPosts(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	posts, err := h.userService.GetUserPosts(r.Context(), userID, limit, offset)
-	if err != nil {
-		utils.WriteError(w, http.StatusInternalServerError, "Failed to retrieve posts")
-		return
-	}
-
-	// Convert to response format
-	var postResponses []*models.PostResponse
-	for _, post := range posts {
-		postResponses = append(postResponses, post.ToResponse())
-	}
-
-	utils.WriteSuccess(w, "User posts retrieved successfully", postResponses)
-}
-
-func (h *UserHandler) GetFollowers(w http.ResponseWriter, r *http.Request) {
-	vars := mux.Vars(r)
-	userIDStr := vars["id"]
-
-	userID, err := uuid.Parse(userIDStr)
+	after, err := parseCursor(r)
 	if err != nil {
-		utils.WriteError(w, http.StatusBadRequest, "Invalid user ID")
+		utils.WriteError(w, http.StatusBadRequest, "Invalid cursor")
 		return
 	}
-
-	// Parse pagination parameters
-	limit := 20
-	offset := 0
-	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
-		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 100 {
-			limit = l
-		}
-	}
-	if offsetStr := r.URL.Query().Get("offset"); offsetStr != "" {
-		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
-			offset = o
-		}
+	if after != nil {
+		offset = 0
 	}
 
-	followers, err := h.userService.GetFollowers(r.Context(), userID, limit, offset)
+	followers, err := h.userService.GetFollowers(r.Context(), userID, limit, offset, after)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, "Failed to retrieve followers")
 		return
@@ -181,7 +192,7 @@ func (h *UserHandler) GetFollowers(w http.ResponseWriter, r *http.Request) {
 		userResponses = append(userResponses, user.ToResponse())
 	}
 
-	utils.WriteSuccess(w, "Followers retrieved successfully", userResponses)
+	utils.WritePaginated(w, "Followers retrieved successfully", userResponses, pagination.Next(followers, limit, followPosition))
 }
 
 func (h *UserHandler) GetFollowing(w http.ResponseWriter, r *http.Request) {
@@ -208,7 +219,16 @@ func (h *UserHandler) GetFollowing(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	following, err := h.userService.GetFollowing(r.Context(), userID, limit, offset)
+	after, err := parseCursor(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	if after != nil {
+		offset = 0
+	}
+
+	following, err := h.userService.GetFollowing(r.Context(), userID, limit, offset, after)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, "Failed to retrieve following")
 		return
@@ -220,7 +240,7 @@ func (h *UserHandler) GetFollowing(w http.ResponseWriter, r *http.Request) {
 		userResponses = append(userResponses, user.ToResponse())
 	}
 
-	utils.WriteSuccess(w, "Following retrieved successfully", userResponses)
+	utils.WritePaginated(w, "Following retrieved successfully", userResponses, pagination.Next(following, limit, followPosition))
 }
 
 // @Summary Get nearby users
@@ -394,6 +414,7 @@ func (h *UserHandler) GetUserBands(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param limit query int false "Number of users to return (default: 20, max: 100)" default(20)
 // @Param offset query int false "Number of users to skip (default: 0)" default(0)
+// @Param cursor query string false "Opaque cursor from a previous next_cursor; takes precedence over offset"
 // @Success 200 {object} map[string]interface{} "Users retrieved successfully"
 // @Failure 400 {object} map[string]interface{} "Invalid pagination parameters"
 // @Failure 500 {object} map[string]interface{} "Internal server error"
@@ -411,6 +432,9 @@ func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 			limit = l
 		}
 	}
+	if limit > 100 {
+		limit = 100 // Matches the service cap so next_cursor is computed on full pages
+	}
 
 	if offsetStr != "" {
 		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
@@ -418,7 +442,16 @@ func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	users, err := h.userService.GetAllUsers(r.Context(), limit, offset)
+	after, err := parseCursor(r)
+	if err != nil {
+		utils.WriteError(w, http.StatusBadRequest, "Invalid cursor")
+		return
+	}
+	if after != nil {
+		offset = 0
+	}
+
+	users, err := h.userService.GetAllUsers(r.Context(), limit, offset, after)
 	if err != nil {
 		utils.WriteError(w, http.StatusInternalServerError, "Failed to retrieve users")
 		return
@@ -431,5 +464,5 @@ func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 		"count":  len(users),
 	}
 
-	utils.WriteSuccess(w, "Users retrieved successfully", response)
+	utils.WritePaginated(w, "Users retrieved successfully", response, pagination.Next(users, limit, userPosition))
 }
@@ -7,6 +7,7 @@ import (
 
 	"musicapp/internal/middleware"
 	"musicapp/internal/models"
+	"musicapp/internal/pagination"
 	"musicapp/internal/storage"
 
 	"github.com/google/uuid"
@@ -14,8 +15,6 @@ import (
 
 // Cache defines the interface for caching operations
 type Cache interface {
-	SetSession(ctx context.Context, userID string, data interface{}, expiration time.Duration) error
-	DeleteSession(ctx context.Context, userID string) error
 	IsBlacklisted(ctx context.Context, jti string) (bool, error)
 	AddToBlacklist(ctx context.Context, jti string, expiration time.Duration) error
 }
@@ -26,6 +25,7 @@ type UserRepository interface {
 	GetByUsername(ctx context.Context, username string) (*models.User, error)
 	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
 	Create(ctx context.Context, user *models.User) error
+	UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error
 }
 
 // UserRepositoryExtended extends UserRepository with additional operations
@@ -33,14 +33,45 @@ type UserRepositoryExtended interface {
 	UserRepository
 	Update(ctx context.Context, user *models.User) error
 	GetNearby(ctx context.Context, lat, lng float64, radiusKm, limit int) ([]*models.User, error)
-	GetFollowers(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.User, error)
-	GetFollowing(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.User, error)
-	GetAll(ctx context.Context, limit, offset int) ([]*models.User, error)
+	GetFollowers(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.User, error)
+	GetFollowing(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.User, error)
+	GetAll(ctx context.Context, limit, offset int, after *pagination.Cursor) ([]*models.User, error)
+}
+
+// RefreshTokenRepository defines the interface for refresh token storage
+type RefreshTokenRepository interface {
+	Create(ctx context.Context, token *models.RefreshToken) error
+	GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error)
+	MarkUsed(ctx context.Context, id uuid.UUID) (bool, error)
+	RevokeFamily(ctx context.Context, familyID uuid.UUID) error
+}
+
+// SessionRepository defines the interface for signed-in device sessions
+type SessionRepository interface {
+	Create(ctx context.Context, session *models.Session) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error)
+	GetActiveByUserID(ctx context.Context, userID uuid.UUID, seenSince time.Time) ([]*models.Session, error)
+	Touch(ctx context.Context, id uuid.UUID, ipAddress *string) error
+	Revoke(ctx context.Context, id uuid.UUID) error
+}
+
+// MFAVerifier defines the second-factor checks used during login
+type MFAVerifier interface {
+	IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error)
+	StartChallenge(ctx context.Context, userID uuid.UUID) (*models.MFAChallenge, error)
+	CompleteChallenge(ctx context.Context, token, code string) (uuid.UUID, error)
+}
+
+// LoginThrottle defines the brute-force checks around password logins
+type LoginThrottle interface {
+	Check(ctx context.Context, email, ipAddress string) error
+	RecordFailure(ctx context.Context, email, ipAddress string, user *models.User) error
+	RecordSuccess(ctx context.Context, email string) error
 }
 
 // AuthMiddleware defines the interface for authentication operations
 type AuthMiddleware interface {
-	GenerateToken(userID, username string) (string, error)
+	GenerateToken(userID, username, role, sessionID string) (string, error)
 	ValidateToken(tokenString string) (*middleware.Claims, error)
 }
 
@@ -0,0 +1,46 @@
+// Package mail sends transactional email such as password reset links.
+// Services depend on the Mailer interface; production uses SMTPMailer and
+// development and tests use FileMailer.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Message is a plain-text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers messages
+type Mailer interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// format renders the message as an RFC 5322 email from the given sender.
+// Header values are rejected if they contain line breaks, which would let
+// a caller inject extra headers.
+func (m *Message) format(from string, date time.Time) ([]byte, error) {
+	for _, value := range []string{from, m.To, m.Subject} {
+		if strings.ContainsAny(value, "\r\n") {
+			return nil, fmt.Errorf("invalid mail header value %q", value)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", m.To)
+	fmt.Fprintf(&buf, "Subject: %s\r\n", m.Subject)
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("\r\n")
+	buf.WriteString(strings.ReplaceAll(m.Body, "\n", "\r\n"))
+	return buf.Bytes(), nil
+}
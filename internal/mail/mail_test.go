@@ -0,0 +1,60 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMessage_Format(t *testing.T) {
+	msg := &Message{To: "fan@example.com", Subject: "Reset your password", Body: "Line one\nLine two"}
+
+	data, err := msg.format("MusicApp <no-reply@example.com>", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	got := string(data)
+	for _, want := range []string{
+		"From: MusicApp <no-reply@example.com>\r\n",
+		"To: fan@example.com\r\n",
+		"Subject: Reset your password\r\n",
+		"\r\n\r\nLine one\r\nLine two",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected message to contain %q, got %q", want, got)
+		}
+	}
+}
+
+func TestMessage_FormatRejectsHeaderInjection(t *testing.T) {
+	msg := &Message{To: "fan@example.com\r\nBcc: everyone@example.com", Subject: "Hi"}
+
+	if _, err := msg.format("no-reply@example.com", time.Now()); err == nil {
+		t.Error("Expected header injection to be rejected")
+	}
+}
+
+func TestFileMailer_Send(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "mail")
+	mailer, err := NewFileMailer(dir, "no-reply@example.com")
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if err := mailer.Send(context.Background(), &Message{To: "fan@example.com", Subject: "Hello", Body: "World"}); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 message file, got %d", len(files))
+	}
+	data, _ := os.ReadFile(files[0])
+	if !strings.Contains(string(data), "Subject: Hello") || !strings.Contains(string(data), "World") {
+		t.Errorf("Unexpected message contents: %q", data)
+	}
+}
@@ -0,0 +1,58 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPMailer sends mail through an SMTP relay
+type SMTPMailer struct {
+	addr string
+	auth smtp.Auth
+	from string
+}
+
+// NewSMTPMailer creates a mailer for the given relay. Authentication is
+// skipped when username is empty.
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	var auth smtp.Auth
+	if username != "" {
+		auth = smtp.PlainAuth("", username, password, host)
+	}
+
+	return &SMTPMailer{
+		addr: host + ":" + strconv.Itoa(port),
+		auth: auth,
+		from: from,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := msg.format(m.from, time.Now())
+	if err != nil {
+		return err
+	}
+
+	// The envelope needs bare addresses, while headers may carry display names
+	sender, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+	recipient, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient address: %w", err)
+	}
+
+	if err := smtp.SendMail(m.addr, m.auth, sender.Address, []string{recipient.Address}, data); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
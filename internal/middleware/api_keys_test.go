@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type mockAPIKeyVerifier struct {
+	keys map[string]*APIKey
+	err  error
+}
+
+func (m *mockAPIKeyVerifier) VerifyAPIKey(ctx context.Context, token string) (*APIKey, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return m.keys[token], nil
+}
+
+func TestRequireScope(t *testing.T) {
+	const apiKey = APIKeyPrefix + "posting-script"
+	verifier := &mockAPIKeyVerifier{keys: map[string]*APIKey{
+		apiKey: {ID: "key-1", UserID: "user123", Username: "testuser", Scopes: []string{ScopePostsWrite}},
+	}}
+
+	middleware := NewAuthMiddleware([]byte("test-secret-key"), nil)
+	middleware.SetAPIKeyVerifier(verifier)
+	jwtToken, err := middleware.GenerateToken("user123", "testuser", RoleUser, "")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		token        string
+		scope        string // empty for RequireAuth
+		expectStatus int
+	}{
+		{name: "API key with the scope", token: apiKey, scope: ScopePostsWrite, expectStatus: http.StatusOK},
+		{name: "API key without the scope", token: apiKey, scope: ScopeProfileWrite, expectStatus: http.StatusForbidden},
+		{name: "API key on a route without scope", token: apiKey, expectStatus: http.StatusForbidden},
+		{name: "unknown API key", token: APIKeyPrefix + "unknown", scope: ScopePostsWrite, expectStatus: http.StatusUnauthorized},
+		{name: "JWT on a scoped route", token: jwtToken, scope: ScopeProfileWrite, expectStatus: http.StatusOK},
+		{name: "JWT on a route without scope", token: jwtToken, expectStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if userID, _ := GetUserIDFromContext(r.Context()); userID != "user123" {
+					t.Errorf("Expected userID 'user123', got '%s'", userID)
+				}
+				keyID, isAPIKey := GetAPIKeyIDFromContext(r.Context())
+				if isAPIKey != (tt.token == apiKey) || (isAPIKey && keyID != "key-1") {
+					t.Errorf("Unexpected API key in context: %q", keyID)
+				}
+				w.WriteHeader(http.StatusOK)
+			})
+
+			var protected http.Handler
+			if tt.scope == "" {
+				protected = middleware.RequireAuth(handler)
+			} else {
+				protected = middleware.RequireScope(tt.scope, handler)
+			}
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rr := httptest.NewRecorder()
+			protected.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectStatus, rr.Code)
+			}
+		})
+	}
+}
+
+func TestRequireScope_Verifier(t *testing.T) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	request := func() *http.Request {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", "Bearer "+APIKeyPrefix+"key")
+		return req
+	}
+
+	t.Run("API keys rejected without a verifier", func(t *testing.T) {
+		middleware := NewAuthMiddleware([]byte("test-secret-key"), nil)
+		rr := httptest.NewRecorder()
+		middleware.RequireScope(ScopePostsRead, handler).ServeHTTP(rr, request())
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status %d, got %d", http.StatusUnauthorized, rr.Code)
+		}
+	})
+
+	t.Run("verifier failure", func(t *testing.T) {
+		middleware := NewAuthMiddleware([]byte("test-secret-key"), nil)
+		middleware.SetAPIKeyVerifier(&mockAPIKeyVerifier{err: fmt.Errorf("database down")})
+		rr := httptest.NewRecorder()
+		middleware.RequireScope(ScopePostsRead, handler).ServeHTTP(rr, request())
+		if rr.Code != http.StatusInternalServerError {
+			t.Errorf("Expected status %d, got %d", http.StatusInternalServerError, rr.Code)
+		}
+	})
+}
@@ -10,29 +10,76 @@ import (
 	"musicapp/internal/cache"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
+// AccessTokenTTL is how long an access token is valid. Access tokens are
+// short-lived; clients renew them with a refresh token.
+const AccessTokenTTL = 15 * time.Minute
+
 type Claims struct {
-	UserID   string `json:"user_id"`
-	Username string `json:"username"`
+	UserID    string `json:"user_id"`
+	Username  string `json:"username"`
+	SessionID string `json:"sid,omitempty"`
+	Role      string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
+// SessionRevocationKey is the blacklist entry that revokes every access
+// token issued for a session, rather than a single token
+func SessionRevocationKey(sessionID string) string {
+	return "session:" + sessionID
+}
+
 type AuthMiddleware struct {
-	jwtSecret []byte
+	jwtSecret []byte  // HS256; signs only when there is no asymmetric signing key
+	keys      *KeySet // RS256/EdDSA keys, or nil
 	cache     *cache.Cache
+	apiKeys   APIKeyVerifier // personal API keys, or nil
+	bands     BandAuthorizer // band permissions, or nil
 }
 
+// NewAuthMiddleware creates a middleware that signs and verifies HS256
+// tokens with a shared secret
 func NewAuthMiddleware(jwtSecret []byte, cache *cache.Cache) *AuthMiddleware {
+	return NewAuthMiddlewareWithKeys(jwtSecret, nil, cache)
+}
+
+// NewAuthMiddlewareWithKeys creates a middleware that signs with the key
+// set's signing key when it has one. HS256 tokens are still accepted while
+// jwtSecret is set, so tokens issued before a switch to asymmetric keys
+// keep working until they expire.
+func NewAuthMiddlewareWithKeys(jwtSecret []byte, keys *KeySet, cache *cache.Cache) *AuthMiddleware {
 	return &AuthMiddleware{
 		jwtSecret: jwtSecret,
+		keys:      keys,
 		cache:     cache,
 	}
 }
 
+// Keys returns the asymmetric key set, which may be nil
+func (a *AuthMiddleware) Keys() *KeySet {
+	return a.keys
+}
+
+// RequireAuth rejects requests without a valid, unrevoked token. Personal
+// API keys are only accepted on routes wrapped with RequireScope instead.
 func (a *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
+	return a.requireAuth("", next)
+}
+
+func (a *AuthMiddleware) requireAuth(scope string, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		authHeader := r.Header.Get("Authorization")
+
+		// Browsers cannot set headers on WebSocket handshakes, so upgrade
+		// requests may carry the token in the query string instead
+		if authHeader == "" && isWebSocketUpgrade(r) {
+			if token := r.URL.Query().Get("access_token"); token != "" {
+				authHeader = "Bearer " + token
+			}
+		}
+
 		if authHeader == "" {
 			http.Error(w, "Authorization header required", http.StatusUnauthorized)
 			return
@@ -47,6 +94,15 @@ func (a *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 
 		tokenString := parts[1]
 
+		if isAPIKey(tokenString) {
+			key := a.authenticateAPIKey(w, r, tokenString, scope)
+			if key == nil {
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(withAPIKey(r.Context(), key)))
+			return
+		}
+
 		// Parse and validate the token
 		claims, err := a.ValidateToken(tokenString)
 		if err != nil {
@@ -56,34 +112,115 @@ func (a *AuthMiddleware) RequireAuth(next http.Handler) http.Handler {
 
 		// Check if token is blacklisted
 		if a.cache != nil {
-			isBlacklisted, err := a.cache.IsBlacklisted(r.Context(), claims.ID)
+			isRevoked, err := a.isRevoked(r.Context(), claims)
 			if err != nil {
 				http.Error(w, "Internal server error", http.StatusInternalServerError)
 				return
 			}
-			if isBlacklisted {
+			if isRevoked {
 				http.Error(w, "Token has been revoked", http.StatusUnauthorized)
 				return
 			}
 		}
 
-		// Add user info to context
-		ctx := context.WithValue(r.Context(), "user_id", claims.UserID)
-		ctx = context.WithValue(ctx, "username", claims.Username)
-		ctx = context.WithValue(ctx, "jti", claims.ID)
+		next.ServeHTTP(w, r.WithContext(withClaims(r.Context(), claims)))
+	})
+}
+
+// OptionalAuth adds user info to the context when a valid, unrevoked Bearer
+// token is present, so public endpoints can personalize their responses.
+// Requests without a usable token are passed through anonymously.
+func (a *AuthMiddleware) OptionalAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+		if len(parts) != 2 || parts[0] != "Bearer" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		claims, err := a.ValidateToken(parts[1])
+		if err != nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if a.cache != nil {
+			isRevoked, err := a.isRevoked(r.Context(), claims)
+			if err != nil || isRevoked {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
 
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, r.WithContext(withClaims(r.Context(), claims)))
 	})
 }
 
+// isRevoked checks whether the token itself or its whole session has been
+// blacklisted
+func (a *AuthMiddleware) isRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	isBlacklisted, err := a.cache.IsBlacklisted(ctx, claims.ID)
+	if err != nil || isBlacklisted || claims.SessionID == "" {
+		return isBlacklisted, err
+	}
+	return a.cache.IsBlacklisted(ctx, SessionRevocationKey(claims.SessionID))
+}
+
+// StillAuthorized reports whether the access token a request was
+// authenticated with is still unexpired and unrevoked. RequireAuth only
+// checks once, so long-lived connections such as chat sockets call this
+// periodically. Expired tokens are reported as unauthorized because a
+// session revocation is only remembered for as long as its tokens live.
+func (a *AuthMiddleware) StillAuthorized(ctx context.Context) (bool, error) {
+	expiresAt, ok := ctx.Value("token_expires_at").(time.Time)
+	if !ok || !time.Now().Before(expiresAt) {
+		return false, nil
+	}
+	if a.cache == nil {
+		return true, nil
+	}
+
+	jti, _ := GetJTIFromContext(ctx)
+	sessionID, _ := GetSessionIDFromContext(ctx)
+	claims := &Claims{SessionID: sessionID, RegisteredClaims: jwt.RegisteredClaims{ID: jti}}
+	isRevoked, err := a.isRevoked(ctx, claims)
+	if err != nil {
+		return false, err
+	}
+	return !isRevoked, nil
+}
+
+// withClaims adds user info from validated claims to the context
+func withClaims(ctx context.Context, claims *Claims) context.Context {
+	if claims.ExpiresAt != nil {
+		ctx = context.WithValue(ctx, "token_expires_at", claims.ExpiresAt.Time)
+	}
+	ctx = context.WithValue(ctx, "user_id", claims.UserID)
+	ctx = context.WithValue(ctx, "username", claims.Username)
+	ctx = context.WithValue(ctx, "session_id", claims.SessionID)
+	ctx = context.WithValue(ctx, "role", claims.Role)
+	return context.WithValue(ctx, "jti", claims.ID)
+}
+
+func isWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket")
+}
+
 func (a *AuthMiddleware) ValidateToken(tokenString string) (*Claims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		// Validate the signing method
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		// Pick the key by algorithm so a token cannot choose how it is checked
+		switch token.Method.(type) {
+		case *jwt.SigningMethodHMAC:
+			if len(a.jwtSecret) == 0 {
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			}
+			return a.jwtSecret, nil
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodEd25519:
+			return a.keys.verificationKey(token)
+		default:
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
-		return a.jwtSecret, nil
-	})
+	}, jwt.WithValidMethods([]string{"HS256", "RS256", "EdDSA"}))
 
 	if err != nil {
 		return nil, err
@@ -97,32 +234,31 @@ func (a *AuthMiddleware) ValidateToken(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
-func (a *AuthMiddleware) GenerateToken(userID, username string) (string, error) {
+// GenerateToken signs an access token with the asymmetric signing key if
+// there is one, otherwise HS256. role is the user's platform role, and
+// sessionID ties the token to the login it was issued for, so the session
+// can be revoked as a whole.
+func (a *AuthMiddleware) GenerateToken(userID, username, role, sessionID string) (string, error) {
 	claims := &Claims{
-		UserID:   userID,
-		Username: username,
+		UserID:    userID,
+		Username:  username,
+		SessionID: sessionID,
+		Role:      role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)), // 24 hours
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			ID:        fmt.Sprintf("%s_%d", userID, time.Now().Unix()), // Unique JTI
+			ID:        uuid.New().String(), // Unique JTI, even for tokens issued in the same second
 		},
 	}
 
+	if signed, ok, err := a.keys.sign(claims); ok {
+		return signed, err
+	}
+
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString(a.jwtSecret)
 }
 
-func (a *AuthMiddleware) RequireBandAdmin(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// This middleware should be used after RequireAuth
-		// TODO: Check if user is admin of the band
-		// For now, we'll implement this in the handler level
-		// This is a placeholder for the band admin check
-
-		next.ServeHTTP(w, r)
-	})
-}
-
 // Helper function to get user ID from context
 func GetUserIDFromContext(ctx context.Context) (string, bool) {
 	userID, ok := ctx.Value("user_id").(string)
@@ -135,6 +271,12 @@ func GetUsernameFromContext(ctx context.Context) (string, bool) {
 	return username, ok
 }
 
+// Helper function to get the session ID from context
+func GetSessionIDFromContext(ctx context.Context) (string, bool) {
+	sessionID, ok := ctx.Value("session_id").(string)
+	return sessionID, ok && sessionID != ""
+}
+
 // Helper function to get JTI from context
 func GetJTIFromContext(ctx context.Context) (string, bool) {
 	jti, ok := ctx.Value("jti").(string)
@@ -1,11 +1,14 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
 	"musicapp/internal/cache"
+
+	"github.com/gorilla/mux"
 )
 
 func TestAuthMiddleware_RequireAuth(t *testing.T) {
@@ -142,11 +145,17 @@ func TestAuthMiddleware_RequireAuth_WithValidToken(t *testing.T) {
 func TestAuthMiddleware_RequireBandAdmin(t *testing.T) {
 	tests := []struct {
 		name         string
+		userID       string
 		expectStatus int
 	}{
 		{
-			name:         "band admin middleware placeholder",
-			expectStatus: http.StatusOK,
+			name:         "request without a user",
+			expectStatus: http.StatusUnauthorized,
+		},
+		{
+			name:         "no band authorizer configured",
+			userID:       "00000000-0000-0000-0000-000000000001",
+			expectStatus: http.StatusInternalServerError,
 		},
 	}
 
@@ -163,6 +172,10 @@ func TestAuthMiddleware_RequireBandAdmin(t *testing.T) {
 
 			// Create request
 			req := httptest.NewRequest("GET", "/test", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "00000000-0000-0000-0000-000000000002"})
+			if tt.userID != "" {
+				req = req.WithContext(context.WithValue(req.Context(), "user_id", tt.userID))
+			}
 
 			// Create response recorder
 			w := httptest.NewRecorder()
@@ -176,9 +189,9 @@ func TestAuthMiddleware_RequireBandAdmin(t *testing.T) {
 				t.Errorf("Expected status %d, got %d", tt.expectStatus, w.Code)
 			}
 
-			// Verify response body
-			if w.Body.String() != "band admin test" {
-				t.Errorf("Expected body 'band admin test', got '%s'", w.Body.String())
+			// Verify the handler was not reached
+			if w.Body.String() == "band admin test" {
+				t.Error("Expected the request to be refused before reaching the handler")
 			}
 		})
 	}
@@ -218,7 +231,7 @@ func TestAuthMiddleware_ValidateToken_EdgeCases(t *testing.T) {
 			// Generate token with wrong secret to test error cases
 			if tt.name == "token with wrong secret" {
 				wrongMiddleware := NewAuthMiddleware([]byte("wrong-secret"), &cache.Cache{})
-				token, err := wrongMiddleware.GenerateToken("user123", "testuser")
+				token, err := wrongMiddleware.GenerateToken("user123", "testuser", RoleUser, "")
 				if err != nil {
 					t.Fatalf("Failed to generate token: %v", err)
 				}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"musicapp/internal/cache"
 )
@@ -52,7 +53,7 @@ func TestRequireAuth(t *testing.T) {
 
 			// Generate token if needed
 			if tt.name == "valid token" {
-				token, err := middleware.GenerateToken(tt.expectUserID, tt.expectUsername)
+				token, err := middleware.GenerateToken(tt.expectUserID, tt.expectUsername, RoleUser, "")
 				if err != nil {
 					t.Fatalf("Failed to generate token: %v", err)
 				}
@@ -102,6 +103,111 @@ func TestRequireAuth(t *testing.T) {
 	}
 }
 
+func TestRequireAuth_WebSocketQueryToken(t *testing.T) {
+	middleware := NewAuthMiddleware([]byte("test-secret-key"), nil)
+	token, err := middleware.GenerateToken("user123", "testuser", RoleUser, "")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	handler := middleware.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name         string
+		upgrade      string
+		expectStatus int
+	}{
+		{
+			name:         "websocket upgrade accepts query token",
+			upgrade:      "websocket",
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "plain request ignores query token",
+			upgrade:      "",
+			expectStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/test?access_token="+token, nil)
+			if tt.upgrade != "" {
+				req.Header.Set("Upgrade", tt.upgrade)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectStatus, rr.Code)
+			}
+		})
+	}
+}
+
+func TestOptionalAuth(t *testing.T) {
+	middleware := NewAuthMiddleware([]byte("test-secret-key"), nil)
+	token, err := middleware.GenerateToken("user123", "testuser", RoleUser, "")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		authHeader   string
+		expectUserID string
+	}{
+		{
+			name:         "valid token sets user",
+			authHeader:   "Bearer " + token,
+			expectUserID: "user123",
+		},
+		{
+			name:         "missing header is anonymous",
+			authHeader:   "",
+			expectUserID: "",
+		},
+		{
+			name:         "invalid token is anonymous",
+			authHeader:   "Bearer invalid-token",
+			expectUserID: "",
+		},
+		{
+			name:         "malformed header is anonymous",
+			authHeader:   "Basic " + token,
+			expectUserID: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotUserID string
+			handler := middleware.OptionalAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotUserID, _ = GetUserIDFromContext(r.Context())
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != http.StatusOK {
+				t.Errorf("Expected status %d, got %d", http.StatusOK, rr.Code)
+			}
+			if gotUserID != tt.expectUserID {
+				t.Errorf("Expected user ID %q, got %q", tt.expectUserID, gotUserID)
+			}
+		})
+	}
+}
+
 func TestNewAuthMiddleware(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -181,7 +287,7 @@ func TestAuthMiddleware_ValidateToken(t *testing.T) {
 
 			// Generate token if needed
 			if tt.name == "valid token" {
-				token, err := middleware.GenerateToken("user123", "testuser")
+				token, err := middleware.GenerateToken("user123", "testuser", RoleUser, "")
 				if err != nil {
 					t.Fatalf("Failed to generate token: %v", err)
 				}
@@ -189,7 +295,7 @@ func TestAuthMiddleware_ValidateToken(t *testing.T) {
 			} else if tt.name == "token with wrong secret" {
 				// Generate token with different secret
 				wrongMiddleware := NewAuthMiddleware([]byte("wrong-secret"), nil)
-				token, err := wrongMiddleware.GenerateToken("user123", "testuser")
+				token, err := wrongMiddleware.GenerateToken("user123", "testuser", RoleUser, "")
 				if err != nil {
 					t.Fatalf("Failed to generate token: %v", err)
 				}
@@ -263,7 +369,7 @@ func TestAuthMiddleware_GenerateToken(t *testing.T) {
 			middleware := NewAuthMiddleware(tt.jwtSecret, nil)
 
 			// Test GenerateToken
-			token, err := middleware.GenerateToken(tt.userID, tt.username)
+			token, err := middleware.GenerateToken(tt.userID, tt.username, RoleUser, "")
 
 			// Verify results
 			if tt.expectError {
@@ -420,4 +526,63 @@ func TestGetJTIFromContext(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestRequireAuth_SessionClaims(t *testing.T) {
+	middleware := NewAuthMiddleware([]byte("test-secret-key"), nil)
+	token, err := middleware.GenerateToken("user123", "testuser", RoleUser, "session-1")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	claims, err := middleware.ValidateToken(token)
+	if err != nil {
+		t.Fatalf("Expected token to validate, got: %v", err)
+	}
+	if claims.SessionID != "session-1" {
+		t.Errorf("Expected session ID 'session-1', got '%s'", claims.SessionID)
+	}
+	if remaining := time.Until(claims.ExpiresAt.Time); remaining > AccessTokenTTL {
+		t.Errorf("Expected token to expire within %v, got %v", AccessTokenTTL, remaining)
+	}
+
+	var gotSessionID string
+	handler := middleware.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSessionID, _ = GetSessionIDFromContext(r.Context())
+	}))
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if gotSessionID != "session-1" {
+		t.Errorf("Expected session ID in context, got '%s'", gotSessionID)
+	}
+}
+
+func TestStillAuthorized(t *testing.T) {
+	middleware := NewAuthMiddleware([]byte("test-secret-key"), nil)
+	token, err := middleware.GenerateToken("user123", "testuser", RoleUser, "session-1")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	var ctx context.Context
+	handler := middleware.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx = r.Context()
+	}))
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if authorized, err := middleware.StillAuthorized(ctx); err != nil || !authorized {
+		t.Errorf("Expected a fresh token to stay authorized, got %v, %v", authorized, err)
+	}
+
+	expired := context.WithValue(ctx, "token_expires_at", time.Now().Add(-time.Second))
+	if authorized, err := middleware.StillAuthorized(expired); err != nil || authorized {
+		t.Errorf("Expected an expired token to lose authorization, got %v, %v", authorized, err)
+	}
+
+	if authorized, _ := middleware.StillAuthorized(context.Background()); authorized {
+		t.Error("Expected an unauthenticated context not to be authorized")
+	}
+}
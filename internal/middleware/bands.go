@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// BandAuthorizer checks a user's permissions within a band, returning an
+// AppError when they are missing; implemented by service.BandService
+type BandAuthorizer interface {
+	AuthorizeBand(ctx context.Context, bandID, userID uuid.UUID, permission models.BandPermission) error
+}
+
+// SetBandAuthorizer enables RequireBandPermission and RequireBandAdmin.
+// Without an authorizer those routes are refused.
+func (a *AuthMiddleware) SetBandAuthorizer(authorizer BandAuthorizer) {
+	a.bands = authorizer
+}
+
+// RequireBandPermission lets a request through only if the current user has
+// permission in the band named by the route's {id}, so that unauthorized
+// requests are turned away before their body, such as an upload, is read.
+// It must be used after RequireAuth or RequireScope.
+func (a *AuthMiddleware) RequireBandPermission(permission models.BandPermission, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := GetUserIDFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Authorization header required", http.StatusUnauthorized)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		bandID, err := uuid.Parse(mux.Vars(r)["id"])
+		if err != nil {
+			http.Error(w, "Invalid band ID", http.StatusBadRequest)
+			return
+		}
+
+		if a.bands == nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		if err := a.bands.AuthorizeBand(r.Context(), bandID, userID, permission); err != nil {
+			if appErr := errors.GetAppError(err); appErr != nil {
+				http.Error(w, appErr.Message, appErr.HTTPStatus)
+				return
+			}
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequireBandAdmin is RequireBandPermission for managing the band's members,
+// which its owner and admins can do
+func (a *AuthMiddleware) RequireBandAdmin(next http.Handler) http.Handler {
+	return a.RequireBandPermission(models.BandPermissionManageMembers, next)
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+type mockBandAuthorizer struct {
+	permissions map[uuid.UUID][]models.BandPermission
+	err         error
+}
+
+func (m *mockBandAuthorizer) AuthorizeBand(ctx context.Context, bandID, userID uuid.UUID, permission models.BandPermission) error {
+	if m.err != nil {
+		return m.err
+	}
+	for _, p := range m.permissions[userID] {
+		if p == permission {
+			return nil
+		}
+	}
+	return errors.New(errors.ErrCodeForbidden, "Only band admins can manage members")
+}
+
+func TestRequireBandPermission(t *testing.T) {
+	adminID := uuid.New()
+	memberID := uuid.New()
+	bandID := uuid.New().String()
+
+	tests := []struct {
+		name         string
+		userID       string
+		bandID       string
+		authorizer   *mockBandAuthorizer
+		expectStatus int
+	}{
+		{name: "band admin", userID: adminID.String(), bandID: bandID, expectStatus: http.StatusOK},
+		{name: "band member", userID: memberID.String(), bandID: bandID, expectStatus: http.StatusForbidden},
+		{name: "invalid band ID", userID: adminID.String(), bandID: "not-a-uuid", expectStatus: http.StatusBadRequest},
+		{name: "no user", bandID: bandID, expectStatus: http.StatusUnauthorized},
+		{name: "lookup failure", userID: adminID.String(), bandID: bandID, authorizer: &mockBandAuthorizer{err: fmt.Errorf("connection refused")}, expectStatus: http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authorizer := tt.authorizer
+			if authorizer == nil {
+				authorizer = &mockBandAuthorizer{permissions: map[uuid.UUID][]models.BandPermission{
+					adminID: {models.BandPermissionManageMembers},
+				}}
+			}
+			middleware := NewAuthMiddleware([]byte("test-secret-key"), nil)
+			middleware.SetBandAuthorizer(authorizer)
+
+			handler := middleware.RequireBandAdmin(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("PUT", "/bands/"+tt.bandID+"/members/x/role", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": tt.bandID})
+			if tt.userID != "" {
+				req = req.WithContext(context.WithValue(req.Context(), "user_id", tt.userID))
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectStatus, rr.Code)
+			}
+		})
+	}
+}
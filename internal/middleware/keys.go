@@ -0,0 +1,224 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSAKeyBits is the smallest RSA key accepted for signing or verification
+const minRSAKeyBits = 2048
+
+// KeySet holds the asymmetric keys for access tokens: the key that signs
+// new tokens and every public key still accepted for verification. Keys
+// are identified by their RFC 7638 thumbprint, which is sent as the kid
+// header so verifiers can pick the right key during a rotation.
+type KeySet struct {
+	signer     crypto.Signer
+	signingKID string
+	method     jwt.SigningMethod
+
+	keys  map[string]crypto.PublicKey
+	order []string // kids in the order keys were added, for a stable JWKS
+}
+
+// NewKeySet builds a key set. signer may be nil to publish and accept new
+// keys before switching signing over to them. The signer's public key is
+// always accepted for verification.
+func NewKeySet(signer crypto.Signer, verificationKeys []crypto.PublicKey) (*KeySet, error) {
+	k := &KeySet{keys: make(map[string]crypto.PublicKey)}
+
+	if signer != nil {
+		method, err := signingMethodFor(signer.Public())
+		if err != nil {
+			return nil, err
+		}
+		kid, err := k.add(signer.Public())
+		if err != nil {
+			return nil, err
+		}
+		k.signer = signer
+		k.signingKID = kid
+		k.method = method
+	}
+
+	for _, key := range verificationKeys {
+		if _, err := k.add(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return k, nil
+}
+
+func (k *KeySet) add(key crypto.PublicKey) (string, error) {
+	if _, err := signingMethodFor(key); err != nil {
+		return "", err
+	}
+	kid, err := KeyID(key)
+	if err != nil {
+		return "", err
+	}
+	if _, exists := k.keys[kid]; !exists {
+		k.keys[kid] = key
+		k.order = append(k.order, kid)
+	}
+	return kid, nil
+}
+
+// sign signs claims with the signing key, or reports false if there is none
+func (k *KeySet) sign(claims jwt.Claims) (string, bool, error) {
+	if k == nil || k.signer == nil {
+		return "", false, nil
+	}
+
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = k.signingKID
+
+	// RS256 signing needs the concrete RSA key rather than a crypto.Signer
+	var key interface{} = k.signer
+	if rsaKey, ok := k.signer.(*rsa.PrivateKey); ok {
+		key = rsaKey
+	}
+
+	signed, err := token.SignedString(key)
+	return signed, true, err
+}
+
+// verificationKey returns the public key for a token's kid, checking that
+// its type matches the token's algorithm
+func (k *KeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	if k == nil {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	kid, _ := token.Header["kid"].(string)
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %q", kid)
+	}
+
+	method, err := signingMethodFor(key)
+	if err != nil || method.Alg() != token.Method.Alg() {
+		return nil, fmt.Errorf("signing method %v does not match key %q", token.Header["alg"], kid)
+	}
+
+	return key, nil
+}
+
+// JWK is a public key in JSON Web Key format (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// JWKS is a JSON Web Key Set, as served from /.well-known/jwks.json
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns every verification key in the set. A nil key set has none.
+func (k *KeySet) JWKS() JWKS {
+	set := JWKS{Keys: []JWK{}}
+	if k == nil {
+		return set
+	}
+
+	for _, kid := range k.order {
+		jwk, err := toJWK(k.keys[kid])
+		if err != nil {
+			continue
+		}
+		jwk.Kid = kid
+		set.Keys = append(set.Keys, jwk)
+	}
+	return set
+}
+
+// KeyID returns the RFC 7638 thumbprint of a public key
+func KeyID(key crypto.PublicKey) (string, error) {
+	jwk, err := toJWK(key)
+	if err != nil {
+		return "", err
+	}
+
+	// The thumbprint covers only the required members, in lexical order
+	var members interface{}
+	switch jwk.Kty {
+	case "RSA":
+		members = struct {
+			E   string `json:"e"`
+			Kty string `json:"kty"`
+			N   string `json:"n"`
+		}{jwk.E, jwk.Kty, jwk.N}
+	default:
+		members = struct {
+			Crv string `json:"crv"`
+			Kty string `json:"kty"`
+			X   string `json:"x"`
+		}{jwk.Crv, jwk.Kty, jwk.X}
+	}
+
+	encoded, err := json.Marshal(members)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(encoded)
+	return base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func toJWK(key crypto.PublicKey) (JWK, error) {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		return JWK{
+			Kty: "RSA",
+			Use: "sig",
+			Alg: jwt.SigningMethodRS256.Alg(),
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	case ed25519.PublicKey:
+		return JWK{
+			Kty: "OKP",
+			Use: "sig",
+			Alg: jwt.SigningMethodEdDSA.Alg(),
+			Crv: "Ed25519",
+			X:   base64.RawURLEncoding.EncodeToString(key),
+		}, nil
+	default:
+		return JWK{}, fmt.Errorf("unsupported key type %T", key)
+	}
+}
+
+// signingMethodFor returns the algorithm used with a key: RS256 for RSA
+// and EdDSA for Ed25519
+func signingMethodFor(key crypto.PublicKey) (jwt.SigningMethod, error) {
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < minRSAKeyBits {
+			return nil, fmt.Errorf("RSA keys must be at least %d bits", minRSAKeyBits)
+		}
+		return jwt.SigningMethodRS256, nil
+	case ed25519.PublicKey:
+		return jwt.SigningMethodEdDSA, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %T; use RSA or Ed25519", key)
+	}
+}
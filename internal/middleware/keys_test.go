@@ -0,0 +1,237 @@
+package middleware
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func generateEd25519Key(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate Ed25519 key: %v", err)
+	}
+	return key
+}
+
+func TestKeySet_SignAndVerify(t *testing.T) {
+	rsaKey := generateRSAKey(t)
+
+	tests := []struct {
+		name      string
+		signer    crypto.Signer
+		expectAlg string
+	}{
+		{name: "RSA key", signer: rsaKey, expectAlg: "RS256"},
+		{name: "Ed25519 key", signer: generateEd25519Key(t), expectAlg: "EdDSA"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keys, err := NewKeySet(tt.signer, nil)
+			if err != nil {
+				t.Fatalf("Failed to build key set: %v", err)
+			}
+			middleware := NewAuthMiddlewareWithKeys(nil, keys, nil)
+
+			tokenString, err := middleware.GenerateToken("user123", "testuser", RoleUser, "session123")
+			if err != nil {
+				t.Fatalf("Failed to generate token: %v", err)
+			}
+
+			token, _, err := jwt.NewParser().ParseUnverified(tokenString, &Claims{})
+			if err != nil {
+				t.Fatalf("Failed to parse token: %v", err)
+			}
+			if token.Method.Alg() != tt.expectAlg {
+				t.Errorf("Expected alg %s, got %s", tt.expectAlg, token.Method.Alg())
+			}
+			if token.Header["kid"] != keys.signingKID {
+				t.Errorf("Expected kid %s, got %v", keys.signingKID, token.Header["kid"])
+			}
+
+			claims, err := middleware.ValidateToken(tokenString)
+			if err != nil {
+				t.Fatalf("Expected token to validate, got: %v", err)
+			}
+			if claims.UserID != "user123" || claims.SessionID != "session123" {
+				t.Errorf("Unexpected claims: %+v", claims)
+			}
+		})
+	}
+}
+
+func TestKeySet_Rotation(t *testing.T) {
+	oldKey := generateEd25519Key(t)
+	newKey := generateEd25519Key(t)
+
+	oldKeys, _ := NewKeySet(oldKey, nil)
+	oldToken, err := NewAuthMiddlewareWithKeys(nil, oldKeys, nil).GenerateToken("user123", "testuser", RoleUser, "")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	// After rotating, the old public key stays accepted until its tokens expire
+	rotated, err := NewKeySet(newKey, []crypto.PublicKey{oldKey.Public()})
+	if err != nil {
+		t.Fatalf("Failed to build key set: %v", err)
+	}
+	middleware := NewAuthMiddlewareWithKeys(nil, rotated, nil)
+
+	if _, err := middleware.ValidateToken(oldToken); err != nil {
+		t.Errorf("Expected token signed with the previous key to validate, got: %v", err)
+	}
+
+	newToken, _ := middleware.GenerateToken("user123", "testuser", RoleUser, "")
+	if _, err := NewAuthMiddlewareWithKeys(nil, oldKeys, nil).ValidateToken(newToken); err == nil {
+		t.Error("Expected token signed with an unknown key to be rejected")
+	}
+
+	// Once the old key is dropped its tokens stop validating
+	dropped, _ := NewKeySet(newKey, nil)
+	if _, err := NewAuthMiddlewareWithKeys(nil, dropped, nil).ValidateToken(oldToken); err == nil {
+		t.Error("Expected token signed with a removed key to be rejected")
+	}
+}
+
+func TestKeySet_HS256Migration(t *testing.T) {
+	secret := []byte("test-secret-key")
+	keys, _ := NewKeySet(generateEd25519Key(t), nil)
+
+	legacy, err := NewAuthMiddleware(secret, nil).GenerateToken("user123", "testuser", RoleUser, "")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	if _, err := NewAuthMiddlewareWithKeys(secret, keys, nil).ValidateToken(legacy); err != nil {
+		t.Errorf("Expected HS256 token to validate while the secret is configured, got: %v", err)
+	}
+	if _, err := NewAuthMiddlewareWithKeys(nil, keys, nil).ValidateToken(legacy); err == nil {
+		t.Error("Expected HS256 token to be rejected without a secret")
+	}
+}
+
+func TestKeySet_RejectsMismatchedTokens(t *testing.T) {
+	rsaKey := generateRSAKey(t)
+	edKey := generateEd25519Key(t)
+
+	keys, _ := NewKeySet(edKey, []crypto.PublicKey{rsaKey.Public()})
+	middleware := NewAuthMiddlewareWithKeys(nil, keys, nil)
+	rsaKID, _ := KeyID(rsaKey.Public())
+	edKID, _ := KeyID(edKey.Public())
+
+	claims := &Claims{
+		UserID: "user123",
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("Failed to sign token: %v", err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "missing kid", token: sign(jwt.SigningMethodEdDSA, "", edKey)},
+		{name: "unknown kid", token: sign(jwt.SigningMethodEdDSA, "unknown", edKey)},
+		{name: "alg does not match key", token: sign(jwt.SigningMethodRS256, edKID, rsaKey)},
+		{name: "signed by another key", token: sign(jwt.SigningMethodRS256, rsaKID, generateRSAKey(t))},
+		{name: "HS256 without secret", token: sign(jwt.SigningMethodHS256, edKID, []byte("test-secret-key"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := middleware.ValidateToken(tt.token); err == nil {
+				t.Error("Expected token to be rejected")
+			}
+		})
+	}
+
+	// The RSA verification key itself is accepted
+	if _, err := middleware.ValidateToken(sign(jwt.SigningMethodRS256, rsaKID, rsaKey)); err != nil {
+		t.Errorf("Expected RS256 token with matching kid to validate, got: %v", err)
+	}
+}
+
+func TestNewKeySet_RejectsWeakKeys(t *testing.T) {
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+
+	if _, err := NewKeySet(weak, nil); err == nil {
+		t.Error("Expected 1024-bit RSA signing key to be rejected")
+	}
+	if _, err := NewKeySet(nil, []crypto.PublicKey{weak.Public()}); err == nil {
+		t.Error("Expected 1024-bit RSA verification key to be rejected")
+	}
+}
+
+func TestKeySet_JWKS(t *testing.T) {
+	var empty *KeySet
+	if jwks := empty.JWKS(); jwks.Keys == nil || len(jwks.Keys) != 0 {
+		t.Errorf("Expected empty key list for nil key set, got %+v", jwks)
+	}
+
+	rsaKey := generateRSAKey(t)
+	edKey := generateEd25519Key(t)
+	keys, err := NewKeySet(edKey, []crypto.PublicKey{rsaKey.Public(), edKey.Public()})
+	if err != nil {
+		t.Fatalf("Failed to build key set: %v", err)
+	}
+
+	jwks := keys.JWKS()
+	if len(jwks.Keys) != 2 {
+		t.Fatalf("Expected 2 keys without duplicates, got %d", len(jwks.Keys))
+	}
+
+	ed, rs := jwks.Keys[0], jwks.Keys[1]
+	if ed.Kty != "OKP" || ed.Crv != "Ed25519" || ed.Alg != "EdDSA" || ed.Kid != keys.signingKID || ed.X == "" {
+		t.Errorf("Unexpected Ed25519 JWK: %+v", ed)
+	}
+	if rs.Kty != "RSA" || rs.Alg != "RS256" || rs.E != "AQAB" || rs.N == "" || rs.Use != "sig" {
+		t.Errorf("Unexpected RSA JWK: %+v", rs)
+	}
+}
+
+func TestKeyID(t *testing.T) {
+	// RFC 8037 appendix A.3 thumbprint of the example Ed25519 key
+	public := ed25519.PublicKey{
+		0xd7, 0x5a, 0x98, 0x01, 0x82, 0xb1, 0x0a, 0xb7, 0xd5, 0x4b, 0xfe, 0xd3, 0xc9, 0x64, 0x07, 0x3a,
+		0x0e, 0xe1, 0x72, 0xf3, 0xda, 0xa6, 0x23, 0x25, 0xaf, 0x02, 0x1a, 0x68, 0xf7, 0x07, 0x51, 0x1a,
+	}
+
+	kid, err := KeyID(public)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if kid != "kPrK_qmxVWaYVA9wwBF6Iuo3vVzz7TxHCTwXBygrS4k" {
+		t.Errorf("Unexpected thumbprint: %s", kid)
+	}
+}
+
+func generateRSAKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate RSA key: %v", err)
+	}
+	return key
+}
@@ -164,3 +164,31 @@ func TestResponseWriter(t *testing.T) {
 		})
 	}
 }
+
+func TestResponseWriter_Hijack(t *testing.T) {
+	// httptest.ResponseRecorder does not implement http.Hijacker
+	rw := &responseWriter{ResponseWriter: httptest.NewRecorder(), statusCode: http.StatusOK}
+	if _, _, err := rw.Hijack(); err == nil {
+		t.Error("Expected error when underlying writer cannot be hijacked")
+	}
+
+	// A real server connection can be hijacked through the wrapper
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		wrapped := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		conn, _, err := wrapped.Hijack()
+		if err != nil {
+			t.Errorf("Expected hijack to succeed, got %v", err)
+			return
+		}
+		if wrapped.statusCode != http.StatusSwitchingProtocols {
+			t.Errorf("Expected status code %d, got %d", http.StatusSwitchingProtocols, wrapped.statusCode)
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err == nil {
+		resp.Body.Close()
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+)
+
+// Platform roles. Every user has one, and each role can do everything the
+// roles before it can. Band roles are separate and only apply within a band.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
+// Roles lists every platform role, from least to most privileged
+var Roles = []string{
+	RoleUser,
+	RoleModerator,
+	RoleAdmin,
+}
+
+// IsValidRole reports whether role is a platform role
+func IsValidRole(role string) bool {
+	return roleRank(role) >= 0
+}
+
+// RoleAtLeast reports whether role grants everything required does
+func RoleAtLeast(role, required string) bool {
+	rank := roleRank(required)
+	return rank >= 0 && roleRank(role) >= rank
+}
+
+func roleRank(role string) int {
+	for i, r := range Roles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
+
+// RequireRole is RequireAuth for staff routes: the token's role claim must
+// be at least role. Personal API keys are never accepted.
+func (a *AuthMiddleware) RequireRole(role string, next http.Handler) http.Handler {
+	return a.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if current, _ := GetRoleFromContext(r.Context()); !RoleAtLeast(current, role) {
+			http.Error(w, "Forbidden", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}))
+}
+
+// Helper function to get the platform role from context. Tokens issued
+// before roles existed carry none and count as RoleUser.
+func GetRoleFromContext(ctx context.Context) (string, bool) {
+	role, ok := ctx.Value("role").(string)
+	if !ok {
+		return "", false
+	}
+	if role == "" {
+		role = RoleUser
+	}
+	return role, true
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRoleAtLeast(t *testing.T) {
+	tests := []struct {
+		role     string
+		required string
+		expected bool
+	}{
+		{RoleUser, RoleUser, true},
+		{RoleUser, RoleModerator, false},
+		{RoleModerator, RoleModerator, true},
+		{RoleModerator, RoleAdmin, false},
+		{RoleAdmin, RoleModerator, true},
+		{RoleAdmin, RoleAdmin, true},
+		{"superuser", RoleUser, false},
+		{RoleAdmin, "superuser", false},
+	}
+
+	for _, tt := range tests {
+		if got := RoleAtLeast(tt.role, tt.required); got != tt.expected {
+			t.Errorf("RoleAtLeast(%q, %q) = %v, expected %v", tt.role, tt.required, got, tt.expected)
+		}
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	const apiKey = APIKeyPrefix + "staff-script"
+	verifier := &mockAPIKeyVerifier{keys: map[string]*APIKey{
+		apiKey: {ID: "key-1", UserID: "admin123", Username: "admin", Scopes: Scopes},
+	}}
+
+	middleware := NewAuthMiddleware([]byte("test-secret-key"), nil)
+	middleware.SetAPIKeyVerifier(verifier)
+	token := func(role string) string {
+		jwtToken, err := middleware.GenerateToken("user123", "testuser", role, "")
+		if err != nil {
+			t.Fatalf("Failed to generate token: %v", err)
+		}
+		return jwtToken
+	}
+
+	tests := []struct {
+		name         string
+		token        string
+		required     string
+		expectStatus int
+	}{
+		{name: "moderator on a moderator route", token: token(RoleModerator), required: RoleModerator, expectStatus: http.StatusOK},
+		{name: "admin on a moderator route", token: token(RoleAdmin), required: RoleModerator, expectStatus: http.StatusOK},
+		{name: "moderator on an admin route", token: token(RoleModerator), required: RoleAdmin, expectStatus: http.StatusForbidden},
+		{name: "user on a moderator route", token: token(RoleUser), required: RoleModerator, expectStatus: http.StatusForbidden},
+		{name: "token without a role", token: token(""), required: RoleModerator, expectStatus: http.StatusForbidden},
+		{name: "API key", token: apiKey, required: RoleModerator, expectStatus: http.StatusForbidden},
+		{name: "no token", required: RoleModerator, expectStatus: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			protected := middleware.RequireRole(tt.required, handler)
+
+			req := httptest.NewRequest("GET", "/test", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rr := httptest.NewRecorder()
+			protected.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectStatus, rr.Code)
+			}
+		})
+	}
+}
+
+func TestGetRoleFromContext(t *testing.T) {
+	middleware := NewAuthMiddleware([]byte("test-secret-key"), nil)
+	jwtToken, err := middleware.GenerateToken("user123", "testuser", "", "")
+	if err != nil {
+		t.Fatalf("Failed to generate token: %v", err)
+	}
+
+	handler := middleware.RequireAuth(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if role, ok := GetRoleFromContext(r.Context()); !ok || role != RoleUser {
+			t.Errorf("Expected a token without a role to count as %q, got %q", RoleUser, role)
+		}
+	}))
+
+	req := httptest.NewRequest("GET", "/test", nil)
+	req.Header.Set("Authorization", "Bearer "+jwtToken)
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// UnverifiedAccess controls what users who have not verified their email
+// address are allowed to do
+type UnverifiedAccess string
+
+const (
+	// UnverifiedRestricted lets unverified users use the app, except for
+	// posting, following and joining bands
+	UnverifiedRestricted UnverifiedAccess = "restricted"
+	// UnverifiedReadOnly lets unverified users log in and read, but not
+	// change anything
+	UnverifiedReadOnly UnverifiedAccess = "read_only"
+)
+
+// EmailVerificationChecker reports whether a user has verified their email
+// address
+type EmailVerificationChecker interface {
+	IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+// EmailVerification gates routes on the current user having verified their
+// email address. It must be used after RequireAuth.
+type EmailVerification struct {
+	checker EmailVerificationChecker
+	access  UnverifiedAccess
+}
+
+func NewEmailVerification(checker EmailVerificationChecker, access UnverifiedAccess) *EmailVerification {
+	if access != UnverifiedReadOnly {
+		access = UnverifiedRestricted
+	}
+	return &EmailVerification{
+		checker: checker,
+		access:  access,
+	}
+}
+
+// RequireVerified blocks unverified users under every access policy. It
+// guards the actions throwaway accounts are made for: posting, following
+// and joining bands.
+func (v *EmailVerification) RequireVerified(next http.Handler) http.Handler {
+	return v.require(next)
+}
+
+// RequireVerifiedForWrites blocks unverified users only when they are
+// limited to read-only access
+func (v *EmailVerification) RequireVerifiedForWrites(next http.Handler) http.Handler {
+	if v.access != UnverifiedReadOnly {
+		return next
+	}
+	return v.require(next)
+}
+
+func (v *EmailVerification) require(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userIDStr, ok := GetUserIDFromContext(r.Context())
+		if !ok {
+			http.Error(w, "Authorization header required", http.StatusUnauthorized)
+			return
+		}
+
+		userID, err := uuid.Parse(userIDStr)
+		if err != nil {
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			return
+		}
+
+		verified, err := v.checker.IsEmailVerified(r.Context(), userID)
+		if err != nil {
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+		if !verified {
+			http.Error(w, "Email verification required", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
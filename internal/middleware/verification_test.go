@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+type mockVerificationChecker struct {
+	verified map[uuid.UUID]bool
+}
+
+func (m *mockVerificationChecker) IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return m.verified[userID], nil
+}
+
+func TestEmailVerification(t *testing.T) {
+	verifiedUser := uuid.New()
+	unverifiedUser := uuid.New()
+	checker := &mockVerificationChecker{verified: map[uuid.UUID]bool{verifiedUser: true}}
+
+	tests := []struct {
+		name         string
+		access       UnverifiedAccess
+		readOnlyGate bool
+		userID       string
+		expectStatus int
+	}{
+		{
+			name:         "verified user passes",
+			access:       UnverifiedRestricted,
+			userID:       verifiedUser.String(),
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "unverified user blocked",
+			access:       UnverifiedRestricted,
+			userID:       unverifiedUser.String(),
+			expectStatus: http.StatusForbidden,
+		},
+		{
+			name:         "unverified user writes when restricted",
+			access:       UnverifiedRestricted,
+			readOnlyGate: true,
+			userID:       unverifiedUser.String(),
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "unverified user cannot write when read-only",
+			access:       UnverifiedReadOnly,
+			readOnlyGate: true,
+			userID:       unverifiedUser.String(),
+			expectStatus: http.StatusForbidden,
+		},
+		{
+			name:         "unknown policy falls back to restricted",
+			access:       UnverifiedAccess("bogus"),
+			readOnlyGate: true,
+			userID:       unverifiedUser.String(),
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "missing user",
+			access:       UnverifiedRestricted,
+			expectStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verification := NewEmailVerification(checker, tt.access)
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+
+			var handler http.Handler
+			if tt.readOnlyGate {
+				handler = verification.RequireVerifiedForWrites(next)
+			} else {
+				handler = verification.RequireVerified(next)
+			}
+
+			req := httptest.NewRequest("POST", "/test", nil)
+			if tt.userID != "" {
+				req = req.WithContext(context.WithValue(req.Context(), "user_id", tt.userID))
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectStatus {
+				t.Errorf("Expected status %d, got %d", tt.expectStatus, rr.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Audit log actions, one per staff action
+const (
+	AuditActionUserSuspended   = "user.suspended"
+	AuditActionUserUnsuspended = "user.unsuspended"
+	AuditActionUserLoggedOut   = "user.logged_out"
+	AuditActionUserRoleChanged = "user.role_changed"
+	AuditActionPostDeleted     = "post.deleted"
+	AuditActionCommentDeleted  = "comment.deleted"
+)
+
+// Kinds of record an audit entry can be about
+const (
+	AuditTargetUser    = "user"
+	AuditTargetPost    = "post"
+	AuditTargetComment = "comment"
+)
+
+// AuditLogEntry records one action taken by a member of staff. Targets are
+// kept by ID, so entries survive the deletion of what they describe.
+type AuditLogEntry struct {
+	ID         uuid.UUID       `json:"id" db:"id"`
+	ActorID    *uuid.UUID      `json:"actor_id" db:"actor_id"`
+	Action     string          `json:"action" db:"action"`
+	TargetType string          `json:"target_type" db:"target_type"`
+	TargetID   uuid.UUID       `json:"target_id" db:"target_id"`
+	Details    json.RawMessage `json:"details" db:"details"`
+	IPAddress  *string         `json:"ip_address" db:"ip_address"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditLogFilter narrows the audit log to one actor or one target; zero
+// values match everything
+type AuditLogFilter struct {
+	ActorID  *uuid.UUID
+	TargetID *uuid.UUID
+	Action   string
+}
+
+// UserSearchFilter narrows a staff search for users; zero values match
+// everything
+type UserSearchFilter struct {
+	Query     string
+	Role      string
+	Suspended *bool
+}
+
+// AdminUserResponse is a user as staff see them, including account state
+// hidden from the public profile
+type AdminUserResponse struct {
+	*UserResponse
+	SuspendedAt         *time.Time `json:"suspended_at,omitempty"`
+	SuspensionReason    *string    `json:"suspension_reason,omitempty"`
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
+
+func (u *User) ToAdminResponse() *AdminUserResponse {
+	return &AdminUserResponse{
+		UserResponse:        u.ToResponse(),
+		SuspendedAt:         u.SuspendedAt,
+		SuspensionReason:    u.SuspensionReason,
+		DeletionScheduledAt: u.DeletionScheduledAt,
+	}
+}
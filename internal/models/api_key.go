@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKey is a personal access token a user created for scripts and
+// integrations. Only the hash of the token is stored; TokenPrefix is its
+// first characters, shown so users can tell their keys apart.
+type APIKey struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Name        string     `json:"name" db:"name"`
+	TokenPrefix string     `json:"token_prefix" db:"token_prefix"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	Scopes      []string   `json:"scopes" db:"scopes"`
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at" db:"last_used_at"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	RevokedAt   *time.Time `json:"revoked_at" db:"revoked_at"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name" validate:"required,min=1,max=100"`
+	Scopes []string `json:"scopes" validate:"required,min=1,dive,required"`
+
+	// Days until the key expires; defaults to 90
+	ExpiresInDays *int `json:"expires_in_days,omitempty" validate:"omitempty,min=1,max=365"`
+}
+
+type APIKeyResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Name        string     `json:"name"`
+	TokenPrefix string     `json:"token_prefix"`
+	Scopes      []string   `json:"scopes"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreatedAPIKeyResponse is returned once, when the key is created; the
+// token cannot be retrieved again
+type CreatedAPIKeyResponse struct {
+	*APIKeyResponse
+	Token string `json:"token"`
+}
+
+func (k *APIKey) ToResponse() *APIKeyResponse {
+	return &APIKeyResponse{
+		ID:          k.ID,
+		Name:        k.Name,
+		TokenPrefix: k.TokenPrefix,
+		Scopes:      k.Scopes,
+		ExpiresAt:   k.ExpiresAt,
+		LastUsedAt:  k.LastUsedAt,
+		CreatedAt:   k.CreatedAt,
+	}
+}
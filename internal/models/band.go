@@ -6,6 +6,57 @@ import (
 	"github.com/google/uuid"
 )
 
+// Band roles. Every band has one owner; admins help run it and members
+// belong to it.
+const (
+	BandRoleOwner  = "owner"
+	BandRoleAdmin  = "admin"
+	BandRoleMember = "member"
+)
+
+// BandRoles lists every band role, from least to most privileged
+var BandRoles = []string{
+	BandRoleMember,
+	BandRoleAdmin,
+	BandRoleOwner,
+}
+
+// BandPermission names something band members can be allowed to do
+type BandPermission string
+
+const (
+	BandPermissionEditProfile   BandPermission = "edit_profile"
+	BandPermissionManageMembers BandPermission = "manage_members"
+	BandPermissionPostAsBand    BandPermission = "post_as_band"
+	BandPermissionDeleteBand    BandPermission = "delete_band"
+)
+
+// bandRolePermissions lists the permissions each band role grants
+var bandRolePermissions = map[string][]BandPermission{
+	BandRoleOwner: {
+		BandPermissionEditProfile,
+		BandPermissionManageMembers,
+		BandPermissionPostAsBand,
+		BandPermissionDeleteBand,
+	},
+	BandRoleAdmin: {
+		BandPermissionEditProfile,
+		BandPermissionManageMembers,
+		BandPermissionPostAsBand,
+	},
+}
+
+// BandRoleRank orders band roles from member (0) to owner, and is -1 for
+// anything else
+func BandRoleRank(role string) int {
+	for i, r := range BandRoles {
+		if r == role {
+			return i
+		}
+	}
+	return -1
+}
+
 type Band struct {
 	ID                uuid.UUID `json:"id" db:"id"`
 	Name              string    `json:"name" db:"name"`
@@ -21,13 +72,28 @@ type Band struct {
 }
 
 type BandMember struct {
-	ID       uuid.UUID `json:"id" db:"id"`
-	BandID   uuid.UUID `json:"band_id" db:"band_id"`
-	UserID   uuid.UUID `json:"user_id" db:"user_id"`
-	Role     *string   `json:"role" db:"role"`
-	JoinedAt time.Time `json:"joined_at" db:"joined_at"`
-	User     *User     `json:"user,omitempty"`
-	Band     *Band     `json:"band,omitempty"`
+	ID            uuid.UUID `json:"id" db:"id"`
+	BandID        uuid.UUID `json:"band_id" db:"band_id"`
+	UserID        uuid.UUID `json:"user_id" db:"user_id"`
+	Role          string    `json:"role" db:"role"`
+	CanPostAsBand bool      `json:"can_post_as_band" db:"can_post_as_band"`
+	JoinedAt      time.Time `json:"joined_at" db:"joined_at"`
+	User          *User     `json:"user,omitempty"`
+	Band          *Band     `json:"band,omitempty"`
+}
+
+// Can reports whether the member has a permission. Besides their role's
+// permissions, members can be designated to post as the band.
+func (m *BandMember) Can(permission BandPermission) bool {
+	if permission == BandPermissionPostAsBand && m.CanPostAsBand {
+		return true
+	}
+	for _, p := range bandRolePermissions[m.Role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
 }
 
 type CreateBandRequest struct {
@@ -50,6 +116,16 @@ type UpdateBandRequest struct {
 	LookingFor []string  `json:"looking_for,omitempty"`
 }
 
+type UpdateMemberPostingRequest struct {
+	CanPostAsBand bool `json:"can_post_as_band"`
+}
+
+// UpdateMemberRoleRequest changes a member's band role. Setting "owner"
+// hands the band over, leaving the current owner an admin.
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role"`
+}
+
 type BandResponse struct {
 	ID                uuid.UUID    `json:"id"`
 	Name              string       `json:"name"`
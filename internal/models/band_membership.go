@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Kinds of band membership request
+const (
+	// MembershipRequestInvitation is sent by a band admin to a user
+	MembershipRequestInvitation = "invitation"
+	// MembershipRequestJoin is sent by a user asking to join a band
+	MembershipRequestJoin = "join_request"
+)
+
+// Statuses of a band membership request. Accepted and declined are used for
+// both kinds: an approved join request is accepted, a rejected one declined.
+const (
+	MembershipRequestPending   = "pending"
+	MembershipRequestAccepted  = "accepted"
+	MembershipRequestDeclined  = "declined"
+	MembershipRequestCancelled = "cancelled"
+	MembershipRequestExpired   = "expired"
+)
+
+// BandMembershipRequest is an invitation to join a band, or a user's request
+// to join one. UserID is the invitee or the user asking to join, and Role is
+// the band role they are offered or would like.
+type BandMembershipRequest struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	BandID      uuid.UUID  `json:"band_id" db:"band_id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Kind        string     `json:"kind" db:"kind"`
+	Role        string     `json:"role" db:"role"`
+	Message     *string    `json:"message,omitempty" db:"message"`
+	Status      string     `json:"status" db:"status"`
+	InvitedBy   *uuid.UUID `json:"invited_by,omitempty" db:"invited_by"`
+	RespondedBy *uuid.UUID `json:"responded_by,omitempty" db:"responded_by"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	ExpiresAt   time.Time  `json:"expires_at" db:"expires_at"`
+	RespondedAt *time.Time `json:"responded_at,omitempty" db:"responded_at"`
+	NotifiedAt  *time.Time `json:"notified_at,omitempty" db:"notified_at"`
+}
+
+// InviteToBandRequest invites a user to a band as an admin or member
+type InviteToBandRequest struct {
+	UserID  uuid.UUID `json:"user_id" validate:"required"`
+	Role    string    `json:"role,omitempty"`
+	Message *string   `json:"message,omitempty" validate:"omitempty,max=500"`
+}
+
+// JoinBandRequest asks to join a band, optionally as an admin
+type JoinBandRequest struct {
+	Role    string  `json:"role,omitempty"`
+	Message *string `json:"message,omitempty" validate:"omitempty,max=500"`
+}
+
+// ApproveJoinRequest approves a join request, optionally with a different
+// band role than the one asked for
+type ApproveJoinRequest struct {
+	Role string `json:"role,omitempty"`
+}
@@ -153,3 +153,56 @@ func TestBand_ToResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestBandMember_Can(t *testing.T) {
+	allPermissions := []BandPermission{
+		BandPermissionEditProfile,
+		BandPermissionManageMembers,
+		BandPermissionPostAsBand,
+		BandPermissionDeleteBand,
+	}
+
+	tests := []struct {
+		name     string
+		member   BandMember
+		expected []BandPermission
+	}{
+		{
+			name:     "owner",
+			member:   BandMember{Role: BandRoleOwner},
+			expected: allPermissions,
+		},
+		{
+			name:     "admin",
+			member:   BandMember{Role: BandRoleAdmin},
+			expected: []BandPermission{BandPermissionEditProfile, BandPermissionManageMembers, BandPermissionPostAsBand},
+		},
+		{
+			name:   "member",
+			member: BandMember{Role: BandRoleMember},
+		},
+		{
+			name:     "member designated to post as the band",
+			member:   BandMember{Role: BandRoleMember, CanPostAsBand: true},
+			expected: []BandPermission{BandPermissionPostAsBand},
+		},
+		{
+			name:   "unknown role",
+			member: BandMember{Role: "Admin"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, permission := range allPermissions {
+				expected := false
+				for _, p := range tt.expected {
+					expected = expected || p == permission
+				}
+				if got := tt.member.Can(permission); got != expected {
+					t.Errorf("Can(%q) = %v, expected %v", permission, got, expected)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Comment struct {
+	ID              uuid.UUID  `json:"id" db:"id"`
+	PostID          uuid.UUID  `json:"post_id" db:"post_id"`
+	UserID          uuid.UUID  `json:"user_id" db:"user_id"`
+	ParentCommentID *uuid.UUID `json:"parent_comment_id" db:"parent_comment_id"`
+	Depth           int        `json:"depth" db:"depth"`
+	Content         string     `json:"content" db:"content"`
+	CreatedAt       time.Time  `json:"created_at" db:"created_at"`
+
+	// Joined data
+	LikesCount   int        `json:"likes_count,omitempty"`
+	RepliesCount int        `json:"replies_count,omitempty"`
+	IsLiked      bool       `json:"is_liked,omitempty"`
+	Replies      []*Comment `json:"replies,omitempty"`
+}
+
+type CreateCommentRequest struct {
+	Content         string     `json:"content" validate:"required,min=1,max=1000"`
+	ParentCommentID *uuid.UUID `json:"parent_comment_id,omitempty"`
+}
+
+type CommentResponse struct {
+	ID              uuid.UUID          `json:"id"`
+	PostID          uuid.UUID          `json:"post_id"`
+	UserID          uuid.UUID          `json:"user_id"`
+	ParentCommentID *uuid.UUID         `json:"parent_comment_id"`
+	Depth           int                `json:"depth"`
+	Content         string             `json:"content"`
+	CreatedAt       time.Time          `json:"created_at"`
+	LikesCount      int                `json:"likes_count"`
+	RepliesCount    int                `json:"replies_count"`
+	IsLiked         bool               `json:"is_liked"`
+	Replies         []*CommentResponse `json:"replies"`
+}
+
+func (c *Comment) ToResponse() *CommentResponse {
+	replies := make([]*CommentResponse, 0, len(c.Replies))
+	for _, reply := range c.Replies {
+		replies = append(replies, reply.ToResponse())
+	}
+
+	return &CommentResponse{
+		ID:              c.ID,
+		PostID:          c.PostID,
+		UserID:          c.UserID,
+		ParentCommentID: c.ParentCommentID,
+		Depth:           c.Depth,
+		Content:         c.Content,
+		CreatedAt:       c.CreatedAt,
+		LikesCount:      c.LikesCount,
+		RepliesCount:    c.RepliesCount,
+		IsLiked:         c.IsLiked,
+		Replies:         replies,
+	}
+}
@@ -0,0 +1,99 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestComment_ToResponse(t *testing.T) {
+	parentID := uuid.New()
+
+	tests := []struct {
+		name    string
+		comment *Comment
+	}{
+		{
+			name: "complete comment",
+			comment: &Comment{
+				ID:        uuid.New(),
+				PostID:    uuid.New(),
+				UserID:    uuid.New(),
+				Content:   "Great mix!",
+				CreatedAt: time.Now(),
+			},
+		},
+		{
+			name: "comment with nested replies",
+			comment: &Comment{
+				ID:           parentID,
+				PostID:       uuid.New(),
+				UserID:       uuid.New(),
+				Content:      "Kick is too loud",
+				CreatedAt:    time.Now(),
+				LikesCount:   4,
+				RepliesCount: 2,
+				IsLiked:      true,
+				Replies: []*Comment{
+					{ID: uuid.New(), ParentCommentID: &parentID, Depth: 1, Content: "Agreed"},
+					{ID: uuid.New(), ParentCommentID: &parentID, Depth: 1, Content: "Sounds fine to me"},
+				},
+			},
+		},
+		{
+			name: "comment with zero values",
+			comment: &Comment{
+				Content: "",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.comment.ToResponse()
+
+			if result.ID != tt.comment.ID {
+				t.Errorf("Expected ID %v, got %v", tt.comment.ID, result.ID)
+			}
+			if result.PostID != tt.comment.PostID {
+				t.Errorf("Expected PostID %v, got %v", tt.comment.PostID, result.PostID)
+			}
+			if result.UserID != tt.comment.UserID {
+				t.Errorf("Expected UserID %v, got %v", tt.comment.UserID, result.UserID)
+			}
+			if result.Content != tt.comment.Content {
+				t.Errorf("Expected Content %s, got %s", tt.comment.Content, result.Content)
+			}
+			if !result.CreatedAt.Equal(tt.comment.CreatedAt) {
+				t.Errorf("Expected CreatedAt %v, got %v", tt.comment.CreatedAt, result.CreatedAt)
+			}
+			if result.ParentCommentID != tt.comment.ParentCommentID {
+				t.Errorf("Expected ParentCommentID %v, got %v", tt.comment.ParentCommentID, result.ParentCommentID)
+			}
+			if result.Depth != tt.comment.Depth {
+				t.Errorf("Expected Depth %d, got %d", tt.comment.Depth, result.Depth)
+			}
+			if result.LikesCount != tt.comment.LikesCount {
+				t.Errorf("Expected LikesCount %d, got %d", tt.comment.LikesCount, result.LikesCount)
+			}
+			if result.RepliesCount != tt.comment.RepliesCount {
+				t.Errorf("Expected RepliesCount %d, got %d", tt.comment.RepliesCount, result.RepliesCount)
+			}
+			if result.IsLiked != tt.comment.IsLiked {
+				t.Errorf("Expected IsLiked %v, got %v", tt.comment.IsLiked, result.IsLiked)
+			}
+			if result.Replies == nil {
+				t.Error("Expected Replies to be an empty slice, got nil")
+			}
+			if len(result.Replies) != len(tt.comment.Replies) {
+				t.Fatalf("Expected %d replies, got %d", len(tt.comment.Replies), len(result.Replies))
+			}
+			for i, reply := range tt.comment.Replies {
+				if result.Replies[i].ID != reply.ID {
+					t.Errorf("Expected reply %d ID %v, got %v", i, reply.ID, result.Replies[i].ID)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Conversation struct {
+	ID            uuid.UUID `json:"id" db:"id"`
+	CreatedAt     time.Time `json:"created_at" db:"created_at"`
+	LastMessageAt time.Time `json:"last_message_at" db:"last_message_at"`
+
+	// Joined data, relative to the participant viewing the conversation
+	OtherUserID            uuid.UUID      `json:"other_user_id,omitempty"`
+	OtherUsername          string         `json:"other_username,omitempty"`
+	OtherProfilePictureURL *string        `json:"other_profile_picture_url,omitempty"`
+	LastReadAt             *time.Time     `json:"last_read_at,omitempty"`
+	UnreadCount            int            `json:"unread_count,omitempty"`
+	LastMessage            *DirectMessage `json:"last_message,omitempty"`
+}
+
+type DirectMessage struct {
+	ID             uuid.UUID `json:"id" db:"id"`
+	ConversationID uuid.UUID `json:"conversation_id" db:"conversation_id"`
+	SenderID       uuid.UUID `json:"sender_id" db:"sender_id"`
+	Content        string    `json:"content" db:"content"`
+	CreatedAt      time.Time `json:"created_at" db:"created_at"`
+}
+
+type SendDirectMessageRequest struct {
+	Content string `json:"content" validate:"required,min=1,max=2000"`
+}
+
+type ConversationParticipant struct {
+	ID                uuid.UUID `json:"id"`
+	Username          string    `json:"username"`
+	ProfilePictureURL *string   `json:"profile_picture_url"`
+}
+
+type ConversationResponse struct {
+	ID            uuid.UUID                `json:"id"`
+	OtherUser     *ConversationParticipant `json:"other_user"`
+	LastMessage   *DirectMessageResponse   `json:"last_message"`
+	LastMessageAt time.Time                `json:"last_message_at"`
+	LastReadAt    *time.Time               `json:"last_read_at"`
+	UnreadCount   int                      `json:"unread_count"`
+	CreatedAt     time.Time                `json:"created_at"`
+}
+
+type DirectMessageResponse struct {
+	ID             uuid.UUID `json:"id"`
+	ConversationID uuid.UUID `json:"conversation_id"`
+	SenderID       uuid.UUID `json:"sender_id"`
+	Content        string    `json:"content"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func (c *Conversation) ToResponse() *ConversationResponse {
+	response := &ConversationResponse{
+		ID: c.ID,
+		OtherUser: &ConversationParticipant{
+			ID:                c.OtherUserID,
+			Username:          c.OtherUsername,
+			ProfilePictureURL: c.OtherProfilePictureURL,
+		},
+		LastMessageAt: c.LastMessageAt,
+		LastReadAt:    c.LastReadAt,
+		UnreadCount:   c.UnreadCount,
+		CreatedAt:     c.CreatedAt,
+	}
+
+	if c.LastMessage != nil {
+		response.LastMessage = c.LastMessage.ToResponse()
+	}
+
+	return response
+}
+
+func (m *DirectMessage) ToResponse() *DirectMessageResponse {
+	return &DirectMessageResponse{
+		ID:             m.ID,
+		ConversationID: m.ConversationID,
+		SenderID:       m.SenderID,
+		Content:        m.Content,
+		CreatedAt:      m.CreatedAt,
+	}
+}
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestConversation_ToResponse(t *testing.T) {
+	picture := "https://cdn.example.com/u.jpg"
+	lastRead := time.Now().Add(-time.Hour)
+	lastMessage := &DirectMessage{
+		ID:       uuid.New(),
+		SenderID: uuid.New(),
+		Content:  "Want to co-write?",
+	}
+
+	tests := []struct {
+		name         string
+		conversation *Conversation
+	}{
+		{
+			name: "conversation with last message",
+			conversation: &Conversation{
+				ID:                     uuid.New(),
+				CreatedAt:              time.Now(),
+				LastMessageAt:          time.Now(),
+				OtherUserID:            uuid.New(),
+				OtherUsername:          "bassist",
+				OtherProfilePictureURL: &picture,
+				LastReadAt:             &lastRead,
+				UnreadCount:            2,
+				LastMessage:            lastMessage,
+			},
+		},
+		{
+			name:         "empty conversation",
+			conversation: &Conversation{ID: uuid.New()},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.conversation.ToResponse()
+
+			if result.ID != tt.conversation.ID {
+				t.Errorf("Expected ID %v, got %v", tt.conversation.ID, result.ID)
+			}
+			if result.OtherUser == nil || result.OtherUser.ID != tt.conversation.OtherUserID {
+				t.Errorf("Expected other user %v, got %+v", tt.conversation.OtherUserID, result.OtherUser)
+			}
+			if result.OtherUser.Username != tt.conversation.OtherUsername {
+				t.Errorf("Expected other username %s, got %s", tt.conversation.OtherUsername, result.OtherUser.Username)
+			}
+			if result.UnreadCount != tt.conversation.UnreadCount {
+				t.Errorf("Expected UnreadCount %d, got %d", tt.conversation.UnreadCount, result.UnreadCount)
+			}
+			if result.LastReadAt != tt.conversation.LastReadAt {
+				t.Errorf("Expected LastReadAt %v, got %v", tt.conversation.LastReadAt, result.LastReadAt)
+			}
+
+			if tt.conversation.LastMessage == nil {
+				if result.LastMessage != nil {
+					t.Errorf("Expected nil LastMessage, got %+v", result.LastMessage)
+				}
+				return
+			}
+			if result.LastMessage == nil || result.LastMessage.ID != tt.conversation.LastMessage.ID {
+				t.Errorf("Expected LastMessage %v, got %+v", tt.conversation.LastMessage.ID, result.LastMessage)
+			}
+		})
+	}
+}
+
+func TestDirectMessage_ToResponse(t *testing.T) {
+	message := &DirectMessage{
+		ID:             uuid.New(),
+		ConversationID: uuid.New(),
+		SenderID:       uuid.New(),
+		Content:        "Sent you the stems",
+		CreatedAt:      time.Now(),
+	}
+
+	result := message.ToResponse()
+
+	if result.ID != message.ID {
+		t.Errorf("Expected ID %v, got %v", message.ID, result.ID)
+	}
+	if result.ConversationID != message.ConversationID {
+		t.Errorf("Expected ConversationID %v, got %v", message.ConversationID, result.ConversationID)
+	}
+	if result.SenderID != message.SenderID {
+		t.Errorf("Expected SenderID %v, got %v", message.SenderID, result.SenderID)
+	}
+	if result.Content != message.Content {
+		t.Errorf("Expected Content %s, got %s", message.Content, result.Content)
+	}
+	if !result.CreatedAt.Equal(message.CreatedAt) {
+		t.Errorf("Expected CreatedAt %v, got %v", message.CreatedAt, result.CreatedAt)
+	}
+}
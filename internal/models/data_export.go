@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of a data export
+const (
+	DataExportStatusPending    = "pending"
+	DataExportStatusProcessing = "processing"
+	DataExportStatusCompleted  = "completed"
+	DataExportStatusFailed     = "failed"
+	DataExportStatusExpired    = "expired"
+)
+
+// DataExport is a user's request for a copy of their personal data. The
+// archive is built in the background and stored under ObjectKey until
+// ExpiresAt.
+type DataExport struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Status      string     `json:"status" db:"status"`
+	ObjectKey   *string    `json:"-" db:"object_key"`
+	SizeBytes   *int64     `json:"size_bytes" db:"size_bytes"`
+	Error       *string    `json:"-" db:"error"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	StartedAt   *time.Time `json:"started_at" db:"started_at"`
+	CompletedAt *time.Time `json:"completed_at" db:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at" db:"expires_at"`
+}
+
+type DataExportResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Status      string     `json:"status"`
+	SizeBytes   *int64     `json:"size_bytes,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+
+	// DownloadURL is a short-lived link to the archive, set on completed
+	// exports when a single export is fetched
+	DownloadURL string `json:"download_url,omitempty"`
+}
+
+func (e *DataExport) ToResponse() *DataExportResponse {
+	return &DataExportResponse{
+		ID:          e.ID,
+		Status:      e.Status,
+		SizeBytes:   e.SizeBytes,
+		CreatedAt:   e.CreatedAt,
+		CompletedAt: e.CompletedAt,
+		ExpiresAt:   e.ExpiresAt,
+	}
+}
+
+// DataExportFile is one JSON document in a data export archive
+type DataExportFile struct {
+	Name string
+	Data []byte
+}
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Identity links an account at an external identity provider to a user.
+// Subject is the provider's stable ID for the account.
+type Identity struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Provider    string     `json:"provider" db:"provider"`
+	Subject     string     `json:"-" db:"subject"`
+	Email       *string    `json:"email" db:"email"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at" db:"last_login_at"`
+}
+
+type IdentityResponse struct {
+	ID          uuid.UUID  `json:"id"`
+	Provider    string     `json:"provider"`
+	Email       *string    `json:"email"`
+	CreatedAt   time.Time  `json:"created_at"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+}
+
+func (i *Identity) ToResponse() *IdentityResponse {
+	return &IdentityResponse{
+		ID:          i.ID,
+		Provider:    i.Provider,
+		Email:       i.Email,
+		CreatedAt:   i.CreatedAt,
+		LastLoginAt: i.LastLoginAt,
+	}
+}
+
+// OIDCAuthorization is where to send the user to sign in at a provider
+type OIDCAuthorization struct {
+	AuthorizationURL string `json:"authorization_url"`
+	ExpiresIn        int    `json:"expires_in"` // Seconds the sign-in may take
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Message struct {
+	ID        uuid.UUID `json:"id" db:"id"`
+	BandID    uuid.UUID `json:"band_id" db:"band_id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	Content   string    `json:"content" db:"content"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+
+	// Joined data
+	Username string `json:"username,omitempty"`
+}
+
+type CreateMessageRequest struct {
+	Content string `json:"content" validate:"required,min=1,max=2000"`
+}
+
+type MessageResponse struct {
+	ID        uuid.UUID `json:"id"`
+	BandID    uuid.UUID `json:"band_id"`
+	UserID    uuid.UUID `json:"user_id"`
+	Username  string    `json:"username"`
+	Content   string    `json:"content"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (m *Message) ToResponse() *MessageResponse {
+	return &MessageResponse{
+		ID:        m.ID,
+		BandID:    m.BandID,
+		UserID:    m.UserID,
+		Username:  m.Username,
+		Content:   m.Content,
+		CreatedAt: m.CreatedAt,
+	}
+}
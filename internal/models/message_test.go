@@ -0,0 +1,56 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestMessage_ToResponse(t *testing.T) {
+	tests := []struct {
+		name    string
+		message *Message
+	}{
+		{
+			name: "complete message",
+			message: &Message{
+				ID:        uuid.New(),
+				BandID:    uuid.New(),
+				UserID:    uuid.New(),
+				Content:   "Rehearsal moved to 7pm",
+				CreatedAt: time.Now(),
+				Username:  "drummer",
+			},
+		},
+		{
+			name:    "message with zero values",
+			message: &Message{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := tt.message.ToResponse()
+
+			if result.ID != tt.message.ID {
+				t.Errorf("Expected ID %v, got %v", tt.message.ID, result.ID)
+			}
+			if result.BandID != tt.message.BandID {
+				t.Errorf("Expected BandID %v, got %v", tt.message.BandID, result.BandID)
+			}
+			if result.UserID != tt.message.UserID {
+				t.Errorf("Expected UserID %v, got %v", tt.message.UserID, result.UserID)
+			}
+			if result.Username != tt.message.Username {
+				t.Errorf("Expected Username %s, got %s", tt.message.Username, result.Username)
+			}
+			if result.Content != tt.message.Content {
+				t.Errorf("Expected Content %s, got %s", tt.message.Content, result.Content)
+			}
+			if !result.CreatedAt.Equal(tt.message.CreatedAt) {
+				t.Errorf("Expected CreatedAt %v, got %v", tt.message.CreatedAt, result.CreatedAt)
+			}
+		})
+	}
+}
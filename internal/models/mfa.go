@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TOTPCredential is a user's authenticator app enrollment. Two-factor login
+// is only enforced once the enrollment is confirmed.
+type TOTPCredential struct {
+	UserID       uuid.UUID  `json:"user_id" db:"user_id"`
+	Secret       string     `json:"-" db:"secret"`
+	ConfirmedAt  *time.Time `json:"confirmed_at" db:"confirmed_at"`
+	LastUsedStep *int64     `json:"-" db:"last_used_step"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// TOTPEnrollment is returned when a user starts enrolling an authenticator.
+// ProvisioningURI is meant to be rendered as a QR code.
+type TOTPEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+// RecoveryCodesResponse lists freshly generated recovery codes. They are
+// only ever shown once.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// MFAChallenge is issued instead of tokens when a password login needs a
+// second factor
+type MFAChallenge struct {
+	Token     string
+	ExpiresIn int // Seconds until the challenge expires
+}
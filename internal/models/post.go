@@ -18,18 +18,42 @@ type Post struct {
 	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt  time.Time  `json:"updated_at" db:"updated_at"`
 
+	// QuotedPostID is set on quote posts, which repost another post with commentary
+	QuotedPostID *uuid.UUID `json:"quoted_post_id" db:"quoted_post_id"`
+
 	// Joined data
-	Author       interface{} `json:"author,omitempty"` // User or Band
-	LikesCount   int         `json:"likes_count,omitempty"`
-	RepostsCount int         `json:"reposts_count,omitempty"`
-	IsLiked      bool        `json:"is_liked,omitempty"`
-	IsReposted   bool        `json:"is_reposted,omitempty"`
+	Author        interface{} `json:"author,omitempty"` // *PostAuthor for the user or band
+	LikesCount    int         `json:"likes_count,omitempty"`
+	RepostsCount  int         `json:"reposts_count,omitempty"`
+	CommentsCount int         `json:"comments_count,omitempty"`
+	IsLiked       bool        `json:"is_liked,omitempty"`
+	IsReposted    bool        `json:"is_reposted,omitempty"`
+	QuotedPost    *Post       `json:"quoted_post,omitempty"`
+
+	// Set when the post appears in a feed because followed users reposted it.
+	// RepostedBy is the most recent of RepostersCount followed reposters.
+	RepostedByID   *uuid.UUID  `json:"reposted_by_id,omitempty"`
+	RepostedBy     *PostAuthor `json:"reposted_by,omitempty"`
+	RepostedAt     *time.Time  `json:"reposted_at,omitempty"`
+	RepostersCount int         `json:"reposters_count,omitempty"`
+}
+
+// PostAuthor is the compact user or band summary embedded in posts so
+// clients can render the author without a separate request
+type PostAuthor struct {
+	ID                uuid.UUID `json:"id"`
+	Type              string    `json:"type"` // "user" or "band"
+	Username          string    `json:"username,omitempty"`
+	DisplayName       *string   `json:"display_name,omitempty"`
+	Name              string    `json:"name,omitempty"`
+	ProfilePictureURL *string   `json:"profile_picture_url"`
 }
 
 type CreatePostRequest struct {
-	Content    string   `json:"content" validate:"required,min=1,max=2000"`
-	MediaURLs  []string `json:"media_urls,omitempty"`
-	MediaTypes []string `json:"media_types,omitempty"`
+	Content      string     `json:"content" validate:"required,min=1,max=2000"`
+	MediaURLs    []string   `json:"media_urls,omitempty"`
+	MediaTypes   []string   `json:"media_types,omitempty"`
+	QuotedPostID *uuid.UUID `json:"quoted_post_id,omitempty"`
 }
 
 type UpdatePostRequest struct {
@@ -39,39 +63,56 @@ type UpdatePostRequest struct {
 }
 
 type PostResponse struct {
-	ID           uuid.UUID   `json:"id"`
-	AuthorID     *uuid.UUID  `json:"author_id"`
-	AuthorType   string      `json:"author_type"`
-	BandID       *uuid.UUID  `json:"band_id"`
-	UserID       *uuid.UUID  `json:"user_id"`
-	Content      string      `json:"content"`
-	MediaURLs    []string    `json:"media_urls"`
-	MediaTypes   []string    `json:"media_types"`
-	CreatedAt    time.Time   `json:"created_at"`
-	UpdatedAt    time.Time   `json:"updated_at"`
-	Author       interface{} `json:"author,omitempty"`
-	LikesCount   int         `json:"likes_count"`
-	RepostsCount int         `json:"reposts_count"`
-	IsLiked      bool        `json:"is_liked"`
-	IsReposted   bool        `json:"is_reposted"`
+	ID             uuid.UUID     `json:"id"`
+	AuthorID       *uuid.UUID    `json:"author_id"`
+	AuthorType     string        `json:"author_type"`
+	BandID         *uuid.UUID    `json:"band_id"`
+	UserID         *uuid.UUID    `json:"user_id"`
+	Content        string        `json:"content"`
+	MediaURLs      []string      `json:"media_urls"`
+	MediaTypes     []string      `json:"media_types"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+	Author         interface{}   `json:"author,omitempty"`
+	LikesCount     int           `json:"likes_count"`
+	RepostsCount   int           `json:"reposts_count"`
+	CommentsCount  int           `json:"comments_count"`
+	IsLiked        bool          `json:"is_liked"`
+	IsReposted     bool          `json:"is_reposted"`
+	QuotedPostID   *uuid.UUID    `json:"quoted_post_id"`
+	QuotedPost     *PostResponse `json:"quoted_post,omitempty"`
+	RepostedBy     *PostAuthor   `json:"reposted_by,omitempty"`
+	RepostedAt     *time.Time    `json:"reposted_at,omitempty"`
+	RepostersCount int           `json:"reposters_count,omitempty"`
 }
 
 func (p *Post) ToResponse() *PostResponse {
+	var quoted *PostResponse
+	if p.QuotedPost != nil {
+		quoted = p.QuotedPost.ToResponse()
+	}
+
 	return &PostResponse{
-		ID:           p.ID,
-		AuthorID:     p.AuthorID,
-		AuthorType:   p.AuthorType,
-		BandID:       p.BandID,
-		UserID:       p.UserID,
-		Content:      p.Content,
-		MediaURLs:    p.MediaURLs,
-		MediaTypes:   p.MediaTypes,
-		CreatedAt:    p.CreatedAt,
-		UpdatedAt:    p.UpdatedAt,
-		Author:       p.Author,
-		LikesCount:   p.LikesCount,
-		RepostsCount: p.RepostsCount,
-		IsLiked:      p.IsLiked,
-		IsReposted:   p.IsReposted,
+		ID:             p.ID,
+		AuthorID:       p.AuthorID,
+		AuthorType:     p.AuthorType,
+		BandID:         p.BandID,
+		UserID:         p.UserID,
+		Content:        p.Content,
+		MediaURLs:      p.MediaURLs,
+		MediaTypes:     p.MediaTypes,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+		Author:         p.Author,
+		LikesCount:     p.LikesCount,
+		RepostsCount:   p.RepostsCount,
+		CommentsCount:  p.CommentsCount,
+		IsLiked:        p.IsLiked,
+		IsReposted:     p.IsReposted,
+		QuotedPostID:   p.QuotedPostID,
+		QuotedPost:     quoted,
+		RepostedBy:     p.RepostedBy,
+		RepostedAt:     p.RepostedAt,
+		RepostersCount: p.RepostersCount,
 	}
 }
@@ -29,6 +29,7 @@ func TestPost_ToResponse(t *testing.T) {
 				Author:     "Test User",
 				LikesCount: 10,
 				RepostsCount: 5,
+				CommentsCount: 7,
 				IsLiked:    true,
 				IsReposted: false,
 			},
@@ -46,6 +47,7 @@ func TestPost_ToResponse(t *testing.T) {
 				Author:       "Test User",
 				LikesCount:   10,
 				RepostsCount: 5,
+				CommentsCount: 7,
 				IsLiked:      true,
 				IsReposted:   false,
 			},
@@ -187,6 +189,9 @@ func TestPost_ToResponse(t *testing.T) {
 			if result.RepostsCount != tt.expected.RepostsCount {
 				t.Errorf("Expected RepostsCount %d, got %d", tt.expected.RepostsCount, result.RepostsCount)
 			}
+			if result.CommentsCount != tt.expected.CommentsCount {
+				t.Errorf("Expected CommentsCount %d, got %d", tt.expected.CommentsCount, result.CommentsCount)
+			}
 			if result.IsLiked != tt.expected.IsLiked {
 				t.Errorf("Expected IsLiked %v, got %v", tt.expected.IsLiked, result.IsLiked)
 			}
@@ -197,6 +202,44 @@ func TestPost_ToResponse(t *testing.T) {
 	}
 }
 
+func TestPost_ToResponse_QuoteAndRepost(t *testing.T) {
+	original := &Post{ID: uuid.New(), AuthorType: "user", Content: "Original"}
+	reposter := &PostAuthor{ID: uuid.New(), Type: "user", Username: "fan"}
+	repostedAt := time.Now()
+	quote := &Post{
+		ID:             uuid.New(),
+		AuthorType:     "user",
+		Content:        "Listen to this",
+		QuotedPostID:   &original.ID,
+		QuotedPost:     original,
+		RepostedBy:     reposter,
+		RepostedAt:     &repostedAt,
+		RepostersCount: 3,
+	}
+
+	result := quote.ToResponse()
+
+	if !compareUUIDPtrs(result.QuotedPostID, &original.ID) {
+		t.Errorf("Expected QuotedPostID %v, got %v", original.ID, result.QuotedPostID)
+	}
+	if result.QuotedPost == nil || result.QuotedPost.ID != original.ID || result.QuotedPost.Content != "Original" {
+		t.Errorf("Expected embedded quoted post, got %+v", result.QuotedPost)
+	}
+	if result.RepostedBy != reposter {
+		t.Errorf("Expected RepostedBy %v, got %v", reposter, result.RepostedBy)
+	}
+	if result.RepostedAt == nil || !result.RepostedAt.Equal(repostedAt) {
+		t.Errorf("Expected RepostedAt %v, got %v", repostedAt, result.RepostedAt)
+	}
+	if result.RepostersCount != 3 {
+		t.Errorf("Expected RepostersCount 3, got %d", result.RepostersCount)
+	}
+
+	if plain := original.ToResponse(); plain.QuotedPost != nil || plain.QuotedPostID != nil {
+		t.Errorf("Expected no quote on a plain post, got %+v", plain.QuotedPost)
+	}
+}
+
 // Helper function for UUID pointers
 func uuidPtr(u uuid.UUID) *uuid.UUID {
 	return &u
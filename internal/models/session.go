@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Session is one signed-in device. Its ID is also the refresh token family
+// and the sid claim of the access tokens issued to the device.
+type Session struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"user_id" db:"user_id"`
+	DeviceName *string    `json:"device_name" db:"device_name"`
+	UserAgent  *string    `json:"user_agent" db:"user_agent"`
+	IPAddress  *string    `json:"ip_address" db:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at" db:"created_at"`
+	LastSeenAt time.Time  `json:"last_seen_at" db:"last_seen_at"`
+	RevokedAt  *time.Time `json:"revoked_at" db:"revoked_at"`
+
+	// Whether this is the session making the request
+	Current bool `json:"current,omitempty"`
+}
+
+// DeviceInfo describes the client a session is started or refreshed from
+type DeviceInfo struct {
+	Name      string
+	UserAgent string
+	IPAddress string
+}
+
+type SessionResponse struct {
+	ID         uuid.UUID `json:"id"`
+	DeviceName *string   `json:"device_name"`
+	UserAgent  *string   `json:"user_agent"`
+	IPAddress  *string   `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current"`
+}
+
+func (s *Session) ToResponse() *SessionResponse {
+	return &SessionResponse{
+		ID:         s.ID,
+		DeviceName: s.DeviceName,
+		UserAgent:  s.UserAgent,
+		IPAddress:  s.IPAddress,
+		CreatedAt:  s.CreatedAt,
+		LastSeenAt: s.LastSeenAt,
+		Current:    s.Current,
+	}
+}
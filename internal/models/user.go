@@ -7,23 +7,39 @@ import (
 )
 
 type User struct {
-	ID                uuid.UUID `json:"id" db:"id"`
-	Username          string    `json:"username" db:"username"`
-	Email             string    `json:"email" db:"email"`
-	PasswordHash      string    `json:"-" db:"password_hash"`
-	DisplayName       *string   `json:"display_name" db:"display_name"`
-	Bio               *string   `json:"bio" db:"bio"`
-	ProfilePictureURL *string   `json:"profile_picture_url" db:"profile_picture_url"`
-	Location          *Location `json:"location" db:"location"`
-	City              *string   `json:"city" db:"city"`
-	Country           *string   `json:"country" db:"country"`
-	Genres            []string  `json:"genres" db:"genres"`
-	Skills            []string  `json:"skills" db:"skills"`
-	SpotifyURL        *string   `json:"spotify_url" db:"spotify_url"`
-	SoundcloudURL     *string   `json:"soundcloud_url" db:"soundcloud_url"`
-	InstagramHandle   *string   `json:"instagram_handle" db:"instagram_handle"`
-	CreatedAt         time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at" db:"updated_at"`
+	ID                uuid.UUID  `json:"id" db:"id"`
+	Username          string     `json:"username" db:"username"`
+	Email             string     `json:"email" db:"email"`
+	PasswordHash      string     `json:"-" db:"password_hash"`
+	DisplayName       *string    `json:"display_name" db:"display_name"`
+	Bio               *string    `json:"bio" db:"bio"`
+	ProfilePictureURL *string    `json:"profile_picture_url" db:"profile_picture_url"`
+	Location          *Location  `json:"location" db:"location"`
+	City              *string    `json:"city" db:"city"`
+	Country           *string    `json:"country" db:"country"`
+	Genres            []string   `json:"genres" db:"genres"`
+	Skills            []string   `json:"skills" db:"skills"`
+	SpotifyURL        *string    `json:"spotify_url" db:"spotify_url"`
+	SoundcloudURL     *string    `json:"soundcloud_url" db:"soundcloud_url"`
+	InstagramHandle   *string    `json:"instagram_handle" db:"instagram_handle"`
+	CreatedAt         time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at" db:"updated_at"`
+	EmailVerifiedAt   *time.Time `json:"email_verified_at" db:"email_verified_at"`
+
+	// DeletionScheduledAt is when the account will be permanently deleted,
+	// if the user asked for that; until then the deletion can be cancelled
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at" db:"deletion_scheduled_at"`
+
+	// Role is the user's platform role, one of middleware.Roles
+	Role string `json:"role" db:"role"`
+
+	// SuspendedAt is set while staff have suspended the account, which stops
+	// it from signing in or using API keys
+	SuspendedAt      *time.Time `json:"suspended_at" db:"suspended_at"`
+	SuspensionReason *string    `json:"-" db:"suspension_reason"`
+
+	// Joined data: when the follow was created, for follower/following lists
+	FollowedAt *time.Time `json:"followed_at,omitempty"`
 }
 
 type Location struct {
@@ -54,22 +70,25 @@ type UpdateUserRequest struct {
 }
 
 type UserResponse struct {
-	ID                uuid.UUID `json:"id"`
-	Username          string    `json:"username"`
-	Email             string    `json:"email"`
-	DisplayName       *string   `json:"display_name"`
-	Bio               *string   `json:"bio"`
-	ProfilePictureURL *string   `json:"profile_picture_url"`
-	Location          *Location `json:"location"`
-	City              *string   `json:"city"`
-	Country           *string   `json:"country"`
-	Genres            []string  `json:"genres"`
-	Skills            []string  `json:"skills"`
-	SpotifyURL        *string   `json:"spotify_url"`
-	SoundcloudURL     *string   `json:"soundcloud_url"`
-	InstagramHandle   *string   `json:"instagram_handle"`
-	CreatedAt         time.Time `json:"created_at"`
-	UpdatedAt         time.Time `json:"updated_at"`
+	ID                uuid.UUID  `json:"id"`
+	Username          string     `json:"username"`
+	Email             string     `json:"email"`
+	DisplayName       *string    `json:"display_name"`
+	Bio               *string    `json:"bio"`
+	ProfilePictureURL *string    `json:"profile_picture_url"`
+	Location          *Location  `json:"location"`
+	City              *string    `json:"city"`
+	Country           *string    `json:"country"`
+	Genres            []string   `json:"genres"`
+	Skills            []string   `json:"skills"`
+	SpotifyURL        *string    `json:"spotify_url"`
+	SoundcloudURL     *string    `json:"soundcloud_url"`
+	InstagramHandle   *string    `json:"instagram_handle"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+	EmailVerified     bool       `json:"email_verified"`
+	Role              string     `json:"role"`
+	FollowedAt        *time.Time `json:"followed_at,omitempty"`
 }
 
 func (u *User) ToResponse() *UserResponse {
@@ -90,5 +109,22 @@ func (u *User) ToResponse() *UserResponse {
 		InstagramHandle:   u.InstagramHandle,
 		CreatedAt:         u.CreatedAt,
 		UpdatedAt:         u.UpdatedAt,
+		EmailVerified:     u.EmailVerifiedAt != nil,
+		Role:              u.Role,
+		FollowedAt:        u.FollowedAt,
+	}
+}
+
+// CurrentUserResponse is the signed-in user's own profile, including account
+// state other users do not see
+type CurrentUserResponse struct {
+	*UserResponse
+	DeletionScheduledAt *time.Time `json:"deletion_scheduled_at,omitempty"`
+}
+
+func (u *User) ToCurrentUserResponse() *CurrentUserResponse {
+	return &CurrentUserResponse{
+		UserResponse:        u.ToResponse(),
+		DeletionScheduledAt: u.DeletionScheduledAt,
 	}
 }
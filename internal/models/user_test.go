@@ -1,6 +1,8 @@
 package models
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -196,6 +198,24 @@ func TestUser_ToResponse(t *testing.T) {
 }
 
 // Helper functions for comparison
+func TestUser_DeletionScheduledAtOnlyForTheOwner(t *testing.T) {
+	deleteAt := time.Now().Add(30 * 24 * time.Hour)
+	user := &User{ID: uuid.New(), Username: "leaving", DeletionScheduledAt: &deleteAt}
+
+	public, err := json.Marshal(user.ToResponse())
+	if err != nil {
+		t.Fatalf("Failed to marshal response: %v", err)
+	}
+	if strings.Contains(string(public), "deletion_scheduled_at") {
+		t.Errorf("Expected the public profile not to reveal the deletion date, got %s", public)
+	}
+
+	own := user.ToCurrentUserResponse()
+	if own.DeletionScheduledAt == nil || !own.DeletionScheduledAt.Equal(deleteAt) || own.Username != "leaving" {
+		t.Errorf("Expected the owner's view to include the deletion date, got %+v", own)
+	}
+}
+
 func stringPtr(s string) *string {
 	return &s
 }
@@ -0,0 +1,325 @@
+// Package oidc signs users in with external identity providers using the
+// OAuth2 authorization code flow with PKCE. Providers are configured by
+// issuer URL and their endpoints are discovered from the issuer's
+// /.well-known/openid-configuration. Plain OAuth2 providers without
+// discovery can be configured with explicit endpoints instead, in which case
+// the user is identified through the userinfo endpoint.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxResponseSize bounds the responses read from a provider
+const maxResponseSize = 1 << 20
+
+// Config describes an identity provider
+type Config struct {
+	Name         string
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	// Endpoints for providers without OIDC discovery. When AuthURL,
+	// TokenURL and UserInfoURL are all set, discovery is skipped.
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+}
+
+// Identity is the user a provider vouched for
+type Identity struct {
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+}
+
+// metadata is the subset of the discovery document the flow needs
+type metadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+// Provider talks to one identity provider. Discovery metadata and signing
+// keys are fetched on first use and cached.
+type Provider struct {
+	config Config
+	client *http.Client
+
+	mu       sync.Mutex
+	metadata *metadata
+	keys     *keyCache
+}
+
+// NewProvider creates a provider. openid, email and profile are requested
+// when no scopes are configured.
+func NewProvider(config Config) *Provider {
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "email", "profile"}
+	}
+	config.Issuer = strings.TrimRight(config.Issuer, "/")
+
+	p := &Provider{
+		config: config,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+	p.keys = newKeyCache(p.client)
+	return p
+}
+
+func (p *Provider) Name() string {
+	return p.config.Name
+}
+
+// AuthCodeURL returns the URL to send the user to. state is echoed back to
+// the redirect URL, nonce is bound into the ID token, and codeChallenge is
+// the S256 PKCE challenge for the verifier later passed to Exchange.
+func (p *Provider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	authURL, err := url.Parse(meta.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %w", err)
+	}
+
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", p.config.ClientID)
+	query.Set("redirect_uri", p.config.RedirectURL)
+	query.Set("scope", strings.Join(p.config.Scopes, " "))
+	query.Set("state", state)
+	query.Set("nonce", nonce)
+	query.Set("code_challenge", codeChallenge)
+	query.Set("code_challenge_method", "S256")
+	authURL.RawQuery = query.Encode()
+
+	return authURL.String(), nil
+}
+
+// tokenResponse is the token endpoint's reply
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Exchange redeems an authorization code and returns the signed-in user.
+// The ID token is verified against the provider's keys and the nonce; if
+// the provider issues none, the user is read from the userinfo endpoint.
+func (p *Provider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*Identity, error) {
+	meta, err := p.discover(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, meta.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	var token tokenResponse
+	if err := doJSON(p.client, req, &token); err != nil && token.Error == "" {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	if token.Error != "" {
+		return nil, fmt.Errorf("token request failed: %s %s", token.Error, token.ErrorDescription)
+	}
+
+	var identity *Identity
+	if token.IDToken != "" {
+		identity, err = p.verifyIDToken(ctx, meta, token.IDToken, nonce)
+		if err != nil {
+			return nil, err
+		}
+		if identity.Email != "" || meta.UserInfoEndpoint == "" {
+			return identity, nil
+		}
+	}
+
+	if meta.UserInfoEndpoint == "" || token.AccessToken == "" {
+		return nil, fmt.Errorf("provider returned neither an ID token nor a userinfo endpoint")
+	}
+	info, err := p.userInfo(ctx, meta.UserInfoEndpoint, token.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Some providers leave the email out of the ID token; take it from
+	// userinfo as long as both describe the same user
+	if identity != nil {
+		if info.Subject != identity.Subject {
+			return nil, fmt.Errorf("userinfo subject does not match ID token")
+		}
+		identity.Email, identity.EmailVerified = info.Email, info.EmailVerified
+		return identity, nil
+	}
+	return info, nil
+}
+
+// userInfo reads the user from the userinfo endpoint. Plain OAuth2
+// providers often use id instead of sub, sometimes as a number.
+func (p *Provider) userInfo(ctx context.Context, endpoint, accessToken string) (*Identity, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+
+	var info struct {
+		Subject           flexString `json:"sub"`
+		ID                flexString `json:"id"`
+		Email             string     `json:"email"`
+		EmailVerified     flexBool   `json:"email_verified"`
+		Name              string     `json:"name"`
+		DisplayName       string     `json:"display_name"`
+		PreferredUsername string     `json:"preferred_username"`
+		Username          string     `json:"username"`
+	}
+	if err := doJSON(p.client, req, &info); err != nil {
+		return nil, fmt.Errorf("userinfo request failed: %w", err)
+	}
+
+	identity := &Identity{
+		Subject:           string(info.Subject),
+		Email:             info.Email,
+		EmailVerified:     bool(info.EmailVerified),
+		Name:              firstNonEmpty(info.Name, info.DisplayName),
+		PreferredUsername: firstNonEmpty(info.PreferredUsername, info.Username),
+	}
+	if identity.Subject == "" {
+		identity.Subject = string(info.ID)
+	}
+	if identity.Subject == "" {
+		return nil, fmt.Errorf("userinfo response has no subject")
+	}
+	return identity, nil
+}
+
+// discover returns the provider's endpoints, fetching the discovery
+// document on first use
+func (p *Provider) discover(ctx context.Context) (*metadata, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.metadata != nil {
+		return p.metadata, nil
+	}
+
+	if p.config.AuthURL != "" && p.config.TokenURL != "" && p.config.UserInfoURL != "" {
+		p.metadata = &metadata{
+			Issuer:                p.config.Issuer,
+			AuthorizationEndpoint: p.config.AuthURL,
+			TokenEndpoint:         p.config.TokenURL,
+			UserInfoEndpoint:      p.config.UserInfoURL,
+		}
+		return p.metadata, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.config.Issuer+"/.well-known/openid-configuration", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var meta metadata
+	if err := doJSON(p.client, req, &meta); err != nil {
+		return nil, fmt.Errorf("discovery failed for %s: %w", p.config.Issuer, err)
+	}
+	// The document must be for the configured issuer, or a compromised
+	// discovery endpoint could point us at another issuer's keys
+	if strings.TrimRight(meta.Issuer, "/") != p.config.Issuer {
+		return nil, fmt.Errorf("discovery issuer %q does not match %q", meta.Issuer, p.config.Issuer)
+	}
+	if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" {
+		return nil, fmt.Errorf("discovery document for %s is missing endpoints", p.config.Issuer)
+	}
+
+	p.metadata = &meta
+	return p.metadata, nil
+}
+
+// doJSON sends a request and decodes the JSON response into out. Error
+// responses are decoded too, since OAuth2 errors carry a JSON body.
+func doJSON(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decodeErr := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(out)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return decodeErr
+}
+
+// flexBool accepts booleans sent as JSON strings, as some providers do for
+// email_verified
+type flexBool bool
+
+func (b *flexBool) UnmarshalJSON(data []byte) error {
+	switch strings.Trim(string(data), `"`) {
+	case "true":
+		*b = true
+	default:
+		*b = false
+	}
+	return nil
+}
+
+// flexString accepts identifiers sent as JSON strings or numbers
+type flexString string
+
+func (s *flexString) UnmarshalJSON(data []byte) error {
+	if strings.HasPrefix(string(data), `"`) {
+		var value string
+		if err := json.Unmarshal(data, &value); err != nil {
+			return err
+		}
+		*s = flexString(value)
+		return nil
+	}
+	if string(data) != "null" {
+		*s = flexString(data)
+	}
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,314 @@
+package oidc
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// mockIssuer is a minimal OIDC provider. It hands out one authorization
+// code bound to a PKCE challenge and nonce, like a real provider would
+// after the user signs in.
+type mockIssuer struct {
+	server *httptest.Server
+	key    *rsa.PrivateKey
+	kid    string
+
+	code      string
+	challenge string
+	nonce     string
+	claims    jwt.MapClaims // extra ID token claims
+	issuer    string        // issuer advertised in discovery, if not the server URL
+	noIDToken bool
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+
+	m := &mockIssuer{key: key, kid: "key-1", code: "auth-code"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		issuer := m.issuer
+		if issuer == "" {
+			issuer = m.server.URL
+		}
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":                 issuer,
+			"authorization_endpoint": m.server.URL + "/authorize",
+			"token_endpoint":         m.server.URL + "/token",
+			"userinfo_endpoint":      m.server.URL + "/userinfo",
+			"jwks_uri":               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"keys": []map[string]string{{
+				"kty": "RSA",
+				"kid": m.kid,
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+			}},
+		})
+	})
+	mux.HandleFunc("/token", m.token)
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"id": 12345, "email": "producer@example.com", "display_name": "Producer"}`))
+	})
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+	return m
+}
+
+func (m *mockIssuer) token(w http.ResponseWriter, r *http.Request) {
+	clientID, secret, _ := r.BasicAuth()
+	r.ParseForm()
+
+	if clientID != "client" || secret != "secret" {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+		return
+	}
+	if r.Form.Get("code") != m.code || CodeChallenge(r.Form.Get("code_verifier")) != m.challenge {
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	response := map[string]string{"access_token": "access-token", "token_type": "Bearer"}
+	if !m.noIDToken {
+		response["id_token"] = m.idToken()
+	}
+	json.NewEncoder(w).Encode(response)
+}
+
+// idToken signs an ID token for the authorized user
+func (m *mockIssuer) idToken() string {
+	claims := jwt.MapClaims{
+		"iss":            m.server.URL,
+		"sub":            "subject-1",
+		"aud":            "client",
+		"exp":            time.Now().Add(time.Hour).Unix(),
+		"iat":            time.Now().Unix(),
+		"nonce":          m.nonce,
+		"email":          "producer@example.com",
+		"email_verified": true,
+	}
+	for k, v := range m.claims {
+		claims[k] = v
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = m.kid
+	signed, _ := token.SignedString(m.key)
+	return signed
+}
+
+func (m *mockIssuer) provider() *Provider {
+	return NewProvider(Config{
+		Name:         "mock",
+		Issuer:       m.server.URL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:3000/oauth/mock/callback",
+	})
+}
+
+// authorize simulates the user signing in at the provider
+func (m *mockIssuer) authorize(t *testing.T, provider *Provider) (verifier string) {
+	t.Helper()
+	verifier, err := GenerateCodeVerifier()
+	if err != nil {
+		t.Fatalf("Failed to generate verifier: %v", err)
+	}
+
+	authURL, err := provider.AuthCodeURL(context.Background(), "state-1", "nonce-1", CodeChallenge(verifier))
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	parsed, _ := url.Parse(authURL)
+	m.challenge = parsed.Query().Get("code_challenge")
+	m.nonce = parsed.Query().Get("nonce")
+	return verifier
+}
+
+func TestProvider_AuthCodeURL(t *testing.T) {
+	m := newMockIssuer(t)
+
+	authURL, err := m.provider().AuthCodeURL(context.Background(), "state-1", "nonce-1", "challenge-1")
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	parsed, _ := url.Parse(authURL)
+	if !strings.HasPrefix(authURL, m.server.URL+"/authorize?") {
+		t.Errorf("Expected discovered authorization endpoint, got %s", authURL)
+	}
+	expected := map[string]string{
+		"response_type":         "code",
+		"client_id":             "client",
+		"redirect_uri":          "http://localhost:3000/oauth/mock/callback",
+		"scope":                 "openid email profile",
+		"state":                 "state-1",
+		"nonce":                 "nonce-1",
+		"code_challenge":        "challenge-1",
+		"code_challenge_method": "S256",
+	}
+	for key, value := range expected {
+		if got := parsed.Query().Get(key); got != value {
+			t.Errorf("Expected %s=%q, got %q", key, value, got)
+		}
+	}
+}
+
+func TestProvider_Exchange(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("verified ID token", func(t *testing.T) {
+		m := newMockIssuer(t)
+		provider := m.provider()
+		verifier := m.authorize(t, provider)
+
+		identity, err := provider.Exchange(ctx, "auth-code", verifier, "nonce-1")
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if identity.Subject != "subject-1" || identity.Email != "producer@example.com" || !identity.EmailVerified {
+			t.Errorf("Unexpected identity: %+v", identity)
+		}
+	})
+
+	t.Run("wrong code verifier", func(t *testing.T) {
+		m := newMockIssuer(t)
+		provider := m.provider()
+		m.authorize(t, provider)
+
+		if _, err := provider.Exchange(ctx, "auth-code", "not-the-verifier", "nonce-1"); err == nil {
+			t.Error("Expected exchange with the wrong verifier to fail")
+		}
+	})
+
+	t.Run("nonce mismatch", func(t *testing.T) {
+		m := newMockIssuer(t)
+		provider := m.provider()
+		verifier := m.authorize(t, provider)
+
+		if _, err := provider.Exchange(ctx, "auth-code", verifier, "other-nonce"); err == nil || !strings.Contains(err.Error(), "nonce") {
+			t.Errorf("Expected nonce mismatch, got: %v", err)
+		}
+	})
+
+	t.Run("wrong audience", func(t *testing.T) {
+		m := newMockIssuer(t)
+		m.claims = jwt.MapClaims{"aud": "another-client"}
+		provider := m.provider()
+		verifier := m.authorize(t, provider)
+
+		if _, err := provider.Exchange(ctx, "auth-code", verifier, "nonce-1"); err == nil {
+			t.Error("Expected ID token for another client to be rejected")
+		}
+	})
+
+	t.Run("expired ID token", func(t *testing.T) {
+		m := newMockIssuer(t)
+		m.claims = jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()}
+		provider := m.provider()
+		verifier := m.authorize(t, provider)
+
+		if _, err := provider.Exchange(ctx, "auth-code", verifier, "nonce-1"); err == nil {
+			t.Error("Expected expired ID token to be rejected")
+		}
+	})
+
+	t.Run("email_verified as a string", func(t *testing.T) {
+		m := newMockIssuer(t)
+		m.claims = jwt.MapClaims{"email_verified": "true"}
+		provider := m.provider()
+		verifier := m.authorize(t, provider)
+
+		identity, err := provider.Exchange(ctx, "auth-code", verifier, "nonce-1")
+		if err != nil || !identity.EmailVerified {
+			t.Errorf("Expected string email_verified to be accepted, got %+v, %v", identity, err)
+		}
+	})
+
+	t.Run("discovery for another issuer", func(t *testing.T) {
+		m := newMockIssuer(t)
+		m.issuer = "https://evil.example.com"
+
+		if _, err := m.provider().AuthCodeURL(ctx, "state-1", "nonce-1", "challenge-1"); err == nil {
+			t.Error("Expected mismatched discovery issuer to be rejected")
+		}
+	})
+
+	t.Run("plain OAuth2 provider", func(t *testing.T) {
+		m := newMockIssuer(t)
+		m.noIDToken = true
+		provider := NewProvider(Config{
+			Name:         "plain",
+			ClientID:     "client",
+			ClientSecret: "secret",
+			RedirectURL:  "http://localhost:3000/oauth/plain/callback",
+			Scopes:       []string{"user-read-email"},
+			AuthURL:      m.server.URL + "/authorize",
+			TokenURL:     m.server.URL + "/token",
+			UserInfoURL:  m.server.URL + "/userinfo",
+		})
+		verifier := m.authorize(t, provider)
+
+		identity, err := provider.Exchange(ctx, "auth-code", verifier, "nonce-1")
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if identity.Subject != "12345" || identity.Email != "producer@example.com" || identity.EmailVerified || identity.Name != "Producer" {
+			t.Errorf("Unexpected identity: %+v", identity)
+		}
+	})
+}
+
+func TestProvider_KeyRotation(t *testing.T) {
+	m := newMockIssuer(t)
+	provider := m.provider()
+	verifier := m.authorize(t, provider)
+	if _, err := provider.Exchange(context.Background(), "auth-code", verifier, "nonce-1"); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	// The provider rotates to a new key; it is fetched once the cached keys
+	// are old enough to refresh
+	m.key, _ = rsa.GenerateKey(rand.Reader, 2048)
+	m.kid = "key-2"
+	provider.keys.fetchedAt = time.Now().Add(-keyRefreshInterval)
+
+	verifier = m.authorize(t, provider)
+	if _, err := provider.Exchange(context.Background(), "auth-code", verifier, "nonce-1"); err != nil {
+		t.Errorf("Expected token signed with the rotated key to validate, got: %v", err)
+	}
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// RFC 7636 appendix B
+	if got := CodeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"); got != "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM" {
+		t.Errorf("Unexpected challenge: %s", got)
+	}
+}
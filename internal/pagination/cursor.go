@@ -0,0 +1,71 @@
+// Package pagination implements opaque keyset cursors for list endpoints.
+//
+// A cursor records the (created_at, id) position of the last item on a page.
+// The next page is everything strictly after that position in the list's sort
+// order, so rows inserted while a client scrolls neither shift nor repeat
+// items the way LIMIT/OFFSET paging does.
+package pagination
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor is the keyset position of the last item on a page
+type Cursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+// Encode returns the opaque token handed to clients as next_cursor
+func (c Cursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Decode parses a token produced by Encode
+func Decode(token string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor")
+	}
+
+	return &Cursor{CreatedAt: createdAt, ID: id}, nil
+}
+
+// Values returns the cursor position as query arguments. Both are nil for a
+// nil cursor, so queries can skip the bound with "$n::timestamp IS NULL".
+func (c *Cursor) Values() (*time.Time, *uuid.UUID) {
+	if c == nil {
+		return nil, nil
+	}
+	return &c.CreatedAt, &c.ID
+}
+
+// Next returns the token for the page after items, or "" when the page is
+// shorter than limit and there is nothing more to fetch
+func Next[T any](items []T, limit int, position func(T) Cursor) string {
+	if limit <= 0 || len(items) < limit {
+		return ""
+	}
+	return position(items[len(items)-1]).Encode()
+}
@@ -0,0 +1,78 @@
+package pagination
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	original := Cursor{
+		CreatedAt: time.Date(2024, 3, 9, 18, 30, 15, 123456000, time.UTC),
+		ID:        uuid.New(),
+	}
+
+	decoded, err := Decode(original.Encode())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !decoded.CreatedAt.Equal(original.CreatedAt) {
+		t.Errorf("Expected CreatedAt %v, got %v", original.CreatedAt, decoded.CreatedAt)
+	}
+	if decoded.ID != original.ID {
+		t.Errorf("Expected ID %v, got %v", original.ID, decoded.ID)
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "not base64", token: "%%%"},
+		{name: "missing separator", token: "bm8tc2VwYXJhdG9y"},
+		{name: "bad timestamp", token: Cursor{ID: uuid.New()}.Encode()[:4] + "x"},
+		{name: "empty", token: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Decode(tt.token); err == nil {
+				t.Errorf("Expected error for token %q", tt.token)
+			}
+		})
+	}
+}
+
+func TestCursor_Values(t *testing.T) {
+	var empty *Cursor
+	if at, id := empty.Values(); at != nil || id != nil {
+		t.Errorf("Expected nil values for nil cursor, got %v %v", at, id)
+	}
+
+	cursor := &Cursor{CreatedAt: time.Now(), ID: uuid.New()}
+	at, id := cursor.Values()
+	if at == nil || !at.Equal(cursor.CreatedAt) || id == nil || *id != cursor.ID {
+		t.Errorf("Expected cursor values, got %v %v", at, id)
+	}
+}
+
+func TestNext(t *testing.T) {
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	position := func(id uuid.UUID) Cursor { return Cursor{CreatedAt: time.Unix(0, 0), ID: id} }
+
+	if token := Next(ids[:2], 3, position); token != "" {
+		t.Errorf("Expected no cursor for a short page, got %q", token)
+	}
+
+	token := Next(ids, 3, position)
+	cursor, err := Decode(token)
+	if err != nil {
+		t.Fatalf("Expected a valid cursor for a full page, got %v", err)
+	}
+	if cursor.ID != ids[2] {
+		t.Errorf("Expected cursor at last item %v, got %v", ids[2], cursor.ID)
+	}
+}
@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+
+	"musicapp/internal/db"
+	"musicapp/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type AccountTokenRepository struct {
+	db *db.DB
+}
+
+func NewAccountTokenRepository(db *db.DB) *AccountTokenRepository {
+	return &AccountTokenRepository{db: db}
+}
+
+func (r *AccountTokenRepository) Create(ctx context.Context, token *models.AccountToken) error {
+	query := `
+		INSERT INTO account_tokens (id, user_id, purpose, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		token.ID, token.UserID, token.Purpose, token.TokenHash, token.ExpiresAt,
+	)
+	return err
+}
+
+func (r *AccountTokenRepository) GetByHash(ctx context.Context, purpose, tokenHash string) (*models.AccountToken, error) {
+	query := `
+		SELECT id, user_id, purpose, token_hash, expires_at, used_at, created_at
+		FROM account_tokens
+		WHERE purpose = $1 AND token_hash = $2
+	`
+
+	var token models.AccountToken
+	err := r.db.Pool.QueryRow(ctx, query, purpose, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.Purpose, &token.TokenHash,
+		&token.ExpiresAt, &token.UsedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkUsed consumes a token. It reports false when the token was already
+// used, so a token cannot be redeemed twice even by concurrent requests.
+func (r *AccountTokenRepository) MarkUsed(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `UPDATE account_tokens SET used_at = NOW() WHERE id = $1 AND used_at IS NULL`
+
+	tag, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// InvalidateForUser consumes every outstanding token of one purpose for a
+// user, so only the most recently mailed token works
+func (r *AccountTokenRepository) InvalidateForUser(ctx context.Context, userID uuid.UUID, purpose string) error {
+	query := `UPDATE account_tokens SET used_at = NOW() WHERE user_id = $1 AND purpose = $2 AND used_at IS NULL`
+	_, err := r.db.Pool.Exec(ctx, query, userID, purpose)
+	return err
+}
@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"context"
+
+	"musicapp/internal/db"
+	"musicapp/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type APIKeyRepository struct {
+	db *db.DB
+}
+
+func NewAPIKeyRepository(db *db.DB) *APIKeyRepository {
+	return &APIKeyRepository{db: db}
+}
+
+func (r *APIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	query := `
+		INSERT INTO api_keys (id, user_id, name, token_prefix, token_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING created_at
+	`
+
+	return r.db.Pool.QueryRow(ctx, query,
+		key.ID, key.UserID, key.Name, key.TokenPrefix, key.TokenHash, key.Scopes, key.ExpiresAt,
+	).Scan(&key.CreatedAt)
+}
+
+func (r *APIKeyRepository) GetByHash(ctx context.Context, tokenHash string) (*models.APIKey, error) {
+	query := `
+		SELECT id, user_id, name, token_prefix, token_hash, scopes, expires_at, last_used_at, created_at, revoked_at
+		FROM api_keys
+		WHERE token_hash = $1
+	`
+
+	row := r.db.Pool.QueryRow(ctx, query, tokenHash)
+	return r.scanAPIKey(row)
+}
+
+// GetActiveByUserID lists a user's unrevoked keys, including expired ones,
+// newest first
+func (r *APIKeyRepository) GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	query := `
+		SELECT id, user_id, name, token_prefix, token_hash, scopes, expires_at, last_used_at, created_at, revoked_at
+		FROM api_keys
+		WHERE user_id = $1 AND revoked_at IS NULL
+		ORDER BY created_at DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*models.APIKey
+	for rows.Next() {
+		key, err := r.scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, rows.Err()
+}
+
+// CountActiveByUserID counts a user's unrevoked, unexpired keys
+func (r *APIKeyRepository) CountActiveByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := `SELECT COUNT(*) FROM api_keys WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > NOW()`
+
+	var count int
+	err := r.db.Pool.QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+// TouchLastUsed records that the key was just used. It writes at most once
+// a minute per key, so busy scripts do not turn every request into a write.
+func (r *APIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	query := `
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id)
+	return err
+}
+
+// Revoke revokes one of the user's keys. It reports false if the user has
+// no such active key.
+func (r *APIKeyRepository) Revoke(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	query := `UPDATE api_keys SET revoked_at = NOW() WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL`
+	tag, err := r.db.Pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// RevokeAllForUser revokes every active key the user holds and returns how
+// many were revoked
+func (r *APIKeyRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := `UPDATE api_keys SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL`
+	tag, err := r.db.Pool.Exec(ctx, query, userID)
+	if err != nil {
+		return 0, err
+	}
+	return int(tag.RowsAffected()), nil
+}
+
+func (r *APIKeyRepository) scanAPIKey(row pgx.Row) (*models.APIKey, error) {
+	var key models.APIKey
+
+	err := row.Scan(
+		&key.ID, &key.UserID, &key.Name, &key.TokenPrefix, &key.TokenHash, &key.Scopes,
+		&key.ExpiresAt, &key.LastUsedAt, &key.CreatedAt, &key.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+
+	"musicapp/internal/db"
+	"musicapp/internal/models"
+	"musicapp/internal/pagination"
+)
+
+type AuditLogRepository struct {
+	db *db.DB
+}
+
+func NewAuditLogRepository(db *db.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+func (r *AuditLogRepository) Create(ctx context.Context, entry *models.AuditLogEntry) error {
+	query := `
+		INSERT INTO audit_log (id, actor_id, action, target_type, target_id, details, ip_address, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+		RETURNING created_at
+	`
+
+	return r.db.Pool.QueryRow(ctx, query,
+		entry.ID, entry.ActorID, entry.Action, entry.TargetType, entry.TargetID, entry.Details, entry.IPAddress,
+	).Scan(&entry.CreatedAt)
+}
+
+// List lists audit entries matching the filter, newest first
+func (r *AuditLogRepository) List(ctx context.Context, filter models.AuditLogFilter, limit int, after *pagination.Cursor) ([]*models.AuditLogEntry, error) {
+	query := `
+		SELECT id, actor_id, action, target_type, target_id, details, ip_address, created_at
+		FROM audit_log
+		WHERE ($1::uuid IS NULL OR actor_id = $1)
+			AND ($2::uuid IS NULL OR target_id = $2)
+			AND ($3 = '' OR action = $3)
+			AND ($4::timestamp IS NULL OR (created_at, id) < ($4::timestamp, $5::uuid))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $6
+	`
+
+	afterAt, afterID := after.Values()
+	rows, err := r.db.Pool.Query(ctx, query, filter.ActorID, filter.TargetID, filter.Action, afterAt, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []*models.AuditLogEntry
+	for rows.Next() {
+		var entry models.AuditLogEntry
+		if err := rows.Scan(
+			&entry.ID, &entry.ActorID, &entry.Action, &entry.TargetType, &entry.TargetID,
+			&entry.Details, &entry.IPAddress, &entry.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
@@ -2,9 +2,11 @@ package repository
 
 import (
 	"context"
+	"errors"
 
 	"musicapp/internal/db"
 	"musicapp/internal/models"
+	"musicapp/internal/pagination"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -135,7 +137,7 @@ func (r *BandRepository) RemoveMember(ctx context.Context, bandID, userID uuid.U
 
 func (r *BandRepository) GetMembers(ctx context.Context, bandID uuid.UUID) ([]*models.BandMember, error) {
 	query := `
-		SELECT bm.id, bm.band_id, bm.user_id, bm.role, bm.joined_at,
+		SELECT bm.id, bm.band_id, bm.user_id, bm.role, bm.can_post_as_band, bm.joined_at,
 			u.id, u.username, u.email, u.display_name, u.bio, u.profile_picture_url,
 			ST_Y(u.location::geometry) as lat, ST_X(u.location::geometry) as lng,
 			u.city, u.country, u.genres, u.skills,
@@ -172,16 +174,89 @@ func (r *BandRepository) IsMember(ctx context.Context, bandID, userID uuid.UUID)
 	return exists, err
 }
 
-func (r *BandRepository) IsAdmin(ctx context.Context, bandID, userID uuid.UUID) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM band_members WHERE band_id = $1 AND user_id = $2 AND role = 'Admin')`
-	var exists bool
-	err := r.db.Pool.QueryRow(ctx, query, bandID, userID).Scan(&exists)
-	return exists, err
+// GetMember gets a user's membership of a band, or nil if they are not a
+// member
+func (r *BandRepository) GetMember(ctx context.Context, bandID, userID uuid.UUID) (*models.BandMember, error) {
+	query := `
+		SELECT id, band_id, user_id, role, can_post_as_band, joined_at
+		FROM band_members
+		WHERE band_id = $1 AND user_id = $2
+	`
+
+	var member models.BandMember
+	err := r.db.Pool.QueryRow(ctx, query, bandID, userID).Scan(
+		&member.ID, &member.BandID, &member.UserID, &member.Role, &member.CanPostAsBand, &member.JoinedAt,
+	)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+// SetMemberRole changes a member's band role. Ownership is handed over with
+// TransferOwnership instead.
+func (r *BandRepository) SetMemberRole(ctx context.Context, bandID, userID uuid.UUID, role string) error {
+	query := `UPDATE band_members SET role = $3 WHERE band_id = $1 AND user_id = $2 AND role <> 'owner'`
+	tag, err := r.db.Pool.Exec(ctx, query, bandID, userID, role)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
+}
+
+// TransferOwnership makes a member the band's owner and the current owner an
+// admin, in one transaction
+func (r *BandRepository) TransferOwnership(ctx context.Context, bandID, ownerID, newOwnerID uuid.UUID) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	// Demote first: a band can only have one owner at a time
+	query := `UPDATE band_members SET role = 'admin' WHERE band_id = $1 AND user_id = $2 AND role = 'owner'`
+	tag, err := tx.Exec(ctx, query, bandID, ownerID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	query = `UPDATE band_members SET role = 'owner' WHERE band_id = $1 AND user_id = $2`
+	tag, err = tx.Exec(ctx, query, bandID, newOwnerID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SetCanPostAsBand grants or revokes a member's permission to post as the band
+func (r *BandRepository) SetCanPostAsBand(ctx context.Context, bandID, userID uuid.UUID, canPost bool) error {
+	query := `UPDATE band_members SET can_post_as_band = $3 WHERE band_id = $1 AND user_id = $2`
+	tag, err := r.db.Pool.Exec(ctx, query, bandID, userID, canPost)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+	return nil
 }
 
 func (r *BandRepository) GetUserBands(ctx context.Context, userID uuid.UUID) ([]*models.BandMember, error) {
 	query := `
-		SELECT bm.id, bm.band_id, bm.user_id, bm.role, bm.joined_at,
+		SELECT bm.id, bm.band_id, bm.user_id, bm.role, bm.can_post_as_band, bm.joined_at,
 			b.id, b.name, b.bio, b.profile_picture_url,
 			ST_Y(b.location::geometry) as lat, ST_X(b.location::geometry) as lng,
 			b.city, b.country, b.genres, b.looking_for,
@@ -211,17 +286,19 @@ func (r *BandRepository) GetUserBands(ctx context.Context, userID uuid.UUID) ([]
 }
 
 // GetAll gets all bands with pagination
-func (r *BandRepository) GetAll(ctx context.Context, limit, offset int) ([]*models.Band, error) {
+func (r *BandRepository) GetAll(ctx context.Context, limit, offset int, after *pagination.Cursor) ([]*models.Band, error) {
 	query := `
 		SELECT id, name, bio, profile_picture_url,
 			ST_Y(location::geometry) as lat, ST_X(location::geometry) as lng,
 			city, country, genres, looking_for, created_at, updated_at
 		FROM bands
-		ORDER BY created_at DESC
+		WHERE $3::timestamp IS NULL OR (created_at, id) < ($3::timestamp, $4::uuid)
+		ORDER BY created_at DESC, id DESC
 		LIMIT $1 OFFSET $2
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, limit, offset)
+	afterAt, afterID := after.Values()
+	rows, err := r.db.Pool.Query(ctx, query, limit, offset, afterAt, afterID)
 	if err != nil {
 		return nil, err
 	}
@@ -239,6 +316,31 @@ func (r *BandRepository) GetAll(ctx context.Context, limit, offset int) ([]*mode
 	return bands, rows.Err()
 }
 
+// GetAuthorSummaries loads compact author summaries for the given bands in one query
+func (r *BandRepository) GetAuthorSummaries(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.PostAuthor, error) {
+	authors := make(map[uuid.UUID]*models.PostAuthor)
+	if len(ids) == 0 {
+		return authors, nil
+	}
+
+	query := `SELECT id, name, profile_picture_url FROM bands WHERE id = ANY($1)`
+	rows, err := r.db.Pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		author := &models.PostAuthor{Type: "band"}
+		if err := rows.Scan(&author.ID, &author.Name, &author.ProfilePictureURL); err != nil {
+			return nil, err
+		}
+		authors[author.ID] = author
+	}
+
+	return authors, rows.Err()
+}
+
 func (r *BandRepository) scanBand(row pgx.Row) (*models.Band, error) {
 	var band models.Band
 	var lat, lng *float64
@@ -296,7 +398,7 @@ func (r *BandRepository) scanBandMember(row pgx.Row) (*models.BandMember, error)
 	var lat, lng *float64
 
 	err := row.Scan(
-		&member.ID, &member.BandID, &member.UserID, &member.Role, &member.JoinedAt,
+		&member.ID, &member.BandID, &member.UserID, &member.Role, &member.CanPostAsBand, &member.JoinedAt,
 		&user.ID, &user.Username, &user.Email, &user.DisplayName, &user.Bio, &user.ProfilePictureURL,
 		&lat, &lng, &user.City, &user.Country, &user.Genres, &user.Skills,
 		&user.SpotifyURL, &user.SoundcloudURL, &user.InstagramHandle,
@@ -324,7 +426,7 @@ func (r *BandRepository) scanUserBandMembership(row pgx.Row) (*models.BandMember
 	var lat, lng *float64
 
 	err := row.Scan(
-		&membership.ID, &membership.BandID, &membership.UserID, &membership.Role, &membership.JoinedAt,
+		&membership.ID, &membership.BandID, &membership.UserID, &membership.Role, &membership.CanPostAsBand, &membership.JoinedAt,
 		&band.ID, &band.Name, &band.Bio, &band.ProfilePictureURL,
 		&lat, &lng, &band.City, &band.Country, &band.Genres, &band.LookingFor,
 		&band.CreatedAt, &band.UpdatedAt,
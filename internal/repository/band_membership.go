@@ -0,0 +1,211 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"musicapp/internal/db"
+	"musicapp/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Pending requests past expires_at are expired whether or not a status
+// update has caught up with them yet, so reads report the effective status
+const membershipRequestColumns = `
+	id, band_id, user_id, kind, role, message,
+	CASE WHEN status = 'pending' AND expires_at <= NOW() THEN 'expired' ELSE status END,
+	invited_by, responded_by, created_at, expires_at, responded_at, notified_at
+`
+
+type BandMembershipRepository struct {
+	db *db.DB
+}
+
+func NewBandMembershipRepository(db *db.DB) *BandMembershipRepository {
+	return &BandMembershipRepository{db: db}
+}
+
+// Create stores a pending request. It reports false if the user already has
+// an open invitation or join request for the band.
+func (r *BandMembershipRepository) Create(ctx context.Context, request *models.BandMembershipRequest) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Expire a lapsed request first so it does not block a new one
+	query := `
+		UPDATE band_membership_requests SET status = 'expired'
+		WHERE band_id = $1 AND user_id = $2 AND status = 'pending' AND expires_at <= NOW()
+	`
+	if _, err := tx.Exec(ctx, query, request.BandID, request.UserID); err != nil {
+		return false, err
+	}
+
+	query = `
+		INSERT INTO band_membership_requests (id, band_id, user_id, kind, role, message, status, invited_by, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, 'pending', $7, NOW(), $8)
+		ON CONFLICT (band_id, user_id) WHERE status = 'pending' DO NOTHING
+		RETURNING status, created_at
+	`
+	err = tx.QueryRow(ctx, query,
+		request.ID, request.BandID, request.UserID, request.Kind, request.Role,
+		request.Message, request.InvitedBy, request.ExpiresAt,
+	).Scan(&request.Status, &request.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+// GetByID gets a request, or nil if there is none
+func (r *BandMembershipRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BandMembershipRequest, error) {
+	query := `SELECT ` + membershipRequestColumns + ` FROM band_membership_requests WHERE id = $1`
+
+	request, err := r.scanMembershipRequest(r.db.Pool.QueryRow(ctx, query, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return request, err
+}
+
+// GetPending gets the user's open invitation or join request for a band, or
+// nil if there is none
+func (r *BandMembershipRepository) GetPending(ctx context.Context, bandID, userID uuid.UUID) (*models.BandMembershipRequest, error) {
+	query := `
+		SELECT ` + membershipRequestColumns + `
+		FROM band_membership_requests
+		WHERE band_id = $1 AND user_id = $2 AND status = 'pending' AND expires_at > NOW()
+	`
+
+	request, err := r.scanMembershipRequest(r.db.Pool.QueryRow(ctx, query, bandID, userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, nil
+	}
+	return request, err
+}
+
+// GetPendingByBand lists a band's open requests of one kind, oldest first
+func (r *BandMembershipRepository) GetPendingByBand(ctx context.Context, bandID uuid.UUID, kind string, limit int) ([]*models.BandMembershipRequest, error) {
+	query := `
+		SELECT ` + membershipRequestColumns + `
+		FROM band_membership_requests
+		WHERE band_id = $1 AND kind = $2 AND status = 'pending' AND expires_at > NOW()
+		ORDER BY created_at ASC
+		LIMIT $3
+	`
+
+	return r.queryMembershipRequests(ctx, query, bandID, kind, limit)
+}
+
+// GetByUser lists a user's most recent requests of one kind, in any status,
+// newest first
+func (r *BandMembershipRepository) GetByUser(ctx context.Context, userID uuid.UUID, kind string, limit int) ([]*models.BandMembershipRequest, error) {
+	query := `
+		SELECT ` + membershipRequestColumns + `
+		FROM band_membership_requests
+		WHERE user_id = $1 AND kind = $2
+		ORDER BY created_at DESC
+		LIMIT $3
+	`
+
+	return r.queryMembershipRequests(ctx, query, userID, kind, limit)
+}
+
+// Accept accepts a pending request and adds the user to the band with the
+// given role, in one transaction. It reports false if the request is no
+// longer pending or has expired.
+func (r *BandMembershipRepository) Accept(ctx context.Context, id, respondedBy uuid.UUID, role string) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `
+		UPDATE band_membership_requests
+		SET status = 'accepted', role = $3, responded_by = $2, responded_at = NOW()
+		WHERE id = $1 AND status = 'pending' AND expires_at > NOW()
+		RETURNING band_id, user_id
+	`
+	var bandID, userID uuid.UUID
+	err = tx.QueryRow(ctx, query, id, respondedBy, role).Scan(&bandID, &userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	query = `
+		INSERT INTO band_members (id, band_id, user_id, role, joined_at)
+		VALUES (gen_random_uuid(), $1, $2, $3, NOW())
+		ON CONFLICT (band_id, user_id) DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, query, bandID, userID, role); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+// Close declines or cancels a pending request. It reports false if the
+// request is no longer pending or has expired.
+func (r *BandMembershipRepository) Close(ctx context.Context, id uuid.UUID, status string, respondedBy uuid.UUID) (bool, error) {
+	query := `
+		UPDATE band_membership_requests
+		SET status = $2, responded_by = $3, responded_at = NOW()
+		WHERE id = $1 AND status = 'pending' AND expires_at > NOW()
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, status, respondedBy)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// MarkNotified records that the recipient was told about a request
+func (r *BandMembershipRepository) MarkNotified(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE band_membership_requests SET notified_at = NOW() WHERE id = $1`
+	_, err := r.db.Pool.Exec(ctx, query, id)
+	return err
+}
+
+func (r *BandMembershipRepository) queryMembershipRequests(ctx context.Context, query string, args ...interface{}) ([]*models.BandMembershipRequest, error) {
+	rows, err := r.db.Pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []*models.BandMembershipRequest
+	for rows.Next() {
+		request, err := r.scanMembershipRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+
+	return requests, rows.Err()
+}
+
+func (r *BandMembershipRepository) scanMembershipRequest(row pgx.Row) (*models.BandMembershipRequest, error) {
+	var request models.BandMembershipRequest
+	err := row.Scan(
+		&request.ID, &request.BandID, &request.UserID, &request.Kind, &request.Role, &request.Message,
+		&request.Status, &request.InvitedBy, &request.RespondedBy,
+		&request.CreatedAt, &request.ExpiresAt, &request.RespondedAt, &request.NotifiedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+
+	"musicapp/internal/db"
+	"musicapp/internal/models"
+	"musicapp/internal/pagination"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type CommentRepository struct {
+	db *db.DB
+}
+
+func NewCommentRepository(db *db.DB) *CommentRepository {
+	return &CommentRepository{db: db}
+}
+
+func (r *CommentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	query := `
+		INSERT INTO comments (id, post_id, user_id, parent_comment_id, depth, content, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW())
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		comment.ID, comment.PostID, comment.UserID, comment.ParentCommentID, comment.Depth, comment.Content,
+	)
+	return err
+}
+
+func (r *CommentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Comment, error) {
+	query := `
+		SELECT c.id, c.post_id, c.user_id, c.parent_comment_id, c.depth, c.content, c.created_at,
+			COALESCE(l.likes_count, 0) as likes_count,
+			COALESCE(rp.replies_count, 0) as replies_count
+		FROM comments c
+		LEFT JOIN (
+			SELECT comment_id, COUNT(*) as likes_count
+			FROM comment_likes
+			GROUP BY comment_id
+		) l ON c.id = l.comment_id
+		LEFT JOIN (
+			SELECT parent_comment_id, COUNT(*) as replies_count
+			FROM comments
+			WHERE parent_comment_id IS NOT NULL
+			GROUP BY parent_comment_id
+		) rp ON c.id = rp.parent_comment_id
+		WHERE c.id = $1
+	`
+
+	row := r.db.Pool.QueryRow(ctx, query, id)
+	return r.scanComment(row)
+}
+
+// GetByPostID returns the top-level comments on a post, oldest first
+func (r *CommentRepository) GetByPostID(ctx context.Context, postID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Comment, error) {
+	query := `
+		SELECT c.id, c.post_id, c.user_id, c.parent_comment_id, c.depth, c.content, c.created_at,
+			COALESCE(l.likes_count, 0) as likes_count,
+			COALESCE(rp.replies_count, 0) as replies_count
+		FROM comments c
+		LEFT JOIN (
+			SELECT comment_id, COUNT(*) as likes_count
+			FROM comment_likes
+			GROUP BY comment_id
+		) l ON c.id = l.comment_id
+		LEFT JOIN (
+			SELECT parent_comment_id, COUNT(*) as replies_count
+			FROM comments
+			WHERE parent_comment_id IS NOT NULL
+			GROUP BY parent_comment_id
+		) rp ON c.id = rp.parent_comment_id
+		WHERE c.post_id = $1 AND c.parent_comment_id IS NULL
+			AND ($4::timestamp IS NULL OR (c.created_at, c.id) > ($4::timestamp, $5::uuid))
+		ORDER BY c.created_at ASC, c.id ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	afterAt, afterID := after.Values()
+	rows, err := r.db.Pool.Query(ctx, query, postID, limit, offset, afterAt, afterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanComments(rows)
+}
+
+// GetReplies returns the direct replies to a comment, oldest first
+func (r *CommentRepository) GetReplies(ctx context.Context, parentID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Comment, error) {
+	query := `
+		SELECT c.id, c.post_id, c.user_id, c.parent_comment_id, c.depth, c.content, c.created_at,
+			COALESCE(l.likes_count, 0) as likes_count,
+			COALESCE(rp.replies_count, 0) as replies_count
+		FROM comments c
+		LEFT JOIN (
+			SELECT comment_id, COUNT(*) as likes_count
+			FROM comment_likes
+			GROUP BY comment_id
+		) l ON c.id = l.comment_id
+		LEFT JOIN (
+			SELECT parent_comment_id, COUNT(*) as replies_count
+			FROM comments
+			WHERE parent_comment_id IS NOT NULL
+			GROUP BY parent_comment_id
+		) rp ON c.id = rp.parent_comment_id
+		WHERE c.parent_comment_id = $1
+			AND ($4::timestamp IS NULL OR (c.created_at, c.id) > ($4::timestamp, $5::uuid))
+		ORDER BY c.created_at ASC, c.id ASC
+		LIMIT $2 OFFSET $3
+	`
+
+	afterAt, afterID := after.Values()
+	rows, err := r.db.Pool.Query(ctx, query, parentID, limit, offset, afterAt, afterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanComments(rows)
+}
+
+// GetRepliesForParents returns up to perParent of the oldest direct replies
+// for each of the given comments in a single query
+func (r *CommentRepository) GetRepliesForParents(ctx context.Context, parentIDs []uuid.UUID, perParent int) ([]*models.Comment, error) {
+	if len(parentIDs) == 0 {
+		return []*models.Comment{}, nil
+	}
+
+	query := `
+		SELECT c.id, c.post_id, c.user_id, c.parent_comment_id, c.depth, c.content, c.created_at,
+			COALESCE(l.likes_count, 0) as likes_count,
+			COALESCE(rp.replies_count, 0) as replies_count
+		FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY parent_comment_id ORDER BY created_at ASC, id ASC) as rn
+			FROM comments
+			WHERE parent_comment_id = ANY($1)
+		) c
+		LEFT JOIN (
+			SELECT comment_id, COUNT(*) as likes_count
+			FROM comment_likes
+			GROUP BY comment_id
+		) l ON c.id = l.comment_id
+		LEFT JOIN (
+			SELECT parent_comment_id, COUNT(*) as replies_count
+			FROM comments
+			WHERE parent_comment_id IS NOT NULL
+			GROUP BY parent_comment_id
+		) rp ON c.id = rp.parent_comment_id
+		WHERE c.rn <= $2
+		ORDER BY c.created_at ASC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, parentIDs, perParent)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return r.scanComments(rows)
+}
+
+func (r *CommentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM comments WHERE id = $1`
+	_, err := r.db.Pool.Exec(ctx, query, id)
+	return err
+}
+
+func (r *CommentRepository) LikeComment(ctx context.Context, userID, commentID uuid.UUID) error {
+	query := `
+		INSERT INTO comment_likes (id, user_id, comment_id, created_at)
+		VALUES (gen_random_uuid(), $1, $2, NOW())
+		ON CONFLICT (user_id, comment_id) DO NOTHING
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query, userID, commentID)
+	return err
+}
+
+func (r *CommentRepository) UnlikeComment(ctx context.Context, userID, commentID uuid.UUID) error {
+	query := `DELETE FROM comment_likes WHERE user_id = $1 AND comment_id = $2`
+	_, err := r.db.Pool.Exec(ctx, query, userID, commentID)
+	return err
+}
+
+// GetLikedCommentIDs returns the subset of commentIDs the user has liked
+func (r *CommentRepository) GetLikedCommentIDs(ctx context.Context, userID uuid.UUID, commentIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	liked := make(map[uuid.UUID]bool)
+	if len(commentIDs) == 0 {
+		return liked, nil
+	}
+
+	query := `SELECT comment_id FROM comment_likes WHERE user_id = $1 AND comment_id = ANY($2)`
+	rows, err := r.db.Pool.Query(ctx, query, userID, commentIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var commentID uuid.UUID
+		if err := rows.Scan(&commentID); err != nil {
+			return nil, err
+		}
+		liked[commentID] = true
+	}
+
+	return liked, rows.Err()
+}
+
+func (r *CommentRepository) scanComments(rows pgx.Rows) ([]*models.Comment, error) {
+	var comments []*models.Comment
+	for rows.Next() {
+		comment, err := r.scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, rows.Err()
+}
+
+func (r *CommentRepository) scanComment(row pgx.Row) (*models.Comment, error) {
+	var comment models.Comment
+
+	err := row.Scan(
+		&comment.ID, &comment.PostID, &comment.UserID, &comment.ParentCommentID, &comment.Depth,
+		&comment.Content, &comment.CreatedAt,
+		&comment.LikesCount, &comment.RepliesCount,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &comment, nil
+}
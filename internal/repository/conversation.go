@@ -0,0 +1,246 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+
+	"musicapp/internal/db"
+	"musicapp/internal/models"
+	"musicapp/internal/pagination"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type ConversationRepository struct {
+	db *db.DB
+}
+
+func NewConversationRepository(db *db.DB) *ConversationRepository {
+	return &ConversationRepository{db: db}
+}
+
+// GetOrCreate returns the conversation between two users, creating it and
+// both participant rows if it does not exist yet
+func (r *ConversationRepository) GetOrCreate(ctx context.Context, userID, otherUserID uuid.UUID) (*models.Conversation, error) {
+	low, high := userID, otherUserID
+	if bytes.Compare(high[:], low[:]) < 0 {
+		low, high = high, low
+	}
+
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	// The no-op update makes RETURNING yield the existing row on conflict
+	query := `
+		INSERT INTO conversations (id, user_low_id, user_high_id, created_at, last_message_at)
+		VALUES ($1, $2, $3, NOW(), NOW())
+		ON CONFLICT (user_low_id, user_high_id) DO UPDATE SET user_low_id = EXCLUDED.user_low_id
+		RETURNING id, created_at, last_message_at
+	`
+
+	var conversation models.Conversation
+	err = tx.QueryRow(ctx, query, uuid.New(), low, high).Scan(
+		&conversation.ID, &conversation.CreatedAt, &conversation.LastMessageAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	participantQuery := `
+		INSERT INTO conversation_participants (conversation_id, user_id, joined_at)
+		VALUES ($1, $2, NOW()), ($1, $3, NOW())
+		ON CONFLICT (conversation_id, user_id) DO NOTHING
+	`
+	if _, err := tx.Exec(ctx, participantQuery, conversation.ID, low, high); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &conversation, nil
+}
+
+func (r *ConversationRepository) IsParticipant(ctx context.Context, conversationID, userID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM conversation_participants WHERE conversation_id = $1 AND user_id = $2)`
+
+	var exists bool
+	err := r.db.Pool.QueryRow(ctx, query, conversationID, userID).Scan(&exists)
+	return exists, err
+}
+
+// GetUserConversations lists a user's conversations, most recently active
+// first, with the other participant, the last message and the unread count
+func (r *ConversationRepository) GetUserConversations(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Conversation, error) {
+	query := `
+		SELECT c.id, c.created_at, c.last_message_at,
+			u.id, u.username, u.profile_picture_url,
+			me.last_read_at,
+			COALESCE(unread.unread_count, 0) as unread_count,
+			lm.id, lm.sender_id, lm.content, lm.created_at
+		FROM conversation_participants me
+		JOIN conversations c ON c.id = me.conversation_id
+		JOIN users u ON u.id = CASE WHEN c.user_low_id = me.user_id THEN c.user_high_id ELSE c.user_low_id END
+		LEFT JOIN LATERAL (
+			SELECT id, sender_id, content, created_at
+			FROM direct_messages
+			WHERE conversation_id = c.id
+			ORDER BY created_at DESC, id DESC
+			LIMIT 1
+		) lm ON true
+		LEFT JOIN LATERAL (
+			SELECT COUNT(*) as unread_count
+			FROM direct_messages
+			WHERE conversation_id = c.id
+				AND sender_id <> me.user_id
+				AND (me.last_read_at IS NULL OR created_at > me.last_read_at)
+		) unread ON true
+		WHERE me.user_id = $1 AND lm.id IS NOT NULL
+			AND ($4::timestamp IS NULL OR (c.last_message_at, c.id) < ($4::timestamp, $5::uuid))
+		ORDER BY c.last_message_at DESC, c.id DESC
+		LIMIT $2 OFFSET $3
+	`
+
+	afterAt, afterID := after.Values()
+	rows, err := r.db.Pool.Query(ctx, query, userID, limit, offset, afterAt, afterID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var conversations []*models.Conversation
+	for rows.Next() {
+		var conversation models.Conversation
+		var lastMessage models.DirectMessage
+
+		err := rows.Scan(
+			&conversation.ID, &conversation.CreatedAt, &conversation.LastMessageAt,
+			&conversation.OtherUserID, &conversation.OtherUsername, &conversation.OtherProfilePictureURL,
+			&conversation.LastReadAt, &conversation.UnreadCount,
+			&lastMessage.ID, &lastMessage.SenderID, &lastMessage.Content, &lastMessage.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		lastMessage.ConversationID = conversation.ID
+		conversation.LastMessage = &lastMessage
+		conversations = append(conversations, &conversation)
+	}
+
+	return conversations, rows.Err()
+}
+
+// CreateMessage stores a message and bumps the conversation's last activity
+func (r *ConversationRepository) CreateMessage(ctx context.Context, message *models.DirectMessage) error {
+	query := `
+		WITH inserted AS (
+			INSERT INTO direct_messages (id, conversation_id, sender_id, content, created_at)
+			VALUES ($1, $2, $3, $4, NOW())
+			RETURNING created_at
+		)
+		UPDATE conversations SET last_message_at = inserted.created_at
+		FROM inserted
+		WHERE conversations.id = $2
+		RETURNING inserted.created_at
+	`
+
+	return r.db.Pool.QueryRow(ctx, query,
+		message.ID, message.ConversationID, message.SenderID, message.Content,
+	).Scan(&message.CreatedAt)
+}
+
+func (r *ConversationRepository) GetMessageByID(ctx context.Context, id uuid.UUID) (*models.DirectMessage, error) {
+	query := `
+		SELECT id, conversation_id, sender_id, content, created_at
+		FROM direct_messages
+		WHERE id = $1
+	`
+
+	row := r.db.Pool.QueryRow(ctx, query, id)
+	return r.scanMessage(row)
+}
+
+// GetMessages returns a conversation's messages, newest first. When before is
+// set, only messages older than that message are returned.
+func (r *ConversationRepository) GetMessages(ctx context.Context, conversationID uuid.UUID, before *uuid.UUID, limit int) ([]*models.DirectMessage, error) {
+	query := `
+		SELECT id, conversation_id, sender_id, content, created_at
+		FROM direct_messages
+		WHERE conversation_id = $1
+			AND ($2::uuid IS NULL OR (created_at, id) < (
+				SELECT created_at, id FROM direct_messages WHERE id = $2 AND conversation_id = $1
+			))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, conversationID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*models.DirectMessage
+	for rows.Next() {
+		message, err := r.scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+func (r *ConversationRepository) DeleteMessage(ctx context.Context, id uuid.UUID) error {
+	query := `DELETE FROM direct_messages WHERE id = $1`
+	_, err := r.db.Pool.Exec(ctx, query, id)
+	return err
+}
+
+// MarkRead moves the participant's read marker to now
+func (r *ConversationRepository) MarkRead(ctx context.Context, conversationID, userID uuid.UUID) error {
+	query := `
+		UPDATE conversation_participants SET last_read_at = NOW()
+		WHERE conversation_id = $1 AND user_id = $2
+	`
+	_, err := r.db.Pool.Exec(ctx, query, conversationID, userID)
+	return err
+}
+
+// GetUnreadCount returns the number of unread messages across all of a user's conversations
+func (r *ConversationRepository) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int, error) {
+	query := `
+		SELECT COUNT(*)
+		FROM conversation_participants me
+		JOIN direct_messages dm ON dm.conversation_id = me.conversation_id
+		WHERE me.user_id = $1
+			AND dm.sender_id <> me.user_id
+			AND (me.last_read_at IS NULL OR dm.created_at > me.last_read_at)
+	`
+
+	var count int
+	err := r.db.Pool.QueryRow(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+func (r *ConversationRepository) scanMessage(row pgx.Row) (*models.DirectMessage, error) {
+	var message models.DirectMessage
+
+	err := row.Scan(
+		&message.ID, &message.ConversationID, &message.SenderID,
+		&message.Content, &message.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &message, nil
+}
@@ -0,0 +1,309 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"musicapp/internal/db"
+	"musicapp/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type DataExportRepository struct {
+	db *db.DB
+}
+
+func NewDataExportRepository(db *db.DB) *DataExportRepository {
+	return &DataExportRepository{db: db}
+}
+
+// Create stores a new export. It reports false if the user already has an
+// export pending or being built.
+func (r *DataExportRepository) Create(ctx context.Context, export *models.DataExport) (bool, error) {
+	query := `
+		INSERT INTO data_exports (id, user_id, status, created_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (user_id) WHERE status IN ('pending', 'processing') DO NOTHING
+		RETURNING created_at
+	`
+
+	err := r.db.Pool.QueryRow(ctx, query, export.ID, export.UserID, export.Status).Scan(&export.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (r *DataExportRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DataExport, error) {
+	query := `
+		SELECT id, user_id, status, object_key, size_bytes, error, created_at, started_at, completed_at, expires_at
+		FROM data_exports
+		WHERE id = $1
+	`
+
+	row := r.db.Pool.QueryRow(ctx, query, id)
+	return r.scanDataExport(row)
+}
+
+// GetByUserID lists a user's most recent exports, newest first
+func (r *DataExportRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*models.DataExport, error) {
+	query := `
+		SELECT id, user_id, status, object_key, size_bytes, error, created_at, started_at, completed_at, expires_at
+		FROM data_exports
+		WHERE user_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exports []*models.DataExport
+	for rows.Next() {
+		export, err := r.scanDataExport(rows)
+		if err != nil {
+			return nil, err
+		}
+		exports = append(exports, export)
+	}
+
+	return exports, rows.Err()
+}
+
+// GetClaimable lists exports waiting to be built, and exports whose build
+// started before staleBefore and was presumably cut short by a restart
+func (r *DataExportRepository) GetClaimable(ctx context.Context, staleBefore time.Time, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT id FROM data_exports
+		WHERE status = 'pending' OR (status = 'processing' AND started_at < $1)
+		ORDER BY created_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, staleBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// Claim marks an export as being built. It reports false if the export is
+// not pending, or is already being built and not yet stale, so only one
+// worker builds each export.
+func (r *DataExportRepository) Claim(ctx context.Context, id uuid.UUID, staleBefore time.Time) (bool, error) {
+	query := `
+		UPDATE data_exports SET status = 'processing', started_at = NOW()
+		WHERE id = $1 AND (status = 'pending' OR (status = 'processing' AND started_at < $2))
+	`
+	tag, err := r.db.Pool.Exec(ctx, query, id, staleBefore)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// MarkCompleted records where the finished archive is stored and until when
+func (r *DataExportRepository) MarkCompleted(ctx context.Context, id uuid.UUID, objectKey string, sizeBytes int64, expiresAt time.Time) error {
+	query := `
+		UPDATE data_exports
+		SET status = 'completed', object_key = $2, size_bytes = $3, expires_at = $4, completed_at = NOW(), error = NULL
+		WHERE id = $1
+	`
+	_, err := r.db.Pool.Exec(ctx, query, id, objectKey, sizeBytes, expiresAt)
+	return err
+}
+
+// MarkFailed records why an export could not be built
+func (r *DataExportRepository) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	query := `UPDATE data_exports SET status = 'failed', error = $2, completed_at = NOW() WHERE id = $1`
+	_, err := r.db.Pool.Exec(ctx, query, id, reason)
+	return err
+}
+
+// GetExpired lists completed exports whose archive expired before the given
+// time
+func (r *DataExportRepository) GetExpired(ctx context.Context, before time.Time, limit int) ([]*models.DataExport, error) {
+	query := `
+		SELECT id, user_id, status, object_key, size_bytes, error, created_at, started_at, completed_at, expires_at
+		FROM data_exports
+		WHERE status = 'completed' AND expires_at <= $1
+		ORDER BY expires_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var exports []*models.DataExport
+	for rows.Next() {
+		export, err := r.scanDataExport(rows)
+		if err != nil {
+			return nil, err
+		}
+		exports = append(exports, export)
+	}
+
+	return exports, rows.Err()
+}
+
+// MarkExpired records that an export's archive has been deleted
+func (r *DataExportRepository) MarkExpired(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE data_exports SET status = 'expired', object_key = NULL WHERE id = $1`
+	_, err := r.db.Pool.Exec(ctx, query, id)
+	return err
+}
+
+// dataExportSections are the files of a data export. Each query builds one
+// JSON document for the user $1; the credentials and tokens stored for the
+// account are deliberately left out.
+var dataExportSections = []struct {
+	name  string
+	query string
+}{
+	{"profile.json", `
+		SELECT row_to_json(u) FROM (
+			SELECT id, username, email, display_name, bio, profile_picture_url,
+				ST_Y(location::geometry) AS latitude, ST_X(location::geometry) AS longitude,
+				city, country, genres, skills, spotify_url, soundcloud_url, instagram_handle,
+				created_at, updated_at, email_verified_at
+			FROM users
+			WHERE id = $1
+		) u
+	`},
+	{"posts.json", `
+		SELECT COALESCE(json_agg(p ORDER BY p.created_at), '[]') FROM (
+			SELECT id, content, media_urls, media_types, quoted_post_id, created_at, updated_at
+			FROM posts
+			WHERE user_id = $1
+		) p
+	`},
+	{"comments.json", `
+		SELECT COALESCE(json_agg(c ORDER BY c.created_at), '[]') FROM (
+			SELECT id, post_id, parent_comment_id, content, created_at
+			FROM comments
+			WHERE user_id = $1
+		) c
+	`},
+	{"likes.json", `
+		SELECT json_build_object(
+			'posts', (SELECT COALESCE(json_agg(l ORDER BY l.created_at), '[]') FROM (
+				SELECT post_id, created_at FROM likes WHERE user_id = $1
+			) l),
+			'comments', (SELECT COALESCE(json_agg(l ORDER BY l.created_at), '[]') FROM (
+				SELECT comment_id, created_at FROM comment_likes WHERE user_id = $1
+			) l)
+		)
+	`},
+	{"reposts.json", `
+		SELECT COALESCE(json_agg(r ORDER BY r.created_at), '[]') FROM (
+			SELECT post_id, created_at FROM reposts WHERE user_id = $1
+		) r
+	`},
+	{"follows.json", `
+		SELECT json_build_object(
+			'following', (SELECT COALESCE(json_agg(f ORDER BY f.followed_at), '[]') FROM (
+				SELECT f.following_type AS type, COALESCE(f.following_user_id, f.following_band_id) AS id,
+					COALESCE(u.username, b.name) AS name, f.created_at AS followed_at
+				FROM follows f
+				LEFT JOIN users u ON u.id = f.following_user_id
+				LEFT JOIN bands b ON b.id = f.following_band_id
+				WHERE f.follower_id = $1
+			) f),
+			'followers', (SELECT COALESCE(json_agg(f ORDER BY f.followed_at), '[]') FROM (
+				SELECT u.id, u.username, f.created_at AS followed_at
+				FROM follows f
+				JOIN users u ON u.id = f.follower_id
+				WHERE f.following_type = 'user' AND f.following_user_id = $1
+			) f)
+		)
+	`},
+	{"band_memberships.json", `
+		SELECT COALESCE(json_agg(m ORDER BY m.joined_at), '[]') FROM (
+			SELECT bm.band_id, b.name AS band_name, bm.role, bm.can_post_as_band, bm.joined_at
+			FROM band_members bm
+			JOIN bands b ON b.id = bm.band_id
+			WHERE bm.user_id = $1
+		) m
+	`},
+	{"band_membership_requests.json", `
+		SELECT COALESCE(json_agg(r ORDER BY r.created_at), '[]') FROM (
+			SELECT r.kind, r.band_id, b.name AS band_name, r.role, r.message,
+				CASE WHEN r.status = 'pending' AND r.expires_at <= NOW() THEN 'expired' ELSE r.status END AS status,
+				r.created_at, r.expires_at, r.responded_at
+			FROM band_membership_requests r
+			JOIN bands b ON b.id = r.band_id
+			WHERE r.user_id = $1
+		) r
+	`},
+	{"media.json", `
+		SELECT COALESCE(json_agg(m ORDER BY m.created_at), '[]') FROM (
+			SELECT 'profile_picture' AS source, NULL::uuid AS post_id, profile_picture_url AS url,
+				'image' AS media_type, updated_at AS created_at
+			FROM users
+			WHERE id = $1 AND profile_picture_url IS NOT NULL
+			UNION ALL
+			SELECT 'post', p.id, media.url, media.media_type, p.created_at
+			FROM posts p, unnest(p.media_urls, p.media_types) AS media(url, media_type)
+			WHERE p.user_id = $1
+		) m
+	`},
+}
+
+// CollectUserData gathers everything a data export contains about the user,
+// one JSON document per file
+func (r *DataExportRepository) CollectUserData(ctx context.Context, userID uuid.UUID) ([]models.DataExportFile, error) {
+	// One snapshot, so the files agree with each other
+	tx, err := r.db.Pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	files := make([]models.DataExportFile, 0, len(dataExportSections))
+	for _, section := range dataExportSections {
+		var data []byte
+		if err := tx.QueryRow(ctx, section.query, userID).Scan(&data); err != nil {
+			return nil, err
+		}
+		files = append(files, models.DataExportFile{Name: section.name, Data: data})
+	}
+
+	return files, tx.Commit(ctx)
+}
+
+func (r *DataExportRepository) scanDataExport(row pgx.Row) (*models.DataExport, error) {
+	var export models.DataExport
+
+	err := row.Scan(
+		&export.ID, &export.UserID, &export.Status, &export.ObjectKey, &export.SizeBytes, &export.Error,
+		&export.CreatedAt, &export.StartedAt, &export.CompletedAt, &export.ExpiresAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
@@ -5,6 +5,7 @@ import (
 
 	"musicapp/internal/db"
 	"musicapp/internal/models"
+	"musicapp/internal/pagination"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -56,9 +57,10 @@ func (r *FollowRepository) IsFollowing(ctx context.Context, followerID uuid.UUID
 	return exists, err
 }
 
-func (r *FollowRepository) GetFollowers(ctx context.Context, followingType string, followingUserID, followingBandID *uuid.UUID, limit, offset int) ([]*models.Follow, error) {
+func (r *FollowRepository) GetFollowers(ctx context.Context, followingType string, followingUserID, followingBandID *uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Follow, error) {
 	var query string
 	var args []interface{}
+	afterAt, afterID := after.Values()
 
 	if followingType == "user" && followingUserID != nil {
 		query = `
@@ -71,10 +73,11 @@ func (r *FollowRepository) GetFollowers(ctx context.Context, followingType strin
 			FROM follows f
 			JOIN users u ON f.follower_id = u.id
 			WHERE f.following_type = $1 AND f.following_user_id = $2
-			ORDER BY f.created_at DESC
+				AND ($5::timestamp IS NULL OR (f.created_at, f.id) < ($5::timestamp, $6::uuid))
+			ORDER BY f.created_at DESC, f.id DESC
 			LIMIT $3 OFFSET $4
 		`
-		args = []interface{}{followingType, followingUserID, limit, offset}
+		args = []interface{}{followingType, followingUserID, limit, offset, afterAt, afterID}
 	} else if followingType == "band" && followingBandID != nil {
 		query = `
 			SELECT f.id, f.follower_id, f.following_type, f.following_user_id, f.following_band_id, f.created_at,
@@ -86,10 +89,11 @@ func (r *FollowRepository) GetFollowers(ctx context.Context, followingType strin
 			FROM follows f
 			JOIN users u ON f.follower_id = u.id
 			WHERE f.following_type = $1 AND f.following_band_id = $2
-			ORDER BY f.created_at DESC
+				AND ($5::timestamp IS NULL OR (f.created_at, f.id) < ($5::timestamp, $6::uuid))
+			ORDER BY f.created_at DESC, f.id DESC
 			LIMIT $3 OFFSET $4
 		`
-		args = []interface{}{followingType, followingBandID, limit, offset}
+		args = []interface{}{followingType, followingBandID, limit, offset, afterAt, afterID}
 	} else {
 		return nil, pgx.ErrNoRows
 	}
@@ -112,7 +116,7 @@ func (r *FollowRepository) GetFollowers(ctx context.Context, followingType strin
 	return follows, rows.Err()
 }
 
-func (r *FollowRepository) GetFollowing(ctx context.Context, followerID uuid.UUID, limit, offset int) ([]*models.Follow, error) {
+func (r *FollowRepository) GetFollowing(ctx context.Context, followerID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Follow, error) {
 	query := `
 		SELECT f.id, f.follower_id, f.following_type, f.following_user_id, f.following_band_id, f.created_at,
 			u.id, u.username, u.email, u.display_name, u.bio, u.profile_picture_url,
@@ -128,11 +132,13 @@ func (r *FollowRepository) GetFollowing(ctx context.Context, followerID uuid.UUI
 		LEFT JOIN users u ON f.following_user_id = u.id
 		LEFT JOIN bands b ON f.following_band_id = b.id
 		WHERE f.follower_id = $1
-		ORDER BY f.created_at DESC
+			AND ($4::timestamp IS NULL OR (f.created_at, f.id) < ($4::timestamp, $5::uuid))
+		ORDER BY f.created_at DESC, f.id DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, followerID, limit, offset)
+	afterAt, afterID := after.Values()
+	rows, err := r.db.Pool.Query(ctx, query, followerID, limit, offset, afterAt, afterID)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"context"
+
+	"musicapp/internal/db"
+	"musicapp/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type IdentityRepository struct {
+	db *db.DB
+}
+
+func NewIdentityRepository(db *db.DB) *IdentityRepository {
+	return &IdentityRepository{db: db}
+}
+
+// Create links an identity. It reports false if the provider subject is
+// already linked, or the user already has an identity at the provider.
+func (r *IdentityRepository) Create(ctx context.Context, identity *models.Identity) (bool, error) {
+	query := `
+		INSERT INTO identities (id, user_id, provider, subject, email, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT DO NOTHING
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query,
+		identity.ID, identity.UserID, identity.Provider, identity.Subject, identity.Email,
+	)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *IdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.Identity, error) {
+	query := `
+		SELECT id, user_id, provider, subject, email, created_at, last_login_at
+		FROM identities
+		WHERE provider = $1 AND subject = $2
+	`
+
+	row := r.db.Pool.QueryRow(ctx, query, provider, subject)
+	return r.scanIdentity(row)
+}
+
+// GetByUserID lists the user's linked identities, oldest first
+func (r *IdentityRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Identity, error) {
+	query := `
+		SELECT id, user_id, provider, subject, email, created_at, last_login_at
+		FROM identities
+		WHERE user_id = $1
+		ORDER BY created_at ASC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var identities []*models.Identity
+	for rows.Next() {
+		identity, err := r.scanIdentity(rows)
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, identity)
+	}
+
+	return identities, rows.Err()
+}
+
+// TouchLogin records a sign-in through the identity
+func (r *IdentityRepository) TouchLogin(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE identities SET last_login_at = NOW() WHERE id = $1`
+	_, err := r.db.Pool.Exec(ctx, query, id)
+	return err
+}
+
+// Delete unlinks one of the user's identities. It reports false if the user
+// has no such identity.
+func (r *IdentityRepository) Delete(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	query := `DELETE FROM identities WHERE id = $1 AND user_id = $2`
+
+	tag, err := r.db.Pool.Exec(ctx, query, id, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *IdentityRepository) scanIdentity(row pgx.Row) (*models.Identity, error) {
+	var identity models.Identity
+
+	err := row.Scan(
+		&identity.ID, &identity.UserID, &identity.Provider, &identity.Subject, &identity.Email,
+		&identity.CreatedAt, &identity.LastLoginAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &identity, nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+
+	"musicapp/internal/db"
+	"musicapp/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type MessageRepository struct {
+	db *db.DB
+}
+
+func NewMessageRepository(db *db.DB) *MessageRepository {
+	return &MessageRepository{db: db}
+}
+
+func (r *MessageRepository) Create(ctx context.Context, message *models.Message) error {
+	query := `
+		INSERT INTO messages (id, band_id, user_id, content, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		message.ID, message.BandID, message.UserID, message.Content,
+	)
+	return err
+}
+
+func (r *MessageRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Message, error) {
+	query := `
+		SELECT m.id, m.band_id, m.user_id, m.content, m.created_at, u.username
+		FROM messages m
+		JOIN users u ON m.user_id = u.id
+		WHERE m.id = $1
+	`
+
+	row := r.db.Pool.QueryRow(ctx, query, id)
+	return r.scanMessage(row)
+}
+
+// GetByBandID returns a band's messages, newest first. When before is set,
+// only messages older than that message are returned.
+func (r *MessageRepository) GetByBandID(ctx context.Context, bandID uuid.UUID, before *uuid.UUID, limit int) ([]*models.Message, error) {
+	query := `
+		SELECT m.id, m.band_id, m.user_id, m.content, m.created_at, u.username
+		FROM messages m
+		JOIN users u ON m.user_id = u.id
+		WHERE m.band_id = $1
+			AND ($2::uuid IS NULL OR (m.created_at, m.id) < (
+				SELECT created_at, id FROM messages WHERE id = $2 AND band_id = $1
+			))
+		ORDER BY m.created_at DESC, m.id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, bandID, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []*models.Message
+	for rows.Next() {
+		message, err := r.scanMessage(rows)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	return messages, rows.Err()
+}
+
+func (r *MessageRepository) scanMessage(row pgx.Row) (*models.Message, error) {
+	var message models.Message
+
+	err := row.Scan(
+		&message.ID, &message.BandID, &message.UserID,
+		&message.Content, &message.CreatedAt, &message.Username,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &message, nil
+}
@@ -0,0 +1,138 @@
+package repository
+
+import (
+	"context"
+
+	"musicapp/internal/db"
+	"musicapp/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type MFARepository struct {
+	db *db.DB
+}
+
+func NewMFARepository(db *db.DB) *MFARepository {
+	return &MFARepository{db: db}
+}
+
+func (r *MFARepository) GetTOTP(ctx context.Context, userID uuid.UUID) (*models.TOTPCredential, error) {
+	query := `
+		SELECT user_id, secret, confirmed_at, last_used_step, created_at
+		FROM totp_credentials
+		WHERE user_id = $1
+	`
+
+	var credential models.TOTPCredential
+	err := r.db.Pool.QueryRow(ctx, query, userID).Scan(
+		&credential.UserID, &credential.Secret, &credential.ConfirmedAt,
+		&credential.LastUsedStep, &credential.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &credential, nil
+}
+
+// IsTOTPEnabled reports whether the user has a confirmed authenticator
+func (r *MFARepository) IsTOTPEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM totp_credentials WHERE user_id = $1 AND confirmed_at IS NOT NULL)`
+
+	var enabled bool
+	err := r.db.Pool.QueryRow(ctx, query, userID).Scan(&enabled)
+	return enabled, err
+}
+
+// SavePendingTOTP stores a new unconfirmed secret, replacing an earlier
+// unconfirmed one. It reports false if the user already has a confirmed
+// authenticator, which is left untouched.
+func (r *MFARepository) SavePendingTOTP(ctx context.Context, userID uuid.UUID, secret string) (bool, error) {
+	query := `
+		INSERT INTO totp_credentials (user_id, secret, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (user_id) DO UPDATE
+			SET secret = EXCLUDED.secret, last_used_step = NULL, created_at = NOW()
+			WHERE totp_credentials.confirmed_at IS NULL
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, userID, secret)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// ConfirmTOTP enables a pending authenticator and replaces the user's
+// recovery codes in one transaction
+func (r *MFARepository) ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	query := `UPDATE totp_credentials SET confirmed_at = NOW(), last_used_step = $2 WHERE user_id = $1`
+	if _, err := tx.Exec(ctx, query, userID, step); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	insertQuery := `INSERT INTO recovery_codes (user_id, code_hash, created_at) SELECT $1, unnest($2::text[]), NOW()`
+	if _, err := tx.Exec(ctx, insertQuery, userID, recoveryCodeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// MarkTOTPStepUsed records the time step of an accepted code. It reports
+// false if that step or a later one was already used, so a code cannot be
+// replayed.
+func (r *MFARepository) MarkTOTPStepUsed(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	query := `
+		UPDATE totp_credentials SET last_used_step = $2
+		WHERE user_id = $1 AND (last_used_step IS NULL OR last_used_step < $2)
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, userID, step)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// UseRecoveryCode consumes a recovery code. It reports false if the code
+// does not exist or was already used.
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	query := `UPDATE recovery_codes SET used_at = NOW() WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL`
+
+	tag, err := r.db.Pool.Exec(ctx, query, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// DeleteTOTP removes the user's authenticator and recovery codes, turning
+// two-factor login off
+func (r *MFARepository) DeleteTOTP(ctx context.Context, userID uuid.UUID) error {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `DELETE FROM totp_credentials WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM recovery_codes WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -2,14 +2,22 @@ package repository
 
 import (
 	"context"
+	"time"
 
 	"musicapp/internal/db"
 	"musicapp/internal/models"
+	"musicapp/internal/pagination"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 )
 
+// postAuthorVisible leaves out posts by accounts scheduled for deletion,
+// which are hidden from listings during the grace period
+const postAuthorVisible = `NOT EXISTS (
+	SELECT 1 FROM users au WHERE au.id = p.user_id AND au.deletion_scheduled_at IS NOT NULL
+)`
+
 type PostRepository struct {
 	db *db.DB
 }
@@ -20,13 +28,13 @@ func NewPostRepository(db *db.DB) *PostRepository {
 
 func (r *PostRepository) Create(ctx context.Context, post *models.Post) error {
 	query := `
-		INSERT INTO posts (id, author_id, author_type, band_id, user_id, content, media_urls, media_types, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW(), NOW())
+		INSERT INTO posts (id, author_id, author_type, band_id, user_id, content, media_urls, media_types, quoted_post_id, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW(), NOW())
 	`
 
 	_, err := r.db.Pool.Exec(ctx, query,
 		post.ID, post.AuthorID, post.AuthorType, post.BandID, post.UserID,
-		post.Content, post.MediaURLs, post.MediaTypes,
+		post.Content, post.MediaURLs, post.MediaTypes, post.QuotedPostID,
 	)
 	return err
 }
@@ -34,9 +42,10 @@ func (r *PostRepository) Create(ctx context.Context, post *models.Post) error {
 func (r *PostRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Post, error) {
 	query := `
 		SELECT p.id, p.author_id, p.author_type, p.band_id, p.user_id, p.content, 
-			p.media_urls, p.media_types, p.created_at, p.updated_at,
+			p.media_urls, p.media_types, p.created_at, p.updated_at, p.quoted_post_id,
 			COALESCE(l.likes_count, 0) as likes_count,
-			COALESCE(r.reposts_count, 0) as reposts_count
+			COALESCE(r.reposts_count, 0) as reposts_count,
+			COALESCE(c.comments_count, 0) as comments_count
 		FROM posts p
 		LEFT JOIN (
 			SELECT post_id, COUNT(*) as likes_count
@@ -48,6 +57,11 @@ func (r *PostRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Pos
 			FROM reposts
 			GROUP BY post_id
 		) r ON p.id = r.post_id
+		LEFT JOIN (
+			SELECT post_id, COUNT(*) as comments_count
+			FROM comments
+			GROUP BY post_id
+		) c ON p.id = c.post_id
 		WHERE p.id = $1
 	`
 
@@ -55,12 +69,63 @@ func (r *PostRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Pos
 	return r.scanPost(row)
 }
 
-func (r *PostRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.Post, error) {
+// GetByIDs loads the given posts with counts in a single query. Missing
+// posts are skipped.
+func (r *PostRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Post, error) {
+	if len(ids) == 0 {
+		return []*models.Post{}, nil
+	}
+
+	query := `
+		SELECT p.id, p.author_id, p.author_type, p.band_id, p.user_id, p.content, 
+			p.media_urls, p.media_types, p.created_at, p.updated_at, p.quoted_post_id,
+			COALESCE(l.likes_count, 0) as likes_count,
+			COALESCE(r.reposts_count, 0) as reposts_count,
+			COALESCE(c.comments_count, 0) as comments_count
+		FROM posts p
+		LEFT JOIN (
+			SELECT post_id, COUNT(*) as likes_count
+			FROM likes
+			GROUP BY post_id
+		) l ON p.id = l.post_id
+		LEFT JOIN (
+			SELECT post_id, COUNT(*) as reposts_count
+			FROM reposts
+			GROUP BY post_id
+		) r ON p.id = r.post_id
+		LEFT JOIN (
+			SELECT post_id, COUNT(*) as comments_count
+			FROM comments
+			GROUP BY post_id
+		) c ON p.id = c.post_id
+		WHERE p.id = ANY($1) AND ` + postAuthorVisible + `
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*models.Post
+	for rows.Next() {
+		post, err := r.scanPost(rows)
+		if err != nil {
+			return nil, err
+		}
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+func (r *PostRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Post, error) {
 	query := `
 		SELECT p.id, p.author_id, p.author_type, p.band_id, p.user_id, p.content, 
-			p.media_urls, p.media_types, p.created_at, p.updated_at,
+			p.media_urls, p.media_types, p.created_at, p.updated_at, p.quoted_post_id,
 			COALESCE(l.likes_count, 0) as likes_count,
-			COALESCE(r.reposts_count, 0) as reposts_count
+			COALESCE(r.reposts_count, 0) as reposts_count,
+			COALESCE(c.comments_count, 0) as comments_count
 		FROM posts p
 		LEFT JOIN (
 			SELECT post_id, COUNT(*) as likes_count
@@ -72,12 +137,19 @@ func (r *PostRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limi
 			FROM reposts
 			GROUP BY post_id
 		) r ON p.id = r.post_id
+		LEFT JOIN (
+			SELECT post_id, COUNT(*) as comments_count
+			FROM comments
+			GROUP BY post_id
+		) c ON p.id = c.post_id
 		WHERE p.user_id = $1
-		ORDER BY p.created_at DESC
+			AND ($4::timestamp IS NULL OR (p.created_at, p.id) < ($4::timestamp, $5::uuid))
+		ORDER BY p.created_at DESC, p.id DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, userID, limit, offset)
+	afterAt, afterID := after.Values()
+	rows, err := r.db.Pool.Query(ctx, query, userID, limit, offset, afterAt, afterID)
 	if err != nil {
 		return nil, err
 	}
@@ -95,12 +167,13 @@ func (r *PostRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limi
 	return posts, rows.Err()
 }
 
-func (r *PostRepository) GetByBandID(ctx context.Context, bandID uuid.UUID, limit, offset int) ([]*models.Post, error) {
+func (r *PostRepository) GetByBandID(ctx context.Context, bandID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Post, error) {
 	query := `
 		SELECT p.id, p.author_id, p.author_type, p.band_id, p.user_id, p.content, 
-			p.media_urls, p.media_types, p.created_at, p.updated_at,
+			p.media_urls, p.media_types, p.created_at, p.updated_at, p.quoted_post_id,
 			COALESCE(l.likes_count, 0) as likes_count,
-			COALESCE(r.reposts_count, 0) as reposts_count
+			COALESCE(r.reposts_count, 0) as reposts_count,
+			COALESCE(c.comments_count, 0) as comments_count
 		FROM posts p
 		LEFT JOIN (
 			SELECT post_id, COUNT(*) as likes_count
@@ -112,12 +185,19 @@ func (r *PostRepository) GetByBandID(ctx context.Context, bandID uuid.UUID, limi
 			FROM reposts
 			GROUP BY post_id
 		) r ON p.id = r.post_id
+		LEFT JOIN (
+			SELECT post_id, COUNT(*) as comments_count
+			FROM comments
+			GROUP BY post_id
+		) c ON p.id = c.post_id
 		WHERE p.band_id = $1
-		ORDER BY p.created_at DESC
+			AND ($4::timestamp IS NULL OR (p.created_at, p.id) < ($4::timestamp, $5::uuid))
+		ORDER BY p.created_at DESC, p.id DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, bandID, limit, offset)
+	afterAt, afterID := after.Values()
+	rows, err := r.db.Pool.Query(ctx, query, bandID, limit, offset, afterAt, afterID)
 	if err != nil {
 		return nil, err
 	}
@@ -135,14 +215,20 @@ func (r *PostRepository) GetByBandID(ctx context.Context, bandID uuid.UUID, limi
 	return posts, rows.Err()
 }
 
-func (r *PostRepository) GetFeed(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.Post, error) {
+// GetFeed returns the personalized feed for userID, or the explore feed of
+// recent posts when userID is uuid.Nil. For the personalized feed the cursor
+// position is the feed event time: the repost time for reposted items.
+func (r *PostRepository) GetFeed(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Post, error) {
+	afterAt, afterID := after.Values()
+
 	// If userID is empty (for explore feed), return recent posts
 	if userID == uuid.Nil {
 		query := `
 			SELECT p.id, p.author_id, p.author_type, p.band_id, p.user_id, p.content, 
-				p.media_urls, p.media_types, p.created_at, p.updated_at,
+				p.media_urls, p.media_types, p.created_at, p.updated_at, p.quoted_post_id,
 				COALESCE(l.likes_count, 0) as likes_count,
-				COALESCE(r.reposts_count, 0) as reposts_count
+				COALESCE(r.reposts_count, 0) as reposts_count,
+				COALESCE(c.comments_count, 0) as comments_count
 			FROM posts p
 			LEFT JOIN (
 				SELECT post_id, COUNT(*) as likes_count
@@ -154,11 +240,18 @@ func (r *PostRepository) GetFeed(ctx context.Context, userID uuid.UUID, limit, o
 				FROM reposts
 				GROUP BY post_id
 			) r ON p.id = r.post_id
-			ORDER BY p.created_at DESC
+			LEFT JOIN (
+				SELECT post_id, COUNT(*) as comments_count
+				FROM comments
+				GROUP BY post_id
+			) c ON p.id = c.post_id
+			WHERE ` + postAuthorVisible + `
+				AND ($3::timestamp IS NULL OR (p.created_at, p.id) < ($3::timestamp, $4::uuid))
+			ORDER BY p.created_at DESC, p.id DESC
 			LIMIT $1 OFFSET $2
 		`
 
-		rows, err := r.db.Pool.Query(ctx, query, limit, offset)
+		rows, err := r.db.Pool.Query(ctx, query, limit, offset, afterAt, afterID)
 		if err != nil {
 			return nil, err
 		}
@@ -176,13 +269,44 @@ func (r *PostRepository) GetFeed(ctx context.Context, userID uuid.UUID, limit, o
 		return posts, rows.Err()
 	}
 
-	// Personalized feed for specific user
+	// Personalized feed: posts by followed users and bands merged with posts
+	// that followed users reposted. A post reposted by several followees is
+	// collapsed into one item, attributed to the most recent reposter and
+	// ordered by that latest event.
 	query := `
+		WITH followed AS (
+			SELECT f.following_user_id AS id
+			FROM follows f
+			JOIN users fu ON fu.id = f.following_user_id
+			WHERE f.follower_id = $1 AND f.following_type = 'user' AND fu.deletion_scheduled_at IS NULL
+			UNION
+			SELECT following_band_id AS id FROM follows WHERE follower_id = $1 AND following_type = 'band'
+		),
+		events AS (
+			SELECT p.id AS post_id, NULL::uuid AS reposted_by, p.created_at AS event_at
+			FROM posts p
+			WHERE p.author_id IN (SELECT id FROM followed)
+			UNION ALL
+			SELECT rp.post_id, rp.user_id, rp.created_at
+			FROM reposts rp
+			WHERE rp.user_id IN (SELECT id FROM followed)
+		),
+		ranked AS (
+			SELECT post_id, reposted_by, event_at,
+				ROW_NUMBER() OVER (PARTITION BY post_id ORDER BY event_at DESC, reposted_by NULLS LAST) as rn,
+				COUNT(reposted_by) OVER (PARTITION BY post_id) as reposters_count
+			FROM events
+		)
 		SELECT p.id, p.author_id, p.author_type, p.band_id, p.user_id, p.content, 
-			p.media_urls, p.media_types, p.created_at, p.updated_at,
+			p.media_urls, p.media_types, p.created_at, p.updated_at, p.quoted_post_id,
 			COALESCE(l.likes_count, 0) as likes_count,
-			COALESCE(r.reposts_count, 0) as reposts_count
-		FROM posts p
+			COALESCE(r.reposts_count, 0) as reposts_count,
+			COALESCE(c.comments_count, 0) as comments_count,
+			e.reposted_by,
+			CASE WHEN e.reposted_by IS NOT NULL THEN e.event_at END as reposted_at,
+			e.reposters_count
+		FROM ranked e
+		JOIN posts p ON p.id = e.post_id
 		LEFT JOIN (
 			SELECT post_id, COUNT(*) as likes_count
 			FROM likes
@@ -193,16 +317,18 @@ func (r *PostRepository) GetFeed(ctx context.Context, userID uuid.UUID, limit, o
 			FROM reposts
 			GROUP BY post_id
 		) r ON p.id = r.post_id
-		WHERE p.author_id IN (
-			SELECT following_user_id FROM follows WHERE follower_id = $1 AND following_type = 'user'
-			UNION
-			SELECT following_band_id FROM follows WHERE follower_id = $1 AND following_type = 'band'
-		)
-		ORDER BY p.created_at DESC
+		LEFT JOIN (
+			SELECT post_id, COUNT(*) as comments_count
+			FROM comments
+			GROUP BY post_id
+		) c ON p.id = c.post_id
+		WHERE e.rn = 1 AND ` + postAuthorVisible + `
+			AND ($4::timestamp IS NULL OR (e.event_at, p.id) < ($4::timestamp, $5::uuid))
+		ORDER BY e.event_at DESC, p.id DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, userID, limit, offset)
+	rows, err := r.db.Pool.Query(ctx, query, userID, limit, offset, afterAt, afterID)
 	if err != nil {
 		return nil, err
 	}
@@ -210,10 +336,16 @@ func (r *PostRepository) GetFeed(ctx context.Context, userID uuid.UUID, limit, o
 
 	var posts []*models.Post
 	for rows.Next() {
-		post, err := r.scanPost(rows)
+		var repostedBy *uuid.UUID
+		var repostedAt *time.Time
+		var repostersCount int
+		post, err := r.scanPost(rows, &repostedBy, &repostedAt, &repostersCount)
 		if err != nil {
 			return nil, err
 		}
+		post.RepostedByID = repostedBy
+		post.RepostedAt = repostedAt
+		post.RepostersCount = repostersCount
 		posts = append(posts, post)
 	}
 
@@ -267,6 +399,12 @@ func (r *PostRepository) Repost(ctx context.Context, userID, postID uuid.UUID) e
 	return err
 }
 
+func (r *PostRepository) Unrepost(ctx context.Context, userID, postID uuid.UUID) error {
+	query := `DELETE FROM reposts WHERE user_id = $1 AND post_id = $2`
+	_, err := r.db.Pool.Exec(ctx, query, userID, postID)
+	return err
+}
+
 func (r *PostRepository) IsLiked(ctx context.Context, userID, postID uuid.UUID) (bool, error) {
 	query := `SELECT EXISTS(SELECT 1 FROM likes WHERE user_id = $1 AND post_id = $2)`
 	var exists bool
@@ -281,15 +419,52 @@ func (r *PostRepository) IsReposted(ctx context.Context, userID, postID uuid.UUI
 	return exists, err
 }
 
-func (r *PostRepository) scanPost(row pgx.Row) (*models.Post, error) {
+// GetLikedPostIDs returns the subset of postIDs the user has liked
+func (r *PostRepository) GetLikedPostIDs(ctx context.Context, userID uuid.UUID, postIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	return r.getViewerPostIDs(ctx, `SELECT post_id FROM likes WHERE user_id = $1 AND post_id = ANY($2)`, userID, postIDs)
+}
+
+// GetRepostedPostIDs returns the subset of postIDs the user has reposted
+func (r *PostRepository) GetRepostedPostIDs(ctx context.Context, userID uuid.UUID, postIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	return r.getViewerPostIDs(ctx, `SELECT post_id FROM reposts WHERE user_id = $1 AND post_id = ANY($2)`, userID, postIDs)
+}
+
+func (r *PostRepository) getViewerPostIDs(ctx context.Context, query string, userID uuid.UUID, postIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	matched := make(map[uuid.UUID]bool)
+	if len(postIDs) == 0 {
+		return matched, nil
+	}
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, postIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID uuid.UUID
+		if err := rows.Scan(&postID); err != nil {
+			return nil, err
+		}
+		matched[postID] = true
+	}
+
+	return matched, rows.Err()
+}
+
+// scanPost scans the standard post columns followed by any extra columns
+// the query selects after them
+func (r *PostRepository) scanPost(row pgx.Row, extra ...interface{}) (*models.Post, error) {
 	var post models.Post
 
-	err := row.Scan(
+	dest := []interface{}{
 		&post.ID, &post.AuthorID, &post.AuthorType, &post.BandID, &post.UserID,
 		&post.Content, &post.MediaURLs, &post.MediaTypes,
-		&post.CreatedAt, &post.UpdatedAt,
-		&post.LikesCount, &post.RepostsCount,
-	)
+		&post.CreatedAt, &post.UpdatedAt, &post.QuotedPostID,
+		&post.LikesCount, &post.RepostsCount, &post.CommentsCount,
+	}
+
+	err := row.Scan(append(dest, extra...)...)
 
 	if err != nil {
 		return nil, err
@@ -299,12 +474,13 @@ func (r *PostRepository) scanPost(row pgx.Row) (*models.Post, error) {
 }
 
 // GetAll gets all posts with pagination
-func (r *PostRepository) GetAll(ctx context.Context, limit, offset int) ([]*models.Post, error) {
+func (r *PostRepository) GetAll(ctx context.Context, limit, offset int, after *pagination.Cursor) ([]*models.Post, error) {
 	query := `
 		SELECT p.id, p.author_id, p.author_type, p.band_id, p.user_id, p.content, 
-			p.media_urls, p.media_types, p.created_at, p.updated_at,
+			p.media_urls, p.media_types, p.created_at, p.updated_at, p.quoted_post_id,
 			COALESCE(l.likes_count, 0) as likes_count,
-			COALESCE(r.reposts_count, 0) as reposts_count
+			COALESCE(r.reposts_count, 0) as reposts_count,
+			COALESCE(c.comments_count, 0) as comments_count
 		FROM posts p
 		LEFT JOIN (
 			SELECT post_id, COUNT(*) as likes_count
@@ -316,11 +492,19 @@ func (r *PostRepository) GetAll(ctx context.Context, limit, offset int) ([]*mode
 			FROM reposts
 			GROUP BY post_id
 		) r ON p.id = r.post_id
-		ORDER BY p.created_at DESC
+		LEFT JOIN (
+			SELECT post_id, COUNT(*) as comments_count
+			FROM comments
+			GROUP BY post_id
+		) c ON p.id = c.post_id
+		WHERE ` + postAuthorVisible + `
+			AND ($3::timestamp IS NULL OR (p.created_at, p.id) < ($3::timestamp, $4::uuid))
+		ORDER BY p.created_at DESC, p.id DESC
 		LIMIT $1 OFFSET $2
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, limit, offset)
+	afterAt, afterID := after.Values()
+	rows, err := r.db.Pool.Query(ctx, query, limit, offset, afterAt, afterID)
 	if err != nil {
 		return nil, err
 	}
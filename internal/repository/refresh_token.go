@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+
+	"musicapp/internal/db"
+	"musicapp/internal/models"
+
+	"github.com/google/uuid"
+)
+
+type RefreshTokenRepository struct {
+	db *db.DB
+}
+
+func NewRefreshTokenRepository(db *db.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (id, user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		token.ID, token.UserID, token.FamilyID, token.TokenHash, token.ExpiresAt,
+	)
+	return err
+}
+
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, family_id, token_hash, expires_at, used_at, revoked_at, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
+
+	var token models.RefreshToken
+	err := r.db.Pool.QueryRow(ctx, query, tokenHash).Scan(
+		&token.ID, &token.UserID, &token.FamilyID, &token.TokenHash,
+		&token.ExpiresAt, &token.UsedAt, &token.RevokedAt, &token.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+// MarkUsed consumes a refresh token. It reports false when the token was
+// already used or revoked, so two concurrent refreshes cannot both rotate it.
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id uuid.UUID) (bool, error) {
+	query := `
+		UPDATE refresh_tokens SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL AND revoked_at IS NULL
+	`
+
+	tag, err := r.db.Pool.Exec(ctx, query, id)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// RevokeFamily revokes every refresh token issued for one login
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL`
+	_, err := r.db.Pool.Exec(ctx, query, familyID)
+	return err
+}
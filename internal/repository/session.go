@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"musicapp/internal/db"
+	"musicapp/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type SessionRepository struct {
+	db *db.DB
+}
+
+func NewSessionRepository(db *db.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+func (r *SessionRepository) Create(ctx context.Context, session *models.Session) error {
+	query := `
+		INSERT INTO sessions (id, user_id, device_name, user_agent, ip_address, created_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, NOW(), NOW())
+	`
+
+	_, err := r.db.Pool.Exec(ctx, query,
+		session.ID, session.UserID, session.DeviceName, session.UserAgent, session.IPAddress,
+	)
+	return err
+}
+
+func (r *SessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	query := `
+		SELECT id, user_id, device_name, user_agent, ip_address, created_at, last_seen_at, revoked_at
+		FROM sessions
+		WHERE id = $1
+	`
+
+	row := r.db.Pool.QueryRow(ctx, query, id)
+	return r.scanSession(row)
+}
+
+// GetActiveByUserID lists a user's unrevoked sessions seen since the given
+// time, most recently used first
+func (r *SessionRepository) GetActiveByUserID(ctx context.Context, userID uuid.UUID, seenSince time.Time) ([]*models.Session, error) {
+	query := `
+		SELECT id, user_id, device_name, user_agent, ip_address, created_at, last_seen_at, revoked_at
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND last_seen_at > $2
+		ORDER BY last_seen_at DESC
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, userID, seenSince)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []*models.Session
+	for rows.Next() {
+		session, err := r.scanSession(rows)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// Touch records that the session was just used from the given address
+func (r *SessionRepository) Touch(ctx context.Context, id uuid.UUID, ipAddress *string) error {
+	query := `UPDATE sessions SET last_seen_at = NOW(), ip_address = COALESCE($2, ip_address) WHERE id = $1`
+	_, err := r.db.Pool.Exec(ctx, query, id, ipAddress)
+	return err
+}
+
+func (r *SessionRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	query := `UPDATE sessions SET revoked_at = NOW() WHERE id = $1 AND revoked_at IS NULL`
+	_, err := r.db.Pool.Exec(ctx, query, id)
+	return err
+}
+
+func (r *SessionRepository) scanSession(row pgx.Row) (*models.Session, error) {
+	var session models.Session
+
+	err := row.Scan(
+		&session.ID, &session.UserID, &session.DeviceName, &session.UserAgent, &session.IPAddress,
+		&session.CreatedAt, &session.LastSeenAt, &session.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
@@ -2,9 +2,12 @@ package repository
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"musicapp/internal/db"
 	"musicapp/internal/models"
+	"musicapp/internal/pagination"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -18,6 +21,7 @@ func NewUserRepository(db *db.DB) *UserRepository {
 	return &UserRepository{db: db}
 }
 
+// Create inserts a new user, who starts out with the default role
 func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 	query := `
 		INSERT INTO users (id, username, email, password_hash, display_name, bio, 
@@ -26,6 +30,7 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 		VALUES ($1, $2, $3, $4, $5, $6, $7, 
 			ST_SetSRID(ST_MakePoint($8, $9), 4326)::geography, $10, $11, $12, $13, 
 			$14, $15, $16, NOW(), NOW())
+		RETURNING role
 	`
 
 	var lat, lng *float64
@@ -34,14 +39,13 @@ func (r *UserRepository) Create(ctx context.Context, user *models.User) error {
 		lng = &user.Location.Longitude
 	}
 
-	_, err := r.db.Pool.Exec(ctx, query,
+	return r.db.Pool.QueryRow(ctx, query,
 		user.ID, user.Username, user.Email, user.PasswordHash,
 		user.DisplayName, user.Bio, user.ProfilePictureURL,
 		lat, lng, user.City, user.Country,
 		user.Genres, user.Skills,
 		user.SpotifyURL, user.SoundcloudURL, user.InstagramHandle,
-	)
-	return err
+	).Scan(&user.Role)
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
@@ -51,7 +55,8 @@ func (r *UserRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Use
 			ST_Y(location::geometry) as lat, ST_X(location::geometry) as lng,
 			city, country, genres, skills, 
 			spotify_url, soundcloud_url, instagram_handle, 
-			created_at, updated_at
+			created_at, updated_at, email_verified_at, deletion_scheduled_at,
+			role, suspended_at, suspension_reason
 		FROM users 
 		WHERE id = $1
 	`
@@ -67,7 +72,8 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*models.
 			ST_Y(location::geometry) as lat, ST_X(location::geometry) as lng,
 			city, country, genres, skills, 
 			spotify_url, soundcloud_url, instagram_handle, 
-			created_at, updated_at
+			created_at, updated_at, email_verified_at, deletion_scheduled_at,
+			role, suspended_at, suspension_reason
 		FROM users 
 		WHERE email = $1
 	`
@@ -83,7 +89,8 @@ func (r *UserRepository) GetByUsername(ctx context.Context, username string) (*m
 			ST_Y(location::geometry) as lat, ST_X(location::geometry) as lng,
 			city, country, genres, skills, 
 			spotify_url, soundcloud_url, instagram_handle, 
-			created_at, updated_at
+			created_at, updated_at, email_verified_at, deletion_scheduled_at,
+			role, suspended_at, suspension_reason
 		FROM users 
 		WHERE username = $1
 	`
@@ -118,6 +125,212 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 	return err
 }
 
+// UpdatePassword replaces the user's password hash
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	query := `UPDATE users SET password_hash = $2, updated_at = NOW() WHERE id = $1`
+	_, err := r.db.Pool.Exec(ctx, query, userID, passwordHash)
+	return err
+}
+
+// MarkEmailVerified records that the user confirmed their email address.
+// Verifying again keeps the original timestamp.
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	query := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()), updated_at = NOW() WHERE id = $1`
+	_, err := r.db.Pool.Exec(ctx, query, userID)
+	return err
+}
+
+// UpdateEmail changes the user's email address. The new address starts out
+// unverified.
+func (r *UserRepository) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	query := `UPDATE users SET email = $2, email_verified_at = NULL, updated_at = NOW() WHERE id = $1`
+	_, err := r.db.Pool.Exec(ctx, query, userID, email)
+	return err
+}
+
+// ScheduleDeletion marks the account to be deleted at the given time
+func (r *UserRepository) ScheduleDeletion(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	query := `UPDATE users SET deletion_scheduled_at = $2, updated_at = NOW() WHERE id = $1`
+	_, err := r.db.Pool.Exec(ctx, query, userID, at)
+	return err
+}
+
+// CancelDeletion keeps an account that was scheduled for deletion. It
+// reports false if no deletion was scheduled.
+func (r *UserRepository) CancelDeletion(ctx context.Context, userID uuid.UUID) (bool, error) {
+	query := `UPDATE users SET deletion_scheduled_at = NULL, updated_at = NOW() WHERE id = $1 AND deletion_scheduled_at IS NOT NULL`
+	tag, err := r.db.Pool.Exec(ctx, query, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// GetDueForDeletion lists accounts whose deletion was scheduled for before
+// the given time, oldest first
+func (r *UserRepository) GetDueForDeletion(ctx context.Context, before time.Time, limit int) ([]uuid.UUID, error) {
+	query := `
+		SELECT id FROM users
+		WHERE deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= $1
+		ORDER BY deletion_scheduled_at
+		LIMIT $2
+	`
+
+	rows, err := r.db.Pool.Query(ctx, query, before, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// OwnsBand reports whether the user is the owner of any band
+func (r *UserRepository) OwnsBand(ctx context.Context, userID uuid.UUID) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM band_members WHERE user_id = $1 AND role = 'owner')`
+	var owns bool
+	err := r.db.Pool.QueryRow(ctx, query, userID).Scan(&owns)
+	return owns, err
+}
+
+// DeleteScheduled permanently deletes an account whose deletion was
+// scheduled for before the given time, along with everything that cascades
+// from it. It reports false if the deletion was cancelled in the meantime.
+// Bands the user still owns are handed to their longest-standing admin, else
+// member, in the same transaction so no band is left without an owner.
+func (r *UserRepository) DeleteScheduled(ctx context.Context, userID uuid.UUID, before time.Time) (bool, error) {
+	tx, err := r.db.Pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var ownedBandIDs []uuid.UUID
+	query := `SELECT COALESCE(array_agg(band_id), '{}') FROM band_members WHERE user_id = $1 AND role = 'owner'`
+	if err := tx.QueryRow(ctx, query, userID).Scan(&ownedBandIDs); err != nil {
+		return false, err
+	}
+
+	query = `DELETE FROM users WHERE id = $1 AND deletion_scheduled_at IS NOT NULL AND deletion_scheduled_at <= $2`
+	tag, err := tx.Exec(ctx, query, userID, before)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	if len(ownedBandIDs) > 0 {
+		query = `
+			UPDATE band_members bm SET role = 'owner'
+			FROM (
+				SELECT DISTINCT ON (band_id) id
+				FROM band_members
+				WHERE band_id = ANY($1)
+				ORDER BY band_id, (role = 'admin') DESC, joined_at ASC, id ASC
+			) successors
+			WHERE bm.id = successors.id
+		`
+		if _, err := tx.Exec(ctx, query, ownedBandIDs); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit(ctx)
+}
+
+// SetRole changes the user's platform role
+func (r *UserRepository) SetRole(ctx context.Context, userID uuid.UUID, role string) error {
+	query := `UPDATE users SET role = $2, updated_at = NOW() WHERE id = $1`
+	_, err := r.db.Pool.Exec(ctx, query, userID, role)
+	return err
+}
+
+// Suspend suspends the account for the given reason. It reports false if
+// the account was already suspended.
+func (r *UserRepository) Suspend(ctx context.Context, userID uuid.UUID, reason string) (bool, error) {
+	query := `UPDATE users SET suspended_at = NOW(), suspension_reason = $2, updated_at = NOW() WHERE id = $1 AND suspended_at IS NULL`
+	tag, err := r.db.Pool.Exec(ctx, query, userID, reason)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Unsuspend lifts a suspension. It reports false if the account was not
+// suspended.
+func (r *UserRepository) Unsuspend(ctx context.Context, userID uuid.UUID) (bool, error) {
+	query := `UPDATE users SET suspended_at = NULL, suspension_reason = NULL, updated_at = NOW() WHERE id = $1 AND suspended_at IS NOT NULL`
+	tag, err := r.db.Pool.Exec(ctx, query, userID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// Search finds users for staff, newest first. The query matches part of
+// the username, email address or display name.
+func (r *UserRepository) Search(ctx context.Context, filter models.UserSearchFilter, limit int, after *pagination.Cursor) ([]*models.User, error) {
+	query := `
+		SELECT id, username, email, password_hash, display_name, bio, profile_picture_url,
+			ST_Y(location::geometry) as lat, ST_X(location::geometry) as lng,
+			city, country, genres, skills, spotify_url, soundcloud_url, instagram_handle,
+			created_at, updated_at, email_verified_at, deletion_scheduled_at,
+			role, suspended_at, suspension_reason
+		FROM users
+		WHERE ($1 = '' OR username ILIKE $1 OR email ILIKE $1 OR display_name ILIKE $1)
+			AND ($2 = '' OR role = $2)
+			AND ($3::boolean IS NULL OR (suspended_at IS NOT NULL) = $3)
+			AND ($4::timestamp IS NULL OR (created_at, id) < ($4::timestamp, $5::uuid))
+		ORDER BY created_at DESC, id DESC
+		LIMIT $6
+	`
+
+	pattern := ""
+	if filter.Query != "" {
+		pattern = "%" + likeEscaper.Replace(filter.Query) + "%"
+	}
+
+	afterAt, afterID := after.Values()
+	rows, err := r.db.Pool.Query(ctx, query, pattern, filter.Role, filter.Suspended, afterAt, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []*models.User
+	for rows.Next() {
+		user, err := r.scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// likeEscaper escapes LIKE wildcards so search text matches literally
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+
+// IsEmailVerified reports whether the user has confirmed their email address
+func (r *UserRepository) IsEmailVerified(ctx context.Context, userID uuid.UUID) (bool, error) {
+	query := `SELECT email_verified_at IS NOT NULL FROM users WHERE id = $1`
+
+	var verified bool
+	err := r.db.Pool.QueryRow(ctx, query, userID).Scan(&verified)
+	return verified, err
+}
+
 func (r *UserRepository) GetNearby(ctx context.Context, lat, lng float64, radiusKm int, limit int) ([]*models.User, error) {
 	query := `
 		SELECT id, username, email, password_hash, display_name, bio, 
@@ -125,7 +338,8 @@ func (r *UserRepository) GetNearby(ctx context.Context, lat, lng float64, radius
 			ST_Y(location::geometry) as lat, ST_X(location::geometry) as lng,
 			city, country, genres, skills, 
 			spotify_url, soundcloud_url, instagram_handle, 
-			created_at, updated_at,
+			created_at, updated_at, email_verified_at, deletion_scheduled_at,
+			role, suspended_at, suspension_reason,
 			ST_Distance(location, ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography) as distance_meters
 		FROM users 
 		WHERE ST_DWithin(
@@ -133,6 +347,7 @@ func (r *UserRepository) GetNearby(ctx context.Context, lat, lng float64, radius
 			ST_SetSRID(ST_MakePoint($1, $2), 4326)::geography,
 			$3
 		)
+			AND deletion_scheduled_at IS NULL
 		ORDER BY distance_meters
 		LIMIT $4
 	`
@@ -156,22 +371,26 @@ func (r *UserRepository) GetNearby(ctx context.Context, lat, lng float64, radius
 	return users, rows.Err()
 }
 
-func (r *UserRepository) GetFollowers(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.User, error) {
+func (r *UserRepository) GetFollowers(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.User, error) {
 	query := `
 		SELECT u.id, u.username, u.email, u.password_hash, u.display_name, u.bio, 
 			u.profile_picture_url, 
 			ST_Y(u.location::geometry) as lat, ST_X(u.location::geometry) as lng,
 			u.city, u.country, u.genres, u.skills, 
 			u.spotify_url, u.soundcloud_url, u.instagram_handle, 
-			u.created_at, u.updated_at
+			u.created_at, u.updated_at, u.email_verified_at, u.deletion_scheduled_at,
+			u.role, u.suspended_at, u.suspension_reason, f.created_at
 		FROM users u
 		JOIN follows f ON u.id = f.follower_id
 		WHERE f.following_type = 'user' AND f.following_user_id = $1
-		ORDER BY f.created_at DESC
+			AND u.deletion_scheduled_at IS NULL
+			AND ($4::timestamp IS NULL OR (f.created_at, u.id) < ($4::timestamp, $5::uuid))
+		ORDER BY f.created_at DESC, u.id DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, userID, limit, offset)
+	afterAt, afterID := after.Values()
+	rows, err := r.db.Pool.Query(ctx, query, userID, limit, offset, afterAt, afterID)
 	if err != nil {
 		return nil, err
 	}
@@ -179,32 +398,38 @@ func (r *UserRepository) GetFollowers(ctx context.Context, userID uuid.UUID, lim
 
 	var users []*models.User
 	for rows.Next() {
-		user, err := r.scanUser(rows)
+		var followedAt time.Time
+		user, err := r.scanUser(rows, &followedAt)
 		if err != nil {
 			return nil, err
 		}
+		user.FollowedAt = &followedAt
 		users = append(users, user)
 	}
 
 	return users, rows.Err()
 }
 
-func (r *UserRepository) GetFollowing(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.User, error) {
+func (r *UserRepository) GetFollowing(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.User, error) {
 	query := `
 		SELECT u.id, u.username, u.email, u.password_hash, u.display_name, u.bio, 
 			u.profile_picture_url, 
 			ST_Y(u.location::geometry) as lat, ST_X(u.location::geometry) as lng,
 			u.city, u.country, u.genres, u.skills, 
 			u.spotify_url, u.soundcloud_url, u.instagram_handle, 
-			u.created_at, u.updated_at
+			u.created_at, u.updated_at, u.email_verified_at, u.deletion_scheduled_at,
+			u.role, u.suspended_at, u.suspension_reason, f.created_at
 		FROM users u
 		JOIN follows f ON u.id = f.following_user_id
 		WHERE f.follower_id = $1 AND f.following_type = 'user'
-		ORDER BY f.created_at DESC
+			AND u.deletion_scheduled_at IS NULL
+			AND ($4::timestamp IS NULL OR (f.created_at, u.id) < ($4::timestamp, $5::uuid))
+		ORDER BY f.created_at DESC, u.id DESC
 		LIMIT $2 OFFSET $3
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, userID, limit, offset)
+	afterAt, afterID := after.Values()
+	rows, err := r.db.Pool.Query(ctx, query, userID, limit, offset, afterAt, afterID)
 	if err != nil {
 		return nil, err
 	}
@@ -212,10 +437,12 @@ func (r *UserRepository) GetFollowing(ctx context.Context, userID uuid.UUID, lim
 
 	var users []*models.User
 	for rows.Next() {
-		user, err := r.scanUser(rows)
+		var followedAt time.Time
+		user, err := r.scanUser(rows, &followedAt)
 		if err != nil {
 			return nil, err
 		}
+		user.FollowedAt = &followedAt
 		users = append(users, user)
 	}
 
@@ -223,18 +450,22 @@ func (r *UserRepository) GetFollowing(ctx context.Context, userID uuid.UUID, lim
 }
 
 // GetAll gets all users with pagination
-func (r *UserRepository) GetAll(ctx context.Context, limit, offset int) ([]*models.User, error) {
+func (r *UserRepository) GetAll(ctx context.Context, limit, offset int, after *pagination.Cursor) ([]*models.User, error) {
 	query := `
 		SELECT id, username, email, password_hash, display_name, bio, profile_picture_url,
 			ST_Y(location::geometry) as lat, ST_X(location::geometry) as lng,
 			city, country, genres, skills, spotify_url, soundcloud_url, instagram_handle,
-			created_at, updated_at
+			created_at, updated_at, email_verified_at, deletion_scheduled_at,
+			role, suspended_at, suspension_reason
 		FROM users
-		ORDER BY created_at DESC
+		WHERE deletion_scheduled_at IS NULL
+			AND ($3::timestamp IS NULL OR (created_at, id) < ($3::timestamp, $4::uuid))
+		ORDER BY created_at DESC, id DESC
 		LIMIT $1 OFFSET $2
 	`
 
-	rows, err := r.db.Pool.Query(ctx, query, limit, offset)
+	afterAt, afterID := after.Values()
+	rows, err := r.db.Pool.Query(ctx, query, limit, offset, afterAt, afterID)
 	if err != nil {
 		return nil, err
 	}
@@ -252,18 +483,48 @@ func (r *UserRepository) GetAll(ctx context.Context, limit, offset int) ([]*mode
 	return users, rows.Err()
 }
 
-func (r *UserRepository) scanUser(row pgx.Row) (*models.User, error) {
+// GetAuthorSummaries loads compact author summaries for the given users in one query
+func (r *UserRepository) GetAuthorSummaries(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.PostAuthor, error) {
+	authors := make(map[uuid.UUID]*models.PostAuthor)
+	if len(ids) == 0 {
+		return authors, nil
+	}
+
+	query := `SELECT id, username, display_name, profile_picture_url FROM users WHERE id = ANY($1)`
+	rows, err := r.db.Pool.Query(ctx, query, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		author := &models.PostAuthor{Type: "user"}
+		if err := rows.Scan(&author.ID, &author.Username, &author.DisplayName, &author.ProfilePictureURL); err != nil {
+			return nil, err
+		}
+		authors[author.ID] = author
+	}
+
+	return authors, rows.Err()
+}
+
+// scanUser scans the standard user columns followed by any extra columns
+// the query selects after them
+func (r *UserRepository) scanUser(row pgx.Row, extra ...interface{}) (*models.User, error) {
 	var user models.User
 	var lat, lng *float64
 
-	err := row.Scan(
+	dest := []interface{}{
 		&user.ID, &user.Username, &user.Email, &user.PasswordHash,
 		&user.DisplayName, &user.Bio, &user.ProfilePictureURL,
 		&lat, &lng, &user.City, &user.Country,
 		&user.Genres, &user.Skills,
 		&user.SpotifyURL, &user.SoundcloudURL, &user.InstagramHandle,
-		&user.CreatedAt, &user.UpdatedAt,
-	)
+		&user.CreatedAt, &user.UpdatedAt, &user.EmailVerifiedAt, &user.DeletionScheduledAt,
+		&user.Role, &user.SuspendedAt, &user.SuspensionReason,
+	}
+
+	err := row.Scan(append(dest, extra...)...)
 
 	if err != nil {
 		return nil, err
@@ -290,7 +551,8 @@ func (r *UserRepository) scanUserWithDistance(row pgx.Row) (*models.User, error)
 		&lat, &lng, &user.City, &user.Country,
 		&user.Genres, &user.Skills,
 		&user.SpotifyURL, &user.SoundcloudURL, &user.InstagramHandle,
-		&user.CreatedAt, &user.UpdatedAt, &distance,
+		&user.CreatedAt, &user.UpdatedAt, &user.EmailVerifiedAt, &user.DeletionScheduledAt,
+		&user.Role, &user.SuspendedAt, &user.SuspensionReason, &distance,
 	)
 
 	if err != nil {
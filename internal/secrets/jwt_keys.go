@@ -0,0 +1,118 @@
+package secrets
+
+import (
+	"crypto"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// loadJWTSigningKey loads the private key that signs access tokens from
+// JWT_SIGNING_KEY (PEM) or the file named by JWT_SIGNING_KEY_FILE. It
+// returns nil if neither is set, in which case tokens are signed with
+// JWT_SECRET.
+func loadJWTSigningKey() (crypto.Signer, error) {
+	data, err := readPEM("JWT_SIGNING_KEY", "JWT_SIGNING_KEY_FILE")
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("JWT signing key is not PEM encoded")
+	}
+
+	signer, err := parsePrivateKey(block)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWT signing key: %w", err)
+	}
+	return signer, nil
+}
+
+// loadJWTVerificationKeys loads extra public keys that access tokens may be
+// signed with, from a PEM bundle in JWT_VERIFICATION_KEYS or the file named
+// by JWT_VERIFICATION_KEYS_FILE. During a rotation this holds the previous
+// key, so tokens it signed stay valid until they expire, or the next key,
+// so verifiers learn it before it signs anything.
+func loadJWTVerificationKeys() ([]crypto.PublicKey, error) {
+	data, err := readPEM("JWT_VERIFICATION_KEYS", "JWT_VERIFICATION_KEYS_FILE")
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	var keys []crypto.PublicKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+
+		key, err := parsePublicKey(block)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT verification key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("JWT verification keys contain no PEM blocks")
+	}
+	return keys, nil
+}
+
+// readPEM reads PEM data from an environment variable, or from the file
+// named by a second one. Escaped newlines are accepted in the variable,
+// since many deployment tools cannot set multi-line values.
+func readPEM(envVar, fileEnvVar string) ([]byte, error) {
+	if value := os.Getenv(envVar); value != "" {
+		return []byte(strings.ReplaceAll(value, `\n`, "\n")), nil
+	}
+
+	if path := os.Getenv(fileEnvVar); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", fileEnvVar, err)
+		}
+		return data, nil
+	}
+
+	return nil, nil
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	switch block.Type {
+	case "PRIVATE KEY":
+		key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+		return signer, nil
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %q", block.Type)
+	}
+}
+
+func parsePublicKey(block *pem.Block) (crypto.PublicKey, error) {
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		// Accept private keys too and use their public half
+		signer, err := parsePrivateKey(block)
+		if err != nil {
+			return nil, err
+		}
+		return signer.Public(), nil
+	}
+}
@@ -1,6 +1,7 @@
 package secrets
 
 import (
+	"crypto"
 	"crypto/rand"
 	"encoding/base64"
 	"fmt"
@@ -9,28 +10,57 @@ import (
 
 // SecretManager handles secure secret generation and management
 type SecretManager struct {
-	jwtSecret []byte
+	jwtSecret           []byte
+	jwtSigningKey       crypto.Signer
+	jwtVerificationKeys []crypto.PublicKey
 }
 
 // NewSecretManager creates a new secret manager
 func NewSecretManager() (*SecretManager, error) {
-	jwtSecret, err := getOrGenerateJWTSecret()
+	jwtSigningKey, err := loadJWTSigningKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWT signing key: %w", err)
+	}
+
+	jwtVerificationKeys, err := loadJWTVerificationKeys()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load JWT verification keys: %w", err)
+	}
+
+	// With an asymmetric signing key, JWT_SECRET is only needed to accept
+	// HS256 tokens issued before the switch
+	jwtSecret, err := getOrGenerateJWTSecret(jwtSigningKey == nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize JWT secret: %w", err)
 	}
 
 	return &SecretManager{
-		jwtSecret: jwtSecret,
+		jwtSecret:           jwtSecret,
+		jwtSigningKey:       jwtSigningKey,
+		jwtVerificationKeys: jwtVerificationKeys,
 	}, nil
 }
 
-// GetJWTSecret returns the JWT secret
+// GetJWTSecret returns the JWT secret, or nil if HS256 is not in use
 func (sm *SecretManager) GetJWTSecret() []byte {
 	return sm.jwtSecret
 }
 
-// getOrGenerateJWTSecret gets JWT secret from environment or generates a new one
-func getOrGenerateJWTSecret() ([]byte, error) {
+// GetJWTSigningKey returns the asymmetric key that signs access tokens, or
+// nil if tokens are signed with the JWT secret
+func (sm *SecretManager) GetJWTSigningKey() crypto.Signer {
+	return sm.jwtSigningKey
+}
+
+// GetJWTVerificationKeys returns the extra public keys accepted on access
+// tokens besides the signing key's own
+func (sm *SecretManager) GetJWTVerificationKeys() []crypto.PublicKey {
+	return sm.jwtVerificationKeys
+}
+
+// getOrGenerateJWTSecret gets JWT secret from environment or generates a
+// new one. When the secret is not required it is only read if set.
+func getOrGenerateJWTSecret(required bool) ([]byte, error) {
 	// First, try to get from environment variable
 	if secret := os.Getenv("JWT_SECRET"); secret != "" {
 		if len(secret) < 32 {
@@ -39,6 +69,10 @@ func getOrGenerateJWTSecret() ([]byte, error) {
 		return []byte(secret), nil
 	}
 
+	if !required {
+		return nil, nil
+	}
+
 	// If not in environment, check if we're in development mode
 	if os.Getenv("ENVIRONMENT") == "development" {
 		// Generate a random secret for development
@@ -65,7 +99,7 @@ func ValidateSecretStrength(secret string) error {
 	if len(secret) < 32 {
 		return fmt.Errorf("secret must be at least 32 characters long")
 	}
-	
+
 	// Check for common weak secrets
 	weakSecrets := []string{
 		"your-super-secret-jwt-key-change-in-production",
@@ -75,12 +109,12 @@ func ValidateSecretStrength(secret string) error {
 		"jwt-secret",
 		"change-me",
 	}
-	
+
 	for _, weak := range weakSecrets {
 		if secret == weak {
 			return fmt.Errorf("secret is too weak, please use a stronger secret")
 		}
 	}
-	
+
 	return nil
 }
@@ -1,7 +1,13 @@
 package secrets_test
 
 import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"musicapp/internal/secrets"
@@ -85,6 +91,67 @@ func TestSecretManagerWithProductionMode(t *testing.T) {
 	}
 }
 
+func TestSecretManagerWithSigningKey(t *testing.T) {
+	// Test with an Ed25519 signing key in production and no JWT_SECRET
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to encode key: %v", err)
+	}
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+
+	_, previous, _ := ed25519.GenerateKey(rand.Reader)
+	publicDER, _ := x509.MarshalPKIXPublicKey(previous.Public())
+	bundle := filepath.Join(t.TempDir(), "jwt-verification-keys.pem")
+	if err := os.WriteFile(bundle, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), 0o600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+
+	os.Setenv("ENVIRONMENT", "production")
+	os.Unsetenv("JWT_SECRET")
+	// Escaped newlines, as set by tools that cannot pass multi-line values
+	os.Setenv("JWT_SIGNING_KEY", strings.ReplaceAll(keyPEM, "\n", `\n`))
+	os.Setenv("JWT_VERIFICATION_KEYS_FILE", bundle)
+	defer func() {
+		os.Unsetenv("ENVIRONMENT")
+		os.Unsetenv("JWT_SIGNING_KEY")
+		os.Unsetenv("JWT_VERIFICATION_KEYS_FILE")
+	}()
+
+	sm, err := secrets.NewSecretManager()
+	if err != nil {
+		t.Fatalf("Failed to create secret manager with signing key: %v", err)
+	}
+
+	if sm.GetJWTSecret() != nil {
+		t.Error("Expected no JWT secret when only a signing key is configured")
+	}
+	signer, ok := sm.GetJWTSigningKey().(ed25519.PrivateKey)
+	if !ok || !signer.Equal(key) {
+		t.Error("Expected signing key to be loaded from JWT_SIGNING_KEY")
+	}
+	verificationKeys := sm.GetJWTVerificationKeys()
+	if len(verificationKeys) != 1 || !previous.Public().(ed25519.PublicKey).Equal(verificationKeys[0]) {
+		t.Errorf("Expected one verification key from file, got %d", len(verificationKeys))
+	}
+}
+
+func TestSecretManagerWithInvalidSigningKey(t *testing.T) {
+	os.Setenv("JWT_SECRET", "test-secret-that-is-long-enough-for-validation")
+	os.Setenv("JWT_SIGNING_KEY", "not a key")
+	defer func() {
+		os.Unsetenv("JWT_SECRET")
+		os.Unsetenv("JWT_SIGNING_KEY")
+	}()
+
+	if _, err := secrets.NewSecretManager(); err == nil {
+		t.Error("Expected error for invalid JWT_SIGNING_KEY")
+	}
+}
+
 func TestSecretValidation(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -162,11 +162,11 @@ func (s *Seeder) seedBands(ctx context.Context, count int, users []*models.User)
 		selectedUsers := randomSubsetUsers(users, memberCount)
 
 		for j, user := range selectedUsers {
-			role := "Member"
+			role := models.BandRoleMember
 			if j == 0 {
-				role = "Admin" // First member is admin
-			} else if gofakeit.Bool() {
-				role = randomChoice([]string{"Producer", "Vocalist", "Guitarist", "Drummer"})
+				role = models.BandRoleOwner // First member owns the band
+			} else if gofakeit.IntRange(1, 4) == 1 {
+				role = models.BandRoleAdmin
 			}
 
 			if err := s.bandRepo.AddMember(ctx, band.ID, user.ID, role); err != nil {
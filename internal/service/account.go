@@ -0,0 +1,539 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/logging"
+	"musicapp/internal/mail"
+	"musicapp/internal/models"
+	"musicapp/internal/storage"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// PasswordResetTTL is how long a mailed password reset link stays valid
+const PasswordResetTTL = time.Hour
+
+// EmailVerificationTTL is how long a mailed email verification link stays valid
+const EmailVerificationTTL = 24 * time.Hour
+
+// Users may ask for a new verification email this many times per window
+const (
+	verificationResendLimit  = 3
+	verificationResendWindow = time.Hour
+)
+
+// Users may confirm a change to their account with their password this
+// many times per window
+const (
+	passwordConfirmLimit  = 5
+	passwordConfirmWindow = 15 * time.Minute
+)
+
+// AccountDeletionGracePeriod is how long a deleted account is kept, and can
+// be restored, before it is permanently deleted
+const AccountDeletionGracePeriod = 30 * 24 * time.Hour
+
+// accountPurgeBatchSize is how many due accounts are loaded at a time when
+// purging deleted accounts
+const accountPurgeBatchSize = 100
+
+// mailSendTimeout bounds delivery of mail sent in the background
+const mailSendTimeout = 30 * time.Second
+
+var (
+	errInvalidResetToken        = errors.New(errors.ErrCodeInvalidInput, "Invalid or expired reset token")
+	errInvalidVerificationToken = errors.New(errors.ErrCodeInvalidInput, "Invalid or expired verification token")
+	errEmailAlreadyVerified     = errors.New(errors.ErrCodeConflict, "Email is already verified")
+	errTooManyVerificationMails = errors.New(errors.ErrCodeRateLimited, "Too many verification emails requested, try again later")
+	errIncorrectPassword        = errors.New(errors.ErrCodeInvalidCredentials, "Current password is incorrect")
+	errTooManyPasswordAttempts  = errors.New(errors.ErrCodeRateLimited, "Too many password confirmations, try again later")
+	errNoPasswordSet            = errors.New(errors.ErrCodeBusinessRule, "Your account has no password yet; set one through forgot password first")
+	errEmailUnchanged           = errors.New(errors.ErrCodeInvalidInput, "That is already your email address")
+	errEmailTaken               = errors.New(errors.ErrCodeConflict, "An account with this email already exists")
+	errDeletionScheduled        = errors.New(errors.ErrCodeConflict, "Account is already scheduled for deletion")
+	errDeletionNotScheduled     = errors.New(errors.ErrCodeInvalidInput, "Account is not scheduled for deletion")
+	errOwnerCannotDelete        = errors.New(errors.ErrCodeBusinessRule, "Hand your bands over to another member before deleting your account")
+)
+
+// UserRepositoryForAccount interface for user operations
+type UserRepositoryForAccount interface {
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error
+	MarkEmailVerified(ctx context.Context, userID uuid.UUID) error
+	UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error
+	ScheduleDeletion(ctx context.Context, userID uuid.UUID, at time.Time) error
+	CancelDeletion(ctx context.Context, userID uuid.UUID) (bool, error)
+	OwnsBand(ctx context.Context, userID uuid.UUID) (bool, error)
+	GetDueForDeletion(ctx context.Context, before time.Time, limit int) ([]uuid.UUID, error)
+	DeleteScheduled(ctx context.Context, userID uuid.UUID, before time.Time) (bool, error)
+}
+
+// AccountTokenRepository interface for mailed single-use tokens
+type AccountTokenRepository interface {
+	Create(ctx context.Context, token *models.AccountToken) error
+	GetByHash(ctx context.Context, purpose, tokenHash string) (*models.AccountToken, error)
+	MarkUsed(ctx context.Context, id uuid.UUID) (bool, error)
+	InvalidateForUser(ctx context.Context, userID uuid.UUID, purpose string) error
+}
+
+// SessionRevoker signs a user out of their devices; implemented by
+// AuthService
+type SessionRevoker interface {
+	RevokeAllSessions(ctx context.Context, userID uuid.UUID) error
+	RevokeOtherSessions(ctx context.Context, userID uuid.UUID, currentSessionID string) (int, error)
+}
+
+// APIKeyRevoker revokes a user's personal API keys; implemented by
+// APIKeyService
+type APIKeyRevoker interface {
+	RevokeAll(ctx context.Context, userID uuid.UUID) error
+}
+
+// MediaStore deletes uploaded files; implemented by storage.S3Client
+type MediaStore interface {
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+}
+
+// RateLimiter counts attempts against a key within a window; implemented by
+// cache.Cache
+type RateLimiter interface {
+	CheckRateLimit(ctx context.Context, key string, limit int, window time.Duration) (bool, error)
+}
+
+// AccountService handles email verification and account recovery through
+// mailed links, and changes users make to their own credentials and account
+type AccountService struct {
+	userRepo    UserRepositoryForAccount
+	tokenRepo   AccountTokenRepository
+	sessions    SessionRevoker
+	apiKeys     APIKeyRevoker
+	media       MediaStore
+	mailer      mail.Mailer
+	rateLimiter RateLimiter
+	appURL      string
+	logger      *logging.Logger
+}
+
+// NewAccountService creates an AccountService. appURL is the base URL of the
+// web app that links in outgoing mail point to. media may be nil when file
+// uploads are not configured.
+func NewAccountService(userRepo UserRepositoryForAccount, tokenRepo AccountTokenRepository, sessions SessionRevoker, apiKeys APIKeyRevoker, media MediaStore, mailer mail.Mailer, rateLimiter RateLimiter, appURL string, logger *logging.Logger) *AccountService {
+	return &AccountService{
+		userRepo:    userRepo,
+		tokenRepo:   tokenRepo,
+		sessions:    sessions,
+		apiKeys:     apiKeys,
+		media:       media,
+		mailer:      mailer,
+		rateLimiter: rateLimiter,
+		appURL:      strings.TrimRight(appURL, "/"),
+		logger:      logger,
+	}
+}
+
+// SendVerificationEmail mails the user a link that confirms their email
+// address, replacing any link sent earlier
+func (s *AccountService) SendVerificationEmail(ctx context.Context, user *models.User) error {
+	token, err := s.issueToken(ctx, user.ID, models.TokenPurposeEmailVerification, EmailVerificationTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", s.appURL, url.QueryEscape(token))
+	s.sendInBackground(&mail.Message{
+		To:      user.Email,
+		Subject: "Confirm your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nThanks for signing up. Confirm your email address by opening this link "+
+			"within %d hours:\n\n%s\n\n"+
+			"If you didn't create an account, you can ignore this email.\n",
+			user.Username, int(EmailVerificationTTL.Hours()), link),
+	})
+
+	return nil
+}
+
+// ResendVerificationEmail sends a fresh verification link to a user who has
+// not verified yet. Requests are rate limited per user so the endpoint
+// cannot be used to flood an inbox.
+func (s *AccountService) ResendVerificationEmail(ctx context.Context, userID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return errors.NewUserNotFound(userID.String())
+	}
+	if user.EmailVerifiedAt != nil {
+		return errEmailAlreadyVerified
+	}
+
+	allowed, err := s.rateLimiter.CheckRateLimit(ctx, "verify_email:"+userID.String(), verificationResendLimit, verificationResendWindow)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeRedisError, "Failed to check rate limit")
+	}
+	if !allowed {
+		return errTooManyVerificationMails
+	}
+
+	return s.SendVerificationEmail(ctx, user)
+}
+
+// VerifyEmail marks the user's email address as verified using a mailed
+// verification token
+func (s *AccountService) VerifyEmail(ctx context.Context, token string) error {
+	stored, err := s.redeemToken(ctx, models.TokenPurposeEmailVerification, token, errInvalidVerificationToken)
+	if err != nil {
+		return err
+	}
+
+	if err := s.userRepo.MarkEmailVerified(ctx, stored.UserID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to verify email")
+	}
+
+	userID := stored.UserID.String()
+	s.logger.LogSecurityEvent("email_verified", &userID, nil)
+	return nil
+}
+
+// RequestPasswordReset mails a password reset link if the email belongs to
+// an account. It succeeds either way so callers cannot probe for accounts.
+func (s *AccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetByEmail(ctx, email)
+	if err != nil || user == nil {
+		return nil
+	}
+
+	token, err := s.issueToken(ctx, user.ID, models.TokenPurposePasswordReset, PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/reset-password?token=%s", s.appURL, url.QueryEscape(token))
+	s.sendInBackground(&mail.Message{
+		To:      user.Email,
+		Subject: "Reset your password",
+		Body: fmt.Sprintf("Hi %s,\n\nSomeone asked to reset the password for your account. "+
+			"If it was you, open this link within %d minutes:\n\n%s\n\n"+
+			"If it wasn't, you can ignore this email; your password has not changed.\n",
+			user.Username, int(PasswordResetTTL.Minutes()), link),
+	})
+
+	return nil
+}
+
+// ResetPassword sets a new password using a mailed reset token and signs
+// the user out of every session, since whoever held the old password may
+// still be logged in
+func (s *AccountService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	stored, err := s.redeemToken(ctx, models.TokenPurposePasswordReset, token, errInvalidResetToken)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternalError, "Failed to hash password")
+	}
+	if err := s.userRepo.UpdatePassword(ctx, stored.UserID, hashedPassword); err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to update password")
+	}
+
+	if err := s.sessions.RevokeAllSessions(ctx, stored.UserID); err != nil {
+		return err
+	}
+
+	userID := stored.UserID.String()
+	s.logger.LogSecurityEvent("password_reset", &userID, nil)
+	return nil
+}
+
+// ChangePassword sets a new password after checking the current one. Other
+// sessions are signed out and pending reset links stop working; the
+// session making the change stays signed in.
+func (s *AccountService) ChangePassword(ctx context.Context, userID uuid.UUID, currentSessionID, currentPassword, newPassword string) error {
+	user, err := s.confirmPassword(ctx, userID, currentPassword)
+	if err != nil {
+		return err
+	}
+
+	hashedPassword, err := utils.HashPassword(newPassword)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeInternalError, "Failed to hash password")
+	}
+	if err := s.userRepo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to update password")
+	}
+
+	if err := s.tokenRepo.InvalidateForUser(ctx, userID, models.TokenPurposePasswordReset); err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to invalidate reset tokens")
+	}
+	if _, err := s.sessions.RevokeOtherSessions(ctx, userID, currentSessionID); err != nil {
+		return err
+	}
+
+	s.sendInBackground(&mail.Message{
+		To:      user.Email,
+		Subject: "Your password was changed",
+		Body: fmt.Sprintf("Hi %s,\n\nThe password for your account was just changed and your other devices "+
+			"were signed out.\n\n"+
+			"If you didn't do this, reset your password right away at %s/forgot-password.\n",
+			user.Username, s.appURL),
+	})
+
+	userIDStr := userID.String()
+	s.logger.LogSecurityEvent("password_changed", &userIDStr, nil)
+	return nil
+}
+
+// ChangeEmail moves the account to a new email address after checking the
+// password. The new address has to be verified again, so a verification
+// link is mailed to it, and the old address is told about the change.
+func (s *AccountService) ChangeEmail(ctx context.Context, userID uuid.UUID, newEmail, password string) (*models.User, error) {
+	user, err := s.confirmPassword(ctx, userID, password)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(user.Email, newEmail) {
+		return nil, errEmailUnchanged
+	}
+
+	existing, err := s.userRepo.GetByEmail(ctx, newEmail)
+	if err == nil && existing != nil {
+		return nil, errEmailTaken
+	}
+
+	oldEmail := user.Email
+	if err := s.userRepo.UpdateEmail(ctx, userID, newEmail); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to update email")
+	}
+
+	// Reset links went to the old address
+	if err := s.tokenRepo.InvalidateForUser(ctx, userID, models.TokenPurposePasswordReset); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to invalidate reset tokens")
+	}
+
+	user.Email = newEmail
+	user.EmailVerifiedAt = nil
+	if err := s.SendVerificationEmail(ctx, user); err != nil {
+		return nil, err
+	}
+
+	s.sendInBackground(&mail.Message{
+		To:      oldEmail,
+		Subject: "Your email address was changed",
+		Body: fmt.Sprintf("Hi %s,\n\nThe email address for your account was just changed to %s. "+
+			"We'll send mail there from now on.\n\n"+
+			"If you didn't do this, contact us right away so we can help you get your account back.\n",
+			user.Username, newEmail),
+	})
+
+	userIDStr := userID.String()
+	s.logger.LogSecurityEvent("email_changed", &userIDStr, nil)
+	return user, nil
+}
+
+// DeleteAccount schedules the account for permanent deletion after
+// AccountDeletionGracePeriod and signs it out everywhere, revoking its
+// sessions and API keys. Logging in again and calling RestoreAccount
+// within the grace period keeps the account. Like leaving a band, it is
+// refused while the user still owns one.
+func (s *AccountService) DeleteAccount(ctx context.Context, userID uuid.UUID, password string) (time.Time, error) {
+	user, err := s.confirmPassword(ctx, userID, password)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if user.DeletionScheduledAt != nil {
+		return time.Time{}, errDeletionScheduled
+	}
+
+	ownsBand, err := s.userRepo.OwnsBand(ctx, userID)
+	if err != nil {
+		return time.Time{}, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to check band ownership")
+	}
+	if ownsBand {
+		return time.Time{}, errOwnerCannotDelete
+	}
+
+	deleteAt := time.Now().UTC().Add(AccountDeletionGracePeriod)
+	if err := s.userRepo.ScheduleDeletion(ctx, userID, deleteAt); err != nil {
+		return time.Time{}, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to schedule account deletion")
+	}
+
+	if err := s.sessions.RevokeAllSessions(ctx, userID); err != nil {
+		return time.Time{}, err
+	}
+	if err := s.apiKeys.RevokeAll(ctx, userID); err != nil {
+		return time.Time{}, err
+	}
+
+	s.sendInBackground(&mail.Message{
+		To:      user.Email,
+		Subject: "Your account will be deleted",
+		Body: fmt.Sprintf("Hi %s,\n\nYour account is scheduled to be deleted on %s, together with your posts, "+
+			"messages and uploads.\n\n"+
+			"Changed your mind? Log in before then and restore your account.\n",
+			user.Username, deleteAt.Format("January 2, 2006")),
+	})
+
+	userIDStr := userID.String()
+	s.logger.LogSecurityEvent("account_deletion_scheduled", &userIDStr, map[string]interface{}{
+		"deletion_scheduled_at": deleteAt,
+	})
+	return deleteAt, nil
+}
+
+// RestoreAccount cancels a scheduled account deletion
+func (s *AccountService) RestoreAccount(ctx context.Context, userID uuid.UUID) error {
+	restored, err := s.userRepo.CancelDeletion(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to restore account")
+	}
+	if !restored {
+		return errDeletionNotScheduled
+	}
+
+	userIDStr := userID.String()
+	s.logger.LogSecurityEvent("account_restored", &userIDStr, nil)
+	return nil
+}
+
+// PurgeDeletedAccounts permanently deletes accounts whose grace period has
+// passed, with their uploaded files, and returns how many were deleted
+func (s *AccountService) PurgeDeletedAccounts(ctx context.Context) (int, error) {
+	now := time.Now().UTC()
+	purged := 0
+	for {
+		userIDs, err := s.userRepo.GetDueForDeletion(ctx, now, accountPurgeBatchSize)
+		if err != nil {
+			return purged, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to list accounts to delete")
+		}
+
+		for _, userID := range userIDs {
+			deleted, err := s.userRepo.DeleteScheduled(ctx, userID, now)
+			if err != nil {
+				return purged, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to delete account")
+			}
+			if !deleted {
+				continue
+			}
+			purged++
+			s.deleteMedia(ctx, userID)
+
+			userIDStr := userID.String()
+			s.logger.LogSecurityEvent("account_deleted", &userIDStr, nil)
+		}
+
+		if len(userIDs) < accountPurgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// deleteMedia removes a deleted user's uploads. The account is already
+// gone, so failures are logged for cleanup by hand rather than retried.
+func (s *AccountService) deleteMedia(ctx context.Context, userID uuid.UUID) {
+	if s.media == nil {
+		return
+	}
+
+	for _, prefix := range storage.MediaPrefixes(userID.String()) {
+		if _, err := s.media.DeletePrefix(ctx, prefix); err != nil {
+			s.logger.WithError(err).WithField("prefix", prefix).Error("Failed to delete media of deleted account")
+		}
+	}
+}
+
+// confirmPassword loads the user and checks their password before a change
+// to their account. Attempts are rate limited per user so a stolen access
+// token cannot be used to guess the password. Accounts created through a
+// sign-in provider have no password, so they are asked to set one through
+// the mailed reset link, which proves they own the email address.
+func (s *AccountService) confirmPassword(ctx context.Context, userID uuid.UUID, password string) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, errors.NewUserNotFound(userID.String())
+	}
+	if user.PasswordHash == "" {
+		return nil, errNoPasswordSet
+	}
+
+	allowed, err := s.rateLimiter.CheckRateLimit(ctx, "confirm_password:"+userID.String(), passwordConfirmLimit, passwordConfirmWindow)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeRedisError, "Failed to check rate limit")
+	}
+	if !allowed {
+		return nil, errTooManyPasswordAttempts
+	}
+
+	if !utils.CheckPasswordHash(password, user.PasswordHash) {
+		return nil, errIncorrectPassword
+	}
+	return user, nil
+}
+
+// issueToken creates a new mailed token, invalidating earlier ones for the
+// same purpose
+func (s *AccountService) issueToken(ctx context.Context, userID uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	if err := s.tokenRepo.InvalidateForUser(ctx, userID, purpose); err != nil {
+		return "", errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to invalidate previous tokens")
+	}
+
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return "", errors.Wrap(err, errors.ErrCodeInternalError, "Failed to generate token")
+	}
+
+	record := &models.AccountToken{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().UTC().Add(ttl),
+	}
+	if err := s.tokenRepo.Create(ctx, record); err != nil {
+		return "", errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to store token")
+	}
+
+	return token, nil
+}
+
+// redeemToken consumes a mailed token, failing with invalid if it is
+// unknown, expired or already used
+func (s *AccountService) redeemToken(ctx context.Context, purpose, token string, invalid error) (*models.AccountToken, error) {
+	if token == "" {
+		return nil, invalid
+	}
+
+	stored, err := s.tokenRepo.GetByHash(ctx, purpose, utils.HashToken(token))
+	if err != nil || stored == nil || stored.UsedAt != nil || time.Now().After(stored.ExpiresAt) {
+		return nil, invalid
+	}
+
+	redeemed, err := s.tokenRepo.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to redeem token")
+	}
+	if !redeemed {
+		return nil, invalid
+	}
+
+	return stored, nil
+}
+
+// sendInBackground delivers mail without holding up the request, so the
+// response time does not reveal whether an account exists
+func (s *AccountService) sendInBackground(msg *mail.Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+		defer cancel()
+
+		if err := s.mailer.Send(ctx, msg); err != nil {
+			s.logger.WithError(err).WithField("subject", msg.Subject).Error("Failed to send mail")
+		}
+	}()
+}
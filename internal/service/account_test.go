@@ -0,0 +1,566 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/mail"
+	"musicapp/internal/models"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// Mock implementations for AccountService testing
+
+type MockUserRepositoryForAccount struct {
+	*MockUserRepository
+	passwordHashes map[uuid.UUID]string
+	bandOwners     map[uuid.UUID]bool
+}
+
+func NewMockUserRepositoryForAccount() *MockUserRepositoryForAccount {
+	return &MockUserRepositoryForAccount{
+		MockUserRepository: NewMockUserRepository(),
+		passwordHashes:     make(map[uuid.UUID]string),
+		bandOwners:         make(map[uuid.UUID]bool),
+	}
+}
+
+func (m *MockUserRepositoryForAccount) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	m.passwordHashes[userID] = passwordHash
+	return nil
+}
+
+func (m *MockUserRepositoryForAccount) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	if user, exists := m.usersByID[userID.String()]; exists {
+		delete(m.usersByEmail, user.Email)
+		user.Email = email
+		user.EmailVerifiedAt = nil
+		m.usersByEmail[email] = user
+	}
+	return nil
+}
+
+func (m *MockUserRepositoryForAccount) ScheduleDeletion(ctx context.Context, userID uuid.UUID, at time.Time) error {
+	if user, exists := m.usersByID[userID.String()]; exists {
+		user.DeletionScheduledAt = &at
+	}
+	return nil
+}
+
+func (m *MockUserRepositoryForAccount) CancelDeletion(ctx context.Context, userID uuid.UUID) (bool, error) {
+	user, exists := m.usersByID[userID.String()]
+	if !exists || user.DeletionScheduledAt == nil {
+		return false, nil
+	}
+	user.DeletionScheduledAt = nil
+	return true, nil
+}
+
+func (m *MockUserRepositoryForAccount) OwnsBand(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return m.bandOwners[userID], nil
+}
+
+func (m *MockUserRepositoryForAccount) GetDueForDeletion(ctx context.Context, before time.Time, limit int) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	for _, user := range m.usersByID {
+		if user.DeletionScheduledAt != nil && !user.DeletionScheduledAt.After(before) && len(ids) < limit {
+			ids = append(ids, user.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (m *MockUserRepositoryForAccount) DeleteScheduled(ctx context.Context, userID uuid.UUID, before time.Time) (bool, error) {
+	user, exists := m.usersByID[userID.String()]
+	if !exists || user.DeletionScheduledAt == nil || user.DeletionScheduledAt.After(before) {
+		return false, nil
+	}
+	delete(m.usersByID, userID.String())
+	delete(m.usersByEmail, user.Email)
+	delete(m.usersByUsername, user.Username)
+	return true, nil
+}
+
+func (m *MockUserRepositoryForAccount) MarkEmailVerified(ctx context.Context, userID uuid.UUID) error {
+	if user, exists := m.usersByID[userID.String()]; exists && user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+	}
+	return nil
+}
+
+type MockAccountTokenRepository struct {
+	tokens map[string]*models.AccountToken
+}
+
+func NewMockAccountTokenRepository() *MockAccountTokenRepository {
+	return &MockAccountTokenRepository{tokens: make(map[string]*models.AccountToken)}
+}
+
+func (m *MockAccountTokenRepository) Create(ctx context.Context, token *models.AccountToken) error {
+	m.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (m *MockAccountTokenRepository) GetByHash(ctx context.Context, purpose, tokenHash string) (*models.AccountToken, error) {
+	if token, exists := m.tokens[tokenHash]; exists && token.Purpose == purpose {
+		return token, nil
+	}
+	return nil, fmt.Errorf("no rows in result set")
+}
+
+func (m *MockAccountTokenRepository) MarkUsed(ctx context.Context, id uuid.UUID) (bool, error) {
+	for _, token := range m.tokens {
+		if token.ID == id && token.UsedAt == nil {
+			now := time.Now()
+			token.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockAccountTokenRepository) InvalidateForUser(ctx context.Context, userID uuid.UUID, purpose string) error {
+	now := time.Now()
+	for _, token := range m.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.UsedAt == nil {
+			token.UsedAt = &now
+		}
+	}
+	return nil
+}
+
+type MockSessionRevoker struct {
+	revokedUsers []uuid.UUID
+	keptSessions []string
+}
+
+func (m *MockSessionRevoker) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	m.revokedUsers = append(m.revokedUsers, userID)
+	return nil
+}
+
+func (m *MockSessionRevoker) RevokeOtherSessions(ctx context.Context, userID uuid.UUID, currentSessionID string) (int, error) {
+	m.keptSessions = append(m.keptSessions, currentSessionID)
+	return 0, nil
+}
+
+type MockAPIKeyRevoker struct {
+	revokedUsers []uuid.UUID
+}
+
+func (m *MockAPIKeyRevoker) RevokeAll(ctx context.Context, userID uuid.UUID) error {
+	m.revokedUsers = append(m.revokedUsers, userID)
+	return nil
+}
+
+type MockMediaStore struct {
+	deletedPrefixes []string
+}
+
+func (m *MockMediaStore) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	m.deletedPrefixes = append(m.deletedPrefixes, prefix)
+	return 1, nil
+}
+
+type MockRateLimiter struct {
+	counts map[string]int
+}
+
+func NewMockRateLimiter() *MockRateLimiter {
+	return &MockRateLimiter{counts: make(map[string]int)}
+}
+
+func (m *MockRateLimiter) CheckRateLimit(ctx context.Context, key string, limit int, window time.Duration) (bool, error) {
+	m.counts[key]++
+	return m.counts[key] <= limit, nil
+}
+
+type MockMailer struct {
+	sent chan *mail.Message
+}
+
+func NewMockMailer() *MockMailer {
+	return &MockMailer{sent: make(chan *mail.Message, 10)}
+}
+
+func (m *MockMailer) Send(ctx context.Context, msg *mail.Message) error {
+	m.sent <- msg
+	return nil
+}
+
+// next waits for the next message sent in the background
+func (m *MockMailer) next(t *testing.T) *mail.Message {
+	t.Helper()
+	select {
+	case msg := <-m.sent:
+		return msg
+	case <-time.After(time.Second):
+		t.Fatal("Expected a message to be sent")
+		return nil
+	}
+}
+
+var (
+	resetLinkPattern        = regexp.MustCompile(`https://app\.example\.com/reset-password\?token=(\S+)`)
+	verificationLinkPattern = regexp.MustCompile(`https://app\.example\.com/verify-email\?token=(\S+)`)
+)
+
+// mailedToken extracts the token from the link in a mailed message
+func mailedToken(t *testing.T, msg *mail.Message, pattern *regexp.Regexp) string {
+	t.Helper()
+	match := pattern.FindStringSubmatch(msg.Body)
+	if match == nil {
+		t.Fatalf("Expected a link in the email, got %q", msg.Body)
+	}
+	token, _ := url.QueryUnescape(match[1])
+	return token
+}
+
+func TestAccountService_PasswordReset(t *testing.T) {
+	setup := func() (*AccountService, *MockUserRepositoryForAccount, *MockSessionRevoker, *MockMailer, *models.User) {
+		userRepo := NewMockUserRepositoryForAccount()
+		user := &models.User{ID: uuid.New(), Username: "testuser", Email: "test@example.com"}
+		userRepo.usersByEmail[user.Email] = user
+		userRepo.usersByID[user.ID.String()] = user
+
+		sessions := &MockSessionRevoker{}
+		mailer := NewMockMailer()
+		accountService := NewAccountService(userRepo, NewMockAccountTokenRepository(), sessions, &MockAPIKeyRevoker{}, nil, mailer, NewMockRateLimiter(), "https://app.example.com/", createTestLogger())
+		return accountService, userRepo, sessions, mailer, user
+	}
+
+	requestToken := func(t *testing.T, accountService *AccountService, mailer *MockMailer, email string) string {
+		t.Helper()
+		if err := accountService.RequestPasswordReset(context.Background(), email); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		return mailedToken(t, mailer.next(t), resetLinkPattern)
+	}
+
+	t.Run("reset with a mailed token", func(t *testing.T) {
+		accountService, userRepo, sessions, mailer, user := setup()
+		token := requestToken(t, accountService, mailer, user.Email)
+
+		if err := accountService.ResetPassword(context.Background(), token, "NewPassw0rd!"); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if !utils.CheckPasswordHash("NewPassw0rd!", userRepo.passwordHashes[user.ID]) {
+			t.Error("Expected the password to be updated")
+		}
+		if len(sessions.revokedUsers) != 1 || sessions.revokedUsers[0] != user.ID {
+			t.Errorf("Expected all sessions to be revoked, got %v", sessions.revokedUsers)
+		}
+
+		err := accountService.ResetPassword(context.Background(), token, "OtherPassw0rd!")
+		if err == nil || !strings.Contains(err.Error(), "Invalid or expired reset token") {
+			t.Errorf("Expected the token to be single-use, got: %v", err)
+		}
+	})
+
+	t.Run("a new request invalidates the previous token", func(t *testing.T) {
+		accountService, _, _, mailer, user := setup()
+		first := requestToken(t, accountService, mailer, user.Email)
+		second := requestToken(t, accountService, mailer, user.Email)
+
+		if err := accountService.ResetPassword(context.Background(), first, "NewPassw0rd!"); err == nil {
+			t.Error("Expected the earlier token to be invalidated")
+		}
+		if err := accountService.ResetPassword(context.Background(), second, "NewPassw0rd!"); err != nil {
+			t.Errorf("Expected the latest token to work, got: %v", err)
+		}
+	})
+
+	t.Run("expired tokens are rejected", func(t *testing.T) {
+		accountService, _, _, mailer, user := setup()
+		token := requestToken(t, accountService, mailer, user.Email)
+		accountService.tokenRepo.(*MockAccountTokenRepository).tokens[utils.HashToken(token)].ExpiresAt = time.Now().Add(-time.Minute)
+
+		if err := accountService.ResetPassword(context.Background(), token, "NewPassw0rd!"); err == nil {
+			t.Error("Expected expired token to be rejected")
+		}
+	})
+
+	t.Run("unknown email does not reveal the account", func(t *testing.T) {
+		accountService, _, _, mailer, _ := setup()
+
+		if err := accountService.RequestPasswordReset(context.Background(), "nobody@example.com"); err != nil {
+			t.Errorf("Expected no error for unknown email, got: %v", err)
+		}
+		select {
+		case msg := <-mailer.sent:
+			t.Errorf("Expected no email for unknown account, got %+v", msg)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+}
+
+func TestAccountService_EmailVerification(t *testing.T) {
+	setup := func() (*AccountService, *MockMailer, *models.User) {
+		userRepo := NewMockUserRepositoryForAccount()
+		user := &models.User{ID: uuid.New(), Username: "testuser", Email: "test@example.com"}
+		userRepo.usersByEmail[user.Email] = user
+		userRepo.usersByID[user.ID.String()] = user
+
+		mailer := NewMockMailer()
+		accountService := NewAccountService(userRepo, NewMockAccountTokenRepository(), &MockSessionRevoker{}, &MockAPIKeyRevoker{}, nil, mailer, NewMockRateLimiter(), "https://app.example.com", createTestLogger())
+		return accountService, mailer, user
+	}
+
+	t.Run("verify with a mailed token", func(t *testing.T) {
+		accountService, mailer, user := setup()
+		if err := accountService.SendVerificationEmail(context.Background(), user); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		msg := mailer.next(t)
+		if msg.To != user.Email {
+			t.Errorf("Expected email to %s, got %s", user.Email, msg.To)
+		}
+		token := mailedToken(t, msg, verificationLinkPattern)
+
+		if err := accountService.VerifyEmail(context.Background(), token); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if user.EmailVerifiedAt == nil {
+			t.Error("Expected the email to be marked verified")
+		}
+
+		err := accountService.VerifyEmail(context.Background(), token)
+		if err == nil || !strings.Contains(err.Error(), "Invalid or expired verification token") {
+			t.Errorf("Expected the token to be single-use, got: %v", err)
+		}
+	})
+
+	t.Run("reset tokens cannot verify an email", func(t *testing.T) {
+		accountService, mailer, user := setup()
+		if err := accountService.RequestPasswordReset(context.Background(), user.Email); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		token := mailedToken(t, mailer.next(t), resetLinkPattern)
+
+		if err := accountService.VerifyEmail(context.Background(), token); err == nil {
+			t.Error("Expected a password reset token to be rejected")
+		}
+		if user.EmailVerifiedAt != nil {
+			t.Error("Expected the email to stay unverified")
+		}
+	})
+
+	t.Run("resend is rate limited", func(t *testing.T) {
+		accountService, mailer, user := setup()
+		for i := 0; i < verificationResendLimit; i++ {
+			if err := accountService.ResendVerificationEmail(context.Background(), user.ID); err != nil {
+				t.Fatalf("Expected resend %d to succeed, got: %v", i+1, err)
+			}
+			mailer.next(t)
+		}
+
+		err := accountService.ResendVerificationEmail(context.Background(), user.ID)
+		if err == nil || !strings.Contains(err.Error(), "Too many verification emails") {
+			t.Errorf("Expected rate limit error, got: %v", err)
+		}
+	})
+
+	t.Run("resend is refused once verified", func(t *testing.T) {
+		accountService, _, user := setup()
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+
+		err := accountService.ResendVerificationEmail(context.Background(), user.ID)
+		if err == nil || !strings.Contains(err.Error(), "already verified") {
+			t.Errorf("Expected already verified error, got: %v", err)
+		}
+	})
+}
+
+func TestAccountService_AccountChanges(t *testing.T) {
+	type fixture struct {
+		accountService *AccountService
+		userRepo       *MockUserRepositoryForAccount
+		sessions       *MockSessionRevoker
+		apiKeys        *MockAPIKeyRevoker
+		media          *MockMediaStore
+		mailer         *MockMailer
+		user           *models.User
+	}
+
+	setup := func(t *testing.T) *fixture {
+		t.Helper()
+		hash, err := utils.HashPassword("OldPassw0rd!")
+		if err != nil {
+			t.Fatalf("Failed to hash password: %v", err)
+		}
+		now := time.Now()
+		user := &models.User{ID: uuid.New(), Username: "testuser", Email: "test@example.com", PasswordHash: hash, EmailVerifiedAt: &now}
+
+		f := &fixture{
+			userRepo: NewMockUserRepositoryForAccount(),
+			sessions: &MockSessionRevoker{},
+			apiKeys:  &MockAPIKeyRevoker{},
+			media:    &MockMediaStore{},
+			mailer:   NewMockMailer(),
+			user:     user,
+		}
+		f.userRepo.Create(context.Background(), user)
+		f.accountService = NewAccountService(f.userRepo, NewMockAccountTokenRepository(), f.sessions, f.apiKeys, f.media, f.mailer, NewMockRateLimiter(), "https://app.example.com", createTestLogger())
+		return f
+	}
+
+	t.Run("change password", func(t *testing.T) {
+		f := setup(t)
+
+		err := f.accountService.ChangePassword(context.Background(), f.user.ID, "session-1", "WrongPassw0rd!", "NewPassw0rd!")
+		expectAppError(t, err, errors.ErrCodeInvalidCredentials)
+
+		if err := f.accountService.ChangePassword(context.Background(), f.user.ID, "session-1", "OldPassw0rd!", "NewPassw0rd!"); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if !utils.CheckPasswordHash("NewPassw0rd!", f.userRepo.passwordHashes[f.user.ID]) {
+			t.Error("Expected the password to be updated")
+		}
+		if len(f.sessions.keptSessions) != 1 || f.sessions.keptSessions[0] != "session-1" {
+			t.Errorf("Expected other sessions to be revoked keeping the current one, got %v", f.sessions.keptSessions)
+		}
+		if msg := f.mailer.next(t); msg.To != f.user.Email {
+			t.Errorf("Expected a notice to %s, got %s", f.user.Email, msg.To)
+		}
+	})
+
+	t.Run("password confirmations are rate limited", func(t *testing.T) {
+		f := setup(t)
+		for i := 0; i < passwordConfirmLimit; i++ {
+			f.accountService.ChangePassword(context.Background(), f.user.ID, "", "WrongPassw0rd!", "NewPassw0rd!")
+		}
+
+		err := f.accountService.ChangePassword(context.Background(), f.user.ID, "", "OldPassw0rd!", "NewPassw0rd!")
+		expectAppError(t, err, errors.ErrCodeRateLimited)
+	})
+
+	t.Run("change email", func(t *testing.T) {
+		f := setup(t)
+
+		user, err := f.accountService.ChangeEmail(context.Background(), f.user.ID, "new@example.com", "OldPassw0rd!")
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if user.Email != "new@example.com" || user.EmailVerifiedAt != nil {
+			t.Errorf("Expected an unverified new email, got %s verified at %v", user.Email, user.EmailVerifiedAt)
+		}
+
+		// The verification link goes to the new address, a notice to the old one
+		sentTo := map[string]*mail.Message{}
+		for i := 0; i < 2; i++ {
+			msg := f.mailer.next(t)
+			sentTo[msg.To] = msg
+		}
+		if sentTo["test@example.com"] == nil || sentTo["new@example.com"] == nil {
+			t.Fatalf("Expected mail to both addresses, got %v", sentTo)
+		}
+		token := mailedToken(t, sentTo["new@example.com"], verificationLinkPattern)
+		if err := f.accountService.VerifyEmail(context.Background(), token); err != nil {
+			t.Errorf("Expected the new address to verify, got: %v", err)
+		}
+	})
+
+	t.Run("change email to a taken or the same address", func(t *testing.T) {
+		f := setup(t)
+		f.userRepo.Create(context.Background(), &models.User{ID: uuid.New(), Username: "other", Email: "taken@example.com"})
+
+		_, err := f.accountService.ChangeEmail(context.Background(), f.user.ID, "taken@example.com", "OldPassw0rd!")
+		expectAppError(t, err, errors.ErrCodeConflict)
+
+		_, err = f.accountService.ChangeEmail(context.Background(), f.user.ID, "Test@Example.com", "OldPassw0rd!")
+		expectAppError(t, err, errors.ErrCodeInvalidInput)
+	})
+
+	t.Run("delete and restore", func(t *testing.T) {
+		f := setup(t)
+
+		deleteAt, err := f.accountService.DeleteAccount(context.Background(), f.user.ID, "OldPassw0rd!")
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if grace := time.Until(deleteAt); grace < AccountDeletionGracePeriod-time.Minute || grace > AccountDeletionGracePeriod {
+			t.Errorf("Expected deletion after the grace period, got %v", grace)
+		}
+		if len(f.sessions.revokedUsers) != 1 || len(f.apiKeys.revokedUsers) != 1 {
+			t.Errorf("Expected sessions and API keys to be revoked, got %v and %v", f.sessions.revokedUsers, f.apiKeys.revokedUsers)
+		}
+		f.mailer.next(t)
+
+		_, err = f.accountService.DeleteAccount(context.Background(), f.user.ID, "OldPassw0rd!")
+		expectAppError(t, err, errors.ErrCodeConflict)
+
+		if err := f.accountService.RestoreAccount(context.Background(), f.user.ID); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if f.user.DeletionScheduledAt != nil {
+			t.Error("Expected the deletion to be cancelled")
+		}
+		expectAppError(t, f.accountService.RestoreAccount(context.Background(), f.user.ID), errors.ErrCodeInvalidInput)
+	})
+
+	t.Run("passwordless accounts must set a password first", func(t *testing.T) {
+		f := setup(t)
+		f.user.PasswordHash = ""
+
+		_, err := f.accountService.ChangeEmail(context.Background(), f.user.ID, "new@example.com", "")
+		expectAppError(t, err, errors.ErrCodeBusinessRule)
+		_, err = f.accountService.DeleteAccount(context.Background(), f.user.ID, "")
+		expectAppError(t, err, errors.ErrCodeBusinessRule)
+		err = f.accountService.ChangePassword(context.Background(), f.user.ID, "", "", "NewPassw0rd!")
+		expectAppError(t, err, errors.ErrCodeBusinessRule)
+		if f.user.DeletionScheduledAt != nil || f.user.Email != "test@example.com" {
+			t.Error("Expected the account to be left untouched")
+		}
+	})
+
+	t.Run("band owner cannot delete their account", func(t *testing.T) {
+		f := setup(t)
+		f.userRepo.bandOwners[f.user.ID] = true
+
+		_, err := f.accountService.DeleteAccount(context.Background(), f.user.ID, "OldPassw0rd!")
+		expectAppError(t, err, errors.ErrCodeBusinessRule)
+		if f.user.DeletionScheduledAt != nil || len(f.sessions.revokedUsers) != 0 {
+			t.Error("Expected the account to be left untouched")
+		}
+
+		// Once the band is handed over the account can go
+		delete(f.userRepo.bandOwners, f.user.ID)
+		if _, err := f.accountService.DeleteAccount(context.Background(), f.user.ID, "OldPassw0rd!"); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+	})
+
+	t.Run("purge deletes due accounts and their media", func(t *testing.T) {
+		f := setup(t)
+		pending := &models.User{ID: uuid.New(), Username: "pending", Email: "pending@example.com"}
+		f.userRepo.Create(context.Background(), pending)
+		past, future := time.Now().Add(-time.Minute), time.Now().Add(time.Hour)
+		f.user.DeletionScheduledAt = &past
+		pending.DeletionScheduledAt = &future
+
+		purged, err := f.accountService.PurgeDeletedAccounts(context.Background())
+		if err != nil || purged != 1 {
+			t.Fatalf("Expected one account purged, got %d, %v", purged, err)
+		}
+		if _, exists := f.userRepo.usersByID[f.user.ID.String()]; exists {
+			t.Error("Expected the due account to be deleted")
+		}
+		if _, exists := f.userRepo.usersByID[pending.ID.String()]; !exists {
+			t.Error("Expected the account still in its grace period to be kept")
+		}
+		expected := []string{"audio/" + f.user.ID.String() + "/", "images/" + f.user.ID.String() + "/", "exports/" + f.user.ID.String() + "/"}
+		if fmt.Sprint(f.media.deletedPrefixes) != fmt.Sprint(expected) {
+			t.Errorf("Expected media under %v to be deleted, got %v", expected, f.media.deletedPrefixes)
+		}
+	})
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/logging"
+	"musicapp/internal/middleware"
+	"musicapp/internal/models"
+	"musicapp/internal/pagination"
+
+	"github.com/google/uuid"
+)
+
+// adminListMaxLimit caps the page size of staff listings
+const adminListMaxLimit = 100
+
+var (
+	errNotOutranked     = errors.New(errors.ErrCodeForbidden, "You can only act on accounts with a lower role than yours")
+	errAdminOnly        = errors.New(errors.ErrCodeForbidden, "Only admins can change roles")
+	errOwnRole          = errors.New(errors.ErrCodeBusinessRule, "You cannot change your own role")
+	errInvalidRole      = errors.New(errors.ErrCodeInvalidInput, "Unknown role")
+	errAlreadySuspended = errors.New(errors.ErrCodeConflict, "Account is already suspended")
+	errNotSuspended     = errors.New(errors.ErrCodeConflict, "Account is not suspended")
+	errCommentNotFound  = errors.New(errors.ErrCodeNotFound, "Comment not found")
+)
+
+// AdminUserRepository interface for the user operations staff can perform
+type AdminUserRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	Search(ctx context.Context, filter models.UserSearchFilter, limit int, after *pagination.Cursor) ([]*models.User, error)
+	SetRole(ctx context.Context, userID uuid.UUID, role string) error
+	Suspend(ctx context.Context, userID uuid.UUID, reason string) (bool, error)
+	Unsuspend(ctx context.Context, userID uuid.UUID) (bool, error)
+}
+
+// AdminPostRepository interface for removing posts
+type AdminPostRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Post, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// AdminCommentRepository interface for removing comments
+type AdminCommentRepository interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Comment, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// AuditLogRepository interface for the staff audit log
+type AuditLogRepository interface {
+	Create(ctx context.Context, entry *models.AuditLogEntry) error
+	List(ctx context.Context, filter models.AuditLogFilter, limit int, after *pagination.Cursor) ([]*models.AuditLogEntry, error)
+}
+
+// AdminActor is the member of staff taking an action, as recorded in the
+// audit log
+type AdminActor struct {
+	ID        uuid.UUID
+	Role      string
+	IPAddress string
+}
+
+// AdminService carries out staff actions: finding users, suspending them or
+// signing them out, changing roles and removing content. Every change is
+// recorded in the audit log.
+type AdminService struct {
+	userRepo    AdminUserRepository
+	postRepo    AdminPostRepository
+	commentRepo AdminCommentRepository
+	auditRepo   AuditLogRepository
+	sessions    SessionRevoker
+	logger      *logging.Logger
+}
+
+func NewAdminService(userRepo AdminUserRepository, postRepo AdminPostRepository, commentRepo AdminCommentRepository, auditRepo AuditLogRepository, sessions SessionRevoker, logger *logging.Logger) *AdminService {
+	return &AdminService{
+		userRepo:    userRepo,
+		postRepo:    postRepo,
+		commentRepo: commentRepo,
+		auditRepo:   auditRepo,
+		sessions:    sessions,
+		logger:      logger,
+	}
+}
+
+// SearchUsers finds users matching the filter, newest first
+func (s *AdminService) SearchUsers(ctx context.Context, filter models.UserSearchFilter, limit int, after *pagination.Cursor) ([]*models.User, error) {
+	if filter.Role != "" && !middleware.IsValidRole(filter.Role) {
+		return nil, errInvalidRole
+	}
+	if limit <= 0 || limit > adminListMaxLimit {
+		limit = adminListMaxLimit
+	}
+
+	users, err := s.userRepo.Search(ctx, filter, limit, after)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to search users")
+	}
+	return users, nil
+}
+
+// GetUser gets any user, including their account state
+func (s *AdminService) GetUser(ctx context.Context, userID uuid.UUID) (*models.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, errors.NewUserNotFound(userID.String())
+	}
+	return user, nil
+}
+
+// SuspendUser suspends an account and signs it out everywhere. Until the
+// suspension is lifted it cannot sign in and its API keys stop working.
+func (s *AdminService) SuspendUser(ctx context.Context, actor AdminActor, userID uuid.UUID, reason string) (*models.User, error) {
+	if _, err := s.outrankedUser(ctx, actor, userID); err != nil {
+		return nil, err
+	}
+
+	suspended, err := s.userRepo.Suspend(ctx, userID, reason)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to suspend account")
+	}
+	if !suspended {
+		return nil, errAlreadySuspended
+	}
+	s.audit(ctx, actor, models.AuditActionUserSuspended, models.AuditTargetUser, userID, map[string]interface{}{
+		"reason": reason,
+	})
+
+	if err := s.sessions.RevokeAllSessions(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.GetUser(ctx, userID)
+}
+
+// UnsuspendUser lifts a suspension
+func (s *AdminService) UnsuspendUser(ctx context.Context, actor AdminActor, userID uuid.UUID) (*models.User, error) {
+	user, err := s.outrankedUser(ctx, actor, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	details := map[string]interface{}{}
+	if user.SuspendedAt != nil {
+		details["suspended_at"] = *user.SuspendedAt
+	}
+	if user.SuspensionReason != nil {
+		details["reason"] = *user.SuspensionReason
+	}
+
+	unsuspended, err := s.userRepo.Unsuspend(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to lift suspension")
+	}
+	if !unsuspended {
+		return nil, errNotSuspended
+	}
+	s.audit(ctx, actor, models.AuditActionUserUnsuspended, models.AuditTargetUser, userID, details)
+	return s.GetUser(ctx, userID)
+}
+
+// ForceLogout signs a user out of every device
+func (s *AdminService) ForceLogout(ctx context.Context, actor AdminActor, userID uuid.UUID) error {
+	if _, err := s.outrankedUser(ctx, actor, userID); err != nil {
+		return err
+	}
+
+	if err := s.sessions.RevokeAllSessions(ctx, userID); err != nil {
+		return err
+	}
+
+	s.audit(ctx, actor, models.AuditActionUserLoggedOut, models.AuditTargetUser, userID, nil)
+	return nil
+}
+
+// SetRole changes a user's platform role. Only admins can change roles,
+// and not their own, so there is always an admin left to undo a mistake.
+// Demoted users are signed out so their tokens stop carrying the old role.
+func (s *AdminService) SetRole(ctx context.Context, actor AdminActor, userID uuid.UUID, role string) (*models.User, error) {
+	if !middleware.RoleAtLeast(actor.Role, middleware.RoleAdmin) {
+		return nil, errAdminOnly
+	}
+	if !middleware.IsValidRole(role) {
+		return nil, errInvalidRole
+	}
+	if actor.ID == userID {
+		return nil, errOwnRole
+	}
+
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	previous := user.Role
+	if previous == role {
+		return user, nil
+	}
+
+	if err := s.userRepo.SetRole(ctx, userID, role); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to change role")
+	}
+	s.audit(ctx, actor, models.AuditActionUserRoleChanged, models.AuditTargetUser, userID, map[string]interface{}{
+		"from": previous,
+		"to":   role,
+	})
+
+	// Access tokens pick up a new role when they are refreshed; cut a
+	// demoted user's current ones off now rather than when they expire
+	if !middleware.RoleAtLeast(role, previous) {
+		if err := s.sessions.RevokeAllSessions(ctx, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	user.Role = role
+	return user, nil
+}
+
+// DeletePost removes any post. The audit entry keeps its author and content.
+func (s *AdminService) DeletePost(ctx context.Context, actor AdminActor, postID uuid.UUID, reason string) error {
+	post, err := s.postRepo.GetByID(ctx, postID)
+	if err != nil || post == nil {
+		return errors.NewPostNotFound(postID.String())
+	}
+
+	if err := s.postRepo.Delete(ctx, postID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to delete post")
+	}
+
+	s.audit(ctx, actor, models.AuditActionPostDeleted, models.AuditTargetPost, postID, map[string]interface{}{
+		"reason":     reason,
+		"user_id":    post.UserID,
+		"band_id":    post.BandID,
+		"content":    post.Content,
+		"media_urls": post.MediaURLs,
+	})
+	return nil
+}
+
+// DeleteComment removes any comment along with its replies. The audit
+// entry keeps its author and content.
+func (s *AdminService) DeleteComment(ctx context.Context, actor AdminActor, commentID uuid.UUID, reason string) error {
+	comment, err := s.commentRepo.GetByID(ctx, commentID)
+	if err != nil || comment == nil {
+		return errCommentNotFound
+	}
+
+	if err := s.commentRepo.Delete(ctx, commentID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to delete comment")
+	}
+
+	s.audit(ctx, actor, models.AuditActionCommentDeleted, models.AuditTargetComment, commentID, map[string]interface{}{
+		"reason":  reason,
+		"post_id": comment.PostID,
+		"user_id": comment.UserID,
+		"content": comment.Content,
+	})
+	return nil
+}
+
+// ListAuditLog lists audit entries matching the filter, newest first
+func (s *AdminService) ListAuditLog(ctx context.Context, filter models.AuditLogFilter, limit int, after *pagination.Cursor) ([]*models.AuditLogEntry, error) {
+	if limit <= 0 || limit > adminListMaxLimit {
+		limit = adminListMaxLimit
+	}
+
+	entries, err := s.auditRepo.List(ctx, filter, limit, after)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to list audit log")
+	}
+	return entries, nil
+}
+
+// outrankedUser loads a user the actor may act on: someone else, with a
+// lower role than the actor's
+func (s *AdminService) outrankedUser(ctx context.Context, actor AdminActor, userID uuid.UUID) (*models.User, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.ID == actor.ID || middleware.RoleAtLeast(user.Role, actor.Role) {
+		return nil, errNotOutranked
+	}
+	return user, nil
+}
+
+// audit records a staff action. The action has already happened by then,
+// so a failure to record it is logged in full rather than returned.
+func (s *AdminService) audit(ctx context.Context, actor AdminActor, action, targetType string, targetID uuid.UUID, details map[string]interface{}) {
+	if details == nil {
+		details = map[string]interface{}{}
+	}
+	actorID := actor.ID
+	fields := map[string]interface{}{
+		"actor_id":    actorID.String(),
+		"action":      action,
+		"target_type": targetType,
+		"target_id":   targetID.String(),
+		"details":     details,
+	}
+
+	data, err := json.Marshal(details)
+	if err == nil {
+		entry := &models.AuditLogEntry{
+			ID:         uuid.New(),
+			ActorID:    &actorID,
+			Action:     action,
+			TargetType: targetType,
+			TargetID:   targetID,
+			Details:    data,
+			IPAddress:  optionalString(actor.IPAddress),
+		}
+		err = s.auditRepo.Create(ctx, entry)
+	}
+	if err != nil {
+		s.logger.WithError(fmt.Errorf("failed to write audit log: %w", err)).WithFields(fields).Error("Staff action not recorded in the audit log")
+		return
+	}
+
+	actorIDStr := actorID.String()
+	s.logger.LogSecurityEvent("admin_action", &actorIDStr, fields)
+}
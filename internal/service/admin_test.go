@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/middleware"
+	"musicapp/internal/models"
+	"musicapp/internal/pagination"
+
+	"github.com/google/uuid"
+)
+
+// Mock implementations for AdminService testing
+
+type MockUserRepositoryForAdmin struct {
+	*MockUserRepository
+}
+
+func NewMockUserRepositoryForAdmin() *MockUserRepositoryForAdmin {
+	return &MockUserRepositoryForAdmin{MockUserRepository: NewMockUserRepository()}
+}
+
+func (m *MockUserRepositoryForAdmin) Search(ctx context.Context, filter models.UserSearchFilter, limit int, after *pagination.Cursor) ([]*models.User, error) {
+	var users []*models.User
+	for _, user := range m.usersByID {
+		if filter.Role == "" || user.Role == filter.Role {
+			users = append(users, user)
+		}
+	}
+	return users, nil
+}
+
+func (m *MockUserRepositoryForAdmin) SetRole(ctx context.Context, userID uuid.UUID, role string) error {
+	if user, exists := m.usersByID[userID.String()]; exists {
+		user.Role = role
+	}
+	return nil
+}
+
+func (m *MockUserRepositoryForAdmin) Suspend(ctx context.Context, userID uuid.UUID, reason string) (bool, error) {
+	user, exists := m.usersByID[userID.String()]
+	if !exists || user.SuspendedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	user.SuspendedAt = &now
+	user.SuspensionReason = &reason
+	return true, nil
+}
+
+func (m *MockUserRepositoryForAdmin) Unsuspend(ctx context.Context, userID uuid.UUID) (bool, error) {
+	user, exists := m.usersByID[userID.String()]
+	if !exists || user.SuspendedAt == nil {
+		return false, nil
+	}
+	user.SuspendedAt = nil
+	user.SuspensionReason = nil
+	return true, nil
+}
+
+type MockAuditLogRepository struct {
+	entries []*models.AuditLogEntry
+}
+
+func (m *MockAuditLogRepository) Create(ctx context.Context, entry *models.AuditLogEntry) error {
+	entry.CreatedAt = time.Now()
+	m.entries = append(m.entries, entry)
+	return nil
+}
+
+func (m *MockAuditLogRepository) List(ctx context.Context, filter models.AuditLogFilter, limit int, after *pagination.Cursor) ([]*models.AuditLogEntry, error) {
+	var entries []*models.AuditLogEntry
+	for _, entry := range m.entries {
+		if filter.Action == "" || entry.Action == filter.Action {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+func (m *MockAuditLogRepository) details(t *testing.T, i int) map[string]interface{} {
+	t.Helper()
+	var details map[string]interface{}
+	if err := json.Unmarshal(m.entries[i].Details, &details); err != nil {
+		t.Fatalf("Invalid audit details: %v", err)
+	}
+	return details
+}
+
+func TestAdminService(t *testing.T) {
+	ctx := context.Background()
+
+	type fixture struct {
+		service     *AdminService
+		userRepo    *MockUserRepositoryForAdmin
+		postRepo    *MockPostRepository
+		commentRepo *MockCommentRepository
+		auditRepo   *MockAuditLogRepository
+		sessions    *MockSessionRevoker
+		admin       AdminActor
+		moderator   AdminActor
+	}
+
+	addUser := func(f *fixture, username, role string) *models.User {
+		user := &models.User{ID: uuid.New(), Username: username, Email: username + "@example.com", Role: role}
+		f.userRepo.Create(ctx, user)
+		return user
+	}
+
+	setup := func() *fixture {
+		f := &fixture{
+			userRepo:    NewMockUserRepositoryForAdmin(),
+			postRepo:    NewMockPostRepository(),
+			commentRepo: NewMockCommentRepository(),
+			auditRepo:   &MockAuditLogRepository{},
+			sessions:    &MockSessionRevoker{},
+		}
+		f.service = NewAdminService(f.userRepo, f.postRepo, f.commentRepo, f.auditRepo, f.sessions, createTestLogger())
+		admin := addUser(f, "admin", middleware.RoleAdmin)
+		moderator := addUser(f, "moderator", middleware.RoleModerator)
+		f.admin = AdminActor{ID: admin.ID, Role: admin.Role, IPAddress: "203.0.113.7"}
+		f.moderator = AdminActor{ID: moderator.ID, Role: moderator.Role}
+		return f
+	}
+
+	t.Run("suspend and unsuspend", func(t *testing.T) {
+		f := setup()
+		user := addUser(f, "spammer", middleware.RoleUser)
+
+		suspended, err := f.service.SuspendUser(ctx, f.moderator, user.ID, "Spam")
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if suspended.SuspendedAt == nil || *suspended.SuspensionReason != "Spam" {
+			t.Errorf("Expected the account to be suspended, got %+v", suspended)
+		}
+		if len(f.sessions.revokedUsers) != 1 || f.sessions.revokedUsers[0] != user.ID {
+			t.Errorf("Expected the user's sessions to be revoked, got %v", f.sessions.revokedUsers)
+		}
+		if len(f.auditRepo.entries) != 1 {
+			t.Fatalf("Expected one audit entry, got %d", len(f.auditRepo.entries))
+		}
+		entry := f.auditRepo.entries[0]
+		if entry.Action != models.AuditActionUserSuspended || *entry.ActorID != f.moderator.ID || entry.TargetID != user.ID {
+			t.Errorf("Unexpected audit entry: %+v", entry)
+		}
+		if f.auditRepo.details(t, 0)["reason"] != "Spam" {
+			t.Error("Expected the reason to be audited")
+		}
+
+		_, err = f.service.SuspendUser(ctx, f.moderator, user.ID, "Spam")
+		expectAppError(t, err, errors.ErrCodeConflict)
+
+		if _, err := f.service.UnsuspendUser(ctx, f.moderator, user.ID); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if user.SuspendedAt != nil {
+			t.Error("Expected the suspension to be lifted")
+		}
+		if len(f.auditRepo.entries) != 2 || f.auditRepo.details(t, 1)["reason"] != "Spam" {
+			t.Error("Expected the lifted suspension to be audited with its reason")
+		}
+
+		_, err = f.service.UnsuspendUser(ctx, f.moderator, user.ID)
+		expectAppError(t, err, errors.ErrCodeConflict)
+	})
+
+	t.Run("staff can only act on lower roles", func(t *testing.T) {
+		f := setup()
+		otherModerator := addUser(f, "othermod", middleware.RoleModerator)
+
+		for _, target := range []uuid.UUID{otherModerator.ID, f.admin.ID, f.moderator.ID} {
+			_, err := f.service.SuspendUser(ctx, f.moderator, target, "No")
+			expectAppError(t, err, errors.ErrCodeForbidden)
+		}
+		expectAppError(t, f.service.ForceLogout(ctx, f.moderator, f.admin.ID), errors.ErrCodeForbidden)
+
+		if _, err := f.service.SuspendUser(ctx, f.admin, otherModerator.ID, "Abuse of tools"); err != nil {
+			t.Errorf("Expected an admin to suspend a moderator, got: %v", err)
+		}
+
+		_, err := f.service.SuspendUser(ctx, f.admin, uuid.New(), "Gone")
+		expectAppError(t, err, errors.ErrCodeNotFound)
+	})
+
+	t.Run("force logout", func(t *testing.T) {
+		f := setup()
+		user := addUser(f, "listener", middleware.RoleUser)
+
+		if err := f.service.ForceLogout(ctx, f.moderator, user.ID); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if len(f.sessions.revokedUsers) != 1 || f.sessions.revokedUsers[0] != user.ID {
+			t.Errorf("Expected the user's sessions to be revoked, got %v", f.sessions.revokedUsers)
+		}
+		if len(f.auditRepo.entries) != 1 || f.auditRepo.entries[0].Action != models.AuditActionUserLoggedOut {
+			t.Error("Expected the logout to be audited")
+		}
+	})
+
+	t.Run("set role", func(t *testing.T) {
+		f := setup()
+		user := addUser(f, "helper", middleware.RoleUser)
+
+		_, err := f.service.SetRole(ctx, f.moderator, user.ID, middleware.RoleModerator)
+		expectAppError(t, err, errors.ErrCodeForbidden)
+		_, err = f.service.SetRole(ctx, f.admin, f.admin.ID, middleware.RoleUser)
+		expectAppError(t, err, errors.ErrCodeBusinessRule)
+		_, err = f.service.SetRole(ctx, f.admin, user.ID, "owner")
+		expectAppError(t, err, errors.ErrCodeInvalidInput)
+
+		promoted, err := f.service.SetRole(ctx, f.admin, user.ID, middleware.RoleModerator)
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if promoted.Role != middleware.RoleModerator {
+			t.Errorf("Expected moderator, got %q", promoted.Role)
+		}
+		if len(f.sessions.revokedUsers) != 0 {
+			t.Error("Expected a promotion to keep the user's sessions")
+		}
+		details := f.auditRepo.details(t, 0)
+		if details["from"] != middleware.RoleUser || details["to"] != middleware.RoleModerator {
+			t.Errorf("Unexpected audit details: %v", details)
+		}
+
+		if _, err := f.service.SetRole(ctx, f.admin, user.ID, middleware.RoleUser); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if len(f.sessions.revokedUsers) != 1 {
+			t.Error("Expected a demotion to revoke the user's sessions")
+		}
+
+		if _, err := f.service.SetRole(ctx, f.admin, user.ID, middleware.RoleUser); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if len(f.auditRepo.entries) != 2 {
+			t.Error("Expected an unchanged role not to be audited")
+		}
+	})
+
+	t.Run("delete content", func(t *testing.T) {
+		f := setup()
+		author := uuid.New()
+		post := &models.Post{ID: uuid.New(), UserID: &author, Content: "Buy followers"}
+		f.postRepo.postsByID[post.ID.String()] = post
+		comment := &models.Comment{ID: uuid.New(), PostID: uuid.New(), UserID: author, Content: "Click here"}
+		f.commentRepo.commentsByID[comment.ID.String()] = comment
+
+		if err := f.service.DeletePost(ctx, f.moderator, post.ID, "Spam"); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if _, exists := f.postRepo.postsByID[post.ID.String()]; exists {
+			t.Error("Expected the post to be deleted")
+		}
+		if details := f.auditRepo.details(t, 0); details["content"] != "Buy followers" || details["user_id"] != author.String() {
+			t.Errorf("Expected the post to be kept in the audit log, got %v", details)
+		}
+
+		if err := f.service.DeleteComment(ctx, f.moderator, comment.ID, "Spam"); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if len(f.commentRepo.deletedIDs) != 1 || f.commentRepo.deletedIDs[0] != comment.ID {
+			t.Error("Expected the comment to be deleted")
+		}
+		if entry := f.auditRepo.entries[1]; entry.Action != models.AuditActionCommentDeleted || entry.TargetType != models.AuditTargetComment {
+			t.Errorf("Unexpected audit entry: %+v", entry)
+		}
+
+		expectAppError(t, f.service.DeletePost(ctx, f.moderator, uuid.New(), ""), errors.ErrCodeNotFound)
+		expectAppError(t, f.service.DeleteComment(ctx, f.moderator, uuid.New(), ""), errors.ErrCodeNotFound)
+	})
+
+	t.Run("search validates role", func(t *testing.T) {
+		f := setup()
+
+		users, err := f.service.SearchUsers(ctx, models.UserSearchFilter{Role: middleware.RoleAdmin}, 0, nil)
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if len(users) != 1 || users[0].ID != f.admin.ID {
+			t.Errorf("Expected only the admin, got %d users", len(users))
+		}
+
+		_, err = f.service.SearchUsers(ctx, models.UserSearchFilter{Role: "owner"}, 0, nil)
+		expectAppError(t, err, errors.ErrCodeInvalidInput)
+	})
+}
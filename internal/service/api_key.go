@@ -0,0 +1,197 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/logging"
+	"musicapp/internal/middleware"
+	"musicapp/internal/models"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	// defaultAPIKeyLifetime applies when a key is created without an expiry
+	defaultAPIKeyLifetime = 90 * 24 * time.Hour
+
+	// maxAPIKeysPerUser bounds the unexpired keys a user can hold at once
+	maxAPIKeysPerUser = 20
+
+	// apiKeyPrefixLength is how much of a key is kept in the clear to tell
+	// keys apart: the mapp_ prefix and a few random characters
+	apiKeyPrefixLength = len(middleware.APIKeyPrefix) + 6
+)
+
+var (
+	errAPIKeyNotFound  = errors.New(errors.ErrCodeNotFound, "API key not found")
+	errTooManyAPIKeys  = errors.New(errors.ErrCodeBusinessRule, fmt.Sprintf("You can have at most %d active API keys; revoke one first", maxAPIKeysPerUser))
+	errDuplicateScopes = errors.New(errors.ErrCodeInvalidInput, "Scopes must not repeat")
+)
+
+// APIKeyRepository interface for personal API key storage
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *models.APIKey) error
+	GetByHash(ctx context.Context, tokenHash string) (*models.APIKey, error)
+	GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error)
+	CountActiveByUserID(ctx context.Context, userID uuid.UUID) (int, error)
+	TouchLastUsed(ctx context.Context, id uuid.UUID) error
+	Revoke(ctx context.Context, id, userID uuid.UUID) (bool, error)
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+// UserRepositoryForAPIKeys interface for user operations
+type UserRepositoryForAPIKeys interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+}
+
+// APIKeyService manages personal API keys and verifies them for
+// AuthMiddleware
+type APIKeyService struct {
+	apiKeyRepo APIKeyRepository
+	userRepo   UserRepositoryForAPIKeys
+	logger     *logging.Logger
+}
+
+func NewAPIKeyService(apiKeyRepo APIKeyRepository, userRepo UserRepositoryForAPIKeys, logger *logging.Logger) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo: apiKeyRepo,
+		userRepo:   userRepo,
+		logger:     logger,
+	}
+}
+
+// Create issues a new API key. The returned token is only available now;
+// just its hash is stored.
+func (s *APIKeyService) Create(ctx context.Context, userID uuid.UUID, req *models.CreateAPIKeyRequest) (*models.APIKey, string, error) {
+	seen := make(map[string]bool, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		if !middleware.IsValidScope(scope) {
+			return nil, "", errors.New(errors.ErrCodeInvalidInput, fmt.Sprintf("Unknown scope %q", scope))
+		}
+		if seen[scope] {
+			return nil, "", errDuplicateScopes
+		}
+		seen[scope] = true
+	}
+
+	count, err := s.apiKeyRepo.CountActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, "", errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to count API keys")
+	}
+	if count >= maxAPIKeysPerUser {
+		return nil, "", errTooManyAPIKeys
+	}
+
+	secret, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, "", errors.Wrap(err, errors.ErrCodeInternalError, "Failed to generate API key")
+	}
+	token := middleware.APIKeyPrefix + secret
+
+	lifetime := defaultAPIKeyLifetime
+	if req.ExpiresInDays != nil {
+		lifetime = time.Duration(*req.ExpiresInDays) * 24 * time.Hour
+	}
+
+	key := &models.APIKey{
+		ID:          uuid.New(),
+		UserID:      userID,
+		Name:        req.Name,
+		TokenPrefix: token[:apiKeyPrefixLength],
+		TokenHash:   utils.HashToken(token),
+		Scopes:      req.Scopes,
+		ExpiresAt:   time.Now().UTC().Add(lifetime),
+	}
+	if err := s.apiKeyRepo.Create(ctx, key); err != nil {
+		return nil, "", errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to create API key")
+	}
+
+	userIDStr := userID.String()
+	s.logger.LogSecurityEvent("api_key_created", &userIDStr, map[string]interface{}{
+		"api_key_id": key.ID.String(),
+		"scopes":     key.Scopes,
+	})
+	return key, token, nil
+}
+
+// List lists the user's unrevoked API keys, newest first
+func (s *APIKeyService) List(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	keys, err := s.apiKeyRepo.GetActiveByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to list API keys")
+	}
+	return keys, nil
+}
+
+// Revoke stops one of the user's API keys from working
+func (s *APIKeyService) Revoke(ctx context.Context, userID, keyID uuid.UUID) error {
+	revoked, err := s.apiKeyRepo.Revoke(ctx, keyID, userID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to revoke API key")
+	}
+	if !revoked {
+		return errAPIKeyNotFound
+	}
+
+	userIDStr := userID.String()
+	s.logger.LogSecurityEvent("api_key_revoked", &userIDStr, map[string]interface{}{"api_key_id": keyID.String()})
+	return nil
+}
+
+// RevokeAll stops all of the user's API keys from working
+func (s *APIKeyService) RevokeAll(ctx context.Context, userID uuid.UUID) error {
+	revoked, err := s.apiKeyRepo.RevokeAllForUser(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to revoke API keys")
+	}
+
+	if revoked > 0 {
+		userIDStr := userID.String()
+		s.logger.LogSecurityEvent("api_keys_revoked", &userIDStr, map[string]interface{}{"count": revoked})
+	}
+	return nil
+}
+
+// VerifyAPIKey implements middleware.APIKeyVerifier. Unknown, expired and
+// revoked keys yield nil without an error; failed lookups return the error
+// so the request fails with a server error rather than as unauthorized.
+func (s *APIKeyService) VerifyAPIKey(ctx context.Context, token string) (*middleware.APIKey, error) {
+	key, err := s.apiKeyRepo.GetByHash(ctx, utils.HashToken(token))
+	if err != nil && !stderrors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+	if key == nil {
+		return nil, nil
+	}
+	if key.RevokedAt != nil || !time.Now().Before(key.ExpiresAt) {
+		return nil, nil
+	}
+
+	// Keys of suspended accounts stop working, and work again if the
+	// suspension is lifted
+	user, err := s.userRepo.GetByID(ctx, key.UserID)
+	if err != nil && !stderrors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to load API key owner: %w", err)
+	}
+	if user == nil || user.SuspendedAt != nil {
+		return nil, nil
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(ctx, key.ID); err != nil {
+		// Last use is informational; don't fail the request over it
+		s.logger.WithError(err).Warn("Failed to record API key use")
+	}
+
+	return &middleware.APIKey{
+		ID:       key.ID.String(),
+		UserID:   key.UserID.String(),
+		Username: user.Username,
+		Scopes:   key.Scopes,
+	}, nil
+}
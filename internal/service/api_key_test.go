@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/middleware"
+	"musicapp/internal/models"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Mock implementations for APIKeyService testing
+
+type MockAPIKeyRepository struct {
+	keys           map[uuid.UUID]*models.APIKey
+	getByHashError error
+}
+
+func NewMockAPIKeyRepository() *MockAPIKeyRepository {
+	return &MockAPIKeyRepository{keys: make(map[uuid.UUID]*models.APIKey)}
+}
+
+func (m *MockAPIKeyRepository) Create(ctx context.Context, key *models.APIKey) error {
+	key.CreatedAt = time.Now()
+	m.keys[key.ID] = key
+	return nil
+}
+
+func (m *MockAPIKeyRepository) GetByHash(ctx context.Context, tokenHash string) (*models.APIKey, error) {
+	if m.getByHashError != nil {
+		return nil, m.getByHashError
+	}
+	for _, key := range m.keys {
+		if key.TokenHash == tokenHash {
+			return key, nil
+		}
+	}
+	return nil, pgx.ErrNoRows
+}
+
+func (m *MockAPIKeyRepository) GetActiveByUserID(ctx context.Context, userID uuid.UUID) ([]*models.APIKey, error) {
+	var keys []*models.APIKey
+	for _, key := range m.keys {
+		if key.UserID == userID && key.RevokedAt == nil {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+func (m *MockAPIKeyRepository) CountActiveByUserID(ctx context.Context, userID uuid.UUID) (int, error) {
+	count := 0
+	for _, key := range m.keys {
+		if key.UserID == userID && key.RevokedAt == nil && key.ExpiresAt.After(time.Now()) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockAPIKeyRepository) TouchLastUsed(ctx context.Context, id uuid.UUID) error {
+	if key, exists := m.keys[id]; exists {
+		now := time.Now()
+		key.LastUsedAt = &now
+	}
+	return nil
+}
+
+func (m *MockAPIKeyRepository) Revoke(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	key, exists := m.keys[id]
+	if !exists || key.UserID != userID || key.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	return true, nil
+}
+
+func (m *MockAPIKeyRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) (int, error) {
+	revoked := 0
+	for _, key := range m.keys {
+		if key.UserID == userID && key.RevokedAt == nil {
+			now := time.Now()
+			key.RevokedAt = &now
+			revoked++
+		}
+	}
+	return revoked, nil
+}
+
+func TestAPIKeyService(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func() (*APIKeyService, *MockAPIKeyRepository, *models.User) {
+		userRepo := NewMockUserRepository()
+		user := &models.User{ID: uuid.New(), Username: "releasebot", Email: "bot@example.com"}
+		userRepo.Create(ctx, user)
+		apiKeyRepo := NewMockAPIKeyRepository()
+		return NewAPIKeyService(apiKeyRepo, userRepo, createTestLogger()), apiKeyRepo, user
+	}
+
+	t.Run("create and verify", func(t *testing.T) {
+		apiKeyService, apiKeyRepo, user := setup()
+
+		key, token, err := apiKeyService.Create(ctx, user.ID, &models.CreateAPIKeyRequest{
+			Name:   "Release announcements",
+			Scopes: []string{middleware.ScopePostsWrite},
+		})
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if !strings.HasPrefix(token, middleware.APIKeyPrefix) || !strings.HasPrefix(token, key.TokenPrefix) {
+			t.Errorf("Unexpected token %q with prefix %q", token, key.TokenPrefix)
+		}
+		if key.TokenHash != utils.HashToken(token) || strings.Contains(key.TokenHash, token) {
+			t.Error("Expected only the token hash to be stored")
+		}
+		if lifetime := time.Until(key.ExpiresAt); lifetime < 89*24*time.Hour || lifetime > 90*24*time.Hour {
+			t.Errorf("Expected the default 90 day lifetime, got %v", lifetime)
+		}
+
+		verified, err := apiKeyService.VerifyAPIKey(ctx, token)
+		if err != nil || verified == nil {
+			t.Fatalf("Expected key to verify, got %v, %v", verified, err)
+		}
+		if verified.UserID != user.ID.String() || verified.Username != "releasebot" || !verified.HasScope(middleware.ScopePostsWrite) {
+			t.Errorf("Unexpected verified key: %+v", verified)
+		}
+		if apiKeyRepo.keys[key.ID].LastUsedAt == nil {
+			t.Error("Expected last use to be recorded")
+		}
+	})
+
+	t.Run("invalid scopes", func(t *testing.T) {
+		apiKeyService, _, user := setup()
+
+		for _, scopes := range [][]string{{"admin"}, {middleware.ScopePostsRead, middleware.ScopePostsRead}} {
+			_, _, err := apiKeyService.Create(ctx, user.ID, &models.CreateAPIKeyRequest{Name: "script", Scopes: scopes})
+			if appErr := errors.GetAppError(err); appErr == nil || appErr.Code != errors.ErrCodeInvalidInput {
+				t.Errorf("Expected invalid input for scopes %v, got: %v", scopes, err)
+			}
+		}
+	})
+
+	t.Run("expired, revoked and unknown keys", func(t *testing.T) {
+		apiKeyService, apiKeyRepo, user := setup()
+		days := 1
+		key, token, _ := apiKeyService.Create(ctx, user.ID, &models.CreateAPIKeyRequest{
+			Name: "sync", Scopes: []string{middleware.ScopeProfileRead}, ExpiresInDays: &days,
+		})
+
+		apiKeyRepo.keys[key.ID].ExpiresAt = time.Now().Add(-time.Second)
+		if verified, _ := apiKeyService.VerifyAPIKey(ctx, token); verified != nil {
+			t.Error("Expected expired key to be rejected")
+		}
+
+		apiKeyRepo.keys[key.ID].ExpiresAt = time.Now().Add(time.Hour)
+		if err := apiKeyService.Revoke(ctx, user.ID, key.ID); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if verified, _ := apiKeyService.VerifyAPIKey(ctx, token); verified != nil {
+			t.Error("Expected revoked key to be rejected")
+		}
+
+		if verified, err := apiKeyService.VerifyAPIKey(ctx, middleware.APIKeyPrefix+"unknown"); verified != nil || err != nil {
+			t.Errorf("Expected unknown key to be rejected without error, got %v, %v", verified, err)
+		}
+	})
+
+	t.Run("suspended user", func(t *testing.T) {
+		apiKeyService, _, user := setup()
+		_, token, _ := apiKeyService.Create(ctx, user.ID, &models.CreateAPIKeyRequest{Name: "sync", Scopes: []string{middleware.ScopeProfileRead}})
+
+		suspendedAt := time.Now()
+		user.SuspendedAt = &suspendedAt
+		if verified, err := apiKeyService.VerifyAPIKey(ctx, token); verified != nil || err != nil {
+			t.Errorf("Expected a suspended user's key to be rejected without error, got %v, %v", verified, err)
+		}
+	})
+
+	t.Run("lookup failure is an error", func(t *testing.T) {
+		apiKeyService, apiKeyRepo, user := setup()
+		_, token, _ := apiKeyService.Create(ctx, user.ID, &models.CreateAPIKeyRequest{Name: "sync", Scopes: []string{middleware.ScopeProfileRead}})
+
+		apiKeyRepo.getByHashError = fmt.Errorf("connection refused")
+		if verified, err := apiKeyService.VerifyAPIKey(ctx, token); verified != nil || err == nil {
+			t.Errorf("Expected the lookup error to be returned, got %v, %v", verified, err)
+		}
+	})
+
+	t.Run("revoke another user's key", func(t *testing.T) {
+		apiKeyService, _, user := setup()
+		key, _, _ := apiKeyService.Create(ctx, user.ID, &models.CreateAPIKeyRequest{Name: "sync", Scopes: []string{middleware.ScopeProfileRead}})
+
+		err := apiKeyService.Revoke(ctx, uuid.New(), key.ID)
+		if appErr := errors.GetAppError(err); appErr == nil || appErr.Code != errors.ErrCodeNotFound {
+			t.Errorf("Expected not found, got: %v", err)
+		}
+	})
+
+	t.Run("active key limit", func(t *testing.T) {
+		apiKeyService, _, user := setup()
+		req := &models.CreateAPIKeyRequest{Name: "script", Scopes: []string{middleware.ScopePostsRead}}
+		for i := 0; i < maxAPIKeysPerUser; i++ {
+			if _, _, err := apiKeyService.Create(ctx, user.ID, req); err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+		}
+
+		if _, _, err := apiKeyService.Create(ctx, user.ID, req); err != errTooManyAPIKeys {
+			t.Errorf("Expected key limit error, got: %v", err)
+		}
+	})
+}
@@ -8,6 +8,7 @@ import (
 	"musicapp/internal/cache"
 	"musicapp/internal/errors"
 	"musicapp/internal/interfaces"
+	"musicapp/internal/logging"
 	"musicapp/internal/middleware"
 	"musicapp/internal/models"
 	"musicapp/internal/repository"
@@ -19,38 +20,58 @@ import (
 // AuthService now depends on interfaces, not concrete types
 // This makes it much easier to test and more flexible
 type AuthService struct {
-	userRepo       interfaces.UserRepository
-	cache          interfaces.Cache
-	authMiddleware interfaces.AuthMiddleware
+	userRepo         interfaces.UserRepository
+	cache            interfaces.Cache
+	authMiddleware   interfaces.AuthMiddleware
+	refreshTokenRepo interfaces.RefreshTokenRepository
+	sessionRepo      interfaces.SessionRepository
+	mfa              interfaces.MFAVerifier
+	throttle         interfaces.LoginThrottle
+	logger           *logging.Logger
 }
 
+// RefreshTokenTTL is how long a refresh token can be exchanged. Each
+// refresh rotates the token, so an active client is never logged out.
+const RefreshTokenTTL = 30 * 24 * time.Hour
+
+// errRefreshTokenReused is returned when a rotated refresh token is presented again
+var errRefreshTokenReused = errors.New(errors.ErrCodeTokenInvalid, "Refresh token has already been used; please log in again")
+
+// errAccountSuspended is returned when a suspended account tries to sign in
+var errAccountSuspended = errors.New(errors.ErrCodeForbidden, "This account has been suspended")
+
 // NewAuthService creates a new AuthService with dependency injection
 // This follows the dependency injection pattern for better testability
-func NewAuthService(userRepo interfaces.UserRepository, cache interfaces.Cache, authMiddleware interfaces.AuthMiddleware) *AuthService {
+func NewAuthService(userRepo interfaces.UserRepository, cache interfaces.Cache, authMiddleware interfaces.AuthMiddleware, refreshTokenRepo interfaces.RefreshTokenRepository, sessionRepo interfaces.SessionRepository, mfa interfaces.MFAVerifier, throttle interfaces.LoginThrottle, logger *logging.Logger) *AuthService {
 	return &AuthService{
-		userRepo:       userRepo,
-		cache:          cache,
-		authMiddleware: authMiddleware,
+		userRepo:         userRepo,
+		cache:            cache,
+		authMiddleware:   authMiddleware,
+		refreshTokenRepo: refreshTokenRepo,
+		sessionRepo:      sessionRepo,
+		mfa:              mfa,
+		throttle:         throttle,
+		logger:           logger,
 	}
 }
 
-// RegisterUser registers a new user and returns JWT token
-func (s *AuthService) RegisterUser(ctx context.Context, req *models.CreateUserRequest) (*models.User, string, error) {
+// RegisterUser registers a new user and returns an access and refresh token
+func (s *AuthService) RegisterUser(ctx context.Context, req *models.CreateUserRequest, device models.DeviceInfo) (*models.User, *models.TokenPair, error) {
 	// Check if user already exists
 	existingUser, err := s.userRepo.GetByEmail(ctx, req.Email)
 	if err == nil && existingUser != nil {
-		return nil, "", errors.NewUserAlreadyExists("email", req.Email)
+		return nil, nil, errors.NewUserAlreadyExists("email", req.Email)
 	}
 
 	existingUser, err = s.userRepo.GetByUsername(ctx, req.Username)
 	if err == nil && existingUser != nil {
-		return nil, "", errors.NewUserAlreadyExists("username", req.Username)
+		return nil, nil, errors.NewUserAlreadyExists("username", req.Username)
 	}
 
 	// Hash password
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
-		return nil, "", errors.Wrap(err, errors.ErrCodeInternalError, "Failed to hash password")
+		return nil, nil, errors.Wrap(err, errors.ErrCodeInternalError, "Failed to hash password")
 	}
 
 	// Create user
@@ -65,78 +86,321 @@ func (s *AuthService) RegisterUser(ctx context.Context, req *models.CreateUserRe
 	}
 
 	if err := s.userRepo.Create(ctx, user); err != nil {
-		return nil, "", errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to create user")
+		return nil, nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to create user")
 	}
 
-	// Generate JWT token
-	token, err := s.authMiddleware.GenerateToken(user.ID.String(), user.Username)
+	tokens, err := s.startSession(ctx, user, device)
 	if err != nil {
-		return nil, "", errors.Wrap(err, errors.ErrCodeInternalError, "Failed to generate token")
-	}
-
-	// Store session in Redis
-	sessionData := map[string]interface{}{
-		"user_id":  user.ID.String(),
-		"username": user.Username,
-		"email":    user.Email,
-	}
-	if err := s.cache.SetSession(ctx, user.ID.String(), sessionData, 24*time.Hour); err != nil {
-		// Log error but don't fail the request
-		// TODO: Add proper logging
+		return nil, nil, err
 	}
 
-	return user, token, nil
+	return user, tokens, nil
 }
 
-// LoginUser authenticates user and returns JWT token
-func (s *AuthService) LoginUser(ctx context.Context, email, password string) (*models.User, string, error) {
+// LoginUser authenticates user and returns an access and refresh token.
+// Users with two-factor authentication get an MFA challenge instead, to be
+// completed with VerifyMFA. Repeated failures lock the account or IP
+// address out for a while.
+func (s *AuthService) LoginUser(ctx context.Context, email, password string, device models.DeviceInfo) (*models.User, *models.TokenPair, *models.MFAChallenge, error) {
+	if err := s.throttle.Check(ctx, email, device.IPAddress); err != nil {
+		return nil, nil, nil, err
+	}
+
 	// Get user by email
 	user, err := s.userRepo.GetByEmail(ctx, email)
 	if err != nil || user == nil {
-		return nil, "", errors.ErrInvalidCredentials
+		return nil, nil, nil, s.loginFailed(ctx, email, device, nil)
 	}
 
 	// Check password
 	if !utils.CheckPasswordHash(password, user.PasswordHash) {
-		return nil, "", errors.ErrInvalidCredentials
+		return nil, nil, nil, s.loginFailed(ctx, email, device, user)
+	}
+
+	if err := s.throttle.RecordSuccess(ctx, email); err != nil {
+		return nil, nil, nil, err
+	}
+
+	s.upgradePasswordHash(ctx, user, password)
+
+	tokens, challenge, err := s.CompleteLogin(ctx, user, device)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return user, tokens, challenge, nil
+}
+
+// upgradePasswordHash rehashes a verified password whose hash uses an older
+// algorithm, such as bcrypt from before argon2id, or weaker parameters. The
+// plaintext is only available at login, so this is when hashes move on.
+func (s *AuthService) upgradePasswordHash(ctx context.Context, user *models.User, password string) {
+	if !utils.PasswordNeedsRehash(user.PasswordHash) {
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(password)
+	if err != nil {
+		return
+	}
+	// The old hash keeps working, so a failed upgrade is retried at the
+	// next login rather than failing this one
+	if err := s.userRepo.UpdatePassword(ctx, user.ID, hashedPassword); err == nil {
+		user.PasswordHash = hashedPassword
+	}
+}
+
+// CompleteLogin signs in a user whose first factor has been checked, by
+// password or by an identity provider. Users with two-factor
+// authentication get an MFA challenge instead of tokens.
+func (s *AuthService) CompleteLogin(ctx context.Context, user *models.User, device models.DeviceInfo) (*models.TokenPair, *models.MFAChallenge, error) {
+	mfaEnabled, err := s.mfa.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if mfaEnabled {
+		challenge, err := s.mfa.StartChallenge(ctx, user.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return nil, challenge, nil
+	}
+
+	tokens, err := s.startSession(ctx, user, device)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return tokens, nil, nil
+}
+
+// loginFailed records a failed login, returning the lockout error if it
+// triggered one and invalid credentials otherwise
+func (s *AuthService) loginFailed(ctx context.Context, email string, device models.DeviceInfo, user *models.User) error {
+	if err := s.throttle.RecordFailure(ctx, email, device.IPAddress, user); err != nil {
+		return err
+	}
+	return errors.ErrInvalidCredentials
+}
+
+// VerifyMFA completes a two-step login with an authenticator or recovery
+// code and returns an access and refresh token
+func (s *AuthService) VerifyMFA(ctx context.Context, mfaToken, code string, device models.DeviceInfo) (*models.User, *models.TokenPair, error) {
+	userID, err := s.mfa.CompleteChallenge(ctx, mfaToken, code)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, nil, errors.ErrInvalidCredentials
+	}
+
+	tokens, err := s.startSession(ctx, user, device)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return user, tokens, nil
+}
+
+// RefreshTokens exchanges a refresh token for a new access token and a new
+// refresh token. Refresh tokens are single-use: presenting one that was
+// already rotated means it has leaked, so its whole family is revoked.
+func (s *AuthService) RefreshTokens(ctx context.Context, refreshToken string, device models.DeviceInfo) (*models.User, *models.TokenPair, error) {
+	if refreshToken == "" {
+		return nil, nil, errors.ErrTokenInvalid
+	}
+
+	stored, err := s.refreshTokenRepo.GetByHash(ctx, utils.HashToken(refreshToken))
+	if err != nil || stored == nil {
+		return nil, nil, errors.ErrTokenInvalid
+	}
+	if stored.RevokedAt != nil {
+		return nil, nil, errors.ErrTokenInvalid
+	}
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, nil, errors.ErrTokenExpired
+	}
+
+	rotated, err := s.refreshTokenRepo.MarkUsed(ctx, stored.ID)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to rotate refresh token")
+	}
+	if !rotated {
+		if err := s.revokeSession(ctx, stored.FamilyID); err != nil {
+			return nil, nil, err
+		}
+		return nil, nil, errRefreshTokenReused
 	}
 
-	// Generate JWT token
-	token, err := s.authMiddleware.GenerateToken(user.ID.String(), user.Username)
+	user, err := s.userRepo.GetByID(ctx, stored.UserID)
+	if err != nil || user == nil {
+		return nil, nil, errors.NewUserNotFound(stored.UserID.String())
+	}
+	if user.SuspendedAt != nil {
+		return nil, nil, errAccountSuspended
+	}
+
+	tokens, err := s.issueTokens(ctx, user, stored.FamilyID)
 	if err != nil {
-		return nil, "", errors.Wrap(err, errors.ErrCodeInternalError, "Failed to generate token")
+		return nil, nil, err
 	}
 
-	// Store session in Redis
-	sessionData := map[string]interface{}{
-		"user_id":  user.ID.String(),
-		"username": user.Username,
-		"email":    user.Email,
+	if err := s.sessionRepo.Touch(ctx, stored.FamilyID, optionalString(device.IPAddress)); err != nil {
+		// Last-seen is informational; don't fail the refresh over it
+		s.logger.WithError(err).Warn("Failed to record session activity")
 	}
-	if err := s.cache.SetSession(ctx, user.ID.String(), sessionData, 24*time.Hour); err != nil {
-		// Log error but don't fail the request
-		// TODO: Add proper logging
+
+	return user, tokens, nil
+}
+
+// startSession records a new signed-in device and issues its first tokens
+func (s *AuthService) startSession(ctx context.Context, user *models.User, device models.DeviceInfo) (*models.TokenPair, error) {
+	// Every way of signing in ends here, so this is where suspended accounts
+	// are kept out
+	if user.SuspendedAt != nil {
+		return nil, errAccountSuspended
 	}
 
-	return user, token, nil
+	session := &models.Session{
+		ID:         uuid.New(),
+		UserID:     user.ID,
+		DeviceName: optionalString(device.Name),
+		UserAgent:  optionalString(device.UserAgent),
+		IPAddress:  optionalString(device.IPAddress),
+	}
+	if err := s.sessionRepo.Create(ctx, session); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to create session")
+	}
+
+	return s.issueTokens(ctx, user, session.ID)
 }
 
-// LogoutUser invalidates JWT token
-func (s *AuthService) LogoutUser(ctx context.Context, jti, userID string) error {
-	// Add token to blacklist (24 hours from now)
-	if err := s.cache.AddToBlacklist(ctx, jti, 24*time.Hour); err != nil {
+// issueTokens signs an access token and stores a new refresh token in the
+// given family. A family is one login session and survives rotation.
+func (s *AuthService) issueTokens(ctx context.Context, user *models.User, familyID uuid.UUID) (*models.TokenPair, error) {
+	accessToken, err := s.authMiddleware.GenerateToken(user.ID.String(), user.Username, user.Role, familyID.String())
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternalError, "Failed to generate token")
+	}
+
+	refreshToken, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternalError, "Failed to generate refresh token")
+	}
+
+	record := &models.RefreshToken{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(refreshToken),
+		ExpiresAt: time.Now().UTC().Add(RefreshTokenTTL),
+	}
+	if err := s.refreshTokenRepo.Create(ctx, record); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to store refresh token")
+	}
+
+	return &models.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(middleware.AccessTokenTTL.Seconds()),
+	}, nil
+}
+
+// revokeSession ends a session: it revokes the session's refresh token
+// family and blacklists the session, which cuts off access tokens already
+// issued to it
+func (s *AuthService) revokeSession(ctx context.Context, familyID uuid.UUID) error {
+	if err := s.sessionRepo.Revoke(ctx, familyID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to revoke session")
+	}
+	if err := s.refreshTokenRepo.RevokeFamily(ctx, familyID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to revoke session")
+	}
+	if err := s.cache.AddToBlacklist(ctx, middleware.SessionRevocationKey(familyID.String()), middleware.AccessTokenTTL); err != nil {
+		return errors.Wrap(err, errors.ErrCodeRedisError, "Failed to revoke session")
+	}
+	return nil
+}
+
+// LogoutUser invalidates the access token and, when the token belongs to a
+// session, signs that device out
+func (s *AuthService) LogoutUser(ctx context.Context, jti, sessionID string) error {
+	// Add token to blacklist until it would have expired anyway
+	if err := s.cache.AddToBlacklist(ctx, jti, middleware.AccessTokenTTL); err != nil {
 		return errors.Wrap(err, errors.ErrCodeRedisError, "Failed to blacklist token")
 	}
 
-	// Clear session
-	if err := s.cache.DeleteSession(ctx, userID); err != nil {
-		// Log error but don't fail the request
-		// TODO: Add proper logging
+	if familyID, err := uuid.Parse(sessionID); err == nil {
+		if err := s.revokeSession(ctx, familyID); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// ListSessions returns the user's signed-in devices, flagging the one with
+// the given session ID as current
+func (s *AuthService) ListSessions(ctx context.Context, userID uuid.UUID, currentSessionID string) ([]*models.Session, error) {
+	sessions, err := s.sessionRepo.GetActiveByUserID(ctx, userID, time.Now().UTC().Add(-RefreshTokenTTL))
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to list sessions")
+	}
+
+	for _, session := range sessions {
+		session.Current = session.ID.String() == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// RevokeSession signs out one of the user's devices
+func (s *AuthService) RevokeSession(ctx context.Context, userID, sessionID uuid.UUID) error {
+	session, err := s.sessionRepo.GetByID(ctx, sessionID)
+	if err != nil || session == nil || session.UserID != userID || session.RevokedAt != nil {
+		return errors.NewWithDetails(errors.ErrCodeNotFound, "Session not found", fmt.Sprintf("Session with ID %s not found", sessionID))
+	}
+
+	return s.revokeSession(ctx, session.ID)
+}
+
+// RevokeOtherSessions signs out every device except the one making the
+// request and returns how many sessions were revoked
+func (s *AuthService) RevokeOtherSessions(ctx context.Context, userID uuid.UUID, currentSessionID string) (int, error) {
+	sessions, err := s.ListSessions(ctx, userID, currentSessionID)
+	if err != nil {
+		return 0, err
+	}
+
+	revoked := 0
+	for _, session := range sessions {
+		if session.Current {
+			continue
+		}
+		if err := s.revokeSession(ctx, session.ID); err != nil {
+			return revoked, err
+		}
+		revoked++
+	}
+
+	return revoked, nil
+}
+
+// RevokeAllSessions signs the user out of every device
+func (s *AuthService) RevokeAllSessions(ctx context.Context, userID uuid.UUID) error {
+	_, err := s.RevokeOtherSessions(ctx, userID, "")
+	return err
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
 // ValidateToken validates JWT token and returns user info
 func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*models.User, error) {
 	// Parse and validate the token
@@ -145,8 +409,11 @@ func (s *AuthService) ValidateToken(ctx context.Context, tokenString string) (*m
 		return nil, fmt.Errorf("invalid token: %w", err)
 	}
 
-	// Check if token is blacklisted
+	// Check if token or its session is blacklisted
 	isBlacklisted, err := s.cache.IsBlacklisted(ctx, claims.ID)
+	if err == nil && !isBlacklisted && claims.SessionID != "" {
+		isBlacklisted, err = s.cache.IsBlacklisted(ctx, middleware.SessionRevocationKey(claims.SessionID))
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to check token blacklist: %w", err)
 	}
@@ -196,6 +463,10 @@ func (a *UserRepositoryAdapter) Create(ctx context.Context, user *models.User) e
 	return a.repo.Create(ctx, user)
 }
 
+func (a *UserRepositoryAdapter) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	return a.repo.UpdatePassword(ctx, userID, passwordHash)
+}
+
 // CacheAdapter adapts cache.Cache to Cache interface
 type CacheAdapter struct {
 	cache *cache.Cache
@@ -205,14 +476,6 @@ func NewCacheAdapter(cache *cache.Cache) interfaces.Cache {
 	return &CacheAdapter{cache: cache}
 }
 
-func (a *CacheAdapter) SetSession(ctx context.Context, userID string, data interface{}, expiration time.Duration) error {
-	return a.cache.SetSession(ctx, userID, data, expiration)
-}
-
-func (a *CacheAdapter) DeleteSession(ctx context.Context, userID string) error {
-	return a.cache.DeleteSession(ctx, userID)
-}
-
 func (a *CacheAdapter) IsBlacklisted(ctx context.Context, jti string) (bool, error) {
 	return a.cache.IsBlacklisted(ctx, jti)
 }
@@ -230,8 +493,8 @@ func NewAuthMiddlewareAdapter(middleware *middleware.AuthMiddleware) interfaces.
 	return &AuthMiddlewareAdapter{middleware: middleware}
 }
 
-func (a *AuthMiddlewareAdapter) GenerateToken(userID, username string) (string, error) {
-	return a.middleware.GenerateToken(userID, username)
+func (a *AuthMiddlewareAdapter) GenerateToken(userID, username, role, sessionID string) (string, error) {
+	return a.middleware.GenerateToken(userID, username, role, sessionID)
 }
 
 func (a *AuthMiddlewareAdapter) ValidateToken(tokenString string) (*middleware.Claims, error) {
@@ -16,6 +16,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
 )
 
 // Mock implementations for testing the interface-based AuthService
@@ -67,9 +68,14 @@ func (m *MockUserRepository) Create(ctx context.Context, user *models.User) erro
 	return nil
 }
 
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	if user, exists := m.usersByID[userID.String()]; exists {
+		user.PasswordHash = passwordHash
+	}
+	return nil
+}
+
 type MockCache struct {
-	setSessionError    error
-	deleteSessionError error
 	isBlacklistedError error
 	addToBlacklistError error
 	blacklistedTokens  map[string]bool
@@ -81,14 +87,6 @@ func NewMockCache() *MockCache {
 	}
 }
 
-func (m *MockCache) SetSession(ctx context.Context, userID string, data interface{}, expiration time.Duration) error {
-	return m.setSessionError
-}
-
-func (m *MockCache) DeleteSession(ctx context.Context, userID string) error {
-	return m.deleteSessionError
-}
-
 func (m *MockCache) IsBlacklisted(ctx context.Context, jti string) (bool, error) {
 	if m.isBlacklistedError != nil {
 		return false, m.isBlacklistedError
@@ -121,7 +119,7 @@ func NewMockAuthMiddleware() *MockAuthMiddleware {
 	}
 }
 
-func (m *MockAuthMiddleware) GenerateToken(userID, username string) (string, error) {
+func (m *MockAuthMiddleware) GenerateToken(userID, username, role, sessionID string) (string, error) {
 	if m.generateTokenError != nil {
 		return "", m.generateTokenError
 	}
@@ -135,6 +133,153 @@ func (m *MockAuthMiddleware) ValidateToken(tokenString string) (*middleware.Clai
 	return m.claims, nil
 }
 
+type MockRefreshTokenRepository struct {
+	tokens          map[string]*models.RefreshToken
+	revokedFamilies map[uuid.UUID]bool
+	createError     error
+}
+
+func NewMockRefreshTokenRepository() *MockRefreshTokenRepository {
+	return &MockRefreshTokenRepository{
+		tokens:          make(map[string]*models.RefreshToken),
+		revokedFamilies: make(map[uuid.UUID]bool),
+	}
+}
+
+func (m *MockRefreshTokenRepository) Create(ctx context.Context, token *models.RefreshToken) error {
+	if m.createError != nil {
+		return m.createError
+	}
+	m.tokens[token.TokenHash] = token
+	return nil
+}
+
+func (m *MockRefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	if token, exists := m.tokens[tokenHash]; exists {
+		return token, nil
+	}
+	return nil, fmt.Errorf("no rows in result set")
+}
+
+func (m *MockRefreshTokenRepository) MarkUsed(ctx context.Context, id uuid.UUID) (bool, error) {
+	for _, token := range m.tokens {
+		if token.ID == id {
+			if token.UsedAt != nil || token.RevokedAt != nil {
+				return false, nil
+			}
+			now := time.Now()
+			token.UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockRefreshTokenRepository) RevokeFamily(ctx context.Context, familyID uuid.UUID) error {
+	now := time.Now()
+	for _, token := range m.tokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+	m.revokedFamilies[familyID] = true
+	return nil
+}
+
+type MockSessionRepository struct {
+	sessions    map[uuid.UUID]*models.Session
+	createError error
+}
+
+func NewMockSessionRepository() *MockSessionRepository {
+	return &MockSessionRepository{
+		sessions: make(map[uuid.UUID]*models.Session),
+	}
+}
+
+func (m *MockSessionRepository) Create(ctx context.Context, session *models.Session) error {
+	if m.createError != nil {
+		return m.createError
+	}
+	session.CreatedAt = time.Now()
+	session.LastSeenAt = session.CreatedAt
+	m.sessions[session.ID] = session
+	return nil
+}
+
+func (m *MockSessionRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Session, error) {
+	if session, exists := m.sessions[id]; exists {
+		return session, nil
+	}
+	return nil, fmt.Errorf("no rows in result set")
+}
+
+func (m *MockSessionRepository) GetActiveByUserID(ctx context.Context, userID uuid.UUID, seenSince time.Time) ([]*models.Session, error) {
+	var sessions []*models.Session
+	for _, session := range m.sessions {
+		if session.UserID == userID && session.RevokedAt == nil && session.LastSeenAt.After(seenSince) {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (m *MockSessionRepository) Touch(ctx context.Context, id uuid.UUID, ipAddress *string) error {
+	if session, exists := m.sessions[id]; exists {
+		session.LastSeenAt = time.Now()
+		if ipAddress != nil {
+			session.IPAddress = ipAddress
+		}
+	}
+	return nil
+}
+
+func (m *MockSessionRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	if session, exists := m.sessions[id]; exists && session.RevokedAt == nil {
+		now := time.Now()
+		session.RevokedAt = &now
+	}
+	return nil
+}
+
+// MockMFAVerifier reports two-factor authentication as disabled for everyone
+type MockMFAVerifier struct{}
+
+func NewMockMFAVerifier() *MockMFAVerifier {
+	return &MockMFAVerifier{}
+}
+
+func (m *MockMFAVerifier) IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+func (m *MockMFAVerifier) StartChallenge(ctx context.Context, userID uuid.UUID) (*models.MFAChallenge, error) {
+	return nil, fmt.Errorf("two-factor authentication is not enabled")
+}
+
+func (m *MockMFAVerifier) CompleteChallenge(ctx context.Context, token, code string) (uuid.UUID, error) {
+	return uuid.Nil, fmt.Errorf("two-factor authentication is not enabled")
+}
+
+// MockLoginThrottle never locks anyone out
+type MockLoginThrottle struct{}
+
+func NewMockLoginThrottle() *MockLoginThrottle {
+	return &MockLoginThrottle{}
+}
+
+func (m *MockLoginThrottle) Check(ctx context.Context, email, ipAddress string) error {
+	return nil
+}
+
+func (m *MockLoginThrottle) RecordFailure(ctx context.Context, email, ipAddress string, user *models.User) error {
+	return nil
+}
+
+func (m *MockLoginThrottle) RecordSuccess(ctx context.Context, email string) error {
+	return nil
+}
+
 // Test RegisterUser business logic with the REAL AuthService using mocks
 func TestAuthService_RegisterUser(t *testing.T) {
 	tests := []struct {
@@ -236,10 +381,10 @@ func TestAuthService_RegisterUser(t *testing.T) {
 			}
 			
 			// Create REAL AuthService with mocks - this is the key difference!
-			authService := NewAuthService(userRepo, cache, authMid)
+			authService := NewAuthService(userRepo, cache, authMid, NewMockRefreshTokenRepository(), NewMockSessionRepository(), NewMockMFAVerifier(), NewMockLoginThrottle(), createTestLogger())
 			
 			// Test RegisterUser
-			user, token, err := authService.RegisterUser(context.Background(), tt.req)
+			user, token, err := authService.RegisterUser(context.Background(), tt.req, models.DeviceInfo{})
 			
 			// Verify results
 			if tt.expectError {
@@ -254,7 +399,7 @@ func TestAuthService_RegisterUser(t *testing.T) {
 				if user != nil {
 					t.Error("Expected user to be nil on error")
 				}
-				if token != "" {
+				if token != nil {
 					t.Error("Expected token to be empty on error")
 				}
 			} else {
@@ -264,8 +409,8 @@ func TestAuthService_RegisterUser(t *testing.T) {
 				if tt.expectUser && user == nil {
 					t.Error("Expected user but got nil")
 				}
-				if tt.expectToken && token == "" {
-					t.Error("Expected token but got empty string")
+				if tt.expectToken && (token == nil || token.AccessToken == "" || token.RefreshToken == "") {
+					t.Error("Expected access and refresh token")
 				}
 				
 				// Verify user was created with correct data
@@ -364,6 +509,26 @@ func TestAuthService_LoginUser(t *testing.T) {
 			expectError:   true,
 			errorContains: "Failed to generate token",
 		},
+		{
+			name:     "suspended account",
+			email:    "test@example.com",
+			password: "password123",
+			setupMocks: func(userRepo *MockUserRepository, cache *MockCache, authMid *MockAuthMiddleware) {
+				// Add existing user who has been suspended
+				hashedPassword, _ := utils.HashPassword("password123")
+				suspendedAt := time.Now()
+				existingUser := &models.User{
+					ID:           uuid.New(),
+					Email:        "test@example.com",
+					Username:     "testuser",
+					PasswordHash: hashedPassword,
+					SuspendedAt:  &suspendedAt,
+				}
+				userRepo.usersByEmail["test@example.com"] = existingUser
+			},
+			expectError:   true,
+			errorContains: "suspended",
+		},
 		{
 			name:     "empty email",
 			email:    "",
@@ -406,10 +571,10 @@ func TestAuthService_LoginUser(t *testing.T) {
 			}
 			
 			// Create REAL AuthService with mocks
-			authService := NewAuthService(userRepo, cache, authMid)
+			authService := NewAuthService(userRepo, cache, authMid, NewMockRefreshTokenRepository(), NewMockSessionRepository(), NewMockMFAVerifier(), NewMockLoginThrottle(), createTestLogger())
 			
 			// Test LoginUser
-			user, token, err := authService.LoginUser(context.Background(), tt.email, tt.password)
+			user, token, _, err := authService.LoginUser(context.Background(), tt.email, tt.password, models.DeviceInfo{})
 			
 			// Verify results
 			if tt.expectError {
@@ -424,7 +589,7 @@ func TestAuthService_LoginUser(t *testing.T) {
 				if user != nil {
 					t.Error("Expected user to be nil on error")
 				}
-				if token != "" {
+				if token != nil {
 					t.Error("Expected token to be empty on error")
 				}
 			} else {
@@ -434,8 +599,8 @@ func TestAuthService_LoginUser(t *testing.T) {
 				if tt.expectUser && user == nil {
 					t.Error("Expected user but got nil")
 				}
-				if tt.expectToken && token == "" {
-					t.Error("Expected token but got empty string")
+				if tt.expectToken && (token == nil || token.AccessToken == "" || token.RefreshToken == "") {
+					t.Error("Expected access and refresh token")
 				}
 				
 				// Verify user data
@@ -605,7 +770,7 @@ func TestAuthService_ValidateToken(t *testing.T) {
 			}
 			
 			// Create REAL AuthService with mocks
-			authService := NewAuthService(userRepo, cache, authMid)
+			authService := NewAuthService(userRepo, cache, authMid, NewMockRefreshTokenRepository(), NewMockSessionRepository(), NewMockMFAVerifier(), NewMockLoginThrottle(), createTestLogger())
 			
 			// Test ValidateToken
 			user, err := authService.ValidateToken(context.Background(), tt.tokenString)
@@ -650,24 +815,24 @@ func TestAuthService_LogoutUser(t *testing.T) {
 	tests := []struct {
 		name           string
 		jti            string
-		userID         string
+		sessionID      string
 		setupMocks     func(*MockUserRepository, *MockCache, *MockAuthMiddleware)
 		expectError    bool
 		errorContains  string
 	}{
 		{
-			name:   "successful logout",
-			jti:    "token-jti-123",
-			userID: "user-123",
+			name:      "successful logout",
+			jti:       "token-jti-123",
+			sessionID: "",
 			setupMocks: func(userRepo *MockUserRepository, cache *MockCache, authMid *MockAuthMiddleware) {
 				// No errors - everything succeeds
 			},
 			expectError: false,
 		},
 		{
-			name:   "blacklist fails",
-			jti:    "token-jti-123",
-			userID: "user-123",
+			name:      "blacklist fails",
+			jti:       "token-jti-123",
+			sessionID: "",
 			setupMocks: func(userRepo *MockUserRepository, cache *MockCache, authMid *MockAuthMiddleware) {
 				// Make blacklist operation fail
 				cache.addToBlacklistError = fmt.Errorf("redis connection error")
@@ -676,41 +841,39 @@ func TestAuthService_LogoutUser(t *testing.T) {
 			errorContains: "Failed to blacklist token",
 		},
 		{
-			name:   "session deletion fails but logout succeeds",
-			jti:    "token-jti-123",
-			userID: "user-123",
+			name:      "logout signs the session out",
+			jti:       "token-jti-123",
+			sessionID: "7f9c1e4a-2b3d-4c5e-8f6a-9b0c1d2e3f4a",
 			setupMocks: func(userRepo *MockUserRepository, cache *MockCache, authMid *MockAuthMiddleware) {
-				// Make session deletion fail (should not fail logout)
-				cache.deleteSessionError = fmt.Errorf("redis session error")
+				// No errors - the session is revoked along with the token
 			},
-			expectError: false, // Session deletion failure should not fail logout
+			expectError: false,
 		},
 		{
-			name:   "empty jti",
-			jti:    "",
-			userID: "user-123",
+			name:      "empty jti",
+			jti:       "",
+			sessionID: "",
 			setupMocks: func(userRepo *MockUserRepository, cache *MockCache, authMid *MockAuthMiddleware) {
 				// Empty JTI should still work (just blacklist empty string)
 			},
 			expectError: false,
 		},
 		{
-			name:   "empty userID",
-			jti:    "token-jti-123",
-			userID: "",
+			name:      "token without a session",
+			jti:       "token-jti-123",
+			sessionID: "",
 			setupMocks: func(userRepo *MockUserRepository, cache *MockCache, authMid *MockAuthMiddleware) {
-				// Empty userID should still work (just delete empty session)
+				// Tokens issued before sessions existed only blacklist the token itself
 			},
 			expectError: false,
 		},
 		{
-			name:   "both blacklist and session deletion fail",
-			jti:    "token-jti-123",
-			userID: "user-123",
+			name:      "blacklist fails for a session token",
+			jti:       "token-jti-123",
+			sessionID: "7f9c1e4a-2b3d-4c5e-8f6a-9b0c1d2e3f4a",
 			setupMocks: func(userRepo *MockUserRepository, cache *MockCache, authMid *MockAuthMiddleware) {
-				// Make both operations fail
+				// Blacklisting fails before the session is touched
 				cache.addToBlacklistError = fmt.Errorf("redis blacklist error")
-				cache.deleteSessionError = fmt.Errorf("redis session error")
 			},
 			expectError:   true,
 			errorContains: "Failed to blacklist token", // Blacklist error should be returned
@@ -729,10 +892,10 @@ func TestAuthService_LogoutUser(t *testing.T) {
 			}
 			
 			// Create REAL AuthService with mocks
-			authService := NewAuthService(userRepo, cache, authMid)
+			authService := NewAuthService(userRepo, cache, authMid, NewMockRefreshTokenRepository(), NewMockSessionRepository(), NewMockMFAVerifier(), NewMockLoginThrottle(), createTestLogger())
 			
 			// Test LogoutUser
-			err := authService.LogoutUser(context.Background(), tt.jti, tt.userID)
+			err := authService.LogoutUser(context.Background(), tt.jti, tt.sessionID)
 			
 			// Verify results
 			if tt.expectError {
@@ -769,7 +932,7 @@ func TestAuthService_BusinessLogic(t *testing.T) {
 		cache := &cache.Cache{}
 		authMid := &middleware.AuthMiddleware{}
 		
-		authService := NewAuthService(userRepo, cache, authMid)
+		authService := NewAuthService(userRepo, cache, authMid, NewMockRefreshTokenRepository(), NewMockSessionRepository(), NewMockMFAVerifier(), NewMockLoginThrottle(), createTestLogger())
 		
 		if authService == nil {
 			t.Error("Expected AuthService to be created")
@@ -789,8 +952,8 @@ func TestAuthService_BusinessLogic(t *testing.T) {
 		authService := &AuthService{}
 		
 		// Test that methods exist and can be called
-		var registerFunc func(context.Context, *models.CreateUserRequest) (*models.User, string, error)
-		var loginFunc func(context.Context, string, string) (*models.User, string, error)
+		var registerFunc func(context.Context, *models.CreateUserRequest, models.DeviceInfo) (*models.User, *models.TokenPair, error)
+		var loginFunc func(context.Context, string, string, models.DeviceInfo) (*models.User, *models.TokenPair, *models.MFAChallenge, error)
 		var validateFunc func(context.Context, string) (*models.User, error)
 		var logoutFunc func(context.Context, string, string) error
 		
@@ -818,7 +981,7 @@ func TestAuthService_BusinessLogic(t *testing.T) {
 		cache := &cache.Cache{}
 		authMid := &middleware.AuthMiddleware{}
 		
-		authService := NewAuthService(userRepo, cache, authMid)
+		authService := NewAuthService(userRepo, cache, authMid, NewMockRefreshTokenRepository(), NewMockSessionRepository(), NewMockMFAVerifier(), NewMockLoginThrottle(), createTestLogger())
 		
 		// Test that methods handle nil input gracefully
 		// This tests the input validation logic
@@ -829,14 +992,14 @@ func TestAuthService_BusinessLogic(t *testing.T) {
 		}()
 		
 		// Test RegisterUser with nil request
-		user, token, err := authService.RegisterUser(context.Background(), nil)
+		user, token, err := authService.RegisterUser(context.Background(), nil, models.DeviceInfo{})
 		if err == nil {
 			t.Error("Expected error for nil request")
 		}
 		if user != nil {
 			t.Error("Expected user to be nil for nil request")
 		}
-		if token != "" {
+		if token != nil {
 			t.Error("Expected token to be empty for nil request")
 		}
 	})
@@ -956,7 +1119,7 @@ func TestAuthService_RegisterUser_BusinessLogic(t *testing.T) {
 		cache := &cache.Cache{}
 		authMid := &middleware.AuthMiddleware{}
 		
-		authService := NewAuthService(userRepo, cache, authMid)
+		authService := NewAuthService(userRepo, cache, authMid, NewMockRefreshTokenRepository(), NewMockSessionRepository(), NewMockMFAVerifier(), NewMockLoginThrottle(), createTestLogger())
 		
 		req := &models.CreateUserRequest{
 			Username: "testuser",
@@ -970,7 +1133,7 @@ func TestAuthService_RegisterUser_BusinessLogic(t *testing.T) {
 			}
 		}()
 		
-		user, token, err := authService.RegisterUser(context.Background(), req)
+		user, token, err := authService.RegisterUser(context.Background(), req, models.DeviceInfo{})
 		
 		// We expect this to fail due to nil dependencies
 		if err == nil {
@@ -979,8 +1142,202 @@ func TestAuthService_RegisterUser_BusinessLogic(t *testing.T) {
 		if user != nil {
 			t.Error("Expected user to be nil due to nil dependencies")
 		}
-		if token != "" {
+		if token != nil {
 			t.Error("Expected token to be empty due to nil dependencies")
 		}
 	})
-}
\ No newline at end of file
+}
+func TestAuthService_RefreshTokens(t *testing.T) {
+	setup := func() (*AuthService, *MockRefreshTokenRepository, *MockCache, *models.TokenPair) {
+		userRepo := NewMockUserRepository()
+		hashedPassword, _ := utils.HashPassword("password123")
+		user := &models.User{ID: uuid.New(), Username: "testuser", Email: "test@example.com", PasswordHash: hashedPassword}
+		userRepo.usersByEmail[user.Email] = user
+		userRepo.usersByID[user.ID.String()] = user
+
+		refreshRepo := NewMockRefreshTokenRepository()
+		cache := NewMockCache()
+		authService := NewAuthService(userRepo, cache, NewMockAuthMiddleware(), refreshRepo, NewMockSessionRepository(), NewMockMFAVerifier(), NewMockLoginThrottle(), createTestLogger())
+
+		_, tokens, _, err := authService.LoginUser(context.Background(), user.Email, "password123", models.DeviceInfo{})
+		if err != nil {
+			t.Fatalf("Expected login to succeed, got: %v", err)
+		}
+		return authService, refreshRepo, cache, tokens
+	}
+
+	t.Run("rotates the refresh token", func(t *testing.T) {
+		authService, _, _, tokens := setup()
+
+		user, refreshed, err := authService.RefreshTokens(context.Background(), tokens.RefreshToken, models.DeviceInfo{})
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if user == nil || user.Username != "testuser" {
+			t.Errorf("Expected the token owner, got %+v", user)
+		}
+		if refreshed.RefreshToken == "" || refreshed.RefreshToken == tokens.RefreshToken {
+			t.Error("Expected a new refresh token")
+		}
+		if refreshed.ExpiresIn != int(middleware.AccessTokenTTL.Seconds()) {
+			t.Errorf("Expected expires_in %d, got %d", int(middleware.AccessTokenTTL.Seconds()), refreshed.ExpiresIn)
+		}
+
+		if _, _, err := authService.RefreshTokens(context.Background(), refreshed.RefreshToken, models.DeviceInfo{}); err != nil {
+			t.Errorf("Expected the rotated token to be usable, got: %v", err)
+		}
+	})
+
+	t.Run("reuse revokes the family", func(t *testing.T) {
+		authService, refreshRepo, cache, tokens := setup()
+
+		_, refreshed, err := authService.RefreshTokens(context.Background(), tokens.RefreshToken, models.DeviceInfo{})
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+
+		_, _, err = authService.RefreshTokens(context.Background(), tokens.RefreshToken, models.DeviceInfo{})
+		if err == nil || !strings.Contains(err.Error(), "already been used") {
+			t.Fatalf("Expected reuse to be rejected, got: %v", err)
+		}
+
+		stored := refreshRepo.tokens[utils.HashToken(tokens.RefreshToken)]
+		if !refreshRepo.revokedFamilies[stored.FamilyID] {
+			t.Error("Expected the token family to be revoked")
+		}
+		if !cache.blacklistedTokens[middleware.SessionRevocationKey(stored.FamilyID.String())] {
+			t.Error("Expected the session to be blacklisted")
+		}
+		if _, _, err := authService.RefreshTokens(context.Background(), refreshed.RefreshToken, models.DeviceInfo{}); err == nil {
+			t.Error("Expected the newest token in the family to be revoked too")
+		}
+	})
+
+	t.Run("rejects unknown and expired tokens", func(t *testing.T) {
+		authService, refreshRepo, _, tokens := setup()
+
+		if _, _, err := authService.RefreshTokens(context.Background(), "not-a-token", models.DeviceInfo{}); err == nil {
+			t.Error("Expected unknown token to be rejected")
+		}
+
+		refreshRepo.tokens[utils.HashToken(tokens.RefreshToken)].ExpiresAt = time.Now().Add(-time.Minute)
+		if _, _, err := authService.RefreshTokens(context.Background(), tokens.RefreshToken, models.DeviceInfo{}); err == nil {
+			t.Error("Expected expired token to be rejected")
+		}
+	})
+
+	t.Run("logout revokes the session", func(t *testing.T) {
+		authService, refreshRepo, _, tokens := setup()
+
+		stored := refreshRepo.tokens[utils.HashToken(tokens.RefreshToken)]
+		if err := authService.LogoutUser(context.Background(), "token-jti-123", stored.FamilyID.String()); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if _, _, err := authService.RefreshTokens(context.Background(), tokens.RefreshToken, models.DeviceInfo{}); err == nil {
+			t.Error("Expected refresh after logout to be rejected")
+		}
+	})
+}
+
+func TestAuthService_Sessions(t *testing.T) {
+	userRepo := NewMockUserRepository()
+	hashedPassword, _ := utils.HashPassword("password123")
+	user := &models.User{ID: uuid.New(), Username: "testuser", Email: "test@example.com", PasswordHash: hashedPassword}
+	userRepo.usersByEmail[user.Email] = user
+	userRepo.usersByID[user.ID.String()] = user
+
+	refreshRepo := NewMockRefreshTokenRepository()
+	sessionRepo := NewMockSessionRepository()
+	cache := NewMockCache()
+	authService := NewAuthService(userRepo, cache, NewMockAuthMiddleware(), refreshRepo, sessionRepo, NewMockMFAVerifier(), NewMockLoginThrottle(), createTestLogger())
+
+	login := func(device models.DeviceInfo) (*models.TokenPair, uuid.UUID) {
+		_, tokens, _, err := authService.LoginUser(context.Background(), user.Email, "password123", device)
+		if err != nil {
+			t.Fatalf("Expected login to succeed, got: %v", err)
+		}
+		return tokens, refreshRepo.tokens[utils.HashToken(tokens.RefreshToken)].FamilyID
+	}
+
+	_, laptopID := login(models.DeviceInfo{Name: "Laptop", UserAgent: "Firefox", IPAddress: "203.0.113.7"})
+	phoneTokens, phoneID := login(models.DeviceInfo{Name: "Phone"})
+
+	t.Run("each login is its own session", func(t *testing.T) {
+		sessions, err := authService.ListSessions(context.Background(), user.ID, laptopID.String())
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if len(sessions) != 2 {
+			t.Fatalf("Expected 2 sessions, got %d", len(sessions))
+		}
+		for _, session := range sessions {
+			if session.Current != (session.ID == laptopID) {
+				t.Errorf("Expected only the laptop session to be current, got %+v", session)
+			}
+		}
+
+		laptop := sessionRepo.sessions[laptopID]
+		if laptop.DeviceName == nil || *laptop.DeviceName != "Laptop" || laptop.IPAddress == nil || *laptop.IPAddress != "203.0.113.7" {
+			t.Errorf("Expected device details to be recorded, got %+v", laptop)
+		}
+	})
+
+	t.Run("cannot revoke another user's session", func(t *testing.T) {
+		err := authService.RevokeSession(context.Background(), uuid.New(), phoneID)
+		if err == nil || !strings.Contains(err.Error(), "Session not found") {
+			t.Errorf("Expected session not found, got: %v", err)
+		}
+	})
+
+	t.Run("revoke other sessions keeps the current one", func(t *testing.T) {
+		revoked, err := authService.RevokeOtherSessions(context.Background(), user.ID, laptopID.String())
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if revoked != 1 {
+			t.Errorf("Expected 1 session revoked, got %d", revoked)
+		}
+		if sessionRepo.sessions[laptopID].RevokedAt != nil {
+			t.Error("Expected the current session to stay active")
+		}
+		if !cache.blacklistedTokens[middleware.SessionRevocationKey(phoneID.String())] {
+			t.Error("Expected the phone's access tokens to be revoked")
+		}
+		if _, _, err := authService.RefreshTokens(context.Background(), phoneTokens.RefreshToken, models.DeviceInfo{}); err == nil {
+			t.Error("Expected the phone's refresh token to be revoked")
+		}
+	})
+}
+
+func TestAuthService_LoginUpgradesPasswordHash(t *testing.T) {
+	ctx := context.Background()
+	userRepo := NewMockUserRepository()
+	legacyHash, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("Failed to create bcrypt hash: %v", err)
+	}
+	user := &models.User{ID: uuid.New(), Username: "testuser", Email: "test@example.com", PasswordHash: string(legacyHash)}
+	userRepo.Create(ctx, user)
+
+	authService := NewAuthService(userRepo, NewMockCache(), NewMockAuthMiddleware(), NewMockRefreshTokenRepository(), NewMockSessionRepository(), NewMockMFAVerifier(), NewMockLoginThrottle(), createTestLogger())
+
+	// A failed login leaves the hash alone
+	if _, _, _, err := authService.LoginUser(ctx, user.Email, "wrongpassword", models.DeviceInfo{}); err == nil {
+		t.Fatal("Expected wrong password to fail")
+	}
+	if user.PasswordHash != string(legacyHash) {
+		t.Error("Expected hash to be unchanged after a failed login")
+	}
+
+	if _, _, _, err := authService.LoginUser(ctx, user.Email, "password123", models.DeviceInfo{}); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if !strings.HasPrefix(user.PasswordHash, "$argon2id$") {
+		t.Errorf("Expected bcrypt hash to be upgraded to argon2id, got %s", user.PasswordHash)
+	}
+
+	// The upgraded hash keeps working
+	if _, _, _, err := authService.LoginUser(ctx, user.Email, "password123", models.DeviceInfo{}); err != nil {
+		t.Errorf("Expected login with the upgraded hash to succeed, got: %v", err)
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"fmt"
 	"io"
 
+	"musicapp/internal/errors"
 	"musicapp/internal/interfaces"
 	"musicapp/internal/models"
+	"musicapp/internal/pagination"
 	"musicapp/internal/repository"
 	"musicapp/internal/storage"
 
@@ -25,9 +27,12 @@ type BandRepository interface {
 	RemoveMember(ctx context.Context, bandID, userID uuid.UUID) error
 	GetMembers(ctx context.Context, bandID uuid.UUID) ([]*models.BandMember, error)
 	IsMember(ctx context.Context, bandID, userID uuid.UUID) (bool, error)
-	IsAdmin(ctx context.Context, bandID, userID uuid.UUID) (bool, error)
+	GetMember(ctx context.Context, bandID, userID uuid.UUID) (*models.BandMember, error)
+	SetMemberRole(ctx context.Context, bandID, userID uuid.UUID, role string) error
+	TransferOwnership(ctx context.Context, bandID, ownerID, newOwnerID uuid.UUID) error
+	SetCanPostAsBand(ctx context.Context, bandID, userID uuid.UUID, canPost bool) error
 	GetUserBands(ctx context.Context, userID uuid.UUID) ([]*models.BandMember, error)
-	GetAll(ctx context.Context, limit, offset int) ([]*models.Band, error)
+	GetAll(ctx context.Context, limit, offset int, after *pagination.Cursor) ([]*models.Band, error)
 }
 
 type UserRepositoryForBand interface {
@@ -82,8 +87,8 @@ func (s *BandService) CreateBand(ctx context.Context, userID uuid.UUID, req *mod
 		return nil, fmt.Errorf("failed to create band: %w", err)
 	}
 
-	// Add creator as admin member
-	if err := s.bandRepo.AddMember(ctx, band.ID, userID, "Admin"); err != nil {
+	// The creator owns the band
+	if err := s.bandRepo.AddMember(ctx, band.ID, userID, models.BandRoleOwner); err != nil {
 		return nil, fmt.Errorf("failed to add creator as member: %w", err)
 	}
 
@@ -111,14 +116,8 @@ func (s *BandService) GetBand(ctx context.Context, bandID uuid.UUID) (*models.Ba
 
 // UpdateBand updates band details
 func (s *BandService) UpdateBand(ctx context.Context, bandID, userID uuid.UUID, req *models.UpdateBandRequest) (*models.Band, error) {
-	// Check if user is admin of the band
-	isAdmin, err := s.bandRepo.IsAdmin(ctx, bandID, userID)
-	if err != nil {
-		return nil, fmt.Errorf("failed to check permissions: %w", err)
-	}
-
-	if !isAdmin {
-		return nil, fmt.Errorf("only band admins can update band details")
+	if _, err := authorizeBand(ctx, s.bandRepo, bandID, userID, models.BandPermissionEditProfile); err != nil {
+		return nil, err
 	}
 
 	// Get existing band
@@ -163,71 +162,149 @@ func (s *BandService) UpdateBand(ctx context.Context, bandID, userID uuid.UUID,
 	return band, nil
 }
 
-// DeleteBand deletes a band
+// DeleteBand deletes a band. Only its owner can.
 func (s *BandService) DeleteBand(ctx context.Context, bandID, userID uuid.UUID) error {
-	// Check if user is admin of the band
-	isAdmin, err := s.bandRepo.IsAdmin(ctx, bandID, userID)
+	if _, err := authorizeBand(ctx, s.bandRepo, bandID, userID, models.BandPermissionDeleteBand); err != nil {
+		return err
+	}
+
+	if err := s.bandRepo.Delete(ctx, bandID); err != nil {
+		return fmt.Errorf("failed to delete band: %w", err)
+	}
+
+	return nil
+}
+
+// LeaveBand removes a user from a band. The owner has to hand the band over
+// first, so that every band keeps an owner.
+func (s *BandService) LeaveBand(ctx context.Context, bandID, userID uuid.UUID) error {
+	// Check if user is a member
+	member, err := s.bandRepo.GetMember(ctx, bandID, userID)
 	if err != nil {
-		return fmt.Errorf("failed to check permissions: %w", err)
+		return fmt.Errorf("failed to check membership: %w", err)
 	}
 
-	if !isAdmin {
-		return fmt.Errorf("only band admins can delete the band")
+	if member == nil {
+		return fmt.Errorf("user is not a member of this band")
 	}
 
-	if err := s.bandRepo.Delete(ctx, bandID); err != nil {
-		return fmt.Errorf("failed to delete band: %w", err)
+	if member.Role == models.BandRoleOwner {
+		return errOwnerCannotLeave
+	}
+
+	// Remove user from band
+	if err := s.bandRepo.RemoveMember(ctx, bandID, userID); err != nil {
+		return fmt.Errorf("failed to leave band: %w", err)
+	}
+
+	return nil
+}
+
+// SetMemberPostingPermission lets a band admin designate a member who may
+// publish posts as the band, or revoke that permission
+func (s *BandService) SetMemberPostingPermission(ctx context.Context, bandID, adminID, memberID uuid.UUID, canPost bool) error {
+	if _, err := authorizeBand(ctx, s.bandRepo, bandID, adminID, models.BandPermissionManageMembers); err != nil {
+		return err
+	}
+
+	if err := s.bandRepo.SetCanPostAsBand(ctx, bandID, memberID, canPost); err != nil {
+		return errBandMemberNotFound
 	}
 
 	return nil
 }
 
-// JoinBand adds a user to a band
-func (s *BandService) JoinBand(ctx context.Context, bandID, userID uuid.UUID) error {
-	// Check if band exists
-	_, err := s.bandRepo.GetByID(ctx, bandID)
+// SetMemberRole changes a member's band role. Managers can only act on, and
+// hand out, roles below their own, so only the owner appoints admins.
+// Setting the owner role hands the band over, and the previous owner stays
+// on as an admin.
+func (s *BandService) SetMemberRole(ctx context.Context, bandID, actorID, memberID uuid.UUID, role string) error {
+	if models.BandRoleRank(role) < 0 {
+		return errInvalidBandRole
+	}
+
+	actor, err := authorizeBand(ctx, s.bandRepo, bandID, actorID, models.BandPermissionManageMembers)
 	if err != nil {
-		return fmt.Errorf("band not found: %w", err)
+		return err
+	}
+	if actorID == memberID {
+		return errOwnBandRole
 	}
 
-	// Check if user is already a member
-	isMember, err := s.bandRepo.IsMember(ctx, bandID, userID)
+	member, err := s.getMember(ctx, bandID, memberID)
 	if err != nil {
-		return fmt.Errorf("failed to check membership: %w", err)
+		return err
+	}
+
+	if role == models.BandRoleOwner {
+		if actor.Role != models.BandRoleOwner {
+			return errBandOwnerOnly
+		}
+		if err := s.bandRepo.TransferOwnership(ctx, bandID, actorID, memberID); err != nil {
+			return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to hand the band over")
+		}
+		return nil
 	}
 
-	if isMember {
-		return fmt.Errorf("user is already a member of this band")
+	if !outranksMember(actor, member) || models.BandRoleRank(role) >= models.BandRoleRank(actor.Role) {
+		return errBandNotOutranked
+	}
+	if member.Role == role {
+		return nil
 	}
 
-	// Add user as member
-	if err := s.bandRepo.AddMember(ctx, bandID, userID, "Member"); err != nil {
-		return fmt.Errorf("failed to join band: %w", err)
+	if err := s.bandRepo.SetMemberRole(ctx, bandID, memberID, role); err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to change band role")
 	}
 
 	return nil
 }
 
-// LeaveBand removes a user from a band
-func (s *BandService) LeaveBand(ctx context.Context, bandID, userID uuid.UUID) error {
-	// Check if user is a member
-	isMember, err := s.bandRepo.IsMember(ctx, bandID, userID)
+// RemoveMember removes another member from a band. As with roles, managers
+// can only remove members below them.
+func (s *BandService) RemoveMember(ctx context.Context, bandID, actorID, memberID uuid.UUID) error {
+	actor, err := authorizeBand(ctx, s.bandRepo, bandID, actorID, models.BandPermissionManageMembers)
 	if err != nil {
-		return fmt.Errorf("failed to check membership: %w", err)
+		return err
+	}
+	if actorID == memberID {
+		return errRemoveSelf
 	}
 
-	if !isMember {
-		return fmt.Errorf("user is not a member of this band")
+	member, err := s.getMember(ctx, bandID, memberID)
+	if err != nil {
+		return err
+	}
+	if !outranksMember(actor, member) {
+		return errBandNotOutranked
 	}
 
-	// Remove user from band
-	if err := s.bandRepo.RemoveMember(ctx, bandID, userID); err != nil {
-		return fmt.Errorf("failed to leave band: %w", err)
+	if err := s.bandRepo.RemoveMember(ctx, bandID, memberID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to remove band member")
 	}
 
 	return nil
 }
 
+// AuthorizeBand checks that a user has a permission in a band, returning a
+// 403 AppError if not. It backs middleware.RequireBandPermission.
+func (s *BandService) AuthorizeBand(ctx context.Context, bandID, userID uuid.UUID, permission models.BandPermission) error {
+	_, err := authorizeBand(ctx, s.bandRepo, bandID, userID, permission)
+	return err
+}
+
+// getMember loads another user's membership of a band
+func (s *BandService) getMember(ctx context.Context, bandID, userID uuid.UUID) (*models.BandMember, error) {
+	member, err := s.bandRepo.GetMember(ctx, bandID, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to load band member")
+	}
+	if member == nil {
+		return nil, errBandMemberNotFound
+	}
+	return member, nil
+}
+
 // GetBandMembers retrieves all members of a band
 func (s *BandService) GetBandMembers(ctx context.Context, bandID uuid.UUID) ([]*models.BandMember, error) {
 	members, err := s.bandRepo.GetMembers(ctx, bandID)
@@ -267,14 +344,8 @@ func (s *BandService) UploadProfilePicture(ctx context.Context, bandID, userID u
 		return "", fmt.Errorf("S3 client not configured")
 	}
 
-	// Check if user is admin of the band
-	isAdmin, err := s.bandRepo.IsAdmin(ctx, bandID, userID)
-	if err != nil {
-		return "", fmt.Errorf("failed to check permissions: %w", err)
-	}
-
-	if !isAdmin {
-		return "", fmt.Errorf("only band admins can update profile picture")
+	if _, err := authorizeBand(ctx, s.bandRepo, bandID, userID, models.BandPermissionEditProfile); err != nil {
+		return "", err
 	}
 
 	// Validate image file
@@ -308,7 +379,7 @@ func (s *BandService) GetUserBands(ctx context.Context, userID uuid.UUID) ([]*mo
 }
 
 // GetAllBands gets all bands with pagination
-func (s *BandService) GetAllBands(ctx context.Context, limit, offset int) ([]*models.Band, error) {
+func (s *BandService) GetAllBands(ctx context.Context, limit, offset int, after *pagination.Cursor) ([]*models.Band, error) {
 	// Default pagination values
 	if limit <= 0 {
 		limit = 20
@@ -320,7 +391,7 @@ func (s *BandService) GetAllBands(ctx context.Context, limit, offset int) ([]*mo
 		offset = 0
 	}
 
-	return s.bandRepo.GetAll(ctx, limit, offset)
+	return s.bandRepo.GetAll(ctx, limit, offset, after)
 }
 
 // Adapter structs implement interfaces for existing concrete types
@@ -375,12 +446,24 @@ func (a *BandRepositoryAdapter) IsMember(ctx context.Context, bandID, userID uui
 	return a.repo.IsMember(ctx, bandID, userID)
 }
 
-func (a *BandRepositoryAdapter) IsAdmin(ctx context.Context, bandID, userID uuid.UUID) (bool, error) {
-	return a.repo.IsAdmin(ctx, bandID, userID)
+func (a *BandRepositoryAdapter) GetMember(ctx context.Context, bandID, userID uuid.UUID) (*models.BandMember, error) {
+	return a.repo.GetMember(ctx, bandID, userID)
+}
+
+func (a *BandRepositoryAdapter) SetMemberRole(ctx context.Context, bandID, userID uuid.UUID, role string) error {
+	return a.repo.SetMemberRole(ctx, bandID, userID, role)
+}
+
+func (a *BandRepositoryAdapter) TransferOwnership(ctx context.Context, bandID, ownerID, newOwnerID uuid.UUID) error {
+	return a.repo.TransferOwnership(ctx, bandID, ownerID, newOwnerID)
+}
+
+func (a *BandRepositoryAdapter) SetCanPostAsBand(ctx context.Context, bandID, userID uuid.UUID, canPost bool) error {
+	return a.repo.SetCanPostAsBand(ctx, bandID, userID, canPost)
 }
 
-func (a *BandRepositoryAdapter) GetAll(ctx context.Context, limit, offset int) ([]*models.Band, error) {
-	return a.repo.GetAll(ctx, limit, offset)
+func (a *BandRepositoryAdapter) GetAll(ctx context.Context, limit, offset int, after *pagination.Cursor) ([]*models.Band, error) {
+	return a.repo.GetAll(ctx, limit, offset, after)
 }
 
 // UserRepositoryForBandAdapter adapts repository.UserRepository to UserRepositoryForBand interface
@@ -0,0 +1,604 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/logging"
+	"musicapp/internal/mail"
+	"musicapp/internal/models"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// bandInvitationTTL is how long an invitation can be accepted
+	bandInvitationTTL = 14 * 24 * time.Hour
+
+	// joinRequestTTL is how long a join request waits for an answer
+	joinRequestTTL = 30 * 24 * time.Hour
+
+	membershipMessageMaxLength = 500
+	membershipRequestListLimit = 50
+)
+
+var (
+	errInvitationNotFound     = errors.New(errors.ErrCodeNotFound, "Invitation not found")
+	errJoinRequestNotFound    = errors.New(errors.ErrCodeNotFound, "Join request not found")
+	errInvalidMembershipRole  = errors.New(errors.ErrCodeInvalidInput, "Invitations and join requests can only be for the admin or member role")
+	errMembershipMessageLong  = errors.New(errors.ErrCodeInvalidInput, fmt.Sprintf("Message too long (max %d characters)", membershipMessageMaxLength))
+	errUserAlreadyBandMember  = errors.New(errors.ErrCodeConflict, "User is already a member of this band")
+	errAlreadyBandMember      = errors.New(errors.ErrCodeConflict, "You are already a member of this band")
+	errInvitationPending      = errors.New(errors.ErrCodeConflict, "This user already has a pending invitation to the band")
+	errUserAskedToJoin        = errors.New(errors.ErrCodeConflict, "This user has asked to join the band; approve their request instead")
+	errJoinRequestPending     = errors.New(errors.ErrCodeConflict, "You have already asked to join this band")
+	errAlreadyInvitedToBand   = errors.New(errors.ErrCodeConflict, "You have been invited to this band; accept the invitation instead")
+	errInvitationRoleTooHigh  = errors.New(errors.ErrCodeForbidden, "You can only invite users to a lower band role than yours")
+	errJoinRequestRoleTooHigh = errors.New(errors.ErrCodeForbidden, "You can only approve users for a lower band role than yours")
+)
+
+// BandMembershipRequestRepository interface for invitations and join
+// requests
+type BandMembershipRequestRepository interface {
+	Create(ctx context.Context, request *models.BandMembershipRequest) (bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.BandMembershipRequest, error)
+	GetPending(ctx context.Context, bandID, userID uuid.UUID) (*models.BandMembershipRequest, error)
+	GetPendingByBand(ctx context.Context, bandID uuid.UUID, kind string, limit int) ([]*models.BandMembershipRequest, error)
+	GetByUser(ctx context.Context, userID uuid.UUID, kind string, limit int) ([]*models.BandMembershipRequest, error)
+	Accept(ctx context.Context, id, respondedBy uuid.UUID, role string) (bool, error)
+	Close(ctx context.Context, id uuid.UUID, status string, respondedBy uuid.UUID) (bool, error)
+	MarkNotified(ctx context.Context, id uuid.UUID) error
+}
+
+// BandRepositoryForMembership interface for the band lookups membership
+// requests need
+type BandRepositoryForMembership interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Band, error)
+	GetMember(ctx context.Context, bandID, userID uuid.UUID) (*models.BandMember, error)
+	GetMembers(ctx context.Context, bandID uuid.UUID) ([]*models.BandMember, error)
+}
+
+// BandMembershipService handles how users come to join bands. Band admins
+// invite users, who accept or decline, and users ask to join, which band
+// admins approve or reject. Requests are open until answered, withdrawn or
+// expired, and the other side is told about them by email.
+type BandMembershipService struct {
+	requestRepo BandMembershipRequestRepository
+	bandRepo    BandRepositoryForMembership
+	userRepo    UserRepositoryForBand
+	mailer      mail.Mailer
+	appURL      string
+	logger      *logging.Logger
+}
+
+// NewBandMembershipService creates a BandMembershipService. appURL is the
+// base URL of the web app, used for links in notification emails.
+func NewBandMembershipService(requestRepo BandMembershipRequestRepository, bandRepo BandRepositoryForMembership, userRepo UserRepositoryForBand, mailer mail.Mailer, appURL string, logger *logging.Logger) *BandMembershipService {
+	return &BandMembershipService{
+		requestRepo: requestRepo,
+		bandRepo:    bandRepo,
+		userRepo:    userRepo,
+		mailer:      mailer,
+		appURL:      strings.TrimRight(appURL, "/"),
+		logger:      logger,
+	}
+}
+
+// Invite invites a user to a band. As with changing roles, band admins can
+// only invite users to a role below their own.
+func (s *BandMembershipService) Invite(ctx context.Context, bandID, actorID uuid.UUID, req *models.InviteToBandRequest) (*models.BandMembershipRequest, error) {
+	role, err := membershipRole(req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateMembershipMessage(req.Message); err != nil {
+		return nil, err
+	}
+
+	actor, err := authorizeBand(ctx, s.bandRepo, bandID, actorID, models.BandPermissionManageMembers)
+	if err != nil {
+		return nil, err
+	}
+	if models.BandRoleRank(role) >= models.BandRoleRank(actor.Role) {
+		return nil, errInvitationRoleTooHigh
+	}
+
+	invitee, err := s.userRepo.GetByID(ctx, req.UserID)
+	if err != nil || invitee == nil {
+		return nil, errors.NewUserNotFound(req.UserID.String())
+	}
+	if err := s.checkCanRequest(ctx, bandID, req.UserID, false); err != nil {
+		return nil, err
+	}
+
+	request := &models.BandMembershipRequest{
+		ID:        uuid.New(),
+		BandID:    bandID,
+		UserID:    req.UserID,
+		Kind:      models.MembershipRequestInvitation,
+		Role:      role,
+		Message:   req.Message,
+		InvitedBy: &actorID,
+		ExpiresAt: time.Now().Add(bandInvitationTTL),
+	}
+	if err := s.create(ctx, request); err != nil {
+		return nil, err
+	}
+
+	band, err := s.bandRepo.GetByID(ctx, bandID)
+	if err == nil && band != nil {
+		s.notify(request, &mail.Message{
+			To:      invitee.Email,
+			Subject: fmt.Sprintf("You've been invited to join %s", band.Name),
+			Body: fmt.Sprintf("Hi %s,\n\nYou've been invited to join %s as %s.%s\n\n"+
+				"Accept or decline the invitation by %s:\n\n%s/bands/%s\n",
+				invitee.Username, band.Name, anArticle(role), quotedMessage(req.Message),
+				request.ExpiresAt.Format("2 January 2006"), s.appURL, bandID),
+		})
+	}
+
+	return request, nil
+}
+
+// RequestToJoin asks to join a band. The band's admins are told and can
+// approve or reject the request.
+func (s *BandMembershipService) RequestToJoin(ctx context.Context, bandID, userID uuid.UUID, req *models.JoinBandRequest) (*models.BandMembershipRequest, error) {
+	role, err := membershipRole(req.Role)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateMembershipMessage(req.Message); err != nil {
+		return nil, err
+	}
+
+	band, err := s.bandRepo.GetByID(ctx, bandID)
+	if err != nil || band == nil {
+		return nil, errors.NewBandNotFound(bandID.String())
+	}
+	if err := s.checkCanRequest(ctx, bandID, userID, true); err != nil {
+		return nil, err
+	}
+
+	request := &models.BandMembershipRequest{
+		ID:        uuid.New(),
+		BandID:    bandID,
+		UserID:    userID,
+		Kind:      models.MembershipRequestJoin,
+		Role:      role,
+		Message:   req.Message,
+		ExpiresAt: time.Now().Add(joinRequestTTL),
+	}
+	if err := s.create(ctx, request); err != nil {
+		return nil, err
+	}
+
+	requester, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || requester == nil {
+		return request, nil
+	}
+	members, err := s.bandRepo.GetMembers(ctx, bandID)
+	if err != nil {
+		s.logger.WithError(err).WithField("band_id", bandID.String()).Error("Failed to load band admins to notify of a join request")
+		return request, nil
+	}
+
+	var msgs []*mail.Message
+	for _, member := range members {
+		if member.User == nil || !member.Can(models.BandPermissionManageMembers) {
+			continue
+		}
+		msgs = append(msgs, &mail.Message{
+			To:      member.User.Email,
+			Subject: fmt.Sprintf("%s has asked to join %s", requester.Username, band.Name),
+			Body: fmt.Sprintf("Hi %s,\n\n%s has asked to join %s as %s.%s\n\n"+
+				"Approve or reject the request by %s:\n\n%s/bands/%s/join-requests\n",
+				member.User.Username, requester.Username, band.Name, anArticle(role), quotedMessage(req.Message),
+				request.ExpiresAt.Format("2 January 2006"), s.appURL, bandID),
+		})
+	}
+	s.notify(request, msgs...)
+
+	return request, nil
+}
+
+// ListBandInvitations lists a band's open invitations
+func (s *BandMembershipService) ListBandInvitations(ctx context.Context, bandID, actorID uuid.UUID) ([]*models.BandMembershipRequest, error) {
+	return s.listBandRequests(ctx, bandID, actorID, models.MembershipRequestInvitation)
+}
+
+// ListBandJoinRequests lists a band's open join requests
+func (s *BandMembershipService) ListBandJoinRequests(ctx context.Context, bandID, actorID uuid.UUID) ([]*models.BandMembershipRequest, error) {
+	return s.listBandRequests(ctx, bandID, actorID, models.MembershipRequestJoin)
+}
+
+// ListInvitations lists the invitations a user has received, most recent
+// first
+func (s *BandMembershipService) ListInvitations(ctx context.Context, userID uuid.UUID) ([]*models.BandMembershipRequest, error) {
+	return s.listUserRequests(ctx, userID, models.MembershipRequestInvitation)
+}
+
+// ListJoinRequests lists the join requests a user has made, most recent
+// first
+func (s *BandMembershipService) ListJoinRequests(ctx context.Context, userID uuid.UUID) ([]*models.BandMembershipRequest, error) {
+	return s.listUserRequests(ctx, userID, models.MembershipRequestJoin)
+}
+
+// AcceptInvitation accepts an invitation, adding the user to the band with
+// the role they were offered
+func (s *BandMembershipService) AcceptInvitation(ctx context.Context, userID, requestID uuid.UUID) (*models.BandMembershipRequest, error) {
+	request, err := s.userRequest(ctx, userID, requestID, models.MembershipRequestInvitation)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.accept(ctx, request, userID, request.Role); err != nil {
+		return nil, err
+	}
+	s.notifyInviter(ctx, request)
+	return request, nil
+}
+
+// DeclineInvitation declines an invitation
+func (s *BandMembershipService) DeclineInvitation(ctx context.Context, userID, requestID uuid.UUID) (*models.BandMembershipRequest, error) {
+	request, err := s.userRequest(ctx, userID, requestID, models.MembershipRequestInvitation)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.close(ctx, request, models.MembershipRequestDeclined, userID); err != nil {
+		return nil, err
+	}
+	s.notifyInviter(ctx, request)
+	return request, nil
+}
+
+// CancelJoinRequest withdraws a user's join request
+func (s *BandMembershipService) CancelJoinRequest(ctx context.Context, userID, requestID uuid.UUID) error {
+	request, err := s.userRequest(ctx, userID, requestID, models.MembershipRequestJoin)
+	if err != nil {
+		return err
+	}
+	return s.close(ctx, request, models.MembershipRequestCancelled, userID)
+}
+
+// CancelInvitation withdraws an invitation the band has sent
+func (s *BandMembershipService) CancelInvitation(ctx context.Context, bandID, actorID, requestID uuid.UUID) error {
+	if _, err := authorizeBand(ctx, s.bandRepo, bandID, actorID, models.BandPermissionManageMembers); err != nil {
+		return err
+	}
+
+	request, err := s.bandRequest(ctx, bandID, requestID, models.MembershipRequestInvitation)
+	if err != nil {
+		return err
+	}
+	return s.close(ctx, request, models.MembershipRequestCancelled, actorID)
+}
+
+// ApproveJoinRequest adds the user who asked to the band, with the role they
+// asked for unless the approver picks another. Band admins can only approve
+// users for a role below their own.
+func (s *BandMembershipService) ApproveJoinRequest(ctx context.Context, bandID, actorID, requestID uuid.UUID, role string) (*models.BandMembershipRequest, error) {
+	actor, err := authorizeBand(ctx, s.bandRepo, bandID, actorID, models.BandPermissionManageMembers)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := s.bandRequest(ctx, bandID, requestID, models.MembershipRequestJoin)
+	if err != nil {
+		return nil, err
+	}
+
+	if role == "" {
+		role = request.Role
+	}
+	if role, err = membershipRole(role); err != nil {
+		return nil, err
+	}
+	if models.BandRoleRank(role) >= models.BandRoleRank(actor.Role) {
+		return nil, errJoinRequestRoleTooHigh
+	}
+
+	if err := s.accept(ctx, request, actorID, role); err != nil {
+		return nil, err
+	}
+	s.notifyRequester(ctx, request)
+	return request, nil
+}
+
+// RejectJoinRequest rejects a join request
+func (s *BandMembershipService) RejectJoinRequest(ctx context.Context, bandID, actorID, requestID uuid.UUID) (*models.BandMembershipRequest, error) {
+	if _, err := authorizeBand(ctx, s.bandRepo, bandID, actorID, models.BandPermissionManageMembers); err != nil {
+		return nil, err
+	}
+
+	request, err := s.bandRequest(ctx, bandID, requestID, models.MembershipRequestJoin)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.close(ctx, request, models.MembershipRequestDeclined, actorID); err != nil {
+		return nil, err
+	}
+	s.notifyRequester(ctx, request)
+	return request, nil
+}
+
+// checkCanRequest checks that a user is neither in the band nor has an open
+// invitation or join request for it. forJoin is true when the user is asking
+// to join themselves.
+func (s *BandMembershipService) checkCanRequest(ctx context.Context, bandID, userID uuid.UUID, forJoin bool) error {
+	member, err := s.bandRepo.GetMember(ctx, bandID, userID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to check membership")
+	}
+	if member != nil && forJoin {
+		return errAlreadyBandMember
+	}
+	if member != nil {
+		return errUserAlreadyBandMember
+	}
+
+	pending, err := s.requestRepo.GetPending(ctx, bandID, userID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to check pending requests")
+	}
+	return pendingRequestError(pending, forJoin)
+}
+
+// create stores a new request, which loses to any request made for the same
+// user and band since checkCanRequest
+func (s *BandMembershipService) create(ctx context.Context, request *models.BandMembershipRequest) error {
+	created, err := s.requestRepo.Create(ctx, request)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to save request")
+	}
+	if !created {
+		pending, err := s.requestRepo.GetPending(ctx, request.BandID, request.UserID)
+		if err != nil || pending == nil {
+			return errors.New(errors.ErrCodeConflict, "There is already a pending invitation or join request")
+		}
+		return pendingRequestError(pending, request.Kind == models.MembershipRequestJoin)
+	}
+	return nil
+}
+
+func (s *BandMembershipService) accept(ctx context.Context, request *models.BandMembershipRequest, respondedBy uuid.UUID, role string) error {
+	if request.Status != models.MembershipRequestPending {
+		return errRequestClosed(request.Kind, request.Status)
+	}
+
+	accepted, err := s.requestRepo.Accept(ctx, request.ID, respondedBy, role)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to add band member")
+	}
+	if !accepted {
+		return errRequestClosed(request.Kind, "")
+	}
+
+	request.Role = role
+	respond(request, models.MembershipRequestAccepted, respondedBy)
+	return nil
+}
+
+func (s *BandMembershipService) close(ctx context.Context, request *models.BandMembershipRequest, status string, respondedBy uuid.UUID) error {
+	if request.Status != models.MembershipRequestPending {
+		return errRequestClosed(request.Kind, request.Status)
+	}
+
+	closed, err := s.requestRepo.Close(ctx, request.ID, status, respondedBy)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to update request")
+	}
+	if !closed {
+		return errRequestClosed(request.Kind, "")
+	}
+
+	respond(request, status, respondedBy)
+	return nil
+}
+
+func (s *BandMembershipService) listBandRequests(ctx context.Context, bandID, actorID uuid.UUID, kind string) ([]*models.BandMembershipRequest, error) {
+	if _, err := authorizeBand(ctx, s.bandRepo, bandID, actorID, models.BandPermissionManageMembers); err != nil {
+		return nil, err
+	}
+
+	requests, err := s.requestRepo.GetPendingByBand(ctx, bandID, kind, membershipRequestListLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to list requests")
+	}
+	return requests, nil
+}
+
+func (s *BandMembershipService) listUserRequests(ctx context.Context, userID uuid.UUID, kind string) ([]*models.BandMembershipRequest, error) {
+	requests, err := s.requestRepo.GetByUser(ctx, userID, kind, membershipRequestListLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to list requests")
+	}
+	return requests, nil
+}
+
+// userRequest loads a request of the given kind addressed to or made by the
+// user. Other users' requests are reported as not found.
+func (s *BandMembershipService) userRequest(ctx context.Context, userID, requestID uuid.UUID, kind string) (*models.BandMembershipRequest, error) {
+	request, err := s.requestRepo.GetByID(ctx, requestID)
+	if err != nil || request == nil || request.Kind != kind || request.UserID != userID {
+		return nil, requestNotFound(kind)
+	}
+	return request, nil
+}
+
+// bandRequest loads a request of the given kind for the band
+func (s *BandMembershipService) bandRequest(ctx context.Context, bandID, requestID uuid.UUID, kind string) (*models.BandMembershipRequest, error) {
+	request, err := s.requestRepo.GetByID(ctx, requestID)
+	if err != nil || request == nil || request.Kind != kind || request.BandID != bandID {
+		return nil, requestNotFound(kind)
+	}
+	return request, nil
+}
+
+// notifyInviter tells whoever sent an invitation how it was answered
+func (s *BandMembershipService) notifyInviter(ctx context.Context, request *models.BandMembershipRequest) {
+	if request.InvitedBy == nil {
+		return
+	}
+	inviter, err := s.userRepo.GetByID(ctx, *request.InvitedBy)
+	if err != nil || inviter == nil {
+		return
+	}
+	invitee, err := s.userRepo.GetByID(ctx, request.UserID)
+	if err != nil || invitee == nil {
+		return
+	}
+	band, err := s.bandRepo.GetByID(ctx, request.BandID)
+	if err != nil || band == nil {
+		return
+	}
+
+	s.notify(nil, &mail.Message{
+		To:      inviter.Email,
+		Subject: fmt.Sprintf("%s %s your invitation to %s", invitee.Username, request.Status, band.Name),
+		Body: fmt.Sprintf("Hi %s,\n\n%s has %s your invitation to join %s.\n",
+			inviter.Username, invitee.Username, request.Status, band.Name),
+	})
+}
+
+// notifyRequester tells a user how their join request was answered
+func (s *BandMembershipService) notifyRequester(ctx context.Context, request *models.BandMembershipRequest) {
+	requester, err := s.userRepo.GetByID(ctx, request.UserID)
+	if err != nil || requester == nil {
+		return
+	}
+	band, err := s.bandRepo.GetByID(ctx, request.BandID)
+	if err != nil || band == nil {
+		return
+	}
+
+	msg := &mail.Message{
+		To:      requester.Email,
+		Subject: fmt.Sprintf("Your request to join %s was declined", band.Name),
+		Body: fmt.Sprintf("Hi %s,\n\nYour request to join %s was declined.\n",
+			requester.Username, band.Name),
+	}
+	if request.Status == models.MembershipRequestAccepted {
+		msg.Subject = fmt.Sprintf("Welcome to %s", band.Name)
+		msg.Body = fmt.Sprintf("Hi %s,\n\nYour request to join %s was approved, and you are now %s of the band:\n\n%s/bands/%s\n",
+			requester.Username, band.Name, anArticle(request.Role), s.appURL, band.ID)
+	}
+	s.notify(nil, msg)
+}
+
+// notify mails a request's recipients without holding up the request. For
+// a new request, it is marked notified once any recipient has been told.
+func (s *BandMembershipService) notify(request *models.BandMembershipRequest, msgs ...*mail.Message) {
+	if len(msgs) == 0 {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+		defer cancel()
+
+		delivered := false
+		for _, msg := range msgs {
+			if err := s.mailer.Send(ctx, msg); err != nil {
+				s.logger.WithError(err).WithField("subject", msg.Subject).Error("Failed to send mail")
+				continue
+			}
+			delivered = true
+		}
+
+		if delivered && request != nil {
+			if err := s.requestRepo.MarkNotified(ctx, request.ID); err != nil {
+				s.logger.WithError(err).WithField("request_id", request.ID.String()).Error("Failed to mark band membership request notified")
+			}
+		}
+	}()
+}
+
+// membershipRole checks the role an invitation or join request is for,
+// which defaults to member. Ownership is handed over, not joined into.
+func membershipRole(role string) (string, error) {
+	switch role {
+	case "":
+		return models.BandRoleMember, nil
+	case models.BandRoleAdmin, models.BandRoleMember:
+		return role, nil
+	default:
+		return "", errInvalidMembershipRole
+	}
+}
+
+func validateMembershipMessage(message *string) error {
+	if message != nil && len(*message) > membershipMessageMaxLength {
+		return errMembershipMessageLong
+	}
+	return nil
+}
+
+// pendingRequestError explains why a user with an open request cannot be
+// sent another. forJoin is true when the user is asking to join themselves.
+func pendingRequestError(pending *models.BandMembershipRequest, forJoin bool) error {
+	switch {
+	case pending == nil:
+		return nil
+	case pending.Kind == models.MembershipRequestInvitation && forJoin:
+		return errAlreadyInvitedToBand
+	case pending.Kind == models.MembershipRequestInvitation:
+		return errInvitationPending
+	case forJoin:
+		return errJoinRequestPending
+	default:
+		return errUserAskedToJoin
+	}
+}
+
+func requestNotFound(kind string) error {
+	if kind == models.MembershipRequestJoin {
+		return errJoinRequestNotFound
+	}
+	return errInvitationNotFound
+}
+
+// errRequestClosed reports that a request can no longer be answered, and
+// why if its status is known
+func errRequestClosed(kind, status string) error {
+	noun := "invitation"
+	if kind == models.MembershipRequestJoin {
+		noun = "join request"
+	}
+
+	switch status {
+	case models.MembershipRequestExpired:
+		return errors.New(errors.ErrCodeConflict, fmt.Sprintf("This %s has expired", noun))
+	case models.MembershipRequestAccepted, models.MembershipRequestDeclined, models.MembershipRequestCancelled:
+		return errors.New(errors.ErrCodeConflict, fmt.Sprintf("This %s has already been %s", noun, status))
+	default:
+		return errors.New(errors.ErrCodeConflict, fmt.Sprintf("This %s is no longer pending", noun))
+	}
+}
+
+func respond(request *models.BandMembershipRequest, status string, respondedBy uuid.UUID) {
+	now := time.Now()
+	request.Status = status
+	request.RespondedBy = &respondedBy
+	request.RespondedAt = &now
+}
+
+// quotedMessage formats the note sent with a request for an email body
+func quotedMessage(message *string) string {
+	if message == nil || strings.TrimSpace(*message) == "" {
+		return ""
+	}
+	return fmt.Sprintf("\n\nThey wrote:\n\n%s", *message)
+}
+
+// anArticle prefixes a band role with its indefinite article
+func anArticle(role string) string {
+	if role == models.BandRoleAdmin {
+		return "an " + role
+	}
+	return "a " + role
+}
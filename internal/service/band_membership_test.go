@@ -0,0 +1,370 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// MockBandMembershipRepository keeps requests in memory. Notifications are
+// marked from a goroutine, so it is safe for concurrent use.
+type MockBandMembershipRepository struct {
+	mu       sync.Mutex
+	requests map[uuid.UUID]*models.BandMembershipRequest
+	joined   map[uuid.UUID]string
+}
+
+func NewMockBandMembershipRepository() *MockBandMembershipRepository {
+	return &MockBandMembershipRepository{
+		requests: make(map[uuid.UUID]*models.BandMembershipRequest),
+		joined:   make(map[uuid.UUID]string),
+	}
+}
+
+// effective returns a copy of a request with lapsed ones reported expired
+func (m *MockBandMembershipRepository) effective(request *models.BandMembershipRequest) *models.BandMembershipRequest {
+	copied := *request
+	if copied.Status == models.MembershipRequestPending && !time.Now().Before(copied.ExpiresAt) {
+		copied.Status = models.MembershipRequestExpired
+	}
+	return &copied
+}
+
+func (m *MockBandMembershipRepository) Create(ctx context.Context, request *models.BandMembershipRequest) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.requests {
+		if existing.BandID == request.BandID && existing.UserID == request.UserID && m.effective(existing).Status == models.MembershipRequestPending {
+			return false, nil
+		}
+	}
+	request.Status = models.MembershipRequestPending
+	request.CreatedAt = time.Now()
+	stored := *request
+	m.requests[request.ID] = &stored
+	return true, nil
+}
+
+func (m *MockBandMembershipRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.BandMembershipRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	request, exists := m.requests[id]
+	if !exists {
+		return nil, nil
+	}
+	return m.effective(request), nil
+}
+
+func (m *MockBandMembershipRepository) GetPending(ctx context.Context, bandID, userID uuid.UUID) (*models.BandMembershipRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, request := range m.requests {
+		if request.BandID == bandID && request.UserID == userID && m.effective(request).Status == models.MembershipRequestPending {
+			return m.effective(request), nil
+		}
+	}
+	return nil, nil
+}
+
+func (m *MockBandMembershipRepository) GetPendingByBand(ctx context.Context, bandID uuid.UUID, kind string, limit int) ([]*models.BandMembershipRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var requests []*models.BandMembershipRequest
+	for _, request := range m.requests {
+		if request.BandID == bandID && request.Kind == kind && m.effective(request).Status == models.MembershipRequestPending {
+			requests = append(requests, m.effective(request))
+		}
+	}
+	return requests, nil
+}
+
+func (m *MockBandMembershipRepository) GetByUser(ctx context.Context, userID uuid.UUID, kind string, limit int) ([]*models.BandMembershipRequest, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var requests []*models.BandMembershipRequest
+	for _, request := range m.requests {
+		if request.UserID == userID && request.Kind == kind {
+			requests = append(requests, m.effective(request))
+		}
+	}
+	return requests, nil
+}
+
+func (m *MockBandMembershipRepository) Accept(ctx context.Context, id, respondedBy uuid.UUID, role string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	request, exists := m.requests[id]
+	if !exists || m.effective(request).Status != models.MembershipRequestPending {
+		return false, nil
+	}
+	request.Status = models.MembershipRequestAccepted
+	request.Role = role
+	m.joined[request.UserID] = role
+	return true, nil
+}
+
+func (m *MockBandMembershipRepository) Close(ctx context.Context, id uuid.UUID, status string, respondedBy uuid.UUID) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	request, exists := m.requests[id]
+	if !exists || m.effective(request).Status != models.MembershipRequestPending {
+		return false, nil
+	}
+	request.Status = status
+	return true, nil
+}
+
+func (m *MockBandMembershipRepository) MarkNotified(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	m.requests[id].NotifiedAt = &now
+	return nil
+}
+
+func TestBandMembershipService(t *testing.T) {
+	ctx := context.Background()
+
+	type fixture struct {
+		service  *BandMembershipService
+		requests *MockBandMembershipRepository
+		bandRepo *MockBandRepository
+		userRepo *MockUserRepositoryForBand
+		mailer   *MockMailer
+		band     *models.Band
+		owner    *models.User
+		admin    *models.User
+		member   *models.User
+		outsider *models.User
+	}
+
+	setup := func() *fixture {
+		f := &fixture{
+			requests: NewMockBandMembershipRepository(),
+			bandRepo: NewMockBandRepository(),
+			userRepo: NewMockUserRepositoryForBand(),
+			mailer:   NewMockMailer(),
+			band:     &models.Band{ID: uuid.New(), Name: "The Testers"},
+		}
+		f.bandRepo.bandsByID[f.band.ID.String()] = f.band
+		f.bandRepo.memberRoles = make(map[uuid.UUID]string)
+
+		addUser := func(username, role string) *models.User {
+			user := &models.User{ID: uuid.New(), Username: username, Email: username + "@example.com"}
+			f.userRepo.usersByID[user.ID.String()] = user
+			if role != "" {
+				f.bandRepo.memberRoles[user.ID] = role
+				f.bandRepo.members = append(f.bandRepo.members, &models.BandMember{BandID: f.band.ID, UserID: user.ID, Role: role, User: user})
+			}
+			return user
+		}
+		f.owner = addUser("owner", models.BandRoleOwner)
+		f.admin = addUser("admin", models.BandRoleAdmin)
+		f.member = addUser("member", models.BandRoleMember)
+		f.outsider = addUser("outsider", "")
+
+		f.service = NewBandMembershipService(f.requests, f.bandRepo, f.userRepo, f.mailer, "https://app.example.com/", createTestLogger())
+		return f
+	}
+
+	t.Run("invite and accept", func(t *testing.T) {
+		f := setup()
+		message := "Fancy playing bass?"
+
+		invitation, err := f.service.Invite(ctx, f.band.ID, f.admin.ID, &models.InviteToBandRequest{UserID: f.outsider.ID, Message: &message})
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if invitation.Status != models.MembershipRequestPending || invitation.Role != models.BandRoleMember || *invitation.InvitedBy != f.admin.ID {
+			t.Errorf("Unexpected invitation: %+v", invitation)
+		}
+		if time.Until(invitation.ExpiresAt) < bandInvitationTTL-time.Minute {
+			t.Errorf("Expected the invitation to expire in %s, got %s", bandInvitationTTL, invitation.ExpiresAt)
+		}
+
+		msg := f.mailer.next(t)
+		if msg.To != f.outsider.Email || !strings.Contains(msg.Body, message) || !strings.Contains(msg.Body, "https://app.example.com/bands/"+f.band.ID.String()) {
+			t.Errorf("Unexpected invitation email: %+v", msg)
+		}
+
+		_, err = f.service.Invite(ctx, f.band.ID, f.admin.ID, &models.InviteToBandRequest{UserID: f.outsider.ID})
+		expectAppError(t, err, errors.ErrCodeConflict)
+		_, err = f.service.RequestToJoin(ctx, f.band.ID, f.outsider.ID, &models.JoinBandRequest{})
+		expectAppError(t, err, errors.ErrCodeConflict)
+
+		_, err = f.service.AcceptInvitation(ctx, f.member.ID, invitation.ID)
+		expectAppError(t, err, errors.ErrCodeNotFound)
+
+		accepted, err := f.service.AcceptInvitation(ctx, f.outsider.ID, invitation.ID)
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if accepted.Status != models.MembershipRequestAccepted || f.requests.joined[f.outsider.ID] != models.BandRoleMember {
+			t.Errorf("Expected the invitee to join as a member, got %+v", accepted)
+		}
+		if msg := f.mailer.next(t); msg.To != f.admin.Email || !strings.Contains(msg.Subject, "accepted") {
+			t.Errorf("Expected the inviter to be told, got %+v", msg)
+		}
+
+		_, err = f.service.DeclineInvitation(ctx, f.outsider.ID, invitation.ID)
+		expectAppError(t, err, errors.ErrCodeConflict)
+	})
+
+	t.Run("invitation rules", func(t *testing.T) {
+		f := setup()
+
+		_, err := f.service.Invite(ctx, f.band.ID, f.member.ID, &models.InviteToBandRequest{UserID: f.outsider.ID})
+		expectAppError(t, err, errors.ErrCodeForbidden)
+		_, err = f.service.Invite(ctx, f.band.ID, f.admin.ID, &models.InviteToBandRequest{UserID: f.outsider.ID, Role: models.BandRoleAdmin})
+		expectAppError(t, err, errors.ErrCodeForbidden)
+		_, err = f.service.Invite(ctx, f.band.ID, f.owner.ID, &models.InviteToBandRequest{UserID: f.outsider.ID, Role: models.BandRoleOwner})
+		expectAppError(t, err, errors.ErrCodeInvalidInput)
+		_, err = f.service.Invite(ctx, f.band.ID, f.owner.ID, &models.InviteToBandRequest{UserID: f.member.ID})
+		expectAppError(t, err, errors.ErrCodeConflict)
+		_, err = f.service.Invite(ctx, f.band.ID, f.owner.ID, &models.InviteToBandRequest{UserID: uuid.New()})
+		expectAppError(t, err, errors.ErrCodeNotFound)
+
+		invitation, err := f.service.Invite(ctx, f.band.ID, f.owner.ID, &models.InviteToBandRequest{UserID: f.outsider.ID, Role: models.BandRoleAdmin})
+		if err != nil {
+			t.Fatalf("Expected the owner to invite an admin, got: %v", err)
+		}
+		f.mailer.next(t)
+
+		if err := f.service.CancelInvitation(ctx, f.band.ID, f.admin.ID, invitation.ID); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		_, err = f.service.AcceptInvitation(ctx, f.outsider.ID, invitation.ID)
+		expectAppError(t, err, errors.ErrCodeConflict)
+		if _, joined := f.requests.joined[f.outsider.ID]; joined {
+			t.Error("Expected a cancelled invitation not to add the user")
+		}
+	})
+
+	t.Run("expired invitation", func(t *testing.T) {
+		f := setup()
+
+		invitation, err := f.service.Invite(ctx, f.band.ID, f.admin.ID, &models.InviteToBandRequest{UserID: f.outsider.ID})
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		f.mailer.next(t)
+		f.requests.mu.Lock()
+		f.requests.requests[invitation.ID].ExpiresAt = time.Now().Add(-time.Minute)
+		f.requests.mu.Unlock()
+
+		_, err = f.service.AcceptInvitation(ctx, f.outsider.ID, invitation.ID)
+		expectAppError(t, err, errors.ErrCodeConflict)
+		if err == nil || !strings.Contains(err.Error(), "expired") {
+			t.Errorf("Expected the invitation to have expired, got: %v", err)
+		}
+
+		invitations, _ := f.service.ListBandInvitations(ctx, f.band.ID, f.admin.ID)
+		if len(invitations) != 0 {
+			t.Error("Expected an expired invitation not to be listed as pending")
+		}
+		if _, err := f.service.Invite(ctx, f.band.ID, f.admin.ID, &models.InviteToBandRequest{UserID: f.outsider.ID}); err != nil {
+			t.Errorf("Expected an expired invitation not to block a new one, got: %v", err)
+		}
+	})
+
+	t.Run("request to join and approve", func(t *testing.T) {
+		f := setup()
+
+		request, err := f.service.RequestToJoin(ctx, f.band.ID, f.outsider.ID, &models.JoinBandRequest{Role: models.BandRoleAdmin})
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if request.Kind != models.MembershipRequestJoin || request.InvitedBy != nil {
+			t.Errorf("Unexpected join request: %+v", request)
+		}
+
+		notified := map[string]bool{}
+		for i := 0; i < 2; i++ {
+			notified[f.mailer.next(t).To] = true
+		}
+		if !notified[f.owner.Email] || !notified[f.admin.Email] {
+			t.Errorf("Expected the band's admins to be told, got %v", notified)
+		}
+
+		_, err = f.service.RequestToJoin(ctx, f.band.ID, f.outsider.ID, &models.JoinBandRequest{})
+		expectAppError(t, err, errors.ErrCodeConflict)
+		_, err = f.service.Invite(ctx, f.band.ID, f.admin.ID, &models.InviteToBandRequest{UserID: f.outsider.ID})
+		expectAppError(t, err, errors.ErrCodeConflict)
+
+		requests, err := f.service.ListBandJoinRequests(ctx, f.band.ID, f.admin.ID)
+		if err != nil || len(requests) != 1 {
+			t.Fatalf("Expected one pending join request, got %d (%v)", len(requests), err)
+		}
+		_, err = f.service.ListBandJoinRequests(ctx, f.band.ID, f.member.ID)
+		expectAppError(t, err, errors.ErrCodeForbidden)
+
+		// An admin cannot make someone an admin, but can let them in as a
+		// member; requests are only found through their own band
+		_, err = f.service.ApproveJoinRequest(ctx, f.band.ID, f.admin.ID, request.ID, "")
+		expectAppError(t, err, errors.ErrCodeForbidden)
+		_, err = f.service.ApproveJoinRequest(ctx, uuid.New(), f.admin.ID, request.ID, models.BandRoleMember)
+		expectAppError(t, err, errors.ErrCodeNotFound)
+
+		approved, err := f.service.ApproveJoinRequest(ctx, f.band.ID, f.admin.ID, request.ID, models.BandRoleMember)
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if approved.Status != models.MembershipRequestAccepted || f.requests.joined[f.outsider.ID] != models.BandRoleMember {
+			t.Errorf("Expected the user to join as a member, got %+v", approved)
+		}
+		if msg := f.mailer.next(t); msg.To != f.outsider.Email || !strings.Contains(msg.Subject, "Welcome") {
+			t.Errorf("Expected the user to be told, got %+v", msg)
+		}
+	})
+
+	t.Run("reject and cancel join requests", func(t *testing.T) {
+		f := setup()
+
+		_, err := f.service.RequestToJoin(ctx, f.band.ID, f.member.ID, &models.JoinBandRequest{})
+		expectAppError(t, err, errors.ErrCodeConflict)
+		_, err = f.service.RequestToJoin(ctx, uuid.New(), f.outsider.ID, &models.JoinBandRequest{})
+		expectAppError(t, err, errors.ErrCodeNotFound)
+		long := strings.Repeat("a", membershipMessageMaxLength+1)
+		_, err = f.service.RequestToJoin(ctx, f.band.ID, f.outsider.ID, &models.JoinBandRequest{Message: &long})
+		expectAppError(t, err, errors.ErrCodeInvalidInput)
+
+		request, err := f.service.RequestToJoin(ctx, f.band.ID, f.outsider.ID, &models.JoinBandRequest{})
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		f.mailer.next(t)
+		f.mailer.next(t)
+
+		rejected, err := f.service.RejectJoinRequest(ctx, f.band.ID, f.admin.ID, request.ID)
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if rejected.Status != models.MembershipRequestDeclined {
+			t.Errorf("Expected the request to be declined, got %q", rejected.Status)
+		}
+		if msg := f.mailer.next(t); msg.To != f.outsider.Email || !strings.Contains(msg.Subject, "declined") {
+			t.Errorf("Expected the user to be told, got %+v", msg)
+		}
+
+		request, err = f.service.RequestToJoin(ctx, f.band.ID, f.outsider.ID, &models.JoinBandRequest{})
+		if err != nil {
+			t.Fatalf("Expected a rejected user to be able to ask again, got: %v", err)
+		}
+		expectAppError(t, f.service.CancelJoinRequest(ctx, f.member.ID, request.ID), errors.ErrCodeNotFound)
+		if err := f.service.CancelJoinRequest(ctx, f.outsider.ID, request.ID); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+
+		requests, _ := f.service.ListJoinRequests(ctx, f.outsider.ID)
+		if len(requests) != 2 {
+			t.Errorf("Expected both join requests in the user's history, got %d", len(requests))
+		}
+	})
+}
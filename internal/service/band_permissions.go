@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/models"
+
+	"github.com/google/uuid"
+)
+
+var (
+	errBandMemberNotFound = errors.New(errors.ErrCodeNotFound, "Band member not found")
+	errInvalidBandRole    = errors.New(errors.ErrCodeInvalidInput, "Unknown band role")
+	errOwnBandRole        = errors.New(errors.ErrCodeBusinessRule, "You cannot change your own band role")
+	errRemoveSelf         = errors.New(errors.ErrCodeBusinessRule, "Leave the band instead of removing yourself")
+	errOwnerCannotLeave   = errors.New(errors.ErrCodeBusinessRule, "Hand the band over to another member before leaving")
+	errBandOwnerOnly      = errors.New(errors.ErrCodeForbidden, "Only the band owner can hand the band over")
+	errBandNotOutranked   = errors.New(errors.ErrCodeForbidden, "You can only manage members with a lower band role than yours")
+)
+
+// bandPermissionErrors is what a user is told when they lack a permission
+var bandPermissionErrors = map[models.BandPermission]*errors.AppError{
+	models.BandPermissionEditProfile:   errors.New(errors.ErrCodeForbidden, "Only band admins can edit the band"),
+	models.BandPermissionManageMembers: errors.New(errors.ErrCodeForbidden, "Only band admins can manage members"),
+	models.BandPermissionPostAsBand:    errors.New(errors.ErrCodeForbidden, "Only band admins and designated members can post as the band"),
+	models.BandPermissionDeleteBand:    errors.New(errors.ErrCodeForbidden, "Only the band owner can delete the band"),
+}
+
+// BandMemberGetter loads a user's membership of a band; implemented by
+// repository.BandRepository
+type BandMemberGetter interface {
+	GetMember(ctx context.Context, bandID, userID uuid.UUID) (*models.BandMember, error)
+}
+
+// authorizeBand is the band permission policy every service goes through. It
+// returns the user's membership if they have the permission in the band, and
+// a 403 otherwise.
+func authorizeBand(ctx context.Context, members BandMemberGetter, bandID, userID uuid.UUID, permission models.BandPermission) (*models.BandMember, error) {
+	member, err := members.GetMember(ctx, bandID, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to check band permissions")
+	}
+	if member == nil || !member.Can(permission) {
+		if permErr, ok := bandPermissionErrors[permission]; ok {
+			return nil, permErr
+		}
+		return nil, errors.New(errors.ErrCodeForbidden, "You do not have permission to do this")
+	}
+	return member, nil
+}
+
+// outranksMember reports whether actor's band role is higher than member's,
+// which managing another member requires
+func outranksMember(actor, member *models.BandMember) bool {
+	return models.BandRoleRank(actor.Role) > models.BandRoleRank(member.Role)
+}
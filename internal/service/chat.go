@@ -0,0 +1,175 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/models"
+	"musicapp/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// MessageRepository interface for band chat message operations
+type MessageRepository interface {
+	Create(ctx context.Context, message *models.Message) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Message, error)
+	GetByBandID(ctx context.Context, bandID uuid.UUID, before *uuid.UUID, limit int) ([]*models.Message, error)
+}
+
+// BandRepositoryForChat interface for band operations needed by ChatService
+type BandRepositoryForChat interface {
+	IsMember(ctx context.Context, bandID, userID uuid.UUID) (bool, error)
+}
+
+// ChatPubSub fans chat messages out to every API instance serving a band room.
+// *cache.Cache satisfies this interface.
+type ChatPubSub interface {
+	PublishBandMessage(ctx context.Context, bandID string, payload []byte) error
+	SubscribeBandMessages(ctx context.Context, bandID string) (<-chan []byte, func() error, error)
+}
+
+// MaxMessageLength is the longest chat message accepted
+const MaxMessageLength = 2000
+
+type ChatService struct {
+	messageRepo MessageRepository
+	bandRepo    BandRepositoryForChat
+	pubsub      ChatPubSub
+}
+
+func NewChatService(messageRepo MessageRepository, bandRepo BandRepositoryForChat, pubsub ChatPubSub) *ChatService {
+	return &ChatService{
+		messageRepo: messageRepo,
+		bandRepo:    bandRepo,
+		pubsub:      pubsub,
+	}
+}
+
+// GetMessages returns a page of a band's chat history, newest first
+func (s *ChatService) GetMessages(ctx context.Context, bandID, userID uuid.UUID, before *uuid.UUID, limit int) ([]*models.Message, error) {
+	if err := s.requireMember(ctx, bandID, userID); err != nil {
+		return nil, err
+	}
+
+	messages, err := s.messageRepo.GetByBandID(ctx, bandID, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// SendMessage stores a chat message and publishes it to the band room
+func (s *ChatService) SendMessage(ctx context.Context, bandID, userID uuid.UUID, req *models.CreateMessageRequest) (*models.Message, error) {
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return nil, errors.New(errors.ErrCodeValidationFailed, "message content is required")
+	}
+
+	if len(content) > MaxMessageLength {
+		return nil, errors.New(errors.ErrCodeValidationFailed, fmt.Sprintf("message content too long (max %d characters)", MaxMessageLength))
+	}
+
+	if err := s.requireMember(ctx, bandID, userID); err != nil {
+		return nil, err
+	}
+
+	message := &models.Message{
+		ID:      uuid.New(),
+		BandID:  bandID,
+		UserID:  userID,
+		Content: content,
+	}
+
+	if err := s.messageRepo.Create(ctx, message); err != nil {
+		return nil, fmt.Errorf("failed to create message: %w", err)
+	}
+
+	// Re-read to pick up the stored timestamp and sender username
+	created, err := s.messageRepo.GetByID(ctx, message.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get created message: %w", err)
+	}
+
+	// The message is already stored, so a failed publish only delays delivery
+	// until clients reload history
+	if payload, err := json.Marshal(created.ToResponse()); err == nil {
+		_ = s.pubsub.PublishBandMessage(ctx, bandID.String(), payload)
+	}
+
+	return created, nil
+}
+
+// JoinRoom subscribes a band member to live chat messages. The returned
+// function must be called to release the subscription.
+func (s *ChatService) JoinRoom(ctx context.Context, bandID, userID uuid.UUID) (<-chan []byte, func() error, error) {
+	if err := s.requireMember(ctx, bandID, userID); err != nil {
+		return nil, nil, err
+	}
+
+	messages, closeFn, err := s.pubsub.SubscribeBandMessages(ctx, bandID.String())
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeRedisError, "Failed to join chat room")
+	}
+
+	return messages, closeFn, nil
+}
+
+// CheckMembership re-checks that a user is still a band member, for
+// connections that stay in a room after JoinRoom
+func (s *ChatService) CheckMembership(ctx context.Context, bandID, userID uuid.UUID) error {
+	return s.requireMember(ctx, bandID, userID)
+}
+
+func (s *ChatService) requireMember(ctx context.Context, bandID, userID uuid.UUID) error {
+	isMember, err := s.bandRepo.IsMember(ctx, bandID, userID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to check membership")
+	}
+
+	if !isMember {
+		return errors.New(errors.ErrCodeForbidden, "Only band members can access this chat")
+	}
+
+	return nil
+}
+
+// Adapter structs to bridge existing concrete types with new interfaces
+
+// MessageRepositoryAdapter adapts *repository.MessageRepository to MessageRepository interface
+type MessageRepositoryAdapter struct {
+	repo *repository.MessageRepository
+}
+
+func NewMessageRepositoryAdapter(repo *repository.MessageRepository) MessageRepository {
+	return &MessageRepositoryAdapter{repo}
+}
+
+func (a *MessageRepositoryAdapter) Create(ctx context.Context, message *models.Message) error {
+	return a.repo.Create(ctx, message)
+}
+
+func (a *MessageRepositoryAdapter) GetByID(ctx context.Context, id uuid.UUID) (*models.Message, error) {
+	return a.repo.GetByID(ctx, id)
+}
+
+func (a *MessageRepositoryAdapter) GetByBandID(ctx context.Context, bandID uuid.UUID, before *uuid.UUID, limit int) ([]*models.Message, error) {
+	return a.repo.GetByBandID(ctx, bandID, before, limit)
+}
+
+// BandRepositoryForChatAdapter adapts *repository.BandRepository to BandRepositoryForChat interface
+type BandRepositoryForChatAdapter struct {
+	repo *repository.BandRepository
+}
+
+func NewBandRepositoryForChatAdapter(repo *repository.BandRepository) BandRepositoryForChat {
+	return &BandRepositoryForChatAdapter{repo}
+}
+
+func (a *BandRepositoryForChatAdapter) IsMember(ctx context.Context, bandID, userID uuid.UUID) (bool, error) {
+	return a.repo.IsMember(ctx, bandID, userID)
+}
@@ -0,0 +1,300 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/models"
+
+	"github.com/google/uuid"
+)
+
+// Mock implementations for ChatService testing
+
+type MockMessageRepository struct {
+	messagesByID   map[uuid.UUID]*models.Message
+	bandMessages   map[uuid.UUID][]*models.Message
+	createError    error
+	getByBandError error
+	lastBefore     *uuid.UUID
+}
+
+func NewMockMessageRepository() *MockMessageRepository {
+	return &MockMessageRepository{
+		messagesByID: make(map[uuid.UUID]*models.Message),
+		bandMessages: make(map[uuid.UUID][]*models.Message),
+	}
+}
+
+func (m *MockMessageRepository) Create(ctx context.Context, message *models.Message) error {
+	if m.createError != nil {
+		return m.createError
+	}
+	message.CreatedAt = time.Now()
+	m.messagesByID[message.ID] = message
+	m.bandMessages[message.BandID] = append(m.bandMessages[message.BandID], message)
+	return nil
+}
+
+func (m *MockMessageRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Message, error) {
+	message, exists := m.messagesByID[id]
+	if !exists {
+		return nil, fmt.Errorf("message not found")
+	}
+	return message, nil
+}
+
+func (m *MockMessageRepository) GetByBandID(ctx context.Context, bandID uuid.UUID, before *uuid.UUID, limit int) ([]*models.Message, error) {
+	if m.getByBandError != nil {
+		return nil, m.getByBandError
+	}
+	m.lastBefore = before
+	return m.bandMessages[bandID], nil
+}
+
+type MockBandRepositoryForChat struct {
+	members       map[uuid.UUID]bool
+	isMemberError error
+}
+
+func NewMockBandRepositoryForChat() *MockBandRepositoryForChat {
+	return &MockBandRepositoryForChat{members: make(map[uuid.UUID]bool)}
+}
+
+func (m *MockBandRepositoryForChat) IsMember(ctx context.Context, bandID, userID uuid.UUID) (bool, error) {
+	if m.isMemberError != nil {
+		return false, m.isMemberError
+	}
+	return m.members[userID], nil
+}
+
+type MockChatPubSub struct {
+	published      [][]byte
+	publishError   error
+	subscribeError error
+	subscribed     []string
+}
+
+func (m *MockChatPubSub) PublishBandMessage(ctx context.Context, bandID string, payload []byte) error {
+	if m.publishError != nil {
+		return m.publishError
+	}
+	m.published = append(m.published, payload)
+	return nil
+}
+
+func (m *MockChatPubSub) SubscribeBandMessages(ctx context.Context, bandID string) (<-chan []byte, func() error, error) {
+	if m.subscribeError != nil {
+		return nil, nil, m.subscribeError
+	}
+	m.subscribed = append(m.subscribed, bandID)
+	messages := make(chan []byte)
+	return messages, func() error { close(messages); return nil }, nil
+}
+
+func TestChatService_SendMessage(t *testing.T) {
+	bandID := uuid.New()
+	memberID := uuid.New()
+
+	tests := []struct {
+		name          string
+		userID        uuid.UUID
+		req           *models.CreateMessageRequest
+		setupMocks    func(*MockMessageRepository, *MockChatPubSub)
+		expectError   bool
+		expectStatus  int
+		errorContains string
+		expectPublish bool
+	}{
+		{
+			name:          "member sends message",
+			userID:        memberID,
+			req:           &models.CreateMessageRequest{Content: "  Rehearsal at 7  "},
+			setupMocks:    func(messageRepo *MockMessageRepository, pubsub *MockChatPubSub) {},
+			expectPublish: true,
+		},
+		{
+			name:          "non-member is forbidden",
+			userID:        uuid.New(),
+			req:           &models.CreateMessageRequest{Content: "Hello"},
+			setupMocks:    func(messageRepo *MockMessageRepository, pubsub *MockChatPubSub) {},
+			expectError:   true,
+			expectStatus:  http.StatusForbidden,
+			errorContains: "Only band members",
+		},
+		{
+			name:          "blank content",
+			userID:        memberID,
+			req:           &models.CreateMessageRequest{Content: "   "},
+			setupMocks:    func(messageRepo *MockMessageRepository, pubsub *MockChatPubSub) {},
+			expectError:   true,
+			expectStatus:  http.StatusBadRequest,
+			errorContains: "message content is required",
+		},
+		{
+			name:          "content too long",
+			userID:        memberID,
+			req:           &models.CreateMessageRequest{Content: strings.Repeat("a", MaxMessageLength+1)},
+			setupMocks:    func(messageRepo *MockMessageRepository, pubsub *MockChatPubSub) {},
+			expectError:   true,
+			expectStatus:  http.StatusBadRequest,
+			errorContains: "message content too long",
+		},
+		{
+			name:   "repository error",
+			userID: memberID,
+			req:    &models.CreateMessageRequest{Content: "Hello"},
+			setupMocks: func(messageRepo *MockMessageRepository, pubsub *MockChatPubSub) {
+				messageRepo.createError = fmt.Errorf("database error")
+			},
+			expectError:   true,
+			errorContains: "failed to create message",
+		},
+		{
+			name:   "publish failure does not fail the send",
+			userID: memberID,
+			req:    &models.CreateMessageRequest{Content: "Hello"},
+			setupMocks: func(messageRepo *MockMessageRepository, pubsub *MockChatPubSub) {
+				pubsub.publishError = fmt.Errorf("redis down")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messageRepo := NewMockMessageRepository()
+			bandRepo := NewMockBandRepositoryForChat()
+			bandRepo.members[memberID] = true
+			pubsub := &MockChatPubSub{}
+			tt.setupMocks(messageRepo, pubsub)
+
+			service := NewChatService(messageRepo, bandRepo, pubsub)
+			message, err := service.SendMessage(context.Background(), bandID, tt.userID, tt.req)
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error containing '%s', got '%s'", tt.errorContains, err.Error())
+				}
+				if tt.expectStatus != 0 {
+					appErr := errors.GetAppError(err)
+					if appErr == nil || appErr.HTTPStatus != tt.expectStatus {
+						t.Errorf("Expected status %d, got %+v", tt.expectStatus, appErr)
+					}
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if message.Content != strings.TrimSpace(tt.req.Content) {
+				t.Errorf("Expected trimmed content, got '%s'", message.Content)
+			}
+
+			if tt.expectPublish {
+				if len(pubsub.published) != 1 {
+					t.Fatalf("Expected 1 published message, got %d", len(pubsub.published))
+				}
+				var published models.MessageResponse
+				if err := json.Unmarshal(pubsub.published[0], &published); err != nil {
+					t.Fatalf("Published payload is not valid JSON: %v", err)
+				}
+				if published.ID != message.ID {
+					t.Errorf("Expected published ID %v, got %v", message.ID, published.ID)
+				}
+			}
+		})
+	}
+}
+
+func TestChatService_GetMessages(t *testing.T) {
+	bandID := uuid.New()
+	memberID := uuid.New()
+	before := uuid.New()
+
+	messageRepo := NewMockMessageRepository()
+	messageRepo.bandMessages[bandID] = []*models.Message{{ID: uuid.New(), BandID: bandID}}
+	bandRepo := NewMockBandRepositoryForChat()
+	bandRepo.members[memberID] = true
+	service := NewChatService(messageRepo, bandRepo, &MockChatPubSub{})
+
+	messages, err := service.GetMessages(context.Background(), bandID, memberID, &before, 20)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(messages) != 1 {
+		t.Errorf("Expected 1 message, got %d", len(messages))
+	}
+	if messageRepo.lastBefore == nil || *messageRepo.lastBefore != before {
+		t.Error("Expected before cursor to be passed to repository")
+	}
+
+	if _, err := service.GetMessages(context.Background(), bandID, uuid.New(), nil, 20); err == nil {
+		t.Error("Expected non-member to be rejected")
+	}
+
+	bandRepo.isMemberError = fmt.Errorf("database error")
+	if _, err := service.GetMessages(context.Background(), bandID, memberID, nil, 20); err == nil {
+		t.Error("Expected membership lookup error to be returned")
+	}
+}
+
+func TestChatService_JoinRoom(t *testing.T) {
+	bandID := uuid.New()
+	memberID := uuid.New()
+
+	bandRepo := NewMockBandRepositoryForChat()
+	bandRepo.members[memberID] = true
+	pubsub := &MockChatPubSub{}
+	service := NewChatService(NewMockMessageRepository(), bandRepo, pubsub)
+
+	messages, closeFn, err := service.JoinRoom(context.Background(), bandID, memberID)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if messages == nil || closeFn == nil {
+		t.Fatal("Expected subscription channel and close function")
+	}
+	if len(pubsub.subscribed) != 1 || pubsub.subscribed[0] != bandID.String() {
+		t.Errorf("Expected subscription to band %s, got %v", bandID, pubsub.subscribed)
+	}
+	closeFn()
+
+	if _, _, err := service.JoinRoom(context.Background(), bandID, uuid.New()); err == nil {
+		t.Error("Expected non-member to be rejected")
+	}
+
+	pubsub.subscribeError = fmt.Errorf("redis down")
+	if _, _, err := service.JoinRoom(context.Background(), bandID, memberID); err == nil {
+		t.Error("Expected subscribe error to be returned")
+	}
+}
+
+func TestChatService_CheckMembership(t *testing.T) {
+	bandID := uuid.New()
+	memberID := uuid.New()
+
+	bandRepo := NewMockBandRepositoryForChat()
+	bandRepo.members[memberID] = true
+	service := NewChatService(NewMockMessageRepository(), bandRepo, &MockChatPubSub{})
+
+	if err := service.CheckMembership(context.Background(), bandID, memberID); err != nil {
+		t.Fatalf("Expected member to pass, got: %v", err)
+	}
+
+	// Removed from the band while connected
+	delete(bandRepo.members, memberID)
+	err := service.CheckMembership(context.Background(), bandID, memberID)
+	if appErr := errors.GetAppError(err); appErr == nil || appErr.Code != errors.ErrCodeForbidden {
+		t.Errorf("Expected a forbidden error once removed, got: %v", err)
+	}
+}
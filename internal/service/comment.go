@@ -0,0 +1,327 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/models"
+	"musicapp/internal/pagination"
+	"musicapp/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// CommentRepository interface for comment data operations
+type CommentRepository interface {
+	Create(ctx context.Context, comment *models.Comment) error
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Comment, error)
+	GetByPostID(ctx context.Context, postID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Comment, error)
+	GetReplies(ctx context.Context, parentID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Comment, error)
+	GetRepliesForParents(ctx context.Context, parentIDs []uuid.UUID, perParent int) ([]*models.Comment, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	LikeComment(ctx context.Context, userID, commentID uuid.UUID) error
+	UnlikeComment(ctx context.Context, userID, commentID uuid.UUID) error
+	GetLikedCommentIDs(ctx context.Context, userID uuid.UUID, commentIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+}
+
+// PostRepositoryForComment interface for post operations needed by CommentService
+type PostRepositoryForComment interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.Post, error)
+}
+
+const (
+	// DefaultCommentMaxDepth is used when no positive max depth is configured
+	DefaultCommentMaxDepth = 3
+
+	// replyPreviewLimit is how many replies are embedded under each comment
+	// when a thread is returned; the rest are fetched via GetCommentReplies
+	replyPreviewLimit = 3
+)
+
+type CommentService struct {
+	commentRepo CommentRepository
+	postRepo    PostRepositoryForComment
+	bandRepo    BandMemberGetter
+	maxDepth    int
+}
+
+func NewCommentService(commentRepo CommentRepository, postRepo PostRepositoryForComment, bandRepo BandMemberGetter, maxDepth int) *CommentService {
+	if maxDepth <= 0 {
+		maxDepth = DefaultCommentMaxDepth
+	}
+
+	return &CommentService{
+		commentRepo: commentRepo,
+		postRepo:    postRepo,
+		bandRepo:    bandRepo,
+		maxDepth:    maxDepth,
+	}
+}
+
+// CreateComment adds a comment to a post
+func (s *CommentService) CreateComment(ctx context.Context, postID, userID uuid.UUID, req *models.CreateCommentRequest) (*models.Comment, error) {
+	if req.Content == "" {
+		return nil, fmt.Errorf("comment content is required")
+	}
+
+	if len(req.Content) > 1000 {
+		return nil, fmt.Errorf("comment content too long (max 1000 characters)")
+	}
+
+	// Check if post exists
+	if _, err := s.postRepo.GetByID(ctx, postID); err != nil {
+		return nil, fmt.Errorf("post not found: %w", err)
+	}
+
+	comment := &models.Comment{
+		ID:      uuid.New(),
+		PostID:  postID,
+		UserID:  userID,
+		Content: req.Content,
+	}
+
+	// Replies inherit their position in the thread from the parent
+	if req.ParentCommentID != nil {
+		parent, err := s.commentRepo.GetByID(ctx, *req.ParentCommentID)
+		if err != nil {
+			return nil, fmt.Errorf("parent comment not found: %w", err)
+		}
+
+		if parent.PostID != postID {
+			return nil, fmt.Errorf("parent comment belongs to a different post")
+		}
+
+		if parent.Depth+1 > s.maxDepth {
+			return nil, fmt.Errorf("maximum reply depth of %d reached", s.maxDepth)
+		}
+
+		comment.ParentCommentID = &parent.ID
+		comment.Depth = parent.Depth + 1
+	}
+
+	if err := s.commentRepo.Create(ctx, comment); err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+
+	// Get the created comment with its timestamp
+	createdComment, err := s.commentRepo.GetByID(ctx, comment.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve created comment: %w", err)
+	}
+
+	return createdComment, nil
+}
+
+// GetPostComments retrieves top-level comments on a post, oldest first, with
+// the first replies of each thread embedded
+func (s *CommentService) GetPostComments(ctx context.Context, postID uuid.UUID, limit, offset int, after *pagination.Cursor, currentUserID *uuid.UUID) ([]*models.Comment, error) {
+	if limit <= 0 || limit > 100 {
+		return nil, fmt.Errorf("invalid limit: %d (must be 1-100)", limit)
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset: %d (must be >= 0)", offset)
+	}
+
+	// Check if post exists
+	if _, err := s.postRepo.GetByID(ctx, postID); err != nil {
+		return nil, fmt.Errorf("post not found: %w", err)
+	}
+
+	comments, err := s.commentRepo.GetByPostID(ctx, postID, limit, offset, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve comments: %w", err)
+	}
+
+	if err := s.attachReplies(ctx, comments); err != nil {
+		return nil, fmt.Errorf("failed to retrieve replies: %w", err)
+	}
+
+	if currentUserID != nil {
+		s.applyLikeState(ctx, *currentUserID, comments)
+	}
+
+	return comments, nil
+}
+
+// GetCommentReplies retrieves a page of direct replies to a comment, oldest first
+func (s *CommentService) GetCommentReplies(ctx context.Context, commentID uuid.UUID, limit, offset int, after *pagination.Cursor, currentUserID *uuid.UUID) ([]*models.Comment, error) {
+	if limit <= 0 || limit > 100 {
+		return nil, fmt.Errorf("invalid limit: %d (must be 1-100)", limit)
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset: %d (must be >= 0)", offset)
+	}
+
+	// Check if comment exists
+	if _, err := s.commentRepo.GetByID(ctx, commentID); err != nil {
+		return nil, fmt.Errorf("comment not found: %w", err)
+	}
+
+	replies, err := s.commentRepo.GetReplies(ctx, commentID, limit, offset, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve replies: %w", err)
+	}
+
+	if err := s.attachReplies(ctx, replies); err != nil {
+		return nil, fmt.Errorf("failed to retrieve replies: %w", err)
+	}
+
+	if currentUserID != nil {
+		s.applyLikeState(ctx, *currentUserID, replies)
+	}
+
+	return replies, nil
+}
+
+// DeleteComment deletes a comment. The comment author and whoever manages
+// the post it was left on are both allowed to delete it.
+func (s *CommentService) DeleteComment(ctx context.Context, commentID, userID uuid.UUID) error {
+	comment, err := s.commentRepo.GetByID(ctx, commentID)
+	if err != nil {
+		return fmt.Errorf("comment not found: %w", err)
+	}
+
+	if comment.UserID != userID {
+		post, err := s.postRepo.GetByID(ctx, comment.PostID)
+		if err != nil {
+			return fmt.Errorf("post not found: %w", err)
+		}
+
+		if err := s.authorizeModeration(ctx, post, userID); err != nil {
+			return err
+		}
+	}
+
+	if err := s.commentRepo.Delete(ctx, commentID); err != nil {
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+
+	return nil
+}
+
+// authorizeModeration checks that the user may delete other people's
+// comments on a post: its author, or for a band post anyone the band policy
+// lets post as the band
+func (s *CommentService) authorizeModeration(ctx context.Context, post *models.Post, userID uuid.UUID) error {
+	if post.BandID != nil {
+		_, err := authorizeBand(ctx, s.bandRepo, *post.BandID, userID, models.BandPermissionPostAsBand)
+		if appErr := errors.GetAppError(err); appErr != nil && appErr.Code == errors.ErrCodeForbidden {
+			return fmt.Errorf("you can only delete your own comments or comments on your posts")
+		}
+		return err
+	}
+
+	if post.UserID == nil || *post.UserID != userID {
+		return fmt.Errorf("you can only delete your own comments or comments on your posts")
+	}
+	return nil
+}
+
+// LikeComment likes a comment
+func (s *CommentService) LikeComment(ctx context.Context, userID, commentID uuid.UUID) error {
+	// Check if comment exists
+	if _, err := s.commentRepo.GetByID(ctx, commentID); err != nil {
+		return fmt.Errorf("comment not found: %w", err)
+	}
+
+	if err := s.commentRepo.LikeComment(ctx, userID, commentID); err != nil {
+		return fmt.Errorf("failed to like comment: %w", err)
+	}
+
+	return nil
+}
+
+// UnlikeComment unlikes a comment
+func (s *CommentService) UnlikeComment(ctx context.Context, userID, commentID uuid.UUID) error {
+	if err := s.commentRepo.UnlikeComment(ctx, userID, commentID); err != nil {
+		return fmt.Errorf("failed to unlike comment: %w", err)
+	}
+
+	return nil
+}
+
+// attachReplies embeds a preview of replies under each comment, one level per
+// query, until the configured max depth is reached
+func (s *CommentService) attachReplies(ctx context.Context, comments []*models.Comment) error {
+	level := comments
+	for len(level) > 0 {
+		byID := make(map[uuid.UUID]*models.Comment)
+		var parentIDs []uuid.UUID
+		for _, comment := range level {
+			if comment.RepliesCount > 0 && comment.Depth < s.maxDepth {
+				byID[comment.ID] = comment
+				parentIDs = append(parentIDs, comment.ID)
+			}
+		}
+
+		if len(parentIDs) == 0 {
+			return nil
+		}
+
+		replies, err := s.commentRepo.GetRepliesForParents(ctx, parentIDs, replyPreviewLimit)
+		if err != nil {
+			return err
+		}
+
+		for _, reply := range replies {
+			if reply.ParentCommentID == nil {
+				continue
+			}
+			if parent, ok := byID[*reply.ParentCommentID]; ok {
+				parent.Replies = append(parent.Replies, reply)
+			}
+		}
+
+		level = replies
+	}
+
+	return nil
+}
+
+// applyLikeState marks which comments in the tree the current user has liked
+func (s *CommentService) applyLikeState(ctx context.Context, userID uuid.UUID, comments []*models.Comment) {
+	var all []*models.Comment
+	var collect func([]*models.Comment)
+	collect = func(list []*models.Comment) {
+		for _, comment := range list {
+			all = append(all, comment)
+			collect(comment.Replies)
+		}
+	}
+	collect(comments)
+
+	ids := make([]uuid.UUID, 0, len(all))
+	for _, comment := range all {
+		ids = append(ids, comment.ID)
+	}
+
+	liked, err := s.commentRepo.GetLikedCommentIDs(ctx, userID, ids)
+	if err != nil {
+		return
+	}
+
+	for _, comment := range all {
+		comment.IsLiked = liked[comment.ID]
+	}
+}
+
+// Adapter structs to bridge existing concrete types with new interfaces
+
+// CommentRepositoryAdapter adapts *repository.CommentRepository to CommentRepository interface
+type CommentRepositoryAdapter struct {
+	*repository.CommentRepository
+}
+
+func NewCommentRepositoryAdapter(repo *repository.CommentRepository) CommentRepository {
+	return &CommentRepositoryAdapter{repo}
+}
+
+// PostRepositoryForCommentAdapter adapts *repository.PostRepository to PostRepositoryForComment interface
+type PostRepositoryForCommentAdapter struct {
+	*repository.PostRepository
+}
+
+func NewPostRepositoryForCommentAdapter(repo *repository.PostRepository) PostRepositoryForComment {
+	return &PostRepositoryForCommentAdapter{repo}
+}
@@ -0,0 +1,761 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"musicapp/internal/models"
+	"musicapp/internal/pagination"
+
+	"github.com/google/uuid"
+)
+
+// Mock implementations for CommentService testing
+
+type MockCommentRepository struct {
+	commentsByID       map[string]*models.Comment
+	postComments       map[string][]*models.Comment
+	replies            map[string][]*models.Comment
+	likedIDs           map[uuid.UUID]bool
+	createError        error
+	getByIDError       error
+	getByPostIDError   error
+	getRepliesError    error
+	deleteError        error
+	likeCommentError   error
+	unlikeCommentError error
+	deletedIDs         []uuid.UUID
+	likedCommentIDs    []uuid.UUID
+	previewQueries     int
+}
+
+func NewMockCommentRepository() *MockCommentRepository {
+	return &MockCommentRepository{
+		commentsByID: make(map[string]*models.Comment),
+		postComments: make(map[string][]*models.Comment),
+		replies:      make(map[string][]*models.Comment),
+		likedIDs:     make(map[uuid.UUID]bool),
+	}
+}
+
+func (m *MockCommentRepository) Create(ctx context.Context, comment *models.Comment) error {
+	if m.createError != nil {
+		return m.createError
+	}
+	m.commentsByID[comment.ID.String()] = comment
+	m.postComments[comment.PostID.String()] = append(m.postComments[comment.PostID.String()], comment)
+	return nil
+}
+
+func (m *MockCommentRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.Comment, error) {
+	if m.getByIDError != nil {
+		return nil, m.getByIDError
+	}
+	comment, exists := m.commentsByID[id.String()]
+	if !exists {
+		return nil, fmt.Errorf("comment not found")
+	}
+	return comment, nil
+}
+
+func (m *MockCommentRepository) GetByPostID(ctx context.Context, postID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Comment, error) {
+	if m.getByPostIDError != nil {
+		return nil, m.getByPostIDError
+	}
+	return m.postComments[postID.String()], nil
+}
+
+func (m *MockCommentRepository) GetReplies(ctx context.Context, parentID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Comment, error) {
+	if m.getRepliesError != nil {
+		return nil, m.getRepliesError
+	}
+	return m.replies[parentID.String()], nil
+}
+
+func (m *MockCommentRepository) GetRepliesForParents(ctx context.Context, parentIDs []uuid.UUID, perParent int) ([]*models.Comment, error) {
+	if m.getRepliesError != nil {
+		return nil, m.getRepliesError
+	}
+	m.previewQueries++
+	var result []*models.Comment
+	for _, parentID := range parentIDs {
+		replies := m.replies[parentID.String()]
+		if len(replies) > perParent {
+			replies = replies[:perParent]
+		}
+		result = append(result, replies...)
+	}
+	return result, nil
+}
+
+func (m *MockCommentRepository) LikeComment(ctx context.Context, userID, commentID uuid.UUID) error {
+	if m.likeCommentError != nil {
+		return m.likeCommentError
+	}
+	m.likedCommentIDs = append(m.likedCommentIDs, commentID)
+	return nil
+}
+
+func (m *MockCommentRepository) UnlikeComment(ctx context.Context, userID, commentID uuid.UUID) error {
+	return m.unlikeCommentError
+}
+
+func (m *MockCommentRepository) GetLikedCommentIDs(ctx context.Context, userID uuid.UUID, commentIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	liked := make(map[uuid.UUID]bool)
+	for _, id := range commentIDs {
+		if m.likedIDs[id] {
+			liked[id] = true
+		}
+	}
+	return liked, nil
+}
+
+func (m *MockCommentRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	if m.deleteError != nil {
+		return m.deleteError
+	}
+	delete(m.commentsByID, id.String())
+	m.deletedIDs = append(m.deletedIDs, id)
+	return nil
+}
+
+func TestNewCommentService(t *testing.T) {
+	commentRepo := NewMockCommentRepository()
+	postRepo := NewMockPostRepository()
+
+	service := NewCommentService(commentRepo, postRepo, NewMockBandRepositoryForPost(), 5)
+
+	if service == nil {
+		t.Fatal("Expected CommentService to be created, got nil")
+	}
+	if service.maxDepth != 5 {
+		t.Errorf("Expected maxDepth 5, got %d", service.maxDepth)
+	}
+	if service.commentRepo != commentRepo {
+		t.Error("Expected commentRepo to be set correctly")
+	}
+	if service.postRepo != postRepo {
+		t.Error("Expected postRepo to be set correctly")
+	}
+
+	defaulted := NewCommentService(commentRepo, postRepo, NewMockBandRepositoryForPost(), 0)
+	if defaulted.maxDepth != DefaultCommentMaxDepth {
+		t.Errorf("Expected default maxDepth %d, got %d", DefaultCommentMaxDepth, defaulted.maxDepth)
+	}
+}
+
+func TestCommentService_CreateComment(t *testing.T) {
+	postID := uuid.New()
+	parentID := uuid.New()
+
+	tests := []struct {
+		name          string
+		req           *models.CreateCommentRequest
+		setupMocks    func(*MockCommentRepository, *MockPostRepository)
+		expectError   bool
+		errorContains string
+		expectDepth   int
+	}{
+		{
+			name: "successful comment creation",
+			req:  &models.CreateCommentRequest{Content: "Love the low end on this"},
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository) {
+				postRepo.postsByID[postID.String()] = &models.Post{ID: postID}
+			},
+			expectError: false,
+		},
+		{
+			name:          "empty content",
+			req:           &models.CreateCommentRequest{Content: ""},
+			setupMocks:    func(commentRepo *MockCommentRepository, postRepo *MockPostRepository) {},
+			expectError:   true,
+			errorContains: "comment content is required",
+		},
+		{
+			name:          "content too long",
+			req:           &models.CreateCommentRequest{Content: strings.Repeat("a", 1001)},
+			setupMocks:    func(commentRepo *MockCommentRepository, postRepo *MockPostRepository) {},
+			expectError:   true,
+			errorContains: "comment content too long",
+		},
+		{
+			name:          "post not found",
+			req:           &models.CreateCommentRequest{Content: "Hello"},
+			setupMocks:    func(commentRepo *MockCommentRepository, postRepo *MockPostRepository) {},
+			expectError:   true,
+			errorContains: "post not found",
+		},
+		{
+			name: "reply to comment on same post",
+			req:  &models.CreateCommentRequest{Content: "Agreed", ParentCommentID: &parentID},
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository) {
+				postRepo.postsByID[postID.String()] = &models.Post{ID: postID}
+				commentRepo.commentsByID[parentID.String()] = &models.Comment{ID: parentID, PostID: postID, Depth: 1}
+			},
+			expectDepth: 2,
+		},
+		{
+			name: "reply beyond max depth",
+			req:  &models.CreateCommentRequest{Content: "Agreed", ParentCommentID: &parentID},
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository) {
+				postRepo.postsByID[postID.String()] = &models.Post{ID: postID}
+				commentRepo.commentsByID[parentID.String()] = &models.Comment{ID: parentID, PostID: postID, Depth: 2}
+			},
+			expectError:   true,
+			errorContains: "maximum reply depth of 2 reached",
+		},
+		{
+			name: "reply to comment on another post",
+			req:  &models.CreateCommentRequest{Content: "Agreed", ParentCommentID: &parentID},
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository) {
+				postRepo.postsByID[postID.String()] = &models.Post{ID: postID}
+				commentRepo.commentsByID[parentID.String()] = &models.Comment{ID: parentID, PostID: uuid.New()}
+			},
+			expectError:   true,
+			errorContains: "parent comment belongs to a different post",
+		},
+		{
+			name: "reply to missing comment",
+			req:  &models.CreateCommentRequest{Content: "Agreed", ParentCommentID: &parentID},
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository) {
+				postRepo.postsByID[postID.String()] = &models.Post{ID: postID}
+			},
+			expectError:   true,
+			errorContains: "parent comment not found",
+		},
+		{
+			name: "database create error",
+			req:  &models.CreateCommentRequest{Content: "Hello"},
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository) {
+				postRepo.postsByID[postID.String()] = &models.Post{ID: postID}
+				commentRepo.createError = fmt.Errorf("database connection error")
+			},
+			expectError:   true,
+			errorContains: "failed to create comment",
+		},
+		{
+			name: "database get error after create",
+			req:  &models.CreateCommentRequest{Content: "Hello"},
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository) {
+				postRepo.postsByID[postID.String()] = &models.Post{ID: postID}
+				commentRepo.getByIDError = fmt.Errorf("database query error")
+			},
+			expectError:   true,
+			errorContains: "failed to retrieve created comment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commentRepo := NewMockCommentRepository()
+			postRepo := NewMockPostRepository()
+			tt.setupMocks(commentRepo, postRepo)
+
+			commentService := NewCommentService(commentRepo, postRepo, NewMockBandRepositoryForPost(), 2)
+			userID := uuid.New()
+
+			comment, err := commentService.CreateComment(context.Background(), postID, userID, tt.req)
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error to contain '%s', got '%s'", tt.errorContains, err.Error())
+				}
+				if comment != nil {
+					t.Error("Expected comment to be nil on error")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if comment.Content != tt.req.Content {
+				t.Errorf("Expected content '%s', got '%s'", tt.req.Content, comment.Content)
+			}
+			if comment.PostID != postID {
+				t.Errorf("Expected post ID %v, got %v", postID, comment.PostID)
+			}
+			if comment.UserID != userID {
+				t.Errorf("Expected user ID %v, got %v", userID, comment.UserID)
+			}
+			if comment.Depth != tt.expectDepth {
+				t.Errorf("Expected depth %d, got %d", tt.expectDepth, comment.Depth)
+			}
+			if tt.req.ParentCommentID != nil && (comment.ParentCommentID == nil || *comment.ParentCommentID != *tt.req.ParentCommentID) {
+				t.Errorf("Expected parent comment ID %v, got %v", *tt.req.ParentCommentID, comment.ParentCommentID)
+			}
+		})
+	}
+}
+
+func TestCommentService_GetPostComments(t *testing.T) {
+	postID := uuid.New()
+
+	tests := []struct {
+		name          string
+		limit         int
+		offset        int
+		setupMocks    func(*MockCommentRepository, *MockPostRepository)
+		expectError   bool
+		errorContains string
+		expectCount   int
+	}{
+		{
+			name:   "successful retrieval",
+			limit:  20,
+			offset: 0,
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository) {
+				postRepo.postsByID[postID.String()] = &models.Post{ID: postID}
+				commentRepo.postComments[postID.String()] = []*models.Comment{
+					{ID: uuid.New(), PostID: postID, Content: "first"},
+					{ID: uuid.New(), PostID: postID, Content: "second"},
+				}
+			},
+			expectCount: 2,
+		},
+		{
+			name:          "invalid limit",
+			limit:         0,
+			offset:        0,
+			setupMocks:    func(commentRepo *MockCommentRepository, postRepo *MockPostRepository) {},
+			expectError:   true,
+			errorContains: "invalid limit",
+		},
+		{
+			name:          "limit too high",
+			limit:         101,
+			offset:        0,
+			setupMocks:    func(commentRepo *MockCommentRepository, postRepo *MockPostRepository) {},
+			expectError:   true,
+			errorContains: "invalid limit",
+		},
+		{
+			name:          "negative offset",
+			limit:         20,
+			offset:        -1,
+			setupMocks:    func(commentRepo *MockCommentRepository, postRepo *MockPostRepository) {},
+			expectError:   true,
+			errorContains: "invalid offset",
+		},
+		{
+			name:          "post not found",
+			limit:         20,
+			offset:        0,
+			setupMocks:    func(commentRepo *MockCommentRepository, postRepo *MockPostRepository) {},
+			expectError:   true,
+			errorContains: "post not found",
+		},
+		{
+			name:   "database error",
+			limit:  20,
+			offset: 0,
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository) {
+				postRepo.postsByID[postID.String()] = &models.Post{ID: postID}
+				commentRepo.getByPostIDError = fmt.Errorf("database query error")
+			},
+			expectError:   true,
+			errorContains: "failed to retrieve comments",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commentRepo := NewMockCommentRepository()
+			postRepo := NewMockPostRepository()
+			tt.setupMocks(commentRepo, postRepo)
+
+			commentService := NewCommentService(commentRepo, postRepo, NewMockBandRepositoryForPost(), 2)
+
+			comments, err := commentService.GetPostComments(context.Background(), postID, tt.limit, tt.offset, nil, nil)
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error to contain '%s', got '%s'", tt.errorContains, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if len(comments) != tt.expectCount {
+				t.Errorf("Expected %d comments, got %d", tt.expectCount, len(comments))
+			}
+		})
+	}
+}
+
+func TestCommentService_DeleteComment(t *testing.T) {
+	postID := uuid.New()
+	commentID := uuid.New()
+	commentAuthorID := uuid.New()
+	postOwnerID := uuid.New()
+	bandID := uuid.New()
+	bandPosterID := uuid.New()
+	bandAdminID := uuid.New()
+
+	tests := []struct {
+		name          string
+		userID        uuid.UUID
+		setupMocks    func(*MockCommentRepository, *MockPostRepository, *MockBandRepositoryForPost)
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:   "comment author can delete",
+			userID: commentAuthorID,
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository, bandRepo *MockBandRepositoryForPost) {
+				commentRepo.commentsByID[commentID.String()] = &models.Comment{ID: commentID, PostID: postID, UserID: commentAuthorID}
+			},
+		},
+		{
+			name:   "post owner can delete",
+			userID: postOwnerID,
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository, bandRepo *MockBandRepositoryForPost) {
+				commentRepo.commentsByID[commentID.String()] = &models.Comment{ID: commentID, PostID: postID, UserID: commentAuthorID}
+				postRepo.postsByID[postID.String()] = &models.Post{ID: postID, UserID: &postOwnerID}
+			},
+		},
+		{
+			name:   "other user cannot delete",
+			userID: uuid.New(),
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository, bandRepo *MockBandRepositoryForPost) {
+				commentRepo.commentsByID[commentID.String()] = &models.Comment{ID: commentID, PostID: postID, UserID: commentAuthorID}
+				postRepo.postsByID[postID.String()] = &models.Post{ID: postID, UserID: &postOwnerID}
+			},
+			expectError:   true,
+			errorContains: "you can only delete your own comments",
+		},
+		{
+			name:   "band poster can delete comment on band post",
+			userID: bandPosterID,
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository, bandRepo *MockBandRepositoryForPost) {
+				commentRepo.commentsByID[commentID.String()] = &models.Comment{ID: commentID, PostID: postID, UserID: commentAuthorID}
+				postRepo.postsByID[postID.String()] = &models.Post{ID: postID, BandID: &bandID, AuthorType: "band"}
+				bandRepo.posters[bandPosterID] = true
+			},
+		},
+		{
+			name:   "band admin can delete comment on band post",
+			userID: bandAdminID,
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository, bandRepo *MockBandRepositoryForPost) {
+				commentRepo.commentsByID[commentID.String()] = &models.Comment{ID: commentID, PostID: postID, UserID: commentAuthorID}
+				postRepo.postsByID[postID.String()] = &models.Post{ID: postID, BandID: &bandID, AuthorType: "band"}
+				bandRepo.admins[bandAdminID] = true
+			},
+		},
+		{
+			name:   "other user cannot delete comment on band post",
+			userID: uuid.New(),
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository, bandRepo *MockBandRepositoryForPost) {
+				commentRepo.commentsByID[commentID.String()] = &models.Comment{ID: commentID, PostID: postID, UserID: commentAuthorID}
+				postRepo.postsByID[postID.String()] = &models.Post{ID: postID, BandID: &bandID, AuthorType: "band"}
+			},
+			expectError:   true,
+			errorContains: "you can only delete your own comments",
+		},
+		{
+			name:   "band permission lookup fails",
+			userID: bandPosterID,
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository, bandRepo *MockBandRepositoryForPost) {
+				commentRepo.commentsByID[commentID.String()] = &models.Comment{ID: commentID, PostID: postID, UserID: commentAuthorID}
+				postRepo.postsByID[postID.String()] = &models.Post{ID: postID, BandID: &bandID, AuthorType: "band"}
+				bandRepo.isAdminError = fmt.Errorf("database error")
+			},
+			expectError:   true,
+			errorContains: "Failed to check band permissions",
+		},
+		{
+			name:          "comment not found",
+			userID:        commentAuthorID,
+			setupMocks:    func(*MockCommentRepository, *MockPostRepository, *MockBandRepositoryForPost) {},
+			expectError:   true,
+			errorContains: "comment not found",
+		},
+		{
+			name:   "post lookup fails for non-author",
+			userID: postOwnerID,
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository, bandRepo *MockBandRepositoryForPost) {
+				commentRepo.commentsByID[commentID.String()] = &models.Comment{ID: commentID, PostID: postID, UserID: commentAuthorID}
+			},
+			expectError:   true,
+			errorContains: "post not found",
+		},
+		{
+			name:   "database delete error",
+			userID: commentAuthorID,
+			setupMocks: func(commentRepo *MockCommentRepository, postRepo *MockPostRepository, bandRepo *MockBandRepositoryForPost) {
+				commentRepo.commentsByID[commentID.String()] = &models.Comment{ID: commentID, PostID: postID, UserID: commentAuthorID}
+				commentRepo.deleteError = fmt.Errorf("database delete failed")
+			},
+			expectError:   true,
+			errorContains: "failed to delete comment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commentRepo := NewMockCommentRepository()
+			postRepo := NewMockPostRepository()
+			bandRepo := NewMockBandRepositoryForPost()
+			tt.setupMocks(commentRepo, postRepo, bandRepo)
+
+			commentService := NewCommentService(commentRepo, postRepo, bandRepo, 2)
+
+			err := commentService.DeleteComment(context.Background(), commentID, tt.userID)
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error to contain '%s', got '%s'", tt.errorContains, err.Error())
+				}
+				if len(commentRepo.deletedIDs) != 0 {
+					t.Error("Expected comment not to be deleted")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if len(commentRepo.deletedIDs) != 1 || commentRepo.deletedIDs[0] != commentID {
+				t.Errorf("Expected comment %v to be deleted, got %v", commentID, commentRepo.deletedIDs)
+			}
+		})
+	}
+}
+
+func TestCommentService_GetPostComments_Tree(t *testing.T) {
+	postID := uuid.New()
+	viewerID := uuid.New()
+	rootID := uuid.New()
+	childID := uuid.New()
+	grandchildID := uuid.New()
+
+	commentRepo := NewMockCommentRepository()
+	postRepo := NewMockPostRepository()
+	postRepo.postsByID[postID.String()] = &models.Post{ID: postID}
+
+	commentRepo.postComments[postID.String()] = []*models.Comment{
+		{ID: rootID, PostID: postID, RepliesCount: 5},
+	}
+	var rootReplies []*models.Comment
+	rootReplies = append(rootReplies, &models.Comment{ID: childID, PostID: postID, ParentCommentID: &rootID, Depth: 1, RepliesCount: 1})
+	for i := 0; i < 4; i++ {
+		rootReplies = append(rootReplies, &models.Comment{ID: uuid.New(), PostID: postID, ParentCommentID: &rootID, Depth: 1})
+	}
+	commentRepo.replies[rootID.String()] = rootReplies
+	commentRepo.replies[childID.String()] = []*models.Comment{
+		{ID: grandchildID, PostID: postID, ParentCommentID: &childID, Depth: 2, RepliesCount: 1},
+	}
+	commentRepo.likedIDs[grandchildID] = true
+
+	commentService := NewCommentService(commentRepo, postRepo, NewMockBandRepositoryForPost(), 2)
+
+	comments, err := commentService.GetPostComments(context.Background(), postID, 20, 0, nil, &viewerID)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if len(comments) != 1 {
+		t.Fatalf("Expected 1 top-level comment, got %d", len(comments))
+	}
+
+	root := comments[0]
+	if len(root.Replies) != replyPreviewLimit {
+		t.Fatalf("Expected %d embedded replies, got %d", replyPreviewLimit, len(root.Replies))
+	}
+	if root.RepliesCount != 5 {
+		t.Errorf("Expected replies count 5, got %d", root.RepliesCount)
+	}
+
+	child := root.Replies[0]
+	if len(child.Replies) != 1 || child.Replies[0].ID != grandchildID {
+		t.Fatalf("Expected grandchild to be embedded under child, got %v", child.Replies)
+	}
+	if !child.Replies[0].IsLiked {
+		t.Error("Expected grandchild to be marked as liked")
+	}
+	if child.IsLiked {
+		t.Error("Expected child not to be marked as liked")
+	}
+	if len(child.Replies[0].Replies) != 0 {
+		t.Error("Expected no replies to be embedded beyond max depth")
+	}
+	if commentRepo.previewQueries != 2 {
+		t.Errorf("Expected 2 reply preview queries, got %d", commentRepo.previewQueries)
+	}
+}
+
+func TestCommentService_GetCommentReplies(t *testing.T) {
+	commentID := uuid.New()
+
+	tests := []struct {
+		name          string
+		limit         int
+		offset        int
+		setupMocks    func(*MockCommentRepository)
+		expectError   bool
+		errorContains string
+		expectCount   int
+	}{
+		{
+			name:   "successful retrieval",
+			limit:  20,
+			offset: 0,
+			setupMocks: func(commentRepo *MockCommentRepository) {
+				commentRepo.commentsByID[commentID.String()] = &models.Comment{ID: commentID}
+				commentRepo.replies[commentID.String()] = []*models.Comment{
+					{ID: uuid.New(), ParentCommentID: &commentID, Depth: 1},
+					{ID: uuid.New(), ParentCommentID: &commentID, Depth: 1},
+				}
+			},
+			expectCount: 2,
+		},
+		{
+			name:          "invalid limit",
+			limit:         0,
+			setupMocks:    func(commentRepo *MockCommentRepository) {},
+			expectError:   true,
+			errorContains: "invalid limit",
+		},
+		{
+			name:          "negative offset",
+			limit:         20,
+			offset:        -1,
+			setupMocks:    func(commentRepo *MockCommentRepository) {},
+			expectError:   true,
+			errorContains: "invalid offset",
+		},
+		{
+			name:          "comment not found",
+			limit:         20,
+			setupMocks:    func(commentRepo *MockCommentRepository) {},
+			expectError:   true,
+			errorContains: "comment not found",
+		},
+		{
+			name:  "database error",
+			limit: 20,
+			setupMocks: func(commentRepo *MockCommentRepository) {
+				commentRepo.commentsByID[commentID.String()] = &models.Comment{ID: commentID}
+				commentRepo.getRepliesError = fmt.Errorf("database query error")
+			},
+			expectError:   true,
+			errorContains: "failed to retrieve replies",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commentRepo := NewMockCommentRepository()
+			tt.setupMocks(commentRepo)
+
+			commentService := NewCommentService(commentRepo, NewMockPostRepository(), NewMockBandRepositoryForPost(), 3)
+
+			replies, err := commentService.GetCommentReplies(context.Background(), commentID, tt.limit, tt.offset, nil, nil)
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error to contain '%s', got '%s'", tt.errorContains, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if len(replies) != tt.expectCount {
+				t.Errorf("Expected %d replies, got %d", tt.expectCount, len(replies))
+			}
+		})
+	}
+}
+
+func TestCommentService_LikeComment(t *testing.T) {
+	commentID := uuid.New()
+
+	tests := []struct {
+		name          string
+		setupMocks    func(*MockCommentRepository)
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name: "successful like",
+			setupMocks: func(commentRepo *MockCommentRepository) {
+				commentRepo.commentsByID[commentID.String()] = &models.Comment{ID: commentID}
+			},
+		},
+		{
+			name:          "comment not found",
+			setupMocks:    func(commentRepo *MockCommentRepository) {},
+			expectError:   true,
+			errorContains: "comment not found",
+		},
+		{
+			name: "database error",
+			setupMocks: func(commentRepo *MockCommentRepository) {
+				commentRepo.commentsByID[commentID.String()] = &models.Comment{ID: commentID}
+				commentRepo.likeCommentError = fmt.Errorf("database insert failed")
+			},
+			expectError:   true,
+			errorContains: "failed to like comment",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commentRepo := NewMockCommentRepository()
+			tt.setupMocks(commentRepo)
+
+			commentService := NewCommentService(commentRepo, NewMockPostRepository(), NewMockBandRepositoryForPost(), 3)
+
+			err := commentService.LikeComment(context.Background(), uuid.New(), commentID)
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error to contain '%s', got '%s'", tt.errorContains, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if len(commentRepo.likedCommentIDs) != 1 || commentRepo.likedCommentIDs[0] != commentID {
+				t.Errorf("Expected comment %v to be liked, got %v", commentID, commentRepo.likedCommentIDs)
+			}
+		})
+	}
+}
+
+func TestCommentService_UnlikeComment(t *testing.T) {
+	commentRepo := NewMockCommentRepository()
+	commentService := NewCommentService(commentRepo, NewMockPostRepository(), NewMockBandRepositoryForPost(), 3)
+
+	if err := commentService.UnlikeComment(context.Background(), uuid.New(), uuid.New()); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	commentRepo.unlikeCommentError = fmt.Errorf("database delete failed")
+	err := commentService.UnlikeComment(context.Background(), uuid.New(), uuid.New())
+	if err == nil || !strings.Contains(err.Error(), "failed to unlike comment") {
+		t.Errorf("Expected unlike error, got %v", err)
+	}
+}
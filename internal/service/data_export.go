@@ -0,0 +1,307 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/logging"
+	"musicapp/internal/models"
+	"musicapp/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DataExportTTL is how long a finished export archive can be downloaded
+	// before it is deleted
+	DataExportTTL = 7 * 24 * time.Hour
+
+	// dataExportLinkTTL bounds how long a download link stays valid; a new
+	// link is handed out each time the export is fetched
+	dataExportLinkTTL = 15 * time.Minute
+
+	// dataExportCooldown is how long after an export a user has to wait
+	// before requesting another
+	dataExportCooldown = 24 * time.Hour
+
+	// dataExportStaleAfter is how long an export may be in progress before
+	// it is assumed lost, e.g. to a restart, and built again
+	dataExportStaleAfter = time.Hour
+
+	// dataExportTimeout bounds building a single export
+	dataExportTimeout = 10 * time.Minute
+
+	dataExportListLimit = 10
+	dataExportBatchSize = 50
+)
+
+var (
+	errDataExportNotFound    = errors.New(errors.ErrCodeNotFound, "Data export not found")
+	errDataExportInProgress  = errors.New(errors.ErrCodeConflict, "A data export is already being prepared")
+	errDataExportUnavailable = errors.New(errors.ErrCodeExternalService, "Data exports are not available right now")
+)
+
+// DataExportRepository interface for export jobs and the data they collect
+type DataExportRepository interface {
+	Create(ctx context.Context, export *models.DataExport) (bool, error)
+	GetByID(ctx context.Context, id uuid.UUID) (*models.DataExport, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*models.DataExport, error)
+	GetClaimable(ctx context.Context, staleBefore time.Time, limit int) ([]uuid.UUID, error)
+	Claim(ctx context.Context, id uuid.UUID, staleBefore time.Time) (bool, error)
+	MarkCompleted(ctx context.Context, id uuid.UUID, objectKey string, sizeBytes int64, expiresAt time.Time) error
+	MarkFailed(ctx context.Context, id uuid.UUID, reason string) error
+	GetExpired(ctx context.Context, before time.Time, limit int) ([]*models.DataExport, error)
+	MarkExpired(ctx context.Context, id uuid.UUID) error
+	CollectUserData(ctx context.Context, userID uuid.UUID) ([]models.DataExportFile, error)
+}
+
+// ExportStore stores export archives privately; implemented by
+// storage.S3Client
+type ExportStore interface {
+	UploadFile(ctx context.Context, key string, file io.Reader, contentType string, size int64) (*storage.UploadResult, error)
+	GeneratePresignedDownloadURL(ctx context.Context, key string, expiresIn time.Duration) (string, error)
+	DeleteFile(ctx context.Context, key string) error
+}
+
+// DataExportService builds downloadable archives of a user's personal data.
+// Exports are requested through the API and built in the background.
+type DataExportService struct {
+	exportRepo DataExportRepository
+	store      ExportStore
+	logger     *logging.Logger
+}
+
+// NewDataExportService creates a DataExportService. store may be nil when
+// file storage is not configured, in which case exports are unavailable.
+func NewDataExportService(exportRepo DataExportRepository, store ExportStore, logger *logging.Logger) *DataExportService {
+	return &DataExportService{
+		exportRepo: exportRepo,
+		store:      store,
+		logger:     logger,
+	}
+}
+
+// RequestExport queues an export of the user's data and starts building it.
+// A user can have one export in progress and request one a day.
+func (s *DataExportService) RequestExport(ctx context.Context, userID uuid.UUID) (*models.DataExport, error) {
+	if s.store == nil {
+		return nil, errDataExportUnavailable
+	}
+
+	recent, err := s.exportRepo.GetByUserID(ctx, userID, 1)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to check previous exports")
+	}
+	if len(recent) > 0 {
+		latest := recent[0]
+		switch latest.Status {
+		case models.DataExportStatusPending, models.DataExportStatusProcessing:
+			return nil, errDataExportInProgress
+		case models.DataExportStatusCompleted, models.DataExportStatusExpired:
+			if wait := time.Until(latest.CreatedAt.Add(dataExportCooldown)); wait > 0 {
+				return nil, errors.NewRateLimited("You can request one data export a day", wait)
+			}
+		}
+	}
+
+	export := &models.DataExport{
+		ID:     uuid.New(),
+		UserID: userID,
+		Status: models.DataExportStatusPending,
+	}
+	created, err := s.exportRepo.Create(ctx, export)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to create data export")
+	}
+	if !created {
+		// A concurrent request got there first
+		return nil, errDataExportInProgress
+	}
+
+	userIDStr := userID.String()
+	s.logger.LogSecurityEvent("data_export_requested", &userIDStr, map[string]interface{}{"export_id": export.ID.String()})
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), dataExportTimeout)
+		defer cancel()
+		s.processExport(ctx, export.ID)
+	}()
+
+	return export, nil
+}
+
+// ListExports lists the user's most recent exports, newest first
+func (s *DataExportService) ListExports(ctx context.Context, userID uuid.UUID) ([]*models.DataExport, error) {
+	exports, err := s.exportRepo.GetByUserID(ctx, userID, dataExportListLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to list data exports")
+	}
+	return exports, nil
+}
+
+// GetExport returns one of the user's exports and, once it is ready, a
+// short-lived link to download the archive
+func (s *DataExportService) GetExport(ctx context.Context, userID, exportID uuid.UUID) (*models.DataExport, string, error) {
+	export, err := s.exportRepo.GetByID(ctx, exportID)
+	if err != nil || export == nil || export.UserID != userID {
+		return nil, "", errDataExportNotFound
+	}
+
+	if export.Status != models.DataExportStatusCompleted || export.ObjectKey == nil || s.store == nil {
+		return export, "", nil
+	}
+
+	remaining := time.Until(*export.ExpiresAt)
+	if remaining <= 0 {
+		return export, "", nil
+	}
+	url, err := s.store.GeneratePresignedDownloadURL(ctx, *export.ObjectKey, min(remaining, dataExportLinkTTL))
+	if err != nil {
+		return nil, "", errors.Wrap(err, errors.ErrCodeS3Error, "Failed to create download link")
+	}
+
+	return export, url, nil
+}
+
+// ProcessPendingExports builds exports that are still waiting, including
+// ones whose build was interrupted, and returns how many were attempted
+func (s *DataExportService) ProcessPendingExports(ctx context.Context) (int, error) {
+	if s.store == nil {
+		return 0, nil
+	}
+
+	exportIDs, err := s.exportRepo.GetClaimable(ctx, time.Now().UTC().Add(-dataExportStaleAfter), dataExportBatchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to list pending data exports")
+	}
+
+	for _, exportID := range exportIDs {
+		exportCtx, cancel := context.WithTimeout(ctx, dataExportTimeout)
+		s.processExport(exportCtx, exportID)
+		cancel()
+	}
+
+	return len(exportIDs), nil
+}
+
+// DeleteExpiredExports deletes archives whose download window has passed
+// and returns how many were deleted
+func (s *DataExportService) DeleteExpiredExports(ctx context.Context) (int, error) {
+	if s.store == nil {
+		return 0, nil
+	}
+
+	exports, err := s.exportRepo.GetExpired(ctx, time.Now().UTC(), dataExportBatchSize)
+	if err != nil {
+		return 0, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to list expired data exports")
+	}
+
+	deleted := 0
+	for _, export := range exports {
+		if export.ObjectKey != nil {
+			if err := s.store.DeleteFile(ctx, *export.ObjectKey); err != nil {
+				s.logger.WithError(err).WithField("export_id", export.ID.String()).Error("Failed to delete expired data export")
+				continue
+			}
+		}
+		if err := s.exportRepo.MarkExpired(ctx, export.ID); err != nil {
+			return deleted, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to expire data export")
+		}
+		deleted++
+	}
+
+	return deleted, nil
+}
+
+// processExport claims an export, builds its archive and uploads it. Errors
+// are recorded on the export rather than returned.
+func (s *DataExportService) processExport(ctx context.Context, exportID uuid.UUID) {
+	logger := s.logger.WithField("export_id", exportID.String()).WithOperation("data_export")
+
+	claimed, err := s.exportRepo.Claim(ctx, exportID, time.Now().UTC().Add(-dataExportStaleAfter))
+	if err != nil {
+		logger.WithError(err).Error("Failed to claim data export")
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	export, err := s.exportRepo.GetByID(ctx, exportID)
+	if err != nil || export == nil {
+		logger.WithError(err).Error("Failed to load data export")
+		return
+	}
+
+	if err := s.buildExport(ctx, export); err != nil {
+		logger.WithError(err).Error("Failed to build data export")
+		if err := s.exportRepo.MarkFailed(context.WithoutCancel(ctx), exportID, err.Error()); err != nil {
+			logger.WithError(err).Error("Failed to record data export failure")
+		}
+		return
+	}
+
+	logger.Info("Data export completed")
+}
+
+// buildExport collects the user's data into a ZIP of JSON files, stores it
+// and records where
+func (s *DataExportService) buildExport(ctx context.Context, export *models.DataExport) error {
+	files, err := s.exportRepo.CollectUserData(ctx, export.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to collect user data: %w", err)
+	}
+
+	archive, err := buildExportArchive(files, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to build archive: %w", err)
+	}
+
+	key := storage.ExportKey(export.UserID.String(), export.ID.String())
+	if _, err := s.store.UploadFile(ctx, key, bytes.NewReader(archive), "application/zip", int64(len(archive))); err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().UTC().Add(DataExportTTL)
+	if err := s.exportRepo.MarkCompleted(ctx, export.ID, key, int64(len(archive)), expiresAt); err != nil {
+		return fmt.Errorf("failed to record completed export: %w", err)
+	}
+	return nil
+}
+
+// buildExportArchive zips the export files, stamped with the given time
+func buildExportArchive(files []models.DataExportFile, createdAt time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	for _, file := range files {
+		w, err := archive.CreateHeader(&zip.FileHeader{
+			Name:     file.Name,
+			Method:   zip.Deflate,
+			Modified: createdAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		// Indent the JSON so the files are readable when opened by hand
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, file.Data, "", "  "); err != nil {
+			return nil, fmt.Errorf("invalid JSON in %s: %w", file.Name, err)
+		}
+		if _, err := w.Write(pretty.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,370 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/models"
+	"musicapp/internal/storage"
+
+	"github.com/google/uuid"
+)
+
+// Mock implementations for DataExportService testing. Exports are built in
+// a goroutine, so both mocks are safe for concurrent use.
+
+type MockDataExportRepository struct {
+	mu      sync.Mutex
+	exports map[uuid.UUID]*models.DataExport
+	files   []models.DataExportFile
+}
+
+func NewMockDataExportRepository() *MockDataExportRepository {
+	return &MockDataExportRepository{
+		exports: make(map[uuid.UUID]*models.DataExport),
+		files: []models.DataExportFile{
+			{Name: "profile.json", Data: []byte(`{"username":"testuser"}`)},
+			{Name: "posts.json", Data: []byte(`[{"content":"New single out now"}]`)},
+		},
+	}
+}
+
+// get returns a copy of an export, so tests can read it while it is built
+func (m *MockDataExportRepository) get(id uuid.UUID) models.DataExport {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return *m.exports[id]
+}
+
+func (m *MockDataExportRepository) Create(ctx context.Context, export *models.DataExport) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.exports {
+		if existing.UserID == export.UserID && (existing.Status == models.DataExportStatusPending || existing.Status == models.DataExportStatusProcessing) {
+			return false, nil
+		}
+	}
+	export.CreatedAt = time.Now()
+	stored := *export
+	m.exports[export.ID] = &stored
+	return true, nil
+}
+
+func (m *MockDataExportRepository) GetByID(ctx context.Context, id uuid.UUID) (*models.DataExport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	export, exists := m.exports[id]
+	if !exists {
+		return nil, fmt.Errorf("data export not found")
+	}
+	copied := *export
+	return &copied, nil
+}
+
+func (m *MockDataExportRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit int) ([]*models.DataExport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var exports []*models.DataExport
+	for _, export := range m.exports {
+		if export.UserID == userID {
+			copied := *export
+			exports = append(exports, &copied)
+		}
+	}
+	sort.Slice(exports, func(i, j int) bool { return exports[i].CreatedAt.After(exports[j].CreatedAt) })
+	if len(exports) > limit {
+		exports = exports[:limit]
+	}
+	return exports, nil
+}
+
+func (m *MockDataExportRepository) GetClaimable(ctx context.Context, staleBefore time.Time, limit int) ([]uuid.UUID, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var ids []uuid.UUID
+	for _, export := range m.exports {
+		if m.claimable(export, staleBefore) {
+			ids = append(ids, export.ID)
+		}
+	}
+	return ids, nil
+}
+
+func (m *MockDataExportRepository) claimable(export *models.DataExport, staleBefore time.Time) bool {
+	return export.Status == models.DataExportStatusPending ||
+		(export.Status == models.DataExportStatusProcessing && export.StartedAt.Before(staleBefore))
+}
+
+func (m *MockDataExportRepository) Claim(ctx context.Context, id uuid.UUID, staleBefore time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	export, exists := m.exports[id]
+	if !exists || !m.claimable(export, staleBefore) {
+		return false, nil
+	}
+	now := time.Now()
+	export.Status = models.DataExportStatusProcessing
+	export.StartedAt = &now
+	return true, nil
+}
+
+func (m *MockDataExportRepository) MarkCompleted(ctx context.Context, id uuid.UUID, objectKey string, sizeBytes int64, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	export := m.exports[id]
+	now := time.Now()
+	export.Status = models.DataExportStatusCompleted
+	export.ObjectKey = &objectKey
+	export.SizeBytes = &sizeBytes
+	export.CompletedAt = &now
+	export.ExpiresAt = &expiresAt
+	return nil
+}
+
+func (m *MockDataExportRepository) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	export := m.exports[id]
+	export.Status = models.DataExportStatusFailed
+	export.Error = &reason
+	return nil
+}
+
+func (m *MockDataExportRepository) GetExpired(ctx context.Context, before time.Time, limit int) ([]*models.DataExport, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var exports []*models.DataExport
+	for _, export := range m.exports {
+		if export.Status == models.DataExportStatusCompleted && export.ExpiresAt.Before(before) {
+			copied := *export
+			exports = append(exports, &copied)
+		}
+	}
+	return exports, nil
+}
+
+func (m *MockDataExportRepository) MarkExpired(ctx context.Context, id uuid.UUID) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exports[id].Status = models.DataExportStatusExpired
+	return nil
+}
+
+func (m *MockDataExportRepository) CollectUserData(ctx context.Context, userID uuid.UUID) ([]models.DataExportFile, error) {
+	return m.files, nil
+}
+
+type MockExportStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func NewMockExportStore() *MockExportStore {
+	return &MockExportStore{objects: make(map[string][]byte)}
+}
+
+func (m *MockExportStore) UploadFile(ctx context.Context, key string, file io.Reader, contentType string, size int64) (*storage.UploadResult, error) {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[key] = data
+	return &storage.UploadResult{Key: key, Size: size, MimeType: contentType}, nil
+}
+
+func (m *MockExportStore) GeneratePresignedDownloadURL(ctx context.Context, key string, expiresIn time.Duration) (string, error) {
+	return fmt.Sprintf("https://storage.example.com/%s?expires=%d", key, int(expiresIn.Seconds())), nil
+}
+
+func (m *MockExportStore) DeleteFile(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.objects, key)
+	return nil
+}
+
+func (m *MockExportStore) object(key string) ([]byte, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, exists := m.objects[key]
+	return data, exists
+}
+
+func TestDataExportService(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	setup := func() (*DataExportService, *MockDataExportRepository, *MockExportStore) {
+		exportRepo := NewMockDataExportRepository()
+		store := NewMockExportStore()
+		return NewDataExportService(exportRepo, store, createTestLogger()), exportRepo, store
+	}
+
+	// waitForExport waits for the background build of an export to finish
+	waitForExport := func(t *testing.T, exportRepo *MockDataExportRepository, id uuid.UUID) models.DataExport {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if export := exportRepo.get(id); export.Status != models.DataExportStatusPending && export.Status != models.DataExportStatusProcessing {
+				return export
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("Timed out waiting for the data export to be built")
+		return models.DataExport{}
+	}
+
+	t.Run("request and download", func(t *testing.T) {
+		dataExportService, exportRepo, store := setup()
+
+		requested, err := dataExportService.RequestExport(ctx, userID)
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if requested.Status != models.DataExportStatusPending {
+			t.Errorf("Expected a pending export, got %s", requested.Status)
+		}
+
+		export := waitForExport(t, exportRepo, requested.ID)
+		if export.Status != models.DataExportStatusCompleted {
+			t.Fatalf("Expected the export to complete, got %s", export.Status)
+		}
+		if lifetime := time.Until(*export.ExpiresAt); lifetime < DataExportTTL-time.Minute || lifetime > DataExportTTL {
+			t.Errorf("Expected the archive to expire after %v, got %v", DataExportTTL, lifetime)
+		}
+
+		key := storage.ExportKey(userID.String(), requested.ID.String())
+		data, exists := store.object(key)
+		if !exists || *export.ObjectKey != key || *export.SizeBytes != int64(len(data)) {
+			t.Fatalf("Expected the archive to be stored at %s", key)
+		}
+		archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+		if err != nil {
+			t.Fatalf("Expected a valid ZIP: %v", err)
+		}
+		var names []string
+		for _, file := range archive.File {
+			names = append(names, file.Name)
+		}
+		if strings.Join(names, ",") != "profile.json,posts.json" {
+			t.Errorf("Unexpected archive contents: %v", names)
+		}
+
+		_, url, err := dataExportService.GetExport(ctx, userID, requested.ID)
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if !strings.Contains(url, key) || !strings.HasSuffix(url, fmt.Sprintf("expires=%d", int(dataExportLinkTTL.Seconds()))) {
+			t.Errorf("Unexpected download URL %q", url)
+		}
+	})
+
+	t.Run("another user's export", func(t *testing.T) {
+		dataExportService, exportRepo, _ := setup()
+		requested, _ := dataExportService.RequestExport(ctx, userID)
+		waitForExport(t, exportRepo, requested.ID)
+
+		_, _, err := dataExportService.GetExport(ctx, uuid.New(), requested.ID)
+		expectAppError(t, err, errors.ErrCodeNotFound)
+	})
+
+	t.Run("one export at a time and one a day", func(t *testing.T) {
+		dataExportService, exportRepo, _ := setup()
+
+		pending := &models.DataExport{ID: uuid.New(), UserID: userID, Status: models.DataExportStatusPending}
+		exportRepo.Create(ctx, pending)
+		_, err := dataExportService.RequestExport(ctx, userID)
+		expectAppError(t, err, errors.ErrCodeConflict)
+
+		exportRepo.MarkCompleted(ctx, pending.ID, "exports/key.zip", 1, time.Now().Add(DataExportTTL))
+		_, err = dataExportService.RequestExport(ctx, userID)
+		expectAppError(t, err, errors.ErrCodeRateLimited)
+
+		exportRepo.MarkFailed(ctx, pending.ID, "upload failed")
+		if _, err := dataExportService.RequestExport(ctx, userID); err != nil {
+			t.Errorf("Expected a failed export to be retryable, got: %v", err)
+		}
+	})
+
+	t.Run("concurrent requests start one export", func(t *testing.T) {
+		dataExportService, exportRepo, _ := setup()
+
+		errs := make(chan error, 5)
+		for i := 0; i < cap(errs); i++ {
+			go func() {
+				_, err := dataExportService.RequestExport(ctx, userID)
+				errs <- err
+			}()
+		}
+
+		started := 0
+		for i := 0; i < cap(errs); i++ {
+			err := <-errs
+			if err == nil {
+				started++
+				continue
+			}
+			// Requests arriving after the build finished hit the daily limit
+			if appErr := errors.GetAppError(err); appErr == nil || (appErr.Code != errors.ErrCodeConflict && appErr.Code != errors.ErrCodeRateLimited) {
+				t.Errorf("Expected a conflict or rate limit, got: %v", err)
+			}
+		}
+		if started != 1 {
+			t.Errorf("Expected exactly one export to start, got %d", started)
+		}
+
+		exports, _ := exportRepo.GetByUserID(ctx, userID, dataExportListLimit)
+		waitForExport(t, exportRepo, exports[0].ID)
+	})
+
+	t.Run("interrupted builds are resumed", func(t *testing.T) {
+		dataExportService, exportRepo, _ := setup()
+		startedAt := time.Now().Add(-2 * dataExportStaleAfter)
+		stale := &models.DataExport{ID: uuid.New(), UserID: userID, Status: models.DataExportStatusProcessing}
+		exportRepo.Create(ctx, stale)
+		exportRepo.exports[stale.ID].StartedAt = &startedAt
+
+		processed, err := dataExportService.ProcessPendingExports(ctx)
+		if err != nil || processed != 1 {
+			t.Fatalf("Expected one export to be processed, got %d, %v", processed, err)
+		}
+		if status := exportRepo.get(stale.ID).Status; status != models.DataExportStatusCompleted {
+			t.Errorf("Expected the export to complete, got %s", status)
+		}
+	})
+
+	t.Run("expired archives are deleted", func(t *testing.T) {
+		dataExportService, exportRepo, store := setup()
+		requested, _ := dataExportService.RequestExport(ctx, userID)
+		export := waitForExport(t, exportRepo, requested.ID)
+
+		exportRepo.MarkCompleted(ctx, export.ID, *export.ObjectKey, *export.SizeBytes, time.Now().Add(-time.Second))
+		deleted, err := dataExportService.DeleteExpiredExports(ctx)
+		if err != nil || deleted != 1 {
+			t.Fatalf("Expected one export to be deleted, got %d, %v", deleted, err)
+		}
+		if _, exists := store.object(*export.ObjectKey); exists {
+			t.Error("Expected the archive to be deleted")
+		}
+		if status := exportRepo.get(export.ID).Status; status != models.DataExportStatusExpired {
+			t.Errorf("Expected the export to be expired, got %s", status)
+		}
+	})
+
+	t.Run("storage not configured", func(t *testing.T) {
+		dataExportService := NewDataExportService(NewMockDataExportRepository(), nil, createTestLogger())
+		_, err := dataExportService.RequestExport(ctx, userID)
+		expectAppError(t, err, errors.ErrCodeExternalService)
+	})
+}
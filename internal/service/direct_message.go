@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+	"strings"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/models"
+	"musicapp/internal/pagination"
+	"musicapp/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ConversationRepository interface for direct message data operations
+type ConversationRepository interface {
+	GetOrCreate(ctx context.Context, userID, otherUserID uuid.UUID) (*models.Conversation, error)
+	IsParticipant(ctx context.Context, conversationID, userID uuid.UUID) (bool, error)
+	GetUserConversations(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Conversation, error)
+	CreateMessage(ctx context.Context, message *models.DirectMessage) error
+	GetMessageByID(ctx context.Context, id uuid.UUID) (*models.DirectMessage, error)
+	GetMessages(ctx context.Context, conversationID uuid.UUID, before *uuid.UUID, limit int) ([]*models.DirectMessage, error)
+	DeleteMessage(ctx context.Context, id uuid.UUID) error
+	MarkRead(ctx context.Context, conversationID, userID uuid.UUID) error
+	GetUnreadCount(ctx context.Context, userID uuid.UUID) (int, error)
+}
+
+// UserRepositoryForDirectMessage interface for user operations needed by DirectMessageService
+type UserRepositoryForDirectMessage interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+}
+
+type DirectMessageService struct {
+	conversationRepo ConversationRepository
+	userRepo         UserRepositoryForDirectMessage
+}
+
+func NewDirectMessageService(conversationRepo ConversationRepository, userRepo UserRepositoryForDirectMessage) *DirectMessageService {
+	return &DirectMessageService{
+		conversationRepo: conversationRepo,
+		userRepo:         userRepo,
+	}
+}
+
+// SendMessage sends a direct message to another user, starting a
+// conversation between them if needed
+func (s *DirectMessageService) SendMessage(ctx context.Context, senderID, recipientID uuid.UUID, req *models.SendDirectMessageRequest) (*models.DirectMessage, error) {
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return nil, errors.New(errors.ErrCodeValidationFailed, "message content is required")
+	}
+
+	if len(content) > MaxMessageLength {
+		return nil, errors.New(errors.ErrCodeValidationFailed, fmt.Sprintf("message content too long (max %d characters)", MaxMessageLength))
+	}
+
+	if senderID == recipientID {
+		return nil, errors.New(errors.ErrCodeInvalidInput, "You cannot message yourself")
+	}
+
+	recipient, err := s.userRepo.GetByID(ctx, recipientID)
+	if err != nil && !stderrors.Is(err, pgx.ErrNoRows) {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to load recipient")
+	}
+	if recipient == nil {
+		return nil, errors.New(errors.ErrCodeNotFound, "Recipient not found")
+	}
+
+	conversation, err := s.conversationRepo.GetOrCreate(ctx, senderID, recipientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+
+	message := &models.DirectMessage{
+		ID:             uuid.New(),
+		ConversationID: conversation.ID,
+		SenderID:       senderID,
+		Content:        content,
+	}
+
+	if err := s.conversationRepo.CreateMessage(ctx, message); err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	return message, nil
+}
+
+// GetConversations lists the user's conversations, most recently active first
+func (s *DirectMessageService) GetConversations(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Conversation, error) {
+	conversations, err := s.conversationRepo.GetUserConversations(ctx, userID, limit, offset, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversations: %w", err)
+	}
+
+	return conversations, nil
+}
+
+// GetMessages returns a page of a conversation's messages, newest first
+func (s *DirectMessageService) GetMessages(ctx context.Context, conversationID, userID uuid.UUID, before *uuid.UUID, limit int) ([]*models.DirectMessage, error) {
+	if err := s.requireParticipant(ctx, conversationID, userID); err != nil {
+		return nil, err
+	}
+
+	messages, err := s.conversationRepo.GetMessages(ctx, conversationID, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+// MarkRead marks every message in the conversation as read for the user
+func (s *DirectMessageService) MarkRead(ctx context.Context, conversationID, userID uuid.UUID) error {
+	if err := s.requireParticipant(ctx, conversationID, userID); err != nil {
+		return err
+	}
+
+	if err := s.conversationRepo.MarkRead(ctx, conversationID, userID); err != nil {
+		return fmt.Errorf("failed to mark conversation as read: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteMessage deletes a direct message. Only the sender can delete it.
+func (s *DirectMessageService) DeleteMessage(ctx context.Context, messageID, userID uuid.UUID) error {
+	message, err := s.conversationRepo.GetMessageByID(ctx, messageID)
+	if err != nil && !stderrors.Is(err, pgx.ErrNoRows) {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to load message")
+	}
+	if message == nil {
+		return errors.New(errors.ErrCodeNotFound, "Message not found")
+	}
+
+	if message.SenderID != userID {
+		return errors.New(errors.ErrCodeForbidden, "You can only delete your own messages")
+	}
+
+	if err := s.conversationRepo.DeleteMessage(ctx, messageID); err != nil {
+		return fmt.Errorf("failed to delete message: %w", err)
+	}
+
+	return nil
+}
+
+// GetUnreadCount returns the user's unread message count across all conversations
+func (s *DirectMessageService) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int, error) {
+	count, err := s.conversationRepo.GetUnreadCount(ctx, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get unread count: %w", err)
+	}
+
+	return count, nil
+}
+
+// Non-participants get a not-found error so conversation IDs are not confirmed to outsiders
+func (s *DirectMessageService) requireParticipant(ctx context.Context, conversationID, userID uuid.UUID) error {
+	isParticipant, err := s.conversationRepo.IsParticipant(ctx, conversationID, userID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to check conversation access")
+	}
+
+	if !isParticipant {
+		return errors.New(errors.ErrCodeNotFound, "Conversation not found")
+	}
+
+	return nil
+}
+
+// Adapter structs to bridge existing concrete types with new interfaces
+
+// ConversationRepositoryAdapter adapts *repository.ConversationRepository to ConversationRepository interface
+type ConversationRepositoryAdapter struct {
+	repo *repository.ConversationRepository
+}
+
+func NewConversationRepositoryAdapter(repo *repository.ConversationRepository) ConversationRepository {
+	return &ConversationRepositoryAdapter{repo}
+}
+
+func (a *ConversationRepositoryAdapter) GetOrCreate(ctx context.Context, userID, otherUserID uuid.UUID) (*models.Conversation, error) {
+	return a.repo.GetOrCreate(ctx, userID, otherUserID)
+}
+
+func (a *ConversationRepositoryAdapter) IsParticipant(ctx context.Context, conversationID, userID uuid.UUID) (bool, error) {
+	return a.repo.IsParticipant(ctx, conversationID, userID)
+}
+
+func (a *ConversationRepositoryAdapter) GetUserConversations(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Conversation, error) {
+	return a.repo.GetUserConversations(ctx, userID, limit, offset, after)
+}
+
+func (a *ConversationRepositoryAdapter) CreateMessage(ctx context.Context, message *models.DirectMessage) error {
+	return a.repo.CreateMessage(ctx, message)
+}
+
+func (a *ConversationRepositoryAdapter) GetMessageByID(ctx context.Context, id uuid.UUID) (*models.DirectMessage, error) {
+	return a.repo.GetMessageByID(ctx, id)
+}
+
+func (a *ConversationRepositoryAdapter) GetMessages(ctx context.Context, conversationID uuid.UUID, before *uuid.UUID, limit int) ([]*models.DirectMessage, error) {
+	return a.repo.GetMessages(ctx, conversationID, before, limit)
+}
+
+func (a *ConversationRepositoryAdapter) DeleteMessage(ctx context.Context, id uuid.UUID) error {
+	return a.repo.DeleteMessage(ctx, id)
+}
+
+func (a *ConversationRepositoryAdapter) MarkRead(ctx context.Context, conversationID, userID uuid.UUID) error {
+	return a.repo.MarkRead(ctx, conversationID, userID)
+}
+
+func (a *ConversationRepositoryAdapter) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int, error) {
+	return a.repo.GetUnreadCount(ctx, userID)
+}
+
+// UserRepositoryForDirectMessageAdapter adapts *repository.UserRepository to UserRepositoryForDirectMessage interface
+type UserRepositoryForDirectMessageAdapter struct {
+	repo *repository.UserRepository
+}
+
+func NewUserRepositoryForDirectMessageAdapter(repo *repository.UserRepository) UserRepositoryForDirectMessage {
+	return &UserRepositoryForDirectMessageAdapter{repo}
+}
+
+func (a *UserRepositoryForDirectMessageAdapter) GetByID(ctx context.Context, id uuid.UUID) (*models.User, error) {
+	return a.repo.GetByID(ctx, id)
+}
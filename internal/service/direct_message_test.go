@@ -0,0 +1,359 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/models"
+	"musicapp/internal/pagination"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Mock implementations for DirectMessageService testing
+
+type MockConversationRepository struct {
+	conversations     map[[2]uuid.UUID]*models.Conversation
+	participants      map[uuid.UUID]map[uuid.UUID]bool
+	messagesByID      map[uuid.UUID]*models.DirectMessage
+	userConversations []*models.Conversation
+	unreadCount       int
+	getOrCreateError  error
+	getMessageError   error
+	createError       error
+	deleteError       error
+	markReadError     error
+	deletedIDs        []uuid.UUID
+	markedRead        []uuid.UUID
+}
+
+func NewMockConversationRepository() *MockConversationRepository {
+	return &MockConversationRepository{
+		conversations: make(map[[2]uuid.UUID]*models.Conversation),
+		participants:  make(map[uuid.UUID]map[uuid.UUID]bool),
+		messagesByID:  make(map[uuid.UUID]*models.DirectMessage),
+	}
+}
+
+func (m *MockConversationRepository) GetOrCreate(ctx context.Context, userID, otherUserID uuid.UUID) (*models.Conversation, error) {
+	if m.getOrCreateError != nil {
+		return nil, m.getOrCreateError
+	}
+	key := [2]uuid.UUID{userID, otherUserID}
+	if userID.String() > otherUserID.String() {
+		key = [2]uuid.UUID{otherUserID, userID}
+	}
+	if conversation, exists := m.conversations[key]; exists {
+		return conversation, nil
+	}
+	conversation := &models.Conversation{ID: uuid.New(), CreatedAt: time.Now()}
+	m.conversations[key] = conversation
+	m.participants[conversation.ID] = map[uuid.UUID]bool{userID: true, otherUserID: true}
+	return conversation, nil
+}
+
+func (m *MockConversationRepository) IsParticipant(ctx context.Context, conversationID, userID uuid.UUID) (bool, error) {
+	return m.participants[conversationID][userID], nil
+}
+
+func (m *MockConversationRepository) GetUserConversations(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Conversation, error) {
+	return m.userConversations, nil
+}
+
+func (m *MockConversationRepository) CreateMessage(ctx context.Context, message *models.DirectMessage) error {
+	if m.createError != nil {
+		return m.createError
+	}
+	message.CreatedAt = time.Now()
+	m.messagesByID[message.ID] = message
+	return nil
+}
+
+func (m *MockConversationRepository) GetMessageByID(ctx context.Context, id uuid.UUID) (*models.DirectMessage, error) {
+	if m.getMessageError != nil {
+		return nil, m.getMessageError
+	}
+	message, exists := m.messagesByID[id]
+	if !exists {
+		return nil, pgx.ErrNoRows
+	}
+	return message, nil
+}
+
+func (m *MockConversationRepository) GetMessages(ctx context.Context, conversationID uuid.UUID, before *uuid.UUID, limit int) ([]*models.DirectMessage, error) {
+	var messages []*models.DirectMessage
+	for _, message := range m.messagesByID {
+		if message.ConversationID == conversationID {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+func (m *MockConversationRepository) DeleteMessage(ctx context.Context, id uuid.UUID) error {
+	if m.deleteError != nil {
+		return m.deleteError
+	}
+	delete(m.messagesByID, id)
+	m.deletedIDs = append(m.deletedIDs, id)
+	return nil
+}
+
+func (m *MockConversationRepository) MarkRead(ctx context.Context, conversationID, userID uuid.UUID) error {
+	if m.markReadError != nil {
+		return m.markReadError
+	}
+	m.markedRead = append(m.markedRead, conversationID)
+	return nil
+}
+
+func (m *MockConversationRepository) GetUnreadCount(ctx context.Context, userID uuid.UUID) (int, error) {
+	return m.unreadCount, nil
+}
+
+func assertAppErrorStatus(t *testing.T, err error, status int) {
+	t.Helper()
+	appErr := errors.GetAppError(err)
+	if appErr == nil {
+		t.Fatalf("Expected AppError with status %d, got %v", status, err)
+	}
+	if appErr.HTTPStatus != status {
+		t.Errorf("Expected status %d, got %d", status, appErr.HTTPStatus)
+	}
+}
+
+func TestDirectMessageService_SendMessage(t *testing.T) {
+	senderID := uuid.New()
+	recipientID := uuid.New()
+
+	tests := []struct {
+		name          string
+		recipientID   uuid.UUID
+		req           *models.SendDirectMessageRequest
+		setupMocks    func(*MockConversationRepository)
+		lookupError   error
+		expectError   bool
+		expectStatus  int
+		errorContains string
+	}{
+		{
+			name:        "successful send",
+			recipientID: recipientID,
+			req:         &models.SendDirectMessageRequest{Content: "Want to co-write?"},
+			setupMocks:  func(repo *MockConversationRepository) {},
+		},
+		{
+			name:          "blank content",
+			recipientID:   recipientID,
+			req:           &models.SendDirectMessageRequest{Content: "  "},
+			setupMocks:    func(repo *MockConversationRepository) {},
+			expectError:   true,
+			expectStatus:  http.StatusBadRequest,
+			errorContains: "message content is required",
+		},
+		{
+			name:          "content too long",
+			recipientID:   recipientID,
+			req:           &models.SendDirectMessageRequest{Content: strings.Repeat("a", MaxMessageLength+1)},
+			setupMocks:    func(repo *MockConversationRepository) {},
+			expectError:   true,
+			expectStatus:  http.StatusBadRequest,
+			errorContains: "message content too long",
+		},
+		{
+			name:          "messaging yourself",
+			recipientID:   senderID,
+			req:           &models.SendDirectMessageRequest{Content: "Hi me"},
+			setupMocks:    func(repo *MockConversationRepository) {},
+			expectError:   true,
+			expectStatus:  http.StatusBadRequest,
+			errorContains: "cannot message yourself",
+		},
+		{
+			name:          "recipient not found",
+			recipientID:   uuid.New(),
+			req:           &models.SendDirectMessageRequest{Content: "Hello"},
+			setupMocks:    func(repo *MockConversationRepository) {},
+			expectError:   true,
+			expectStatus:  http.StatusNotFound,
+			errorContains: "Recipient not found",
+		},
+		{
+			name:          "recipient lookup fails",
+			recipientID:   recipientID,
+			req:           &models.SendDirectMessageRequest{Content: "Hello"},
+			setupMocks:    func(repo *MockConversationRepository) {},
+			lookupError:   fmt.Errorf("connection refused"),
+			expectError:   true,
+			expectStatus:  http.StatusInternalServerError,
+			errorContains: "Failed to load recipient",
+		},
+		{
+			name:        "repository error",
+			recipientID: recipientID,
+			req:         &models.SendDirectMessageRequest{Content: "Hello"},
+			setupMocks: func(repo *MockConversationRepository) {
+				repo.createError = fmt.Errorf("database error")
+			},
+			expectError:   true,
+			errorContains: "failed to send message",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversationRepo := NewMockConversationRepository()
+			userRepo := NewMockUserRepositoryForPost()
+			userRepo.usersByID[recipientID.String()] = &models.User{ID: recipientID}
+			userRepo.getByIDError = tt.lookupError
+			tt.setupMocks(conversationRepo)
+
+			service := NewDirectMessageService(conversationRepo, userRepo)
+			message, err := service.SendMessage(context.Background(), senderID, tt.recipientID, tt.req)
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error containing '%s', got '%s'", tt.errorContains, err.Error())
+				}
+				if tt.expectStatus != 0 {
+					assertAppErrorStatus(t, err, tt.expectStatus)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if message.SenderID != senderID {
+				t.Errorf("Expected sender %v, got %v", senderID, message.SenderID)
+			}
+
+			// A second message reuses the same conversation
+			again, err := service.SendMessage(context.Background(), recipientID, senderID, tt.req)
+			if err == nil && again.ConversationID != message.ConversationID {
+				t.Error("Expected replies to reuse the existing conversation")
+			}
+		})
+	}
+}
+
+func TestDirectMessageService_GetMessages(t *testing.T) {
+	conversationRepo := NewMockConversationRepository()
+	userRepo := NewMockUserRepositoryForPost()
+	userA, userB := uuid.New(), uuid.New()
+	userRepo.usersByID[userB.String()] = &models.User{ID: userB}
+	service := NewDirectMessageService(conversationRepo, userRepo)
+
+	message, err := service.SendMessage(context.Background(), userA, userB, &models.SendDirectMessageRequest{Content: "Hello"})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	messages, err := service.GetMessages(context.Background(), message.ConversationID, userB, nil, 20)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(messages) != 1 {
+		t.Errorf("Expected 1 message, got %d", len(messages))
+	}
+
+	_, err = service.GetMessages(context.Background(), message.ConversationID, uuid.New(), nil, 20)
+	if err == nil {
+		t.Fatal("Expected outsider to be rejected")
+	}
+	assertAppErrorStatus(t, err, http.StatusNotFound)
+}
+
+func TestDirectMessageService_MarkRead(t *testing.T) {
+	conversationRepo := NewMockConversationRepository()
+	userRepo := NewMockUserRepositoryForPost()
+	userA, userB := uuid.New(), uuid.New()
+	userRepo.usersByID[userB.String()] = &models.User{ID: userB}
+	service := NewDirectMessageService(conversationRepo, userRepo)
+
+	message, _ := service.SendMessage(context.Background(), userA, userB, &models.SendDirectMessageRequest{Content: "Hello"})
+
+	if err := service.MarkRead(context.Background(), message.ConversationID, userB); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(conversationRepo.markedRead) != 1 {
+		t.Errorf("Expected conversation to be marked read, got %v", conversationRepo.markedRead)
+	}
+
+	if err := service.MarkRead(context.Background(), message.ConversationID, uuid.New()); err == nil {
+		t.Error("Expected outsider to be rejected")
+	}
+}
+
+func TestDirectMessageService_DeleteMessage(t *testing.T) {
+	senderID := uuid.New()
+	messageID := uuid.New()
+
+	tests := []struct {
+		name         string
+		userID       uuid.UUID
+		messageID    uuid.UUID
+		lookupError  error
+		expectStatus int
+	}{
+		{
+			name:      "sender deletes own message",
+			userID:    senderID,
+			messageID: messageID,
+		},
+		{
+			name:         "recipient cannot delete",
+			userID:       uuid.New(),
+			messageID:    messageID,
+			expectStatus: http.StatusForbidden,
+		},
+		{
+			name:         "message not found",
+			userID:       senderID,
+			messageID:    uuid.New(),
+			expectStatus: http.StatusNotFound,
+		},
+		{
+			name:         "lookup fails",
+			userID:       senderID,
+			messageID:    messageID,
+			lookupError:  fmt.Errorf("connection refused"),
+			expectStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conversationRepo := NewMockConversationRepository()
+			conversationRepo.messagesByID[messageID] = &models.DirectMessage{ID: messageID, SenderID: senderID}
+			conversationRepo.getMessageError = tt.lookupError
+			service := NewDirectMessageService(conversationRepo, NewMockUserRepositoryForPost())
+
+			err := service.DeleteMessage(context.Background(), tt.messageID, tt.userID)
+
+			if tt.expectStatus != 0 {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				assertAppErrorStatus(t, err, tt.expectStatus)
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(conversationRepo.deletedIDs) != 1 || conversationRepo.deletedIDs[0] != messageID {
+				t.Errorf("Expected message %v to be deleted, got %v", messageID, conversationRepo.deletedIDs)
+			}
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 
 	"musicapp/internal/interfaces"
 	"musicapp/internal/models"
+	"musicapp/internal/pagination"
 	"musicapp/internal/repository"
 
 	"github.com/google/uuid"
@@ -16,8 +17,8 @@ type FollowRepositoryForFollow interface {
 	Create(ctx context.Context, follow *models.Follow) error
 	Delete(ctx context.Context, followerID uuid.UUID, followingType string, followingUserID, followingBandID *uuid.UUID) error
 	IsFollowing(ctx context.Context, followerID uuid.UUID, followingType string, followingUserID, followingBandID *uuid.UUID) (bool, error)
-	GetFollowers(ctx context.Context, followingType string, followingUserID, followingBandID *uuid.UUID, limit, offset int) ([]*models.Follow, error)
-	GetFollowing(ctx context.Context, followerID uuid.UUID, limit, offset int) ([]*models.Follow, error)
+	GetFollowers(ctx context.Context, followingType string, followingUserID, followingBandID *uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Follow, error)
+	GetFollowing(ctx context.Context, followerID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Follow, error)
 }
 
 // UserRepositoryForFollow interface for user operations
@@ -158,7 +159,7 @@ func (s *FollowService) UnfollowBand(ctx context.Context, followerID, followingB
 }
 
 // GetFollowers retrieves users who follow the specified user/band
-func (s *FollowService) GetFollowers(ctx context.Context, followingType string, followingUserID, followingBandID *uuid.UUID, limit, offset int) ([]*models.Follow, error) {
+func (s *FollowService) GetFollowers(ctx context.Context, followingType string, followingUserID, followingBandID *uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Follow, error) {
 	if limit <= 0 || limit > 100 {
 		return nil, fmt.Errorf("invalid limit: %d (must be 1-100)", limit)
 	}
@@ -166,7 +167,7 @@ func (s *FollowService) GetFollowers(ctx context.Context, followingType string,
 		return nil, fmt.Errorf("invalid offset: %d (must be >= 0)", offset)
 	}
 
-	followers, err := s.followRepo.GetFollowers(ctx, followingType, followingUserID, followingBandID, limit, offset)
+	followers, err := s.followRepo.GetFollowers(ctx, followingType, followingUserID, followingBandID, limit, offset, after)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve followers: %w", err)
 	}
@@ -175,7 +176,7 @@ func (s *FollowService) GetFollowers(ctx context.Context, followingType string,
 }
 
 // GetFollowing retrieves users/bands that the specified user is following
-func (s *FollowService) GetFollowing(ctx context.Context, followerID uuid.UUID, limit, offset int) ([]*models.Follow, error) {
+func (s *FollowService) GetFollowing(ctx context.Context, followerID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Follow, error) {
 	if limit <= 0 || limit > 100 {
 		return nil, fmt.Errorf("invalid limit: %d (must be 1-100)", limit)
 	}
@@ -183,7 +184,7 @@ func (s *FollowService) GetFollowing(ctx context.Context, followerID uuid.UUID,
 		return nil, fmt.Errorf("invalid offset: %d (must be >= 0)", offset)
 	}
 
-	following, err := s.followRepo.GetFollowing(ctx, followerID, limit, offset)
+	following, err := s.followRepo.GetFollowing(ctx, followerID, limit, offset, after)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve following: %w", err)
 	}
@@ -224,12 +225,12 @@ func (a *FollowRepositoryAdapter) IsFollowing(ctx context.Context, followerID uu
 	return a.repo.IsFollowing(ctx, followerID, followingType, followingUserID, followingBandID)
 }
 
-func (a *FollowRepositoryAdapter) GetFollowers(ctx context.Context, followingType string, followingUserID, followingBandID *uuid.UUID, limit, offset int) ([]*models.Follow, error) {
-	return a.repo.GetFollowers(ctx, followingType, followingUserID, followingBandID, limit, offset)
+func (a *FollowRepositoryAdapter) GetFollowers(ctx context.Context, followingType string, followingUserID, followingBandID *uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Follow, error) {
+	return a.repo.GetFollowers(ctx, followingType, followingUserID, followingBandID, limit, offset, after)
 }
 
-func (a *FollowRepositoryAdapter) GetFollowing(ctx context.Context, followerID uuid.UUID, limit, offset int) ([]*models.Follow, error) {
-	return a.repo.GetFollowing(ctx, followerID, limit, offset)
+func (a *FollowRepositoryAdapter) GetFollowing(ctx context.Context, followerID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Follow, error) {
+	return a.repo.GetFollowing(ctx, followerID, limit, offset, after)
 }
 
 // UserRepositoryForFollowAdapter adapts repository.UserRepository to UserRepositoryForFollow
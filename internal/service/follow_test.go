@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"musicapp/internal/models"
+	"musicapp/internal/pagination"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -36,11 +37,11 @@ func (m *MockFollowRepositoryForFollow) IsFollowing(ctx context.Context, followe
 	return m.isFollowingResult, m.isFollowingError
 }
 
-func (m *MockFollowRepositoryForFollow) GetFollowers(ctx context.Context, followingType string, followingUserID, followingBandID *uuid.UUID, limit, offset int) ([]*models.Follow, error) {
+func (m *MockFollowRepositoryForFollow) GetFollowers(ctx context.Context, followingType string, followingUserID, followingBandID *uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Follow, error) {
 	return m.getFollowersResult, m.getFollowersError
 }
 
-func (m *MockFollowRepositoryForFollow) GetFollowing(ctx context.Context, followerID uuid.UUID, limit, offset int) ([]*models.Follow, error) {
+func (m *MockFollowRepositoryForFollow) GetFollowing(ctx context.Context, followerID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Follow, error) {
 	return m.getFollowingResult, m.getFollowingError
 }
 
@@ -430,7 +431,7 @@ func TestFollowService_GetFollowers(t *testing.T) {
 			tt.setupMocks(followRepo)
 
 			service := NewFollowService(followRepo, userRepo, bandRepo, cache)
-			followers, err := service.GetFollowers(context.Background(), tt.followingType, tt.followingUserID, tt.followingBandID, tt.limit, tt.offset)
+			followers, err := service.GetFollowers(context.Background(), tt.followingType, tt.followingUserID, tt.followingBandID, tt.limit, tt.offset, nil)
 
 			if tt.expectedError != "" {
 				assert.Error(t, err)
@@ -515,7 +516,7 @@ func TestFollowService_GetFollowing(t *testing.T) {
 			tt.setupMocks(followRepo)
 
 			service := NewFollowService(followRepo, userRepo, bandRepo, cache)
-			following, err := service.GetFollowing(context.Background(), tt.followerID, tt.limit, tt.offset)
+			following, err := service.GetFollowing(context.Background(), tt.followerID, tt.limit, tt.offset, nil)
 
 			if tt.expectedError != "" {
 				assert.Error(t, err)
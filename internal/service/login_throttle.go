@@ -0,0 +1,191 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/logging"
+	"musicapp/internal/mail"
+	"musicapp/internal/models"
+	"musicapp/pkg/utils"
+)
+
+// Failed logins are counted per account and per IP address. Once either
+// count reaches its threshold, logins are locked for loginLockoutBase, and
+// each further failure doubles the lockout up to loginLockoutMax. Counts
+// are forgotten after loginFailureWindow without failures.
+const (
+	accountFailureThreshold = 5
+	ipFailureThreshold      = 20
+	loginFailureWindow      = time.Hour
+	loginLockoutBase        = time.Minute
+	loginLockoutMax         = time.Hour
+)
+
+// LoginAttemptStore tracks failed logins and lockouts; implemented by
+// cache.Cache
+type LoginAttemptStore interface {
+	RecordLoginFailure(ctx context.Context, key string, window time.Duration) (int64, error)
+	ClearLoginFailures(ctx context.Context, key string) error
+	LockLogin(ctx context.Context, key string, duration time.Duration) error
+	LoginLockRemaining(ctx context.Context, key string) (time.Duration, error)
+}
+
+// LoginThrottle protects password logins against brute force with
+// progressive lockouts per account and per IP address
+type LoginThrottle struct {
+	store  LoginAttemptStore
+	mailer mail.Mailer
+	logger *logging.Logger
+}
+
+func NewLoginThrottle(store LoginAttemptStore, mailer mail.Mailer, logger *logging.Logger) *LoginThrottle {
+	return &LoginThrottle{
+		store:  store,
+		mailer: mailer,
+		logger: logger,
+	}
+}
+
+// Check fails with ErrCodeRateLimited while the account or IP address is
+// locked out
+func (t *LoginThrottle) Check(ctx context.Context, email, ipAddress string) error {
+	for _, key := range loginThrottleKeys(email, ipAddress) {
+		remaining, err := t.store.LoginLockRemaining(ctx, key)
+		if err != nil {
+			return errors.Wrap(err, errors.ErrCodeRedisError, "Failed to check login lockout")
+		}
+		if remaining > 0 {
+			return errLoginLocked(remaining)
+		}
+	}
+	return nil
+}
+
+// RecordFailure counts a failed login. Failures are counted for unknown
+// emails too, so lockouts do not reveal which accounts exist. It returns
+// ErrCodeRateLimited if the failure locked the account or IP address.
+func (t *LoginThrottle) RecordFailure(ctx context.Context, email, ipAddress string, user *models.User) error {
+	keys := loginThrottleKeys(email, ipAddress)
+
+	var lockedFor time.Duration
+	for i, key := range keys {
+		threshold := accountFailureThreshold
+		if i > 0 {
+			threshold = ipFailureThreshold
+		}
+
+		failures, err := t.store.RecordLoginFailure(ctx, key, loginFailureWindow)
+		if err != nil {
+			return errors.Wrap(err, errors.ErrCodeRedisError, "Failed to record login failure")
+		}
+
+		duration := loginLockout(failures, threshold)
+		if duration == 0 {
+			continue
+		}
+		if err := t.store.LockLogin(ctx, key, duration); err != nil {
+			return errors.Wrap(err, errors.ErrCodeRedisError, "Failed to lock login")
+		}
+		if duration > lockedFor {
+			lockedFor = duration
+		}
+
+		details := map[string]interface{}{
+			"failures":   failures,
+			"locked_for": duration.String(),
+			"ip_address": ipAddress,
+		}
+		if i > 0 {
+			t.logger.LogSecurityEvent("login_ip_locked", nil, details)
+			continue
+		}
+
+		var userID *string
+		if user != nil {
+			id := user.ID.String()
+			userID = &id
+		}
+		t.logger.LogSecurityEvent("account_locked", userID, details)
+
+		// Tell the owner the first time in a window, not on every extension
+		if user != nil && failures == int64(threshold) {
+			t.notifyLocked(user, duration)
+		}
+	}
+
+	if lockedFor > 0 {
+		return errLoginLocked(lockedFor)
+	}
+	return nil
+}
+
+// RecordSuccess forgets the account's failed logins. Failures from the IP
+// address are kept, so one valid login cannot reset an attack on others.
+func (t *LoginThrottle) RecordSuccess(ctx context.Context, email string) error {
+	if err := t.store.ClearLoginFailures(ctx, accountThrottleKey(email)); err != nil {
+		return errors.Wrap(err, errors.ErrCodeRedisError, "Failed to clear login failures")
+	}
+	return nil
+}
+
+// notifyLocked mails the account owner without holding up the request
+func (t *LoginThrottle) notifyLocked(user *models.User, lockedFor time.Duration) {
+	msg := &mail.Message{
+		To:      user.Email,
+		Subject: "Your account has been temporarily locked",
+		Body: fmt.Sprintf("Hi %s,\n\nThere were %d failed attempts to log in to your account, so logins "+
+			"are blocked for the next %s.\n\n"+
+			"If this wasn't you, someone may be guessing your password; consider resetting it once "+
+			"the lock expires and turning on two-factor authentication.\n",
+			user.Username, accountFailureThreshold, lockedFor),
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), mailSendTimeout)
+		defer cancel()
+
+		if err := t.mailer.Send(ctx, msg); err != nil {
+			t.logger.WithError(err).WithField("subject", msg.Subject).Error("Failed to send mail")
+		}
+	}()
+}
+
+// loginLockout returns how long to lock logins after the given number of
+// failures, or zero below the threshold
+func loginLockout(failures int64, threshold int) time.Duration {
+	if failures < int64(threshold) {
+		return 0
+	}
+
+	lockout := loginLockoutBase
+	for i := int64(threshold); i < failures && lockout < loginLockoutMax; i++ {
+		lockout *= 2
+	}
+	if lockout > loginLockoutMax {
+		lockout = loginLockoutMax
+	}
+	return lockout
+}
+
+// loginThrottleKeys returns the account key and, if known, the IP key
+func loginThrottleKeys(email, ipAddress string) []string {
+	keys := []string{accountThrottleKey(email)}
+	if ipAddress != "" {
+		keys = append(keys, "ip:"+ipAddress)
+	}
+	return keys
+}
+
+// accountThrottleKey keys failures by a hash of the normalized email, so
+// Redis holds no addresses
+func accountThrottleKey(email string) string {
+	return "account:" + utils.HashToken(strings.ToLower(strings.TrimSpace(email)))
+}
+
+func errLoginLocked(retryAfter time.Duration) error {
+	return errors.NewRateLimited("Too many failed login attempts, try again later", retryAfter)
+}
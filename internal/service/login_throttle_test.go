@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/models"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+type MockLoginAttemptStore struct {
+	failures map[string]int64
+	locks    map[string]time.Duration
+}
+
+func NewMockLoginAttemptStore() *MockLoginAttemptStore {
+	return &MockLoginAttemptStore{
+		failures: make(map[string]int64),
+		locks:    make(map[string]time.Duration),
+	}
+}
+
+func (m *MockLoginAttemptStore) RecordLoginFailure(ctx context.Context, key string, window time.Duration) (int64, error) {
+	m.failures[key]++
+	return m.failures[key], nil
+}
+
+func (m *MockLoginAttemptStore) ClearLoginFailures(ctx context.Context, key string) error {
+	delete(m.failures, key)
+	return nil
+}
+
+func (m *MockLoginAttemptStore) LockLogin(ctx context.Context, key string, duration time.Duration) error {
+	m.locks[key] = duration
+	return nil
+}
+
+func (m *MockLoginAttemptStore) LoginLockRemaining(ctx context.Context, key string) (time.Duration, error) {
+	return m.locks[key], nil
+}
+
+// expire lifts every lockout, as if it had run out
+func (m *MockLoginAttemptStore) expire() {
+	m.locks = make(map[string]time.Duration)
+}
+
+func expectLocked(t *testing.T, err error, retryAfter time.Duration) {
+	t.Helper()
+	appErr := errors.GetAppError(err)
+	if appErr == nil || appErr.Code != errors.ErrCodeRateLimited {
+		t.Fatalf("Expected rate limited error, got: %v", err)
+	}
+	if appErr.RetryAfter != retryAfter {
+		t.Errorf("Expected retry after %v, got %v", retryAfter, appErr.RetryAfter)
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	tests := []struct {
+		failures int64
+		expected time.Duration
+	}{
+		{failures: 4, expected: 0},
+		{failures: 5, expected: time.Minute},
+		{failures: 6, expected: 2 * time.Minute},
+		{failures: 8, expected: 8 * time.Minute},
+		{failures: 11, expected: loginLockoutMax},
+		{failures: 100, expected: loginLockoutMax},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d failures", tt.failures), func(t *testing.T) {
+			if got := loginLockout(tt.failures, accountFailureThreshold); got != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestLoginThrottle(t *testing.T) {
+	ctx := context.Background()
+	user := &models.User{ID: uuid.New(), Username: "testuser", Email: "test@example.com"}
+
+	t.Run("account lockout with backoff", func(t *testing.T) {
+		store := NewMockLoginAttemptStore()
+		mailer := NewMockMailer()
+		throttle := NewLoginThrottle(store, mailer, createTestLogger())
+
+		for i := 1; i < accountFailureThreshold; i++ {
+			if err := throttle.RecordFailure(ctx, user.Email, "203.0.113.1", user); err != nil {
+				t.Fatalf("Expected no lockout after %d failures, got: %v", i, err)
+			}
+		}
+
+		expectLocked(t, throttle.RecordFailure(ctx, user.Email, "203.0.113.1", user), time.Minute)
+		msg := mailer.next(t)
+		if msg.To != user.Email || !strings.Contains(msg.Subject, "locked") {
+			t.Errorf("Unexpected lockout notification: %+v", msg)
+		}
+
+		// Addresses are matched regardless of case, and from any IP
+		expectLocked(t, throttle.Check(ctx, "TEST@example.com", "198.51.100.7"), time.Minute)
+
+		// Failing again once the lock runs out doubles it, without another mail
+		store.expire()
+		expectLocked(t, throttle.RecordFailure(ctx, user.Email, "203.0.113.1", user), 2*time.Minute)
+		select {
+		case msg := <-mailer.sent:
+			t.Errorf("Expected a single notification, got another: %s", msg.Subject)
+		case <-time.After(50 * time.Millisecond):
+		}
+	})
+
+	t.Run("unknown emails are locked too", func(t *testing.T) {
+		store := NewMockLoginAttemptStore()
+		throttle := NewLoginThrottle(store, NewMockMailer(), createTestLogger())
+
+		var err error
+		for i := 0; i < accountFailureThreshold; i++ {
+			err = throttle.RecordFailure(ctx, "nobody@example.com", "", nil)
+		}
+		expectLocked(t, err, time.Minute)
+		expectLocked(t, throttle.Check(ctx, "nobody@example.com", ""), time.Minute)
+	})
+
+	t.Run("IP lockout across accounts", func(t *testing.T) {
+		store := NewMockLoginAttemptStore()
+		throttle := NewLoginThrottle(store, NewMockMailer(), createTestLogger())
+
+		var err error
+		for i := 0; i < ipFailureThreshold; i++ {
+			err = throttle.RecordFailure(ctx, fmt.Sprintf("user%d@example.com", i), "203.0.113.1", nil)
+		}
+		expectLocked(t, err, time.Minute)
+
+		expectLocked(t, throttle.Check(ctx, user.Email, "203.0.113.1"), time.Minute)
+		if err := throttle.Check(ctx, user.Email, "198.51.100.7"); err != nil {
+			t.Errorf("Expected other IP addresses to be unaffected, got: %v", err)
+		}
+	})
+
+	t.Run("success clears account failures only", func(t *testing.T) {
+		store := NewMockLoginAttemptStore()
+		throttle := NewLoginThrottle(store, NewMockMailer(), createTestLogger())
+
+		for i := 1; i < accountFailureThreshold; i++ {
+			throttle.RecordFailure(ctx, user.Email, "203.0.113.1", user)
+		}
+		if err := throttle.RecordSuccess(ctx, user.Email); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+
+		if err := throttle.RecordFailure(ctx, user.Email, "203.0.113.1", user); err != nil {
+			t.Errorf("Expected failure count to restart after success, got: %v", err)
+		}
+		if store.failures["ip:203.0.113.1"] != accountFailureThreshold {
+			t.Errorf("Expected IP failures to be kept, got %d", store.failures["ip:203.0.113.1"])
+		}
+	})
+}
+
+func TestAuthService_LoginLockout(t *testing.T) {
+	ctx := context.Background()
+	userRepo := NewMockUserRepository()
+	hashedPassword, _ := utils.HashPassword("password123")
+	user := &models.User{ID: uuid.New(), Username: "testuser", Email: "test@example.com", PasswordHash: hashedPassword}
+	userRepo.usersByEmail[user.Email] = user
+	userRepo.usersByID[user.ID.String()] = user
+
+	store := NewMockLoginAttemptStore()
+	throttle := NewLoginThrottle(store, NewMockMailer(), createTestLogger())
+	authService := NewAuthService(userRepo, NewMockCache(), NewMockAuthMiddleware(), NewMockRefreshTokenRepository(), NewMockSessionRepository(), NewMockMFAVerifier(), throttle, createTestLogger())
+	device := models.DeviceInfo{IPAddress: "203.0.113.1"}
+
+	for i := 1; i < accountFailureThreshold; i++ {
+		_, _, _, err := authService.LoginUser(ctx, user.Email, "wrongpassword", device)
+		if err != errors.ErrInvalidCredentials {
+			t.Fatalf("Expected invalid credentials on attempt %d, got: %v", i, err)
+		}
+	}
+
+	_, _, _, err := authService.LoginUser(ctx, user.Email, "wrongpassword", device)
+	expectLocked(t, err, time.Minute)
+
+	// The right password does not get through while locked
+	_, tokens, _, err := authService.LoginUser(ctx, user.Email, "password123", device)
+	expectLocked(t, err, time.Minute)
+	if tokens != nil {
+		t.Error("Expected no tokens while locked")
+	}
+
+	store.expire()
+	if _, _, _, err := authService.LoginUser(ctx, user.Email, "password123", device); err != nil {
+		t.Fatalf("Expected login to succeed after the lockout, got: %v", err)
+	}
+	if store.failures[accountThrottleKey(user.Email)] != 0 {
+		t.Error("Expected successful login to clear account failures")
+	}
+}
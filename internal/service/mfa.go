@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strings"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/logging"
+	"musicapp/internal/models"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// MFAChallengeTTL is how long a user has to enter their second factor after
+// a successful password check
+const MFAChallengeTTL = 5 * time.Minute
+
+const (
+	// mfaChallengeAttempts is how many codes may be tried against one
+	// challenge before the user has to log in again
+	mfaChallengeAttempts = 5
+
+	// mfaCodeAttemptWindow is how long the same number of attempts applies
+	// to confirming or turning off an authenticator while signed in
+	mfaCodeAttemptWindow = 15 * time.Minute
+
+	recoveryCodeCount = 10
+	totpIssuer        = "MusicApp"
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+var (
+	errInvalidMFACode      = errors.New(errors.ErrCodeInvalidCredentials, "Invalid authentication code")
+	errInvalidMFAChallenge = errors.New(errors.ErrCodeTokenInvalid, "Invalid or expired MFA token; please log in again")
+	errMFAAlreadyEnabled   = errors.New(errors.ErrCodeConflict, "Two-factor authentication is already enabled")
+	errMFANotPending       = errors.New(errors.ErrCodeInvalidInput, "Start two-factor enrollment first")
+	errMFANotEnabled       = errors.New(errors.ErrCodeInvalidInput, "Two-factor authentication is not enabled")
+	errTooManyMFAAttempts  = errors.New(errors.ErrCodeRateLimited, "Too many authentication codes tried, try again later")
+)
+
+// MFARepository interface for authenticator and recovery code storage
+type MFARepository interface {
+	GetTOTP(ctx context.Context, userID uuid.UUID) (*models.TOTPCredential, error)
+	IsTOTPEnabled(ctx context.Context, userID uuid.UUID) (bool, error)
+	SavePendingTOTP(ctx context.Context, userID uuid.UUID, secret string) (bool, error)
+	ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeHashes []string) error
+	MarkTOTPStepUsed(ctx context.Context, userID uuid.UUID, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error)
+	DeleteTOTP(ctx context.Context, userID uuid.UUID) error
+}
+
+// MFAChallengeStore holds pending two-step logins; implemented by cache.Cache
+type MFAChallengeStore interface {
+	SetMFAChallenge(ctx context.Context, tokenHash, userID string, expiration time.Duration) error
+	GetMFAChallenge(ctx context.Context, tokenHash string) (string, error)
+	DeleteMFAChallenge(ctx context.Context, tokenHash string) (bool, error)
+	CheckRateLimit(ctx context.Context, key string, limit int, window time.Duration) (bool, error)
+}
+
+// UserRepositoryForMFA interface for user operations
+type UserRepositoryForMFA interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+}
+
+// MFAService handles TOTP two-factor authentication: enrollment, recovery
+// codes and the second step of login
+type MFAService struct {
+	mfaRepo    MFARepository
+	userRepo   UserRepositoryForMFA
+	challenges MFAChallengeStore
+	logger     *logging.Logger
+}
+
+func NewMFAService(mfaRepo MFARepository, userRepo UserRepositoryForMFA, challenges MFAChallengeStore, logger *logging.Logger) *MFAService {
+	return &MFAService{
+		mfaRepo:    mfaRepo,
+		userRepo:   userRepo,
+		challenges: challenges,
+		logger:     logger,
+	}
+}
+
+// EnrollTOTP generates a new authenticator secret for the user. Two-factor
+// login is not enforced until the secret is confirmed with ConfirmTOTP.
+func (s *MFAService) EnrollTOTP(ctx context.Context, userID uuid.UUID) (*models.TOTPEnrollment, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return nil, errors.NewUserNotFound(userID.String())
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternalError, "Failed to generate secret")
+	}
+
+	saved, err := s.mfaRepo.SavePendingTOTP(ctx, userID, secret)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to store secret")
+	}
+	if !saved {
+		return nil, errMFAAlreadyEnabled
+	}
+
+	return &models.TOTPEnrollment{
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTOTP turns on two-factor login once the user enters a valid code
+// from their app, and returns one-time recovery codes. The codes are only
+// shown this once.
+func (s *MFAService) ConfirmTOTP(ctx context.Context, userID uuid.UUID, code string) ([]string, error) {
+	credential, err := s.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil || credential == nil {
+		return nil, errMFANotPending
+	}
+	if credential.ConfirmedAt != nil {
+		return nil, errMFAAlreadyEnabled
+	}
+
+	if err := s.checkCodeAttempts(ctx, "mfa_confirm", userID); err != nil {
+		return nil, err
+	}
+
+	step, ok := utils.ValidateTOTP(credential.Secret, code, time.Now())
+	if !ok {
+		return nil, errInvalidMFACode
+	}
+
+	codes := make([]string, recoveryCodeCount)
+	hashes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		codes[i], err = generateRecoveryCode()
+		if err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeInternalError, "Failed to generate recovery codes")
+		}
+		hashes[i] = hashRecoveryCode(codes[i])
+	}
+
+	if err := s.mfaRepo.ConfirmTOTP(ctx, userID, step, hashes); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to enable two-factor authentication")
+	}
+
+	userIDStr := userID.String()
+	s.logger.LogSecurityEvent("mfa_enabled", &userIDStr, nil)
+	return codes, nil
+}
+
+// DisableTOTP turns off two-factor login. It takes the account password, if
+// it has one, and a current code or a recovery code. Attempts are rate
+// limited per user, so a stolen access token alone cannot remove it.
+func (s *MFAService) DisableTOTP(ctx context.Context, userID uuid.UUID, password, code string) error {
+	credential, err := s.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil || credential == nil || credential.ConfirmedAt == nil {
+		return errMFANotEnabled
+	}
+
+	if err := s.checkCodeAttempts(ctx, "mfa_disable", userID); err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return errors.NewUserNotFound(userID.String())
+	}
+	// Accounts created through a sign-in provider have no password
+	if user.PasswordHash != "" && !utils.CheckPasswordHash(password, user.PasswordHash) {
+		return errIncorrectPassword
+	}
+
+	if err := s.verifyCode(ctx, credential, code); err != nil {
+		return err
+	}
+
+	if err := s.mfaRepo.DeleteTOTP(ctx, userID); err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to disable two-factor authentication")
+	}
+
+	userIDStr := userID.String()
+	s.logger.LogSecurityEvent("mfa_disabled", &userIDStr, nil)
+	return nil
+}
+
+// IsEnabled reports whether logging in as the user needs a second factor
+func (s *MFAService) IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	enabled, err := s.mfaRepo.IsTOTPEnabled(ctx, userID)
+	if err != nil {
+		return false, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to check two-factor authentication")
+	}
+	return enabled, nil
+}
+
+// StartChallenge records that the user passed the password check and
+// returns the short-lived token that CompleteChallenge exchanges
+func (s *MFAService) StartChallenge(ctx context.Context, userID uuid.UUID) (*models.MFAChallenge, error) {
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternalError, "Failed to generate MFA token")
+	}
+
+	if err := s.challenges.SetMFAChallenge(ctx, utils.HashToken(token), userID.String(), MFAChallengeTTL); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeRedisError, "Failed to store MFA challenge")
+	}
+
+	return &models.MFAChallenge{
+		Token:     token,
+		ExpiresIn: int(MFAChallengeTTL.Seconds()),
+	}, nil
+}
+
+// CompleteChallenge checks a second-factor code against a pending login and
+// returns the user it was for. Each challenge allows a few attempts and can
+// only be completed once.
+func (s *MFAService) CompleteChallenge(ctx context.Context, token, code string) (uuid.UUID, error) {
+	if token == "" {
+		return uuid.Nil, errInvalidMFAChallenge
+	}
+	tokenHash := utils.HashToken(token)
+
+	userIDStr, err := s.challenges.GetMFAChallenge(ctx, tokenHash)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, errors.ErrCodeRedisError, "Failed to load MFA challenge")
+	}
+	userID, err := uuid.Parse(userIDStr)
+	if err != nil {
+		return uuid.Nil, errInvalidMFAChallenge
+	}
+
+	allowed, err := s.challenges.CheckRateLimit(ctx, "auth:mfa_attempts:"+tokenHash, mfaChallengeAttempts, MFAChallengeTTL)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, errors.ErrCodeRedisError, "Failed to check rate limit")
+	}
+	if !allowed {
+		s.challenges.DeleteMFAChallenge(ctx, tokenHash)
+		return uuid.Nil, errInvalidMFAChallenge
+	}
+
+	credential, err := s.mfaRepo.GetTOTP(ctx, userID)
+	if err != nil || credential == nil || credential.ConfirmedAt == nil {
+		return uuid.Nil, errInvalidMFAChallenge
+	}
+	if err := s.verifyCode(ctx, credential, code); err != nil {
+		s.logger.LogSecurityEvent("mfa_failed", &userIDStr, nil)
+		return uuid.Nil, err
+	}
+
+	completed, err := s.challenges.DeleteMFAChallenge(ctx, tokenHash)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, errors.ErrCodeRedisError, "Failed to complete MFA challenge")
+	}
+	if !completed {
+		return uuid.Nil, errInvalidMFAChallenge
+	}
+
+	return userID, nil
+}
+
+// checkCodeAttempts limits how many codes a signed-in user can try for an
+// action, so a stolen access token cannot be used to guess one
+func (s *MFAService) checkCodeAttempts(ctx context.Context, action string, userID uuid.UUID) error {
+	allowed, err := s.challenges.CheckRateLimit(ctx, action+":"+userID.String(), mfaChallengeAttempts, mfaCodeAttemptWindow)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeRedisError, "Failed to check rate limit")
+	}
+	if !allowed {
+		return errTooManyMFAAttempts
+	}
+	return nil
+}
+
+// verifyCode accepts either a current authenticator code or an unused
+// recovery code, consuming whichever was used
+func (s *MFAService) verifyCode(ctx context.Context, credential *models.TOTPCredential, code string) error {
+	code = strings.TrimSpace(code)
+
+	if step, ok := utils.ValidateTOTP(credential.Secret, code, time.Now()); ok {
+		fresh, err := s.mfaRepo.MarkTOTPStepUsed(ctx, credential.UserID, step)
+		if err != nil {
+			return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to record code use")
+		}
+		if !fresh {
+			return errInvalidMFACode
+		}
+		return nil
+	}
+
+	if len(code) == utils.TOTPDigits {
+		return errInvalidMFACode
+	}
+
+	used, err := s.mfaRepo.UseRecoveryCode(ctx, credential.UserID, hashRecoveryCode(code))
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to check recovery code")
+	}
+	if !used {
+		return errInvalidMFACode
+	}
+
+	userID := credential.UserID.String()
+	s.logger.LogSecurityEvent("mfa_recovery_code_used", &userID, nil)
+	return nil
+}
+
+// generateRecoveryCode returns a random code formatted as xxxxx-xxxxx
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, 10)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))[:10]
+	return code[:5] + "-" + code[5:], nil
+}
+
+// hashRecoveryCode hashes a recovery code for storage, ignoring case and
+// separators so codes can be typed loosely
+func hashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+	return utils.HashToken(normalized)
+}
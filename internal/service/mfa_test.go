@@ -0,0 +1,329 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"musicapp/internal/models"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// Mock implementations for MFAService testing
+
+type MockMFARepository struct {
+	credentials   map[uuid.UUID]*models.TOTPCredential
+	recoveryCodes map[uuid.UUID]map[string]bool // code hash -> used
+}
+
+func NewMockMFARepository() *MockMFARepository {
+	return &MockMFARepository{
+		credentials:   make(map[uuid.UUID]*models.TOTPCredential),
+		recoveryCodes: make(map[uuid.UUID]map[string]bool),
+	}
+}
+
+func (m *MockMFARepository) GetTOTP(ctx context.Context, userID uuid.UUID) (*models.TOTPCredential, error) {
+	if credential, exists := m.credentials[userID]; exists {
+		return credential, nil
+	}
+	return nil, fmt.Errorf("no rows in result set")
+}
+
+func (m *MockMFARepository) IsTOTPEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	credential, exists := m.credentials[userID]
+	return exists && credential.ConfirmedAt != nil, nil
+}
+
+func (m *MockMFARepository) SavePendingTOTP(ctx context.Context, userID uuid.UUID, secret string) (bool, error) {
+	if credential, exists := m.credentials[userID]; exists && credential.ConfirmedAt != nil {
+		return false, nil
+	}
+	m.credentials[userID] = &models.TOTPCredential{UserID: userID, Secret: secret, CreatedAt: time.Now()}
+	return true, nil
+}
+
+func (m *MockMFARepository) ConfirmTOTP(ctx context.Context, userID uuid.UUID, step int64, recoveryCodeHashes []string) error {
+	now := time.Now()
+	m.credentials[userID].ConfirmedAt = &now
+	m.credentials[userID].LastUsedStep = &step
+	m.recoveryCodes[userID] = make(map[string]bool)
+	for _, hash := range recoveryCodeHashes {
+		m.recoveryCodes[userID][hash] = false
+	}
+	return nil
+}
+
+func (m *MockMFARepository) MarkTOTPStepUsed(ctx context.Context, userID uuid.UUID, step int64) (bool, error) {
+	credential := m.credentials[userID]
+	if credential.LastUsedStep != nil && *credential.LastUsedStep >= step {
+		return false, nil
+	}
+	credential.LastUsedStep = &step
+	return true, nil
+}
+
+func (m *MockMFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string) (bool, error) {
+	used, exists := m.recoveryCodes[userID][codeHash]
+	if !exists || used {
+		return false, nil
+	}
+	m.recoveryCodes[userID][codeHash] = true
+	return true, nil
+}
+
+func (m *MockMFARepository) DeleteTOTP(ctx context.Context, userID uuid.UUID) error {
+	delete(m.credentials, userID)
+	delete(m.recoveryCodes, userID)
+	return nil
+}
+
+type MockMFAChallengeStore struct {
+	*MockRateLimiter
+	challenges map[string]string
+}
+
+func NewMockMFAChallengeStore() *MockMFAChallengeStore {
+	return &MockMFAChallengeStore{
+		MockRateLimiter: NewMockRateLimiter(),
+		challenges:      make(map[string]string),
+	}
+}
+
+func (m *MockMFAChallengeStore) SetMFAChallenge(ctx context.Context, tokenHash, userID string, expiration time.Duration) error {
+	m.challenges[tokenHash] = userID
+	return nil
+}
+
+func (m *MockMFAChallengeStore) GetMFAChallenge(ctx context.Context, tokenHash string) (string, error) {
+	return m.challenges[tokenHash], nil
+}
+
+func (m *MockMFAChallengeStore) DeleteMFAChallenge(ctx context.Context, tokenHash string) (bool, error) {
+	_, exists := m.challenges[tokenHash]
+	delete(m.challenges, tokenHash)
+	return exists, nil
+}
+
+// codeAt returns the authenticator code for a secret some steps from now
+func codeAt(t *testing.T, secret string, offset int64) string {
+	t.Helper()
+	code, err := utils.TOTPCode(secret, utils.TOTPStep(time.Now())+offset)
+	if err != nil {
+		t.Fatalf("Failed to generate code: %v", err)
+	}
+	return code
+}
+
+func setupMFA(t *testing.T) (*MFAService, *AuthService, *MockMFARepository, *models.User) {
+	t.Helper()
+	userRepo := NewMockUserRepository()
+	hashedPassword, _ := utils.HashPassword("password123")
+	user := &models.User{ID: uuid.New(), Username: "testuser", Email: "test@example.com", PasswordHash: hashedPassword}
+	userRepo.usersByEmail[user.Email] = user
+	userRepo.usersByID[user.ID.String()] = user
+
+	mfaRepo := NewMockMFARepository()
+	mfaService := NewMFAService(mfaRepo, userRepo, NewMockMFAChallengeStore(), createTestLogger())
+	authService := NewAuthService(userRepo, NewMockCache(), NewMockAuthMiddleware(), NewMockRefreshTokenRepository(), NewMockSessionRepository(), mfaService, NewMockLoginThrottle(), createTestLogger())
+	return mfaService, authService, mfaRepo, user
+}
+
+// enableMFA enrolls and confirms TOTP for the user, returning the secret
+// and recovery codes
+func enableMFA(t *testing.T, mfaService *MFAService, userID uuid.UUID) (string, []string) {
+	t.Helper()
+	enrollment, err := mfaService.EnrollTOTP(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("Expected enrollment to succeed, got: %v", err)
+	}
+	// Confirm with the previous step's code so the current one is still
+	// fresh for the test that follows
+	codes, err := mfaService.ConfirmTOTP(context.Background(), userID, codeAt(t, enrollment.Secret, -1))
+	if err != nil {
+		t.Fatalf("Expected confirmation to succeed, got: %v", err)
+	}
+	return enrollment.Secret, codes
+}
+
+func TestMFAService_Enrollment(t *testing.T) {
+	t.Run("enroll and confirm", func(t *testing.T) {
+		mfaService, _, _, user := setupMFA(t)
+
+		enrollment, err := mfaService.EnrollTOTP(context.Background(), user.ID)
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if !strings.HasPrefix(enrollment.ProvisioningURI, "otpauth://totp/MusicApp:test@example.com?") {
+			t.Errorf("Unexpected provisioning URI: %s", enrollment.ProvisioningURI)
+		}
+
+		enabled, _ := mfaService.IsEnabled(context.Background(), user.ID)
+		if enabled {
+			t.Error("Expected MFA to stay off until confirmed")
+		}
+
+		if _, err := mfaService.ConfirmTOTP(context.Background(), user.ID, "abcdef"); err == nil {
+			t.Error("Expected a wrong code to be rejected")
+		}
+
+		codes, err := mfaService.ConfirmTOTP(context.Background(), user.ID, codeAt(t, enrollment.Secret, 0))
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if len(codes) != recoveryCodeCount {
+			t.Errorf("Expected %d recovery codes, got %d", recoveryCodeCount, len(codes))
+		}
+
+		enabled, _ = mfaService.IsEnabled(context.Background(), user.ID)
+		if !enabled {
+			t.Error("Expected MFA to be enabled after confirmation")
+		}
+	})
+
+	t.Run("cannot re-enroll while enabled", func(t *testing.T) {
+		mfaService, _, _, user := setupMFA(t)
+		enableMFA(t, mfaService, user.ID)
+
+		_, err := mfaService.EnrollTOTP(context.Background(), user.ID)
+		if err == nil || !strings.Contains(err.Error(), "already enabled") {
+			t.Errorf("Expected already enabled error, got: %v", err)
+		}
+	})
+
+	t.Run("disable requires the password and a code", func(t *testing.T) {
+		mfaService, _, _, user := setupMFA(t)
+		_, codes := enableMFA(t, mfaService, user.ID)
+
+		if err := mfaService.DisableTOTP(context.Background(), user.ID, "password123", "not-a-code"); err == nil {
+			t.Error("Expected disabling without a valid code to fail")
+		}
+		err := mfaService.DisableTOTP(context.Background(), user.ID, "wrong-password", codes[0])
+		if err == nil || !strings.Contains(err.Error(), "password is incorrect") {
+			t.Errorf("Expected disabling with a wrong password to fail, got: %v", err)
+		}
+		if err := mfaService.DisableTOTP(context.Background(), user.ID, "password123", codes[0]); err != nil {
+			t.Fatalf("Expected a recovery code to disable MFA, got: %v", err)
+		}
+
+		enabled, _ := mfaService.IsEnabled(context.Background(), user.ID)
+		if enabled {
+			t.Error("Expected MFA to be disabled")
+		}
+	})
+}
+
+func TestMFAService_CodeAttemptsAreLimited(t *testing.T) {
+	t.Run("disable", func(t *testing.T) {
+		mfaService, _, _, user := setupMFA(t)
+		secret, _ := enableMFA(t, mfaService, user.ID)
+
+		for i := 0; i < mfaChallengeAttempts; i++ {
+			if err := mfaService.DisableTOTP(context.Background(), user.ID, "password123", "000000"); err == nil {
+				t.Fatal("Expected a wrong code to be rejected")
+			}
+		}
+		err := mfaService.DisableTOTP(context.Background(), user.ID, "password123", codeAt(t, secret, 0))
+		if err == nil || !strings.Contains(err.Error(), "Too many") {
+			t.Errorf("Expected the right code to be refused once the limit is hit, got: %v", err)
+		}
+	})
+
+	t.Run("confirm", func(t *testing.T) {
+		mfaService, _, _, user := setupMFA(t)
+		enrollment, err := mfaService.EnrollTOTP(context.Background(), user.ID)
+		if err != nil {
+			t.Fatalf("Expected enrollment to succeed, got: %v", err)
+		}
+
+		for i := 0; i < mfaChallengeAttempts; i++ {
+			if _, err := mfaService.ConfirmTOTP(context.Background(), user.ID, "abcdef"); err == nil {
+				t.Fatal("Expected a wrong code to be rejected")
+			}
+		}
+		_, err = mfaService.ConfirmTOTP(context.Background(), user.ID, codeAt(t, enrollment.Secret, 0))
+		if err == nil || !strings.Contains(err.Error(), "Too many") {
+			t.Errorf("Expected the right code to be refused once the limit is hit, got: %v", err)
+		}
+	})
+}
+
+func TestAuthService_LoginWithMFA(t *testing.T) {
+	login := func(t *testing.T, authService *AuthService, user *models.User) *models.MFAChallenge {
+		t.Helper()
+		_, tokens, challenge, err := authService.LoginUser(context.Background(), user.Email, "password123", models.DeviceInfo{})
+		if err != nil {
+			t.Fatalf("Expected password check to succeed, got: %v", err)
+		}
+		if tokens != nil {
+			t.Fatal("Expected no tokens before the second factor")
+		}
+		if challenge == nil || challenge.Token == "" {
+			t.Fatal("Expected an MFA challenge")
+		}
+		return challenge
+	}
+
+	t.Run("authenticator code completes login once", func(t *testing.T) {
+		mfaService, authService, _, user := setupMFA(t)
+		secret, _ := enableMFA(t, mfaService, user.ID)
+		challenge := login(t, authService, user)
+
+		_, tokens, err := authService.VerifyMFA(context.Background(), challenge.Token, codeAt(t, secret, 0), models.DeviceInfo{})
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if tokens == nil || tokens.AccessToken == "" || tokens.RefreshToken == "" {
+			t.Error("Expected access and refresh token")
+		}
+
+		if _, _, err := authService.VerifyMFA(context.Background(), challenge.Token, codeAt(t, secret, 0), models.DeviceInfo{}); err == nil {
+			t.Error("Expected the challenge to be single-use")
+		}
+	})
+
+	t.Run("codes cannot be replayed", func(t *testing.T) {
+		mfaService, authService, _, user := setupMFA(t)
+		secret, _ := enableMFA(t, mfaService, user.ID)
+		code := codeAt(t, secret, 0)
+
+		if _, _, err := authService.VerifyMFA(context.Background(), login(t, authService, user).Token, code, models.DeviceInfo{}); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		_, _, err := authService.VerifyMFA(context.Background(), login(t, authService, user).Token, code, models.DeviceInfo{})
+		if err == nil || !strings.Contains(err.Error(), "Invalid authentication code") {
+			t.Errorf("Expected replayed code to be rejected, got: %v", err)
+		}
+	})
+
+	t.Run("recovery codes work once", func(t *testing.T) {
+		mfaService, authService, _, user := setupMFA(t)
+		_, codes := enableMFA(t, mfaService, user.ID)
+
+		if _, _, err := authService.VerifyMFA(context.Background(), login(t, authService, user).Token, strings.ToUpper(codes[0]), models.DeviceInfo{}); err != nil {
+			t.Fatalf("Expected recovery code to work, got: %v", err)
+		}
+		if _, _, err := authService.VerifyMFA(context.Background(), login(t, authService, user).Token, codes[0], models.DeviceInfo{}); err == nil {
+			t.Error("Expected used recovery code to be rejected")
+		}
+	})
+
+	t.Run("challenge is dropped after too many attempts", func(t *testing.T) {
+		mfaService, authService, _, user := setupMFA(t)
+		secret, _ := enableMFA(t, mfaService, user.ID)
+		challenge := login(t, authService, user)
+
+		for i := 0; i < mfaChallengeAttempts; i++ {
+			authService.VerifyMFA(context.Background(), challenge.Token, "wrong-code", models.DeviceInfo{})
+		}
+
+		_, _, err := authService.VerifyMFA(context.Background(), challenge.Token, codeAt(t, secret, 0), models.DeviceInfo{})
+		if err == nil || !strings.Contains(err.Error(), "MFA token") {
+			t.Errorf("Expected the challenge to be invalidated, got: %v", err)
+		}
+	})
+}
@@ -0,0 +1,438 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/logging"
+	"musicapp/internal/models"
+	"musicapp/internal/oidc"
+	"musicapp/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// OIDCStateTTL is how long a user has to finish signing in at the provider
+const OIDCStateTTL = 10 * time.Minute
+
+var (
+	errUnknownProvider       = errors.New(errors.ErrCodeNotFound, "Unknown identity provider")
+	errInvalidOIDCState      = errors.New(errors.ErrCodeInvalidInput, "Invalid or expired sign-in state; please start again")
+	errOIDCSignInFailed      = errors.New(errors.ErrCodeInvalidCredentials, "Sign-in with the identity provider failed")
+	errIdentityLinked        = errors.New(errors.ErrCodeConflict, "This provider account is already linked to another user")
+	errProviderAlreadyLinked = errors.New(errors.ErrCodeConflict, "You already linked an account at this provider")
+	errOIDCEmailTaken        = errors.New(errors.ErrCodeConflict, "An account with this email already exists; log in and link the provider from your account")
+	errOIDCNoEmail           = errors.New(errors.ErrCodeInvalidInput, "The identity provider did not share an email address")
+	errIdentityNotFound      = errors.New(errors.ErrCodeNotFound, "Identity not found")
+	errLastSignInMethod      = errors.New(errors.ErrCodeInvalidInput, "Set a password before unlinking your only sign-in method")
+)
+
+// OIDCProvider is an external identity provider; implemented by oidc.Provider
+type OIDCProvider interface {
+	Name() string
+	AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error)
+	Exchange(ctx context.Context, code, codeVerifier, nonce string) (*oidc.Identity, error)
+}
+
+// IdentityRepository interface for linked provider accounts
+type IdentityRepository interface {
+	Create(ctx context.Context, identity *models.Identity) (bool, error)
+	GetByProviderSubject(ctx context.Context, provider, subject string) (*models.Identity, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Identity, error)
+	TouchLogin(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, id, userID uuid.UUID) (bool, error)
+}
+
+// OIDCStateStore holds sign-ins in progress; implemented by cache.Cache
+type OIDCStateStore interface {
+	SetOIDCState(ctx context.Context, stateHash, value string, expiration time.Duration) error
+	TakeOIDCState(ctx context.Context, stateHash string) (string, error)
+}
+
+// UserRepositoryForOIDC interface for user operations
+type UserRepositoryForOIDC interface {
+	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetByEmail(ctx context.Context, email string) (*models.User, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	Create(ctx context.Context, user *models.User) error
+	MarkEmailVerified(ctx context.Context, userID uuid.UUID) error
+}
+
+// LoginCompleter starts a session once a user is identified; implemented
+// by AuthService
+type LoginCompleter interface {
+	CompleteLogin(ctx context.Context, user *models.User, device models.DeviceInfo) (*models.TokenPair, *models.MFAChallenge, error)
+}
+
+// oidcState is what a sign-in remembers between sending the user to the
+// provider and their return. LinkUserID is set when linking a provider to
+// a signed-in account rather than logging in.
+type oidcState struct {
+	Provider   string `json:"provider"`
+	Verifier   string `json:"verifier"`
+	Nonce      string `json:"nonce"`
+	LinkUserID string `json:"link_user_id,omitempty"`
+}
+
+// OIDCService handles sign-in through external identity providers and the
+// linking of provider accounts to users
+type OIDCService struct {
+	providers    map[string]OIDCProvider
+	identityRepo IdentityRepository
+	userRepo     UserRepositoryForOIDC
+	states       OIDCStateStore
+	logins       LoginCompleter
+	logger       *logging.Logger
+}
+
+func NewOIDCService(providers []OIDCProvider, identityRepo IdentityRepository, userRepo UserRepositoryForOIDC, states OIDCStateStore, logins LoginCompleter, logger *logging.Logger) *OIDCService {
+	byName := make(map[string]OIDCProvider, len(providers))
+	for _, provider := range providers {
+		byName[provider.Name()] = provider
+	}
+
+	return &OIDCService{
+		providers:    byName,
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		states:       states,
+		logins:       logins,
+		logger:       logger,
+	}
+}
+
+// Providers lists the configured provider names
+func (s *OIDCService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// StartLogin returns the URL that signs the user in at the provider
+func (s *OIDCService) StartLogin(ctx context.Context, providerName string) (*models.OIDCAuthorization, error) {
+	return s.start(ctx, providerName, "")
+}
+
+// StartLink returns the URL that links an account at the provider to the
+// signed-in user
+func (s *OIDCService) StartLink(ctx context.Context, providerName string, userID uuid.UUID) (*models.OIDCAuthorization, error) {
+	return s.start(ctx, providerName, userID.String())
+}
+
+func (s *OIDCService) start(ctx context.Context, providerName, linkUserID string) (*models.OIDCAuthorization, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, errUnknownProvider
+	}
+
+	state, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternalError, "Failed to generate state")
+	}
+	nonce, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternalError, "Failed to generate nonce")
+	}
+	verifier, err := oidc.GenerateCodeVerifier()
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternalError, "Failed to generate code verifier")
+	}
+
+	authURL, err := provider.AuthCodeURL(ctx, state, nonce, oidc.CodeChallenge(verifier))
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeExternalService, "Identity provider is unavailable")
+	}
+
+	value, err := json.Marshal(oidcState{
+		Provider:   providerName,
+		Verifier:   verifier,
+		Nonce:      nonce,
+		LinkUserID: linkUserID,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeInternalError, "Failed to encode state")
+	}
+	if err := s.states.SetOIDCState(ctx, utils.HashToken(state), string(value), OIDCStateTTL); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeRedisError, "Failed to store sign-in state")
+	}
+
+	return &models.OIDCAuthorization{
+		AuthorizationURL: authURL,
+		ExpiresIn:        int(OIDCStateTTL.Seconds()),
+	}, nil
+}
+
+// CompleteLogin finishes a provider sign-in with the code and state the
+// provider redirected back with. A provider account seen for the first
+// time creates a new user, unless its email already belongs to one; that
+// user has to link the provider from their account instead, so a provider
+// cannot be used to take over an existing account.
+func (s *OIDCService) CompleteLogin(ctx context.Context, providerName, code, state string, device models.DeviceInfo) (*models.User, *models.TokenPair, *models.MFAChallenge, error) {
+	provider, stored, err := s.takeState(ctx, providerName, state)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if stored.LinkUserID != "" {
+		return nil, nil, nil, errInvalidOIDCState
+	}
+
+	external, err := s.exchange(ctx, provider, code, stored)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var user *models.User
+	identity, err := s.identityRepo.GetByProviderSubject(ctx, providerName, external.Subject)
+	if err == nil && identity != nil {
+		user, err = s.userRepo.GetByID(ctx, identity.UserID)
+		if err != nil || user == nil {
+			return nil, nil, nil, errors.NewUserNotFound(identity.UserID.String())
+		}
+		if err := s.identityRepo.TouchLogin(ctx, identity.ID); err != nil {
+			// Last login is informational; don't fail the sign-in over it
+			s.logger.WithError(err).Warn("Failed to record identity login")
+		}
+	} else {
+		user, err = s.createUser(ctx, providerName, external)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+	}
+
+	tokens, challenge, err := s.logins.CompleteLogin(ctx, user, device)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return user, tokens, challenge, nil
+}
+
+// CompleteLink finishes linking a provider account to the signed-in user.
+// The state must have been issued to the same user, so a link started by
+// someone else cannot attach their provider account to this user.
+func (s *OIDCService) CompleteLink(ctx context.Context, providerName, code, state string, userID uuid.UUID) (*models.Identity, error) {
+	provider, stored, err := s.takeState(ctx, providerName, state)
+	if err != nil {
+		return nil, err
+	}
+	if stored.LinkUserID != userID.String() {
+		return nil, errInvalidOIDCState
+	}
+
+	external, err := s.exchange(ctx, provider, code, stored)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.identityRepo.GetByProviderSubject(ctx, providerName, external.Subject)
+	if err == nil && existing != nil {
+		if existing.UserID == userID {
+			return existing, nil
+		}
+		return nil, errIdentityLinked
+	}
+
+	identity := newIdentity(userID, providerName, external)
+	created, err := s.identityRepo.Create(ctx, identity)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to link identity")
+	}
+	if !created {
+		return nil, errProviderAlreadyLinked
+	}
+
+	userIDStr := userID.String()
+	s.logger.LogSecurityEvent("identity_linked", &userIDStr, map[string]interface{}{"provider": providerName})
+	return identity, nil
+}
+
+// ListIdentities lists the provider accounts linked to the user
+func (s *OIDCService) ListIdentities(ctx context.Context, userID uuid.UUID) ([]*models.Identity, error) {
+	identities, err := s.identityRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to list identities")
+	}
+	return identities, nil
+}
+
+// Unlink removes a linked provider account. Users without a password must
+// keep at least one, or they could no longer sign in.
+func (s *OIDCService) Unlink(ctx context.Context, userID, identityID uuid.UUID) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return errors.NewUserNotFound(userID.String())
+	}
+
+	identities, err := s.identityRepo.GetByUserID(ctx, userID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to list identities")
+	}
+
+	var target *models.Identity
+	for _, identity := range identities {
+		if identity.ID == identityID {
+			target = identity
+		}
+	}
+	if target == nil {
+		return errIdentityNotFound
+	}
+	if user.PasswordHash == "" && len(identities) == 1 {
+		return errLastSignInMethod
+	}
+
+	deleted, err := s.identityRepo.Delete(ctx, identityID, userID)
+	if err != nil {
+		return errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to unlink identity")
+	}
+	if !deleted {
+		return errIdentityNotFound
+	}
+
+	userIDStr := userID.String()
+	s.logger.LogSecurityEvent("identity_unlinked", &userIDStr, map[string]interface{}{"provider": target.Provider})
+	return nil
+}
+
+// takeState consumes a sign-in state, which must belong to the provider
+// the user came back from
+func (s *OIDCService) takeState(ctx context.Context, providerName, state string) (OIDCProvider, *oidcState, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return nil, nil, errUnknownProvider
+	}
+	if state == "" {
+		return nil, nil, errInvalidOIDCState
+	}
+
+	value, err := s.states.TakeOIDCState(ctx, utils.HashToken(state))
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errors.ErrCodeRedisError, "Failed to load sign-in state")
+	}
+
+	var stored oidcState
+	if value == "" || json.Unmarshal([]byte(value), &stored) != nil || stored.Provider != providerName {
+		return nil, nil, errInvalidOIDCState
+	}
+
+	return provider, &stored, nil
+}
+
+func (s *OIDCService) exchange(ctx context.Context, provider OIDCProvider, code string, stored *oidcState) (*oidc.Identity, error) {
+	external, err := provider.Exchange(ctx, code, stored.Verifier, stored.Nonce)
+	if err != nil {
+		s.logger.LogSecurityEvent("oidc_sign_in_failed", nil, map[string]interface{}{
+			"provider": provider.Name(),
+			"error":    err.Error(),
+		})
+		return nil, errOIDCSignInFailed
+	}
+	return external, nil
+}
+
+// createUser signs up a new user from a provider account and links it
+func (s *OIDCService) createUser(ctx context.Context, providerName string, external *oidc.Identity) (*models.User, error) {
+	if external.Email == "" {
+		return nil, errOIDCNoEmail
+	}
+	if existing, err := s.userRepo.GetByEmail(ctx, external.Email); err == nil && existing != nil {
+		return nil, errOIDCEmailTaken
+	}
+
+	username, err := s.availableUsername(ctx, external)
+	if err != nil {
+		return nil, err
+	}
+
+	// Users from a provider have no password until they set one through
+	// the password reset flow
+	user := &models.User{
+		ID:       uuid.New(),
+		Username: username,
+		Email:    external.Email,
+	}
+	if external.Name != "" {
+		user.DisplayName = &external.Name
+	}
+	if err := s.userRepo.Create(ctx, user); err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to create user")
+	}
+
+	if external.EmailVerified {
+		if err := s.userRepo.MarkEmailVerified(ctx, user.ID); err != nil {
+			return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to verify email")
+		}
+		now := time.Now().UTC()
+		user.EmailVerifiedAt = &now
+	}
+
+	created, err := s.identityRepo.Create(ctx, newIdentity(user.ID, providerName, external))
+	if err != nil {
+		return nil, errors.Wrap(err, errors.ErrCodeDatabaseError, "Failed to link identity")
+	}
+	if !created {
+		return nil, errIdentityLinked
+	}
+
+	userIDStr := user.ID.String()
+	s.logger.LogSecurityEvent("identity_signup", &userIDStr, map[string]interface{}{"provider": providerName})
+	return user, nil
+}
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+// availableUsername derives a free username from the provider account,
+// adding a random suffix if the plain one is taken
+func (s *OIDCService) availableUsername(ctx context.Context, external *oidc.Identity) (string, error) {
+	base := external.PreferredUsername
+	if base == "" {
+		base = strings.SplitN(external.Email, "@", 2)[0]
+	}
+	base = strings.Trim(usernameInvalidChars.ReplaceAllString(base, "_"), "_-")
+	if len(base) > 40 {
+		base = strings.Trim(base[:40], "_-")
+	}
+	if len(base) < 3 {
+		base = "user"
+	}
+
+	candidate := base
+	for attempt := 0; attempt < 5; attempt++ {
+		if existing, err := s.userRepo.GetByUsername(ctx, candidate); err != nil || existing == nil {
+			return candidate, nil
+		}
+
+		suffix, err := rand.Int(rand.Reader, big.NewInt(10000))
+		if err != nil {
+			return "", errors.Wrap(err, errors.ErrCodeInternalError, "Failed to generate username")
+		}
+		candidate = fmt.Sprintf("%s_%04d", base, suffix.Int64())
+	}
+
+	return "", errors.New(errors.ErrCodeConflict, "Could not find a free username; please register with a password instead")
+}
+
+func newIdentity(userID uuid.UUID, providerName string, external *oidc.Identity) *models.Identity {
+	identity := &models.Identity{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Provider:  providerName,
+		Subject:   external.Subject,
+		CreatedAt: time.Now().UTC(),
+	}
+	if external.Email != "" {
+		identity.Email = &external.Email
+	}
+	return identity
+}
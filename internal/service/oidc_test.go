@@ -0,0 +1,373 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"musicapp/internal/errors"
+	"musicapp/internal/models"
+	"musicapp/internal/oidc"
+
+	"github.com/google/uuid"
+)
+
+// Mock implementations for OIDCService testing
+
+// MockOIDCProvider signs in whoever identity is set to, as long as the code
+// verifier matches the challenge from the authorization URL
+type MockOIDCProvider struct {
+	name      string
+	identity  *oidc.Identity
+	challenge string
+	nonce     string
+}
+
+func (m *MockOIDCProvider) Name() string {
+	return m.name
+}
+
+func (m *MockOIDCProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	m.challenge, m.nonce = codeChallenge, nonce
+	return "https://idp.example.com/authorize?" + url.Values{"state": {state}}.Encode(), nil
+}
+
+func (m *MockOIDCProvider) Exchange(ctx context.Context, code, codeVerifier, nonce string) (*oidc.Identity, error) {
+	if code != "auth-code" || oidc.CodeChallenge(codeVerifier) != m.challenge || nonce != m.nonce {
+		return nil, fmt.Errorf("invalid_grant")
+	}
+	identity := *m.identity
+	return &identity, nil
+}
+
+type MockIdentityRepository struct {
+	identities map[uuid.UUID]*models.Identity
+}
+
+func NewMockIdentityRepository() *MockIdentityRepository {
+	return &MockIdentityRepository{identities: make(map[uuid.UUID]*models.Identity)}
+}
+
+func (m *MockIdentityRepository) Create(ctx context.Context, identity *models.Identity) (bool, error) {
+	for _, existing := range m.identities {
+		if existing.Provider == identity.Provider && (existing.Subject == identity.Subject || existing.UserID == identity.UserID) {
+			return false, nil
+		}
+	}
+	m.identities[identity.ID] = identity
+	return true, nil
+}
+
+func (m *MockIdentityRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*models.Identity, error) {
+	for _, identity := range m.identities {
+		if identity.Provider == provider && identity.Subject == subject {
+			return identity, nil
+		}
+	}
+	return nil, fmt.Errorf("identity not found")
+}
+
+func (m *MockIdentityRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]*models.Identity, error) {
+	var identities []*models.Identity
+	for _, identity := range m.identities {
+		if identity.UserID == userID {
+			identities = append(identities, identity)
+		}
+	}
+	return identities, nil
+}
+
+func (m *MockIdentityRepository) TouchLogin(ctx context.Context, id uuid.UUID) error {
+	if identity, exists := m.identities[id]; exists {
+		now := time.Now()
+		identity.LastLoginAt = &now
+	}
+	return nil
+}
+
+func (m *MockIdentityRepository) Delete(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	if identity, exists := m.identities[id]; exists && identity.UserID == userID {
+		delete(m.identities, id)
+		return true, nil
+	}
+	return false, nil
+}
+
+type MockOIDCStateStore struct {
+	states map[string]string
+}
+
+func NewMockOIDCStateStore() *MockOIDCStateStore {
+	return &MockOIDCStateStore{states: make(map[string]string)}
+}
+
+func (m *MockOIDCStateStore) SetOIDCState(ctx context.Context, stateHash, value string, expiration time.Duration) error {
+	m.states[stateHash] = value
+	return nil
+}
+
+func (m *MockOIDCStateStore) TakeOIDCState(ctx context.Context, stateHash string) (string, error) {
+	value := m.states[stateHash]
+	delete(m.states, stateHash)
+	return value, nil
+}
+
+type oidcTestEnv struct {
+	service    *OIDCService
+	provider   *MockOIDCProvider
+	userRepo   *MockUserRepositoryForAccount
+	identities *MockIdentityRepository
+}
+
+func newOIDCTestEnv() *oidcTestEnv {
+	userRepo := NewMockUserRepositoryForAccount()
+	authService := NewAuthService(userRepo, NewMockCache(), NewMockAuthMiddleware(), NewMockRefreshTokenRepository(), NewMockSessionRepository(), NewMockMFAVerifier(), NewMockLoginThrottle(), createTestLogger())
+	provider := &MockOIDCProvider{
+		name:     "mock",
+		identity: &oidc.Identity{Subject: "subject-1", Email: "producer@example.com", EmailVerified: true, PreferredUsername: "producer"},
+	}
+	identities := NewMockIdentityRepository()
+
+	return &oidcTestEnv{
+		service:    NewOIDCService([]OIDCProvider{provider}, identities, userRepo, NewMockOIDCStateStore(), authService, createTestLogger()),
+		provider:   provider,
+		userRepo:   userRepo,
+		identities: identities,
+	}
+}
+
+// stateFrom extracts the state parameter the user carries to the provider
+// and back
+func stateFrom(t *testing.T, authorization *models.OIDCAuthorization) string {
+	t.Helper()
+	parsed, err := url.Parse(authorization.AuthorizationURL)
+	if err != nil {
+		t.Fatalf("Invalid authorization URL: %v", err)
+	}
+	return parsed.Query().Get("state")
+}
+
+func (e *oidcTestEnv) login(t *testing.T) (*models.User, *models.TokenPair, error) {
+	t.Helper()
+	authorization, err := e.service.StartLogin(context.Background(), "mock")
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	user, tokens, _, err := e.service.CompleteLogin(context.Background(), "mock", "auth-code", stateFrom(t, authorization), models.DeviceInfo{})
+	return user, tokens, err
+}
+
+func expectAppError(t *testing.T, err error, code errors.ErrorCode) {
+	t.Helper()
+	appErr := errors.GetAppError(err)
+	if appErr == nil || appErr.Code != code {
+		t.Errorf("Expected %s error, got: %v", code, err)
+	}
+}
+
+func TestOIDCService_CompleteLogin(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("first sign-in creates a verified user", func(t *testing.T) {
+		env := newOIDCTestEnv()
+
+		user, tokens, err := env.login(t)
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if tokens == nil || tokens.AccessToken == "" {
+			t.Error("Expected tokens")
+		}
+		if user.Username != "producer" || user.Email != "producer@example.com" || user.PasswordHash != "" {
+			t.Errorf("Unexpected user: %+v", user)
+		}
+		if user.EmailVerifiedAt == nil {
+			t.Error("Expected email verified by the provider to be marked verified")
+		}
+		if len(env.identities.identities) != 1 {
+			t.Errorf("Expected one linked identity, got %d", len(env.identities.identities))
+		}
+
+		// Signing in again finds the same user
+		again, _, err := env.login(t)
+		if err != nil || again.ID != user.ID {
+			t.Errorf("Expected the same user on the next sign-in, got %v, %v", again, err)
+		}
+	})
+
+	t.Run("taken username gets a suffix", func(t *testing.T) {
+		env := newOIDCTestEnv()
+		env.userRepo.Create(ctx, &models.User{ID: uuid.New(), Username: "producer", Email: "other@example.com"})
+
+		user, _, err := env.login(t)
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if user.Username == "producer" || len(user.Username) != len("producer_0000") {
+			t.Errorf("Expected a suffixed username, got %s", user.Username)
+		}
+	})
+
+	t.Run("email of an existing account", func(t *testing.T) {
+		env := newOIDCTestEnv()
+		env.userRepo.Create(ctx, &models.User{ID: uuid.New(), Username: "existing", Email: "producer@example.com", PasswordHash: "hash"})
+
+		_, _, err := env.login(t)
+		expectAppError(t, err, errors.ErrCodeConflict)
+		if len(env.identities.identities) != 0 {
+			t.Error("Expected the provider not to be linked to the existing account")
+		}
+	})
+
+	t.Run("provider without email", func(t *testing.T) {
+		env := newOIDCTestEnv()
+		env.provider.identity.Email = ""
+
+		_, _, err := env.login(t)
+		expectAppError(t, err, errors.ErrCodeInvalidInput)
+	})
+
+	t.Run("state is single use", func(t *testing.T) {
+		env := newOIDCTestEnv()
+		authorization, _ := env.service.StartLogin(ctx, "mock")
+		state := stateFrom(t, authorization)
+
+		if _, _, _, err := env.service.CompleteLogin(ctx, "mock", "auth-code", state, models.DeviceInfo{}); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		_, _, _, err := env.service.CompleteLogin(ctx, "mock", "auth-code", state, models.DeviceInfo{})
+		expectAppError(t, err, errors.ErrCodeInvalidInput)
+	})
+
+	t.Run("unknown state", func(t *testing.T) {
+		env := newOIDCTestEnv()
+		_, _, _, err := env.service.CompleteLogin(ctx, "mock", "auth-code", "forged-state", models.DeviceInfo{})
+		expectAppError(t, err, errors.ErrCodeInvalidInput)
+	})
+
+	t.Run("failed exchange", func(t *testing.T) {
+		env := newOIDCTestEnv()
+		authorization, _ := env.service.StartLogin(ctx, "mock")
+
+		_, _, _, err := env.service.CompleteLogin(ctx, "mock", "wrong-code", stateFrom(t, authorization), models.DeviceInfo{})
+		expectAppError(t, err, errors.ErrCodeInvalidCredentials)
+	})
+
+	t.Run("unknown provider", func(t *testing.T) {
+		env := newOIDCTestEnv()
+		_, err := env.service.StartLogin(ctx, "nope")
+		expectAppError(t, err, errors.ErrCodeNotFound)
+	})
+}
+
+func TestOIDCService_Linking(t *testing.T) {
+	ctx := context.Background()
+
+	newUser := func(env *oidcTestEnv, passwordHash string) *models.User {
+		user := &models.User{ID: uuid.New(), Username: "existing", Email: "existing@example.com", PasswordHash: passwordHash}
+		env.userRepo.Create(ctx, user)
+		return user
+	}
+	link := func(t *testing.T, env *oidcTestEnv, userID uuid.UUID) (*models.Identity, error) {
+		t.Helper()
+		authorization, err := env.service.StartLink(ctx, "mock", userID)
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		return env.service.CompleteLink(ctx, "mock", "auth-code", stateFrom(t, authorization), userID)
+	}
+
+	t.Run("link, sign in and unlink", func(t *testing.T) {
+		env := newOIDCTestEnv()
+		user := newUser(env, "hash")
+
+		identity, err := link(t, env, user.ID)
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if identity.UserID != user.ID || identity.Provider != "mock" {
+			t.Errorf("Unexpected identity: %+v", identity)
+		}
+
+		// The provider now signs in to the existing account, even though the
+		// provider's email is different
+		signedIn, _, err := env.login(t)
+		if err != nil || signedIn.ID != user.ID {
+			t.Errorf("Expected sign-in as the linked user, got %v, %v", signedIn, err)
+		}
+
+		if err := env.service.Unlink(ctx, user.ID, identity.ID); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		identities, _ := env.service.ListIdentities(ctx, user.ID)
+		if len(identities) != 0 {
+			t.Errorf("Expected no identities after unlinking, got %d", len(identities))
+		}
+	})
+
+	t.Run("state issued to another user", func(t *testing.T) {
+		env := newOIDCTestEnv()
+		user := newUser(env, "hash")
+
+		authorization, _ := env.service.StartLink(ctx, "mock", uuid.New())
+		_, err := env.service.CompleteLink(ctx, "mock", "auth-code", stateFrom(t, authorization), user.ID)
+		expectAppError(t, err, errors.ErrCodeInvalidInput)
+	})
+
+	t.Run("login state cannot link", func(t *testing.T) {
+		env := newOIDCTestEnv()
+		user := newUser(env, "hash")
+
+		authorization, _ := env.service.StartLogin(ctx, "mock")
+		_, err := env.service.CompleteLink(ctx, "mock", "auth-code", stateFrom(t, authorization), user.ID)
+		expectAppError(t, err, errors.ErrCodeInvalidInput)
+	})
+
+	t.Run("provider account linked to another user", func(t *testing.T) {
+		env := newOIDCTestEnv()
+		if _, _, err := env.login(t); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		user := newUser(env, "hash")
+
+		_, err := link(t, env, user.ID)
+		expectAppError(t, err, errors.ErrCodeConflict)
+	})
+
+	t.Run("second account at the same provider", func(t *testing.T) {
+		env := newOIDCTestEnv()
+		user := newUser(env, "hash")
+		if _, err := link(t, env, user.ID); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+
+		env.provider.identity.Subject = "subject-2"
+		_, err := link(t, env, user.ID)
+		expectAppError(t, err, errors.ErrCodeConflict)
+	})
+
+	t.Run("last sign-in method of a user without password", func(t *testing.T) {
+		env := newOIDCTestEnv()
+		user, _, err := env.login(t)
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		identities, _ := env.service.ListIdentities(ctx, user.ID)
+
+		err = env.service.Unlink(ctx, user.ID, identities[0].ID)
+		expectAppError(t, err, errors.ErrCodeInvalidInput)
+	})
+
+	t.Run("identity of another user", func(t *testing.T) {
+		env := newOIDCTestEnv()
+		owner := newUser(env, "hash")
+		identity, _ := link(t, env, owner.ID)
+
+		other := &models.User{ID: uuid.New(), Username: "other", Email: "other@example.com", PasswordHash: "hash"}
+		env.userRepo.Create(ctx, other)
+		err := env.service.Unlink(ctx, other.ID, identity.ID)
+		expectAppError(t, err, errors.ErrCodeNotFound)
+	})
+}
@@ -8,6 +8,7 @@ import (
 
 	"musicapp/internal/interfaces"
 	"musicapp/internal/models"
+	"musicapp/internal/pagination"
 	"musicapp/internal/repository"
 	"musicapp/internal/storage"
 
@@ -18,27 +19,34 @@ import (
 type PostRepository interface {
 	Create(ctx context.Context, post *models.Post) error
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Post, error)
-	GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.Post, error)
-	GetByBandID(ctx context.Context, bandID uuid.UUID, limit, offset int) ([]*models.Post, error)
-	GetFeed(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.Post, error)
+	GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Post, error)
+	GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Post, error)
+	GetByBandID(ctx context.Context, bandID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Post, error)
+	GetFeed(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Post, error)
 	Update(ctx context.Context, post *models.Post) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	LikePost(ctx context.Context, userID, postID uuid.UUID) error
 	UnlikePost(ctx context.Context, userID, postID uuid.UUID) error
 	Repost(ctx context.Context, userID, postID uuid.UUID) error
+	Unrepost(ctx context.Context, userID, postID uuid.UUID) error
 	IsLiked(ctx context.Context, userID, postID uuid.UUID) (bool, error)
 	IsReposted(ctx context.Context, userID, postID uuid.UUID) (bool, error)
-	GetAll(ctx context.Context, limit, offset int) ([]*models.Post, error)
+	GetLikedPostIDs(ctx context.Context, userID uuid.UUID, postIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+	GetRepostedPostIDs(ctx context.Context, userID uuid.UUID, postIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+	GetAll(ctx context.Context, limit, offset int, after *pagination.Cursor) ([]*models.Post, error)
 }
 
 // UserRepositoryForPost interface for user operations needed by PostService
 type UserRepositoryForPost interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.User, error)
+	GetAuthorSummaries(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.PostAuthor, error)
 }
 
 // BandRepositoryForPost interface for band operations needed by PostService
 type BandRepositoryForPost interface {
 	GetByID(ctx context.Context, id uuid.UUID) (*models.Band, error)
+	GetMember(ctx context.Context, bandID, userID uuid.UUID) (*models.BandMember, error)
+	GetAuthorSummaries(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.PostAuthor, error)
 }
 
 // S3ClientForPost interface for S3 operations needed by PostService
@@ -77,15 +85,72 @@ func (s *PostService) CreatePost(ctx context.Context, userID uuid.UUID, req *mod
 		return nil, fmt.Errorf("post content too long (max 2000 characters)")
 	}
 
+	if err := s.validateQuotedPost(ctx, req.QuotedPostID); err != nil {
+		return nil, err
+	}
+
 	// Create post
 	post := &models.Post{
-		ID:         uuid.New(),
-		AuthorID:   &userID,
-		AuthorType: "user",
-		UserID:     &userID,
-		Content:    req.Content,
-		MediaURLs:  req.MediaURLs,
-		MediaTypes: req.MediaTypes,
+		ID:           uuid.New(),
+		AuthorID:     &userID,
+		AuthorType:   "user",
+		UserID:       &userID,
+		Content:      req.Content,
+		MediaURLs:    req.MediaURLs,
+		MediaTypes:   req.MediaTypes,
+		QuotedPostID: req.QuotedPostID,
+	}
+
+	if err := s.postRepo.Create(ctx, post); err != nil {
+		return nil, fmt.Errorf("failed to create post: %w", err)
+	}
+
+	// Get the created post with counts
+	createdPost, err := s.postRepo.GetByID(ctx, post.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve created post: %w", err)
+	}
+
+	if err := s.hydratePosts(ctx, []*models.Post{createdPost}, nil); err != nil {
+		return nil, err
+	}
+
+	return createdPost, nil
+}
+
+// CreateBandPost publishes a post as a band. Only band admins and members
+// designated to post as the band may do so.
+func (s *PostService) CreateBandPost(ctx context.Context, bandID, userID uuid.UUID, req *models.CreatePostRequest) (*models.Post, error) {
+	if req.Content == "" {
+		return nil, fmt.Errorf("post content is required")
+	}
+
+	if len(req.Content) > 2000 {
+		return nil, fmt.Errorf("post content too long (max 2000 characters)")
+	}
+
+	if err := s.validateQuotedPost(ctx, req.QuotedPostID); err != nil {
+		return nil, err
+	}
+
+	// Check if band exists
+	if _, err := s.bandRepo.GetByID(ctx, bandID); err != nil {
+		return nil, fmt.Errorf("band not found: %w", err)
+	}
+
+	if _, err := authorizeBand(ctx, s.bandRepo, bandID, userID, models.BandPermissionPostAsBand); err != nil {
+		return nil, err
+	}
+
+	post := &models.Post{
+		ID:           uuid.New(),
+		AuthorID:     &bandID,
+		AuthorType:   "band",
+		BandID:       &bandID,
+		Content:      req.Content,
+		MediaURLs:    req.MediaURLs,
+		MediaTypes:   req.MediaTypes,
+		QuotedPostID: req.QuotedPostID,
 	}
 
 	if err := s.postRepo.Create(ctx, post); err != nil {
@@ -98,9 +163,39 @@ func (s *PostService) CreatePost(ctx context.Context, userID uuid.UUID, req *mod
 		return nil, fmt.Errorf("failed to retrieve created post: %w", err)
 	}
 
+	if err := s.hydratePosts(ctx, []*models.Post{createdPost}, nil); err != nil {
+		return nil, err
+	}
+
 	return createdPost, nil
 }
 
+// GetBandPosts retrieves posts published as a band
+func (s *PostService) GetBandPosts(ctx context.Context, bandID uuid.UUID, limit, offset int, after *pagination.Cursor, currentUserID *uuid.UUID) ([]*models.Post, error) {
+	if limit <= 0 || limit > 100 {
+		return nil, fmt.Errorf("invalid limit: %d (must be 1-100)", limit)
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset: %d (must be >= 0)", offset)
+	}
+
+	// Check if band exists
+	if _, err := s.bandRepo.GetByID(ctx, bandID); err != nil {
+		return nil, fmt.Errorf("band not found: %w", err)
+	}
+
+	posts, err := s.postRepo.GetByBandID(ctx, bandID, limit, offset, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve band posts: %w", err)
+	}
+
+	if err := s.hydratePosts(ctx, posts, currentUserID); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
 // GetPost retrieves a post by ID
 func (s *PostService) GetPost(ctx context.Context, postID uuid.UUID, currentUserID *uuid.UUID) (*models.Post, error) {
 	post, err := s.postRepo.GetByID(ctx, postID)
@@ -108,13 +203,14 @@ func (s *PostService) GetPost(ctx context.Context, postID uuid.UUID, currentUser
 		return nil, fmt.Errorf("post not found: %w", err)
 	}
 
-	// Check if current user has liked/reposted this post
-	if currentUserID != nil {
-		isLiked, _ := s.postRepo.IsLiked(ctx, *currentUserID, postID)
-		isReposted, _ := s.postRepo.IsReposted(ctx, *currentUserID, postID)
+	if post.UserID != nil {
+		if err := s.requireVisibleAuthor(ctx, *post.UserID, currentUserID); err != nil {
+			return nil, fmt.Errorf("post not found: %w", err)
+		}
+	}
 
-		post.IsLiked = isLiked
-		post.IsReposted = isReposted
+	if err := s.hydratePosts(ctx, []*models.Post{post}, currentUserID); err != nil {
+		return nil, err
 	}
 
 	return post, nil
@@ -128,8 +224,13 @@ func (s *PostService) UpdatePost(ctx context.Context, postID, userID uuid.UUID,
 		return nil, fmt.Errorf("post not found: %w", err)
 	}
 
-	// Check if user is the author
-	if post.UserID == nil || *post.UserID != userID {
+	// Check if user can edit the post
+	canManage, err := s.canManagePost(ctx, post, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check permissions: %w", err)
+	}
+
+	if !canManage {
 		return nil, fmt.Errorf("you can only update your own posts")
 	}
 
@@ -154,6 +255,10 @@ func (s *PostService) UpdatePost(ctx context.Context, postID, userID uuid.UUID,
 		return nil, fmt.Errorf("failed to update post: %w", err)
 	}
 
+	if err := s.hydratePosts(ctx, []*models.Post{post}, nil); err != nil {
+		return nil, err
+	}
+
 	return post, nil
 }
 
@@ -165,8 +270,13 @@ func (s *PostService) DeletePost(ctx context.Context, postID, userID uuid.UUID)
 		return fmt.Errorf("post not found: %w", err)
 	}
 
-	// Check if user is the author
-	if post.UserID == nil || *post.UserID != userID {
+	// Check if user can delete the post
+	canManage, err := s.canManagePost(ctx, post, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check permissions: %w", err)
+	}
+
+	if !canManage {
 		return fmt.Errorf("you can only delete your own posts")
 	}
 
@@ -216,8 +326,17 @@ func (s *PostService) Repost(ctx context.Context, userID, postID uuid.UUID) erro
 	return nil
 }
 
+// Unrepost removes the user's repost of a post
+func (s *PostService) Unrepost(ctx context.Context, userID, postID uuid.UUID) error {
+	if err := s.postRepo.Unrepost(ctx, userID, postID); err != nil {
+		return fmt.Errorf("failed to remove repost: %w", err)
+	}
+
+	return nil
+}
+
 // GetFeed retrieves personalized feed for a user
-func (s *PostService) GetFeed(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.Post, error) {
+func (s *PostService) GetFeed(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Post, error) {
 	if limit <= 0 || limit > 100 {
 		return nil, fmt.Errorf("invalid limit: %d (must be 1-100)", limit)
 	}
@@ -225,25 +344,20 @@ func (s *PostService) GetFeed(ctx context.Context, userID uuid.UUID, limit, offs
 		return nil, fmt.Errorf("invalid offset: %d (must be >= 0)", offset)
 	}
 
-	posts, err := s.postRepo.GetFeed(ctx, userID, limit, offset)
+	posts, err := s.postRepo.GetFeed(ctx, userID, limit, offset, after)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve feed: %w", err)
 	}
 
-	// Add like/repost status for current user
-	for _, post := range posts {
-		isLiked, _ := s.postRepo.IsLiked(ctx, userID, post.ID)
-		isReposted, _ := s.postRepo.IsReposted(ctx, userID, post.ID)
-
-		post.IsLiked = isLiked
-		post.IsReposted = isReposted
+	if err := s.hydratePosts(ctx, posts, &userID); err != nil {
+		return nil, err
 	}
 
 	return posts, nil
 }
 
 // GetExploreFeed retrieves explore/trending posts
-func (s *PostService) GetExploreFeed(ctx context.Context, limit, offset int) ([]*models.Post, error) {
+func (s *PostService) GetExploreFeed(ctx context.Context, limit, offset int, after *pagination.Cursor, currentUserID *uuid.UUID) ([]*models.Post, error) {
 	if limit <= 0 || limit > 100 {
 		return nil, fmt.Errorf("invalid limit: %d (must be 1-100)", limit)
 	}
@@ -252,11 +366,15 @@ func (s *PostService) GetExploreFeed(ctx context.Context, limit, offset int) ([]
 	}
 
 	// Use empty UUID for explore feed
-	posts, err := s.postRepo.GetFeed(ctx, uuid.Nil, limit, offset)
+	posts, err := s.postRepo.GetFeed(ctx, uuid.Nil, limit, offset, after)
 	if err != nil {
 		return nil, fmt.Errorf("failed to retrieve explore feed: %w", err)
 	}
 
+	if err := s.hydratePosts(ctx, posts, currentUserID); err != nil {
+		return nil, err
+	}
+
 	return posts, nil
 }
 
@@ -272,11 +390,23 @@ func (s *PostService) UploadMedia(ctx context.Context, postID, userID uuid.UUID,
 		return "", "", fmt.Errorf("post not found: %w", err)
 	}
 
-	// Check if user is the author
-	if post.UserID == nil || *post.UserID != userID {
+	// Check if user can edit the post
+	canManage, err := s.canManagePost(ctx, post, userID)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to check permissions: %w", err)
+	}
+
+	if !canManage {
 		return "", "", fmt.Errorf("you can only add media to your own posts")
 	}
 
+	// Band posts outlive the member who posted them, so their files are
+	// stored under the band rather than the uploader
+	ownerID := userID.String()
+	if post.AuthorType == "band" && post.BandID != nil {
+		ownerID = post.BandID.String()
+	}
+
 	var mediaType string
 	var uploadResult *storage.UploadResult
 
@@ -286,13 +416,13 @@ func (s *PostService) UploadMedia(ctx context.Context, postID, userID uuid.UUID,
 		if err := s.s3Client.ValidateImageFile(filename, int64(len(fileData))); err != nil {
 			return "", "", fmt.Errorf("invalid image file: %w", err)
 		}
-		uploadResult, err = s.s3Client.UploadImage(ctx, userID.String(), filename, bytes.NewReader(fileData), int64(len(fileData)))
+		uploadResult, err = s.s3Client.UploadImage(ctx, ownerID, filename, bytes.NewReader(fileData), int64(len(fileData)))
 	} else if contentType == "audio" {
 		mediaType = "audio"
 		if err := s.s3Client.ValidateAudioFile(filename, int64(len(fileData))); err != nil {
 			return "", "", fmt.Errorf("invalid audio file: %w", err)
 		}
-		uploadResult, err = s.s3Client.UploadAudio(ctx, userID.String(), filename, bytes.NewReader(fileData), int64(len(fileData)))
+		uploadResult, err = s.s3Client.UploadAudio(ctx, ownerID, filename, bytes.NewReader(fileData), int64(len(fileData)))
 	} else {
 		return "", "", fmt.Errorf("unsupported media type: %s", contentType)
 	}
@@ -313,7 +443,7 @@ func (s *PostService) UploadMedia(ctx context.Context, postID, userID uuid.UUID,
 }
 
 // GetAllPosts gets all posts with pagination
-func (s *PostService) GetAllPosts(ctx context.Context, limit, offset int) ([]*models.Post, error) {
+func (s *PostService) GetAllPosts(ctx context.Context, limit, offset int, after *pagination.Cursor, currentUserID *uuid.UUID) ([]*models.Post, error) {
 	// Default pagination values
 	if limit <= 0 {
 		limit = 20
@@ -325,7 +455,247 @@ func (s *PostService) GetAllPosts(ctx context.Context, limit, offset int) ([]*mo
 		offset = 0
 	}
 
-	return s.postRepo.GetAll(ctx, limit, offset)
+	posts, err := s.postRepo.GetAll(ctx, limit, offset, after)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.hydratePosts(ctx, posts, currentUserID); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// GetUserPosts retrieves posts by a specific user
+func (s *PostService) GetUserPosts(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor, currentUserID *uuid.UUID) ([]*models.Post, error) {
+	if limit <= 0 || limit > 100 {
+		return nil, fmt.Errorf("invalid limit: %d (must be 1-100)", limit)
+	}
+	if offset < 0 {
+		return nil, fmt.Errorf("invalid offset: %d (must be >= 0)", offset)
+	}
+
+	if err := s.requireVisibleAuthor(ctx, userID, currentUserID); err != nil {
+		return nil, err
+	}
+
+	posts, err := s.postRepo.GetByUserID(ctx, userID, limit, offset, after)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve user posts: %w", err)
+	}
+
+	if err := s.hydratePosts(ctx, posts, currentUserID); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// requireVisibleAuthor hides a user's posts from everyone but the user once
+// their account is scheduled for deletion
+func (s *PostService) requireVisibleAuthor(ctx context.Context, authorID uuid.UUID, currentUserID *uuid.UUID) error {
+	if currentUserID != nil && *currentUserID == authorID {
+		return nil
+	}
+
+	author, err := s.userRepo.GetByID(ctx, authorID)
+	if err != nil {
+		return fmt.Errorf("failed to load post author: %w", err)
+	}
+	if author == nil || author.DeletionScheduledAt != nil {
+		return fmt.Errorf("user not found")
+	}
+	return nil
+}
+
+// hydratePosts embeds quoted posts, the viewer's like/repost state and author
+// summaries, batching each lookup across the whole page
+func (s *PostService) hydratePosts(ctx context.Context, posts []*models.Post, currentUserID *uuid.UUID) error {
+	if err := s.attachQuotedPosts(ctx, posts); err != nil {
+		return err
+	}
+
+	all := make([]*models.Post, 0, len(posts))
+	for _, post := range posts {
+		all = append(all, post)
+		if post.QuotedPost != nil {
+			all = append(all, post.QuotedPost)
+		}
+	}
+
+	if currentUserID != nil {
+		s.applyViewerState(ctx, *currentUserID, all)
+	}
+
+	return s.attachAuthors(ctx, all)
+}
+
+// attachQuotedPosts loads the originals referenced by quote posts in one query
+func (s *PostService) attachQuotedPosts(ctx context.Context, posts []*models.Post) error {
+	var ids []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, post := range posts {
+		if post.QuotedPostID == nil || seen[*post.QuotedPostID] {
+			continue
+		}
+		seen[*post.QuotedPostID] = true
+		ids = append(ids, *post.QuotedPostID)
+	}
+
+	if len(ids) == 0 {
+		return nil
+	}
+
+	quoted, err := s.postRepo.GetByIDs(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load quoted posts: %w", err)
+	}
+
+	byID := make(map[uuid.UUID]*models.Post, len(quoted))
+	for _, post := range quoted {
+		byID[post.ID] = post
+	}
+
+	for _, post := range posts {
+		if post.QuotedPostID != nil {
+			post.QuotedPost = byID[*post.QuotedPostID]
+		}
+	}
+
+	return nil
+}
+
+// validateQuotedPost checks that the post being quoted exists
+func (s *PostService) validateQuotedPost(ctx context.Context, quotedPostID *uuid.UUID) error {
+	if quotedPostID == nil {
+		return nil
+	}
+
+	if _, err := s.postRepo.GetByID(ctx, *quotedPostID); err != nil {
+		return fmt.Errorf("quoted post not found: %w", err)
+	}
+
+	return nil
+}
+
+// applyViewerState marks which posts the viewer has liked or reposted using
+// one batched query per relation. Failures leave the flags unset rather than
+// failing the listing.
+func (s *PostService) applyViewerState(ctx context.Context, userID uuid.UUID, posts []*models.Post) {
+	if len(posts) == 0 {
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(posts))
+	for _, post := range posts {
+		ids = append(ids, post.ID)
+	}
+
+	if liked, err := s.postRepo.GetLikedPostIDs(ctx, userID, ids); err == nil {
+		for _, post := range posts {
+			post.IsLiked = liked[post.ID]
+		}
+	}
+
+	if reposted, err := s.postRepo.GetRepostedPostIDs(ctx, userID, ids); err == nil {
+		for _, post := range posts {
+			post.IsReposted = reposted[post.ID]
+		}
+	}
+}
+
+// attachAuthors embeds the author summary in each post, loading all user and
+// band authors of the page with one query each
+func (s *PostService) attachAuthors(ctx context.Context, posts []*models.Post) error {
+	var userIDs, bandIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	for _, post := range posts {
+		id, isBand, ok := postAuthorID(post)
+		if !ok || seen[id] {
+			continue
+		}
+		seen[id] = true
+		if isBand {
+			bandIDs = append(bandIDs, id)
+		} else {
+			userIDs = append(userIDs, id)
+		}
+	}
+	for _, post := range posts {
+		if post.RepostedByID != nil && !seen[*post.RepostedByID] {
+			seen[*post.RepostedByID] = true
+			userIDs = append(userIDs, *post.RepostedByID)
+		}
+	}
+
+	if len(userIDs) == 0 && len(bandIDs) == 0 {
+		return nil
+	}
+
+	userAuthors, err := s.userRepo.GetAuthorSummaries(ctx, userIDs)
+	if err != nil {
+		return fmt.Errorf("failed to load post authors: %w", err)
+	}
+
+	bandAuthors, err := s.bandRepo.GetAuthorSummaries(ctx, bandIDs)
+	if err != nil {
+		return fmt.Errorf("failed to load post authors: %w", err)
+	}
+
+	for _, post := range posts {
+		id, isBand, ok := postAuthorID(post)
+		if !ok {
+			continue
+		}
+		var author *models.PostAuthor
+		if isBand {
+			author = bandAuthors[id]
+		} else {
+			author = userAuthors[id]
+		}
+		// Leave Author nil rather than a typed nil pointer inside the interface
+		if author != nil {
+			post.Author = author
+		}
+	}
+	for _, post := range posts {
+		if post.RepostedByID != nil {
+			post.RepostedBy = userAuthors[*post.RepostedByID]
+		}
+	}
+
+	return nil
+}
+
+// postAuthorID returns the ID of the user or band that authored a post
+func postAuthorID(post *models.Post) (id uuid.UUID, isBand bool, ok bool) {
+	if post.AuthorType == "band" && post.BandID != nil {
+		return *post.BandID, true, true
+	}
+	if post.UserID != nil {
+		return *post.UserID, false, true
+	}
+	return uuid.Nil, false, false
+}
+
+// canManagePost reports whether the user may edit or delete a post. User posts
+// belong to their author; band posts to the band's admins and designated members.
+func (s *PostService) canManagePost(ctx context.Context, post *models.Post, userID uuid.UUID) (bool, error) {
+	if post.AuthorType == "band" && post.BandID != nil {
+		return s.canPostAsBand(ctx, *post.BandID, userID)
+	}
+
+	return post.UserID != nil && *post.UserID == userID, nil
+}
+
+func (s *PostService) canPostAsBand(ctx context.Context, bandID, userID uuid.UUID) (bool, error) {
+	member, err := s.bandRepo.GetMember(ctx, bandID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return member != nil && member.Can(models.BandPermissionPostAsBand), nil
 }
 
 // Adapter structs to bridge existing concrete types with new interfaces
@@ -6,11 +6,14 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"musicapp/internal/models"
+	"musicapp/internal/pagination"
 	"musicapp/internal/storage"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 // Mock implementations for PostService testing
@@ -32,8 +35,13 @@ type MockPostRepository struct {
 	likePostError error
 	unlikePostError error
 	repostError   error
+	unrepostError error
 	isLikedResult bool
 	isRepostedResult bool
+	likedPosts    map[uuid.UUID]bool
+	repostedPosts map[uuid.UUID]bool
+	viewerStateQueries int
+	lastAfter     *pagination.Cursor
 }
 
 func NewMockPostRepository() *MockPostRepository {
@@ -65,7 +73,21 @@ func (m *MockPostRepository) GetByID(ctx context.Context, id uuid.UUID) (*models
 	return post, nil
 }
 
-func (m *MockPostRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.Post, error) {
+func (m *MockPostRepository) GetByIDs(ctx context.Context, ids []uuid.UUID) ([]*models.Post, error) {
+	if m.getByIDError != nil {
+		return nil, m.getByIDError
+	}
+	var posts []*models.Post
+	for _, id := range ids {
+		if post, exists := m.postsByID[id.String()]; exists {
+			posts = append(posts, post)
+		}
+	}
+	return posts, nil
+}
+
+func (m *MockPostRepository) GetByUserID(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Post, error) {
+	m.lastAfter = after
 	if m.getByUserIDError != nil {
 		return nil, m.getByUserIDError
 	}
@@ -76,7 +98,8 @@ func (m *MockPostRepository) GetByUserID(ctx context.Context, userID uuid.UUID,
 	return posts, nil
 }
 
-func (m *MockPostRepository) GetByBandID(ctx context.Context, bandID uuid.UUID, limit, offset int) ([]*models.Post, error) {
+func (m *MockPostRepository) GetByBandID(ctx context.Context, bandID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Post, error) {
+	m.lastAfter = after
 	if m.getByBandIDError != nil {
 		return nil, m.getByBandIDError
 	}
@@ -87,7 +110,8 @@ func (m *MockPostRepository) GetByBandID(ctx context.Context, bandID uuid.UUID,
 	return posts, nil
 }
 
-func (m *MockPostRepository) GetFeed(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.Post, error) {
+func (m *MockPostRepository) GetFeed(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.Post, error) {
+	m.lastAfter = after
 	if m.getFeedError != nil {
 		return nil, m.getFeedError
 	}
@@ -131,6 +155,13 @@ func (m *MockPostRepository) Repost(ctx context.Context, userID, postID uuid.UUI
 	return nil
 }
 
+func (m *MockPostRepository) Unrepost(ctx context.Context, userID, postID uuid.UUID) error {
+	if m.unrepostError != nil {
+		return m.unrepostError
+	}
+	return nil
+}
+
 func (m *MockPostRepository) IsLiked(ctx context.Context, userID, postID uuid.UUID) (bool, error) {
 	return m.isLikedResult, nil
 }
@@ -139,7 +170,30 @@ func (m *MockPostRepository) IsReposted(ctx context.Context, userID, postID uuid
 	return m.isRepostedResult, nil
 }
 
-func (m *MockPostRepository) GetAll(ctx context.Context, limit, offset int) ([]*models.Post, error) {
+func (m *MockPostRepository) GetLikedPostIDs(ctx context.Context, userID uuid.UUID, postIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	m.viewerStateQueries++
+	liked := make(map[uuid.UUID]bool)
+	for _, id := range postIDs {
+		if m.isLikedResult || m.likedPosts[id] {
+			liked[id] = true
+		}
+	}
+	return liked, nil
+}
+
+func (m *MockPostRepository) GetRepostedPostIDs(ctx context.Context, userID uuid.UUID, postIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	m.viewerStateQueries++
+	reposted := make(map[uuid.UUID]bool)
+	for _, id := range postIDs {
+		if m.isRepostedResult || m.repostedPosts[id] {
+			reposted[id] = true
+		}
+	}
+	return reposted, nil
+}
+
+func (m *MockPostRepository) GetAll(ctx context.Context, limit, offset int, after *pagination.Cursor) ([]*models.Post, error) {
+	m.lastAfter = after
 	if m.getAllError != nil {
 		return nil, m.getAllError
 	}
@@ -149,6 +203,7 @@ func (m *MockPostRepository) GetAll(ctx context.Context, limit, offset int) ([]*
 type MockUserRepositoryForPost struct {
 	usersByID map[string]*models.User
 	getByIDError error
+	summaryQueries int
 }
 
 func NewMockUserRepositoryForPost() *MockUserRepositoryForPost {
@@ -163,19 +218,36 @@ func (m *MockUserRepositoryForPost) GetByID(ctx context.Context, id uuid.UUID) (
 	}
 	user, exists := m.usersByID[id.String()]
 	if !exists {
-		return nil, fmt.Errorf("user not found")
+		return nil, pgx.ErrNoRows
 	}
 	return user, nil
 }
 
+func (m *MockUserRepositoryForPost) GetAuthorSummaries(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.PostAuthor, error) {
+	m.summaryQueries++
+	authors := make(map[uuid.UUID]*models.PostAuthor)
+	for _, id := range ids {
+		if user, exists := m.usersByID[id.String()]; exists {
+			authors[id] = &models.PostAuthor{ID: id, Type: "user", Username: user.Username, DisplayName: user.DisplayName}
+		}
+	}
+	return authors, nil
+}
+
 type MockBandRepositoryForPost struct {
 	bandsByID map[string]*models.Band
 	getByIDError error
+	admins       map[uuid.UUID]bool
+	posters      map[uuid.UUID]bool
+	isAdminError error
+	summaryQueries int
 }
 
 func NewMockBandRepositoryForPost() *MockBandRepositoryForPost {
 	return &MockBandRepositoryForPost{
 		bandsByID: make(map[string]*models.Band),
+		admins:    make(map[uuid.UUID]bool),
+		posters:   make(map[uuid.UUID]bool),
 	}
 }
 
@@ -190,12 +262,37 @@ func (m *MockBandRepositoryForPost) GetByID(ctx context.Context, id uuid.UUID) (
 	return band, nil
 }
 
+func (m *MockBandRepositoryForPost) GetMember(ctx context.Context, bandID, userID uuid.UUID) (*models.BandMember, error) {
+	if m.isAdminError != nil {
+		return nil, m.isAdminError
+	}
+	if m.admins[userID] {
+		return &models.BandMember{BandID: bandID, UserID: userID, Role: models.BandRoleAdmin}, nil
+	}
+	if m.posters[userID] {
+		return &models.BandMember{BandID: bandID, UserID: userID, Role: models.BandRoleMember, CanPostAsBand: true}, nil
+	}
+	return nil, nil
+}
+
+func (m *MockBandRepositoryForPost) GetAuthorSummaries(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]*models.PostAuthor, error) {
+	m.summaryQueries++
+	authors := make(map[uuid.UUID]*models.PostAuthor)
+	for _, id := range ids {
+		if band, exists := m.bandsByID[id.String()]; exists {
+			authors[id] = &models.PostAuthor{ID: id, Type: "band", Name: band.Name}
+		}
+	}
+	return authors, nil
+}
+
 type MockS3ClientForPost struct {
 	uploadResult        *storage.UploadResult
 	uploadError         error
 	validateError       error
 	validateImageError  error
 	validateAudioError  error
+	uploadedOwnerIDs    []string
 }
 
 func NewMockS3ClientForPost() *MockS3ClientForPost {
@@ -214,6 +311,7 @@ func (m *MockS3ClientForPost) UploadImage(ctx context.Context, userID, filename
 	if m.uploadError != nil {
 		return nil, m.uploadError
 	}
+	m.uploadedOwnerIDs = append(m.uploadedOwnerIDs, userID)
 	return m.uploadResult, nil
 }
 
@@ -221,6 +319,7 @@ func (m *MockS3ClientForPost) UploadAudio(ctx context.Context, userID, filename
 	if m.uploadError != nil {
 		return nil, m.uploadError
 	}
+	m.uploadedOwnerIDs = append(m.uploadedOwnerIDs, userID)
 	return m.uploadResult, nil
 }
 
@@ -1225,7 +1324,7 @@ func TestPostService_GetAllPosts(t *testing.T) {
 			postService := NewPostService(postRepo, userRepo, bandRepo, cache, s3Client)
 			
 			// Test GetAllPosts
-			posts, err := postService.GetAllPosts(context.Background(), tt.limit, tt.offset)
+			posts, err := postService.GetAllPosts(context.Background(), tt.limit, tt.offset, nil, nil)
 			
 			// Verify results
 			if tt.expectError {
@@ -1369,7 +1468,7 @@ func TestPostService_GetFeed(t *testing.T) {
 			postService := NewPostService(postRepo, userRepo, bandRepo, cache, s3Client)
 			
 			// Test GetFeed
-			posts, err := postService.GetFeed(context.Background(), tt.userID, tt.limit, tt.offset)
+			posts, err := postService.GetFeed(context.Background(), tt.userID, tt.limit, tt.offset, nil)
 			
 			// Verify results
 			if tt.expectError {
@@ -1513,7 +1612,7 @@ func TestPostService_GetExploreFeed(t *testing.T) {
 			postService := NewPostService(postRepo, userRepo, bandRepo, cache, s3Client)
 			
 			// Test GetExploreFeed
-			posts, err := postService.GetExploreFeed(context.Background(), tt.limit, tt.offset)
+			posts, err := postService.GetExploreFeed(context.Background(), tt.limit, tt.offset, nil, nil)
 			
 			// Verify results
 			if tt.expectError {
@@ -1852,4 +1951,573 @@ func TestPostService_UploadMedia(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+// Band post media is stored under the band, so it survives the deletion of
+// the member who uploaded it
+func TestPostService_UploadMedia_BandPost(t *testing.T) {
+	bandID := uuid.New()
+	posterID := uuid.New()
+
+	postRepo := NewMockPostRepository()
+	bandRepo := NewMockBandRepositoryForPost()
+	bandRepo.posters[posterID] = true
+	s3Client := NewMockS3ClientForPost()
+	s3Client.uploadResult = &storage.UploadResult{URL: "https://example.com/flyer.jpg"}
+
+	postID := uuid.New()
+	postRepo.postsByID[postID.String()] = &models.Post{
+		ID:         postID,
+		AuthorID:   &bandID,
+		AuthorType: "band",
+		BandID:     &bandID,
+		Content:    "Tour dates",
+	}
+
+	postService := NewPostService(postRepo, NewMockUserRepositoryForPost(), bandRepo, NewMockCache(), s3Client)
+
+	if _, _, err := postService.UploadMedia(context.Background(), postID, posterID, "flyer.jpg", []byte("fake image data"), "image"); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if len(s3Client.uploadedOwnerIDs) != 1 || s3Client.uploadedOwnerIDs[0] != bandID.String() {
+		t.Errorf("Expected the file to be stored under band %s, got %v", bandID, s3Client.uploadedOwnerIDs)
+	}
+}
+
+// Test CreateBandPost business logic with the REAL PostService using mocks
+func TestPostService_CreateBandPost(t *testing.T) {
+	bandID := uuid.New()
+	adminID := uuid.New()
+	posterID := uuid.New()
+
+	tests := []struct {
+		name          string
+		userID        uuid.UUID
+		req           *models.CreatePostRequest
+		setupMocks    func(*MockBandRepositoryForPost)
+		expectError   bool
+		errorContains string
+	}{
+		{
+			name:   "admin posts as band",
+			userID: adminID,
+			req:    &models.CreatePostRequest{Content: "New single out Friday"},
+		},
+		{
+			name:   "designated member posts as band",
+			userID: posterID,
+			req:    &models.CreatePostRequest{Content: "Tour dates announced"},
+		},
+		{
+			name:          "regular member cannot post as band",
+			userID:        uuid.New(),
+			req:           &models.CreatePostRequest{Content: "Hello"},
+			expectError:   true,
+			errorContains: "Only band admins and designated members",
+		},
+		{
+			name:          "empty content",
+			userID:        adminID,
+			req:           &models.CreatePostRequest{Content: ""},
+			expectError:   true,
+			errorContains: "post content is required",
+		},
+		{
+			name:   "band not found",
+			userID: adminID,
+			req:    &models.CreatePostRequest{Content: "Hello"},
+			setupMocks: func(bandRepo *MockBandRepositoryForPost) {
+				delete(bandRepo.bandsByID, bandID.String())
+			},
+			expectError:   true,
+			errorContains: "band not found",
+		},
+		{
+			name:   "permission check failure",
+			userID: adminID,
+			req:    &models.CreatePostRequest{Content: "Hello"},
+			setupMocks: func(bandRepo *MockBandRepositoryForPost) {
+				bandRepo.isAdminError = fmt.Errorf("database connection error")
+			},
+			expectError:   true,
+			errorContains: "Failed to check band permissions",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			postRepo := NewMockPostRepository()
+			bandRepo := NewMockBandRepositoryForPost()
+			bandRepo.bandsByID[bandID.String()] = &models.Band{ID: bandID}
+			bandRepo.admins[adminID] = true
+			bandRepo.posters[posterID] = true
+			if tt.setupMocks != nil {
+				tt.setupMocks(bandRepo)
+			}
+
+			postService := NewPostService(postRepo, NewMockUserRepositoryForPost(), bandRepo, NewMockCache(), NewMockS3ClientForPost())
+
+			post, err := postService.CreateBandPost(context.Background(), bandID, tt.userID, tt.req)
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error to contain '%s', got '%s'", tt.errorContains, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if post.AuthorType != "band" {
+				t.Errorf("Expected author type 'band', got '%s'", post.AuthorType)
+			}
+			if post.BandID == nil || *post.BandID != bandID {
+				t.Errorf("Expected band ID %v, got %v", bandID, post.BandID)
+			}
+			if post.UserID != nil {
+				t.Errorf("Expected band post to have no user ID, got %v", *post.UserID)
+			}
+		})
+	}
+}
+
+// Test that band posts are managed by the band's admins and designated members
+func TestPostService_ManageBandPost(t *testing.T) {
+	bandID := uuid.New()
+	adminID := uuid.New()
+	posterID := uuid.New()
+	content := "Updated announcement"
+
+	tests := []struct {
+		name        string
+		userID      uuid.UUID
+		expectError bool
+	}{
+		{name: "admin", userID: adminID},
+		{name: "designated member", userID: posterID},
+		{name: "other user", userID: uuid.New(), expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			postRepo := NewMockPostRepository()
+			bandRepo := NewMockBandRepositoryForPost()
+			bandRepo.admins[adminID] = true
+			bandRepo.posters[posterID] = true
+
+			postID := uuid.New()
+			postRepo.postsByID[postID.String()] = &models.Post{
+				ID:         postID,
+				AuthorID:   &bandID,
+				AuthorType: "band",
+				BandID:     &bandID,
+				Content:    "Announcement",
+			}
+
+			postService := NewPostService(postRepo, NewMockUserRepositoryForPost(), bandRepo, NewMockCache(), NewMockS3ClientForPost())
+
+			_, updateErr := postService.UpdatePost(context.Background(), postID, tt.userID, &models.UpdatePostRequest{Content: &content})
+			deleteErr := postService.DeletePost(context.Background(), postID, tt.userID)
+
+			if tt.expectError {
+				if updateErr == nil || !strings.Contains(updateErr.Error(), "you can only update your own posts") {
+					t.Errorf("Expected update to be rejected, got %v", updateErr)
+				}
+				if deleteErr == nil || !strings.Contains(deleteErr.Error(), "you can only delete your own posts") {
+					t.Errorf("Expected delete to be rejected, got %v", deleteErr)
+				}
+				return
+			}
+
+			if updateErr != nil {
+				t.Errorf("Expected update to succeed, got %v", updateErr)
+			}
+			if deleteErr != nil {
+				t.Errorf("Expected delete to succeed, got %v", deleteErr)
+			}
+		})
+	}
+}
+
+// Test GetBandPosts business logic with the REAL PostService using mocks
+func TestPostService_GetBandPosts(t *testing.T) {
+	bandID := uuid.New()
+	viewerID := uuid.New()
+
+	postRepo := NewMockPostRepository()
+	postRepo.bandPosts[bandID.String()] = []*models.Post{
+		{ID: uuid.New(), AuthorType: "band", BandID: &bandID},
+		{ID: uuid.New(), AuthorType: "band", BandID: &bandID},
+	}
+	postRepo.isLikedResult = true
+	bandRepo := NewMockBandRepositoryForPost()
+	bandRepo.bandsByID[bandID.String()] = &models.Band{ID: bandID}
+
+	postService := NewPostService(postRepo, NewMockUserRepositoryForPost(), bandRepo, NewMockCache(), NewMockS3ClientForPost())
+
+	posts, err := postService.GetBandPosts(context.Background(), bandID, 20, 0, nil, &viewerID)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if len(posts) != 2 {
+		t.Fatalf("Expected 2 posts, got %d", len(posts))
+	}
+	if !posts[0].IsLiked {
+		t.Error("Expected viewer like state to be applied")
+	}
+
+	if _, err := postService.GetBandPosts(context.Background(), uuid.New(), 20, 0, nil, nil); err == nil || !strings.Contains(err.Error(), "band not found") {
+		t.Errorf("Expected band not found error, got %v", err)
+	}
+
+	if _, err := postService.GetBandPosts(context.Background(), bandID, 0, 0, nil, nil); err == nil || !strings.Contains(err.Error(), "invalid limit") {
+		t.Errorf("Expected invalid limit error, got %v", err)
+	}
+}
+
+// Test that post listings embed user and band authors loaded in batches
+func TestPostService_AttachAuthors(t *testing.T) {
+	userID := uuid.New()
+	otherUserID := uuid.New()
+	bandID := uuid.New()
+	displayName := "The Producer"
+
+	postRepo := NewMockPostRepository()
+	postRepo.feedPosts = []*models.Post{
+		{ID: uuid.New(), AuthorType: "user", UserID: &userID},
+		{ID: uuid.New(), AuthorType: "user", UserID: &userID},
+		{ID: uuid.New(), AuthorType: "band", BandID: &bandID},
+		{ID: uuid.New(), AuthorType: "user", UserID: &otherUserID},
+	}
+	userRepo := NewMockUserRepositoryForPost()
+	userRepo.usersByID[userID.String()] = &models.User{ID: userID, Username: "producer", DisplayName: &displayName}
+	bandRepo := NewMockBandRepositoryForPost()
+	bandRepo.bandsByID[bandID.String()] = &models.Band{ID: bandID, Name: "The Loops"}
+
+	postService := NewPostService(postRepo, userRepo, bandRepo, NewMockCache(), NewMockS3ClientForPost())
+
+	posts, err := postService.GetExploreFeed(context.Background(), 20, 0, nil, nil)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if userRepo.summaryQueries != 1 || bandRepo.summaryQueries != 1 {
+		t.Errorf("Expected one batched author query each, got %d user and %d band", userRepo.summaryQueries, bandRepo.summaryQueries)
+	}
+
+	userAuthor, ok := posts[0].Author.(*models.PostAuthor)
+	if !ok || userAuthor.Username != "producer" || userAuthor.Type != "user" {
+		t.Errorf("Expected user author summary, got %+v", posts[0].Author)
+	}
+	if userAuthor != nil && (userAuthor.DisplayName == nil || *userAuthor.DisplayName != displayName) {
+		t.Errorf("Expected display name %s, got %v", displayName, userAuthor.DisplayName)
+	}
+
+	bandAuthor, ok := posts[2].Author.(*models.PostAuthor)
+	if !ok || bandAuthor.Name != "The Loops" || bandAuthor.Type != "band" {
+		t.Errorf("Expected band author summary, got %+v", posts[2].Author)
+	}
+
+	// Authors that no longer exist are left empty rather than failing the page
+	if posts[3].Author != nil {
+		t.Errorf("Expected missing author to be nil, got %+v", posts[3].Author)
+	}
+}
+
+// Test GetUserPosts business logic with the REAL PostService using mocks
+func TestPostService_GetUserPosts(t *testing.T) {
+	userID := uuid.New()
+
+	tests := []struct {
+		name          string
+		limit         int
+		offset        int
+		setupMocks    func(*MockPostRepository)
+		expectError   bool
+		errorContains string
+		expectedCount int
+	}{
+		{
+			name:  "successful user posts retrieval",
+			limit: 20,
+			setupMocks: func(postRepo *MockPostRepository) {
+				postRepo.userPosts[userID.String()] = []*models.Post{
+					{ID: uuid.New(), AuthorType: "user", UserID: &userID},
+				}
+			},
+			expectedCount: 1,
+		},
+		{
+			name:          "invalid limit",
+			limit:         0,
+			expectError:   true,
+			errorContains: "invalid limit",
+		},
+		{
+			name:          "invalid offset",
+			limit:         20,
+			offset:        -1,
+			expectError:   true,
+			errorContains: "invalid offset",
+		},
+		{
+			name:  "repository error",
+			limit: 20,
+			setupMocks: func(postRepo *MockPostRepository) {
+				postRepo.getByUserIDError = fmt.Errorf("database error")
+			},
+			expectError:   true,
+			errorContains: "failed to retrieve user posts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			postRepo := NewMockPostRepository()
+			if tt.setupMocks != nil {
+				tt.setupMocks(postRepo)
+			}
+			userRepo := NewMockUserRepositoryForPost()
+			userRepo.usersByID[userID.String()] = &models.User{ID: userID, Username: "producer"}
+
+			postService := NewPostService(postRepo, userRepo, NewMockBandRepositoryForPost(), NewMockCache(), NewMockS3ClientForPost())
+
+			posts, err := postService.GetUserPosts(context.Background(), userID, tt.limit, tt.offset, nil, nil)
+
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error but got none")
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error to contain '%s', got '%s'", tt.errorContains, err.Error())
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+			if len(posts) != tt.expectedCount {
+				t.Errorf("Expected %d posts, got %d", tt.expectedCount, len(posts))
+			}
+			if tt.expectedCount > 0 && posts[0].Author == nil {
+				t.Error("Expected author to be attached")
+			}
+		})
+	}
+}
+
+// Posts of an account scheduled for deletion are hidden from everyone but
+// its owner until the account is deleted or restored
+func TestPostService_HidesAuthorsScheduledForDeletion(t *testing.T) {
+	authorID := uuid.New()
+	viewerID := uuid.New()
+	deleteAt := time.Now().Add(30 * 24 * time.Hour)
+	post := &models.Post{ID: uuid.New(), AuthorType: "user", UserID: &authorID, Content: "Last words"}
+
+	postRepo := NewMockPostRepository()
+	postRepo.postsByID[post.ID.String()] = post
+	postRepo.userPosts[authorID.String()] = []*models.Post{post}
+	userRepo := NewMockUserRepositoryForPost()
+	userRepo.usersByID[authorID.String()] = &models.User{ID: authorID, Username: "leaving", DeletionScheduledAt: &deleteAt}
+
+	postService := NewPostService(postRepo, userRepo, NewMockBandRepositoryForPost(), NewMockCache(), NewMockS3ClientForPost())
+	ctx := context.Background()
+
+	for _, viewer := range []*uuid.UUID{nil, &viewerID} {
+		if _, err := postService.GetUserPosts(ctx, authorID, 20, 0, nil, viewer); err == nil {
+			t.Error("Expected the user's posts to be hidden")
+		}
+		if _, err := postService.GetPost(ctx, post.ID, viewer); err == nil || !strings.Contains(err.Error(), "post not found") {
+			t.Errorf("Expected the post to be hidden, got: %v", err)
+		}
+	}
+
+	posts, err := postService.GetUserPosts(ctx, authorID, 20, 0, nil, &authorID)
+	if err != nil || len(posts) != 1 {
+		t.Errorf("Expected the owner to still see their posts, got %d posts and %v", len(posts), err)
+	}
+	if _, err := postService.GetPost(ctx, post.ID, &authorID); err != nil {
+		t.Errorf("Expected the owner to still see their post, got: %v", err)
+	}
+}
+
+// Test that viewer like/repost state is loaded with one query per relation
+// regardless of page size, for every listing that accepts a viewer
+func TestPostService_ApplyViewerState(t *testing.T) {
+	viewerID := uuid.New()
+	authorID := uuid.New()
+	bandID := uuid.New()
+
+	posts := []*models.Post{
+		{ID: uuid.New(), AuthorType: "user", UserID: &authorID},
+		{ID: uuid.New(), AuthorType: "user", UserID: &authorID},
+		{ID: uuid.New(), AuthorType: "user", UserID: &authorID},
+	}
+
+	postRepo := NewMockPostRepository()
+	postRepo.feedPosts = posts
+	postRepo.allPosts = posts
+	postRepo.userPosts[authorID.String()] = posts
+	postRepo.bandPosts[bandID.String()] = posts
+	postRepo.likedPosts = map[uuid.UUID]bool{posts[0].ID: true}
+	postRepo.repostedPosts = map[uuid.UUID]bool{posts[2].ID: true}
+	bandRepo := NewMockBandRepositoryForPost()
+	bandRepo.bandsByID[bandID.String()] = &models.Band{ID: bandID}
+
+	userRepo := NewMockUserRepositoryForPost()
+	userRepo.usersByID[authorID.String()] = &models.User{ID: authorID, Username: "author"}
+
+	postService := NewPostService(postRepo, userRepo, bandRepo, NewMockCache(), NewMockS3ClientForPost())
+	ctx := context.Background()
+
+	listings := map[string]func() ([]*models.Post, error){
+		"feed":       func() ([]*models.Post, error) { return postService.GetFeed(ctx, viewerID, 20, 0, nil) },
+		"explore":    func() ([]*models.Post, error) { return postService.GetExploreFeed(ctx, 20, 0, nil, &viewerID) },
+		"all posts":  func() ([]*models.Post, error) { return postService.GetAllPosts(ctx, 20, 0, nil, &viewerID) },
+		"user posts": func() ([]*models.Post, error) { return postService.GetUserPosts(ctx, authorID, 20, 0, nil, &viewerID) },
+		"band posts": func() ([]*models.Post, error) { return postService.GetBandPosts(ctx, bandID, 20, 0, nil, &viewerID) },
+	}
+
+	for name, list := range listings {
+		t.Run(name, func(t *testing.T) {
+			for _, post := range posts {
+				post.IsLiked = false
+				post.IsReposted = false
+			}
+			postRepo.viewerStateQueries = 0
+
+			result, err := list()
+			if err != nil {
+				t.Fatalf("Expected no error but got: %v", err)
+			}
+
+			if postRepo.viewerStateQueries != 2 {
+				t.Errorf("Expected 2 batched viewer state queries, got %d", postRepo.viewerStateQueries)
+			}
+			if !result[0].IsLiked || result[1].IsLiked || result[2].IsLiked {
+				t.Errorf("Expected only the first post to be liked")
+			}
+			if result[0].IsReposted || result[1].IsReposted || !result[2].IsReposted {
+				t.Errorf("Expected only the last post to be reposted")
+			}
+		})
+	}
+
+	t.Run("anonymous viewer", func(t *testing.T) {
+		postRepo.viewerStateQueries = 0
+		if _, err := postService.GetExploreFeed(ctx, 20, 0, nil, nil); err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if postRepo.viewerStateQueries != 0 {
+			t.Errorf("Expected no viewer state queries without a viewer, got %d", postRepo.viewerStateQueries)
+		}
+	})
+}
+
+// Test Unrepost business logic with the REAL PostService using mocks
+func TestPostService_Unrepost(t *testing.T) {
+	postRepo := NewMockPostRepository()
+	postService := NewPostService(postRepo, NewMockUserRepositoryForPost(), NewMockBandRepositoryForPost(), NewMockCache(), NewMockS3ClientForPost())
+
+	if err := postService.Unrepost(context.Background(), uuid.New(), uuid.New()); err != nil {
+		t.Errorf("Expected no error but got: %v", err)
+	}
+
+	postRepo.unrepostError = fmt.Errorf("database delete failed")
+	err := postService.Unrepost(context.Background(), uuid.New(), uuid.New())
+	if err == nil || !strings.Contains(err.Error(), "failed to remove repost") {
+		t.Errorf("Expected remove repost error, got %v", err)
+	}
+}
+
+// Test quote posts: creation validates the original and responses embed it
+func TestPostService_QuotePost(t *testing.T) {
+	authorID := uuid.New()
+	quoterID := uuid.New()
+	originalID := uuid.New()
+
+	postRepo := NewMockPostRepository()
+	postRepo.postsByID[originalID.String()] = &models.Post{ID: originalID, AuthorType: "user", UserID: &authorID, Content: "New single out now"}
+	userRepo := NewMockUserRepositoryForPost()
+	userRepo.usersByID[authorID.String()] = &models.User{ID: authorID, Username: "artist"}
+	userRepo.usersByID[quoterID.String()] = &models.User{ID: quoterID, Username: "fan"}
+
+	postService := NewPostService(postRepo, userRepo, NewMockBandRepositoryForPost(), NewMockCache(), NewMockS3ClientForPost())
+
+	quote, err := postService.CreatePost(context.Background(), quoterID, &models.CreatePostRequest{
+		Content:      "This one is on repeat",
+		QuotedPostID: &originalID,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if quote.QuotedPostID == nil || *quote.QuotedPostID != originalID {
+		t.Errorf("Expected quoted post ID %v, got %v", originalID, quote.QuotedPostID)
+	}
+	if quote.QuotedPost == nil || quote.QuotedPost.Content != "New single out now" {
+		t.Fatalf("Expected the original to be embedded, got %+v", quote.QuotedPost)
+	}
+	if author, ok := quote.QuotedPost.Author.(*models.PostAuthor); !ok || author.Username != "artist" {
+		t.Errorf("Expected embedded post to carry its author, got %+v", quote.QuotedPost.Author)
+	}
+
+	missingID := uuid.New()
+	_, err = postService.CreatePost(context.Background(), quoterID, &models.CreatePostRequest{
+		Content:      "Quoting nothing",
+		QuotedPostID: &missingID,
+	})
+	if err == nil || !strings.Contains(err.Error(), "quoted post not found") {
+		t.Errorf("Expected quoted post not found error, got %v", err)
+	}
+}
+
+// Test that reposts in the feed are attributed to the reposter
+func TestPostService_GetFeed_Reposts(t *testing.T) {
+	viewerID := uuid.New()
+	authorID := uuid.New()
+	reposterID := uuid.New()
+
+	postRepo := NewMockPostRepository()
+	postRepo.feedPosts = []*models.Post{
+		{ID: uuid.New(), AuthorType: "user", UserID: &authorID, RepostedByID: &reposterID},
+		{ID: uuid.New(), AuthorType: "user", UserID: &reposterID},
+	}
+	userRepo := NewMockUserRepositoryForPost()
+	userRepo.usersByID[authorID.String()] = &models.User{ID: authorID, Username: "artist"}
+	userRepo.usersByID[reposterID.String()] = &models.User{ID: reposterID, Username: "fan"}
+
+	postService := NewPostService(postRepo, userRepo, NewMockBandRepositoryForPost(), NewMockCache(), NewMockS3ClientForPost())
+
+	posts, err := postService.GetFeed(context.Background(), viewerID, 20, 0, nil)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if posts[0].RepostedBy == nil || posts[0].RepostedBy.Username != "fan" {
+		t.Errorf("Expected repost attributed to fan, got %+v", posts[0].RepostedBy)
+	}
+	if posts[1].RepostedBy != nil {
+		t.Errorf("Expected original post to have no reposter, got %+v", posts[1].RepostedBy)
+	}
+	if userRepo.summaryQueries != 1 {
+		t.Errorf("Expected reposters to share the author query, got %d queries", userRepo.summaryQueries)
+	}
+}
+
+func TestPostService_GetFeed_Cursor(t *testing.T) {
+	postRepo := NewMockPostRepository()
+	postService := NewPostService(postRepo, NewMockUserRepositoryForPost(), NewMockBandRepositoryForPost(), NewMockCache(), NewMockS3ClientForPost())
+
+	after := &pagination.Cursor{CreatedAt: time.Now().Add(-time.Hour), ID: uuid.New()}
+	if _, err := postService.GetFeed(context.Background(), uuid.New(), 20, 0, after); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if postRepo.lastAfter != after {
+		t.Errorf("Expected cursor to be passed to the repository, got %+v", postRepo.lastAfter)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"musicapp/internal/interfaces"
 	"musicapp/internal/logging"
 	"musicapp/internal/models"
+	"musicapp/internal/pagination"
 	"musicapp/internal/repository"
 	"musicapp/internal/storage"
 	"musicapp/pkg/utils"
@@ -83,6 +84,19 @@ func (s *UserService) GetUser(ctx context.Context, userID uuid.UUID) (*models.Us
 	return user, nil
 }
 
+// GetProfile retrieves a user for another viewer. Accounts scheduled for
+// deletion are hidden from everyone but their owner during the grace period.
+func (s *UserService) GetProfile(ctx context.Context, userID uuid.UUID, viewerID *uuid.UUID) (*models.User, error) {
+	user, err := s.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletionScheduledAt != nil && (viewerID == nil || *viewerID != userID) {
+		return nil, fmt.Errorf("user not found")
+	}
+	return user, nil
+}
+
 // UpdateUser updates user profile
 func (s *UserService) UpdateUser(ctx context.Context, userID uuid.UUID, req *models.UpdateUserRequest) (*models.User, error) {
 	// Get existing user
@@ -153,22 +167,8 @@ func (s *UserService) GetNearbyUsers(ctx context.Context, lat, lng float64, radi
 	return users, nil
 }
 
-// GetUserPosts retrieves posts by a specific user
-func (s *UserService) GetUserPosts(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.Post, error) {
-	if limit <= 0 || limit > 100 {
-		return nil, fmt.Errorf("invalid limit: %d (must be 1-100)", limit)
-	}
-	if offset < 0 {
-		return nil, fmt.Errorf("invalid offset: %d (must be >= 0)", offset)
-	}
-
-	// This would need to be implemented in the repository
-	// For now, return empty slice
-	return []*models.Post{}, nil
-}
-
 // GetFollowers retrieves users who follow the specified user
-func (s *UserService) GetFollowers(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.User, error) {
+func (s *UserService) GetFollowers(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.User, error) {
 	if limit <= 0 || limit > 100 {
 		return nil, fmt.Errorf("invalid limit: %d (must be 1-100)", limit)
 	}
@@ -176,7 +176,7 @@ func (s *UserService) GetFollowers(ctx context.Context, userID uuid.UUID, limit,
 		return nil, fmt.Errorf("invalid offset: %d (must be >= 0)", offset)
 	}
 
-	followers, err := s.userRepo.GetFollowers(ctx, userID, limit, offset)
+	followers, err := s.userRepo.GetFollowers(ctx, userID, limit, offset, after)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get followers: %w", err)
 	}
@@ -185,7 +185,7 @@ func (s *UserService) GetFollowers(ctx context.Context, userID uuid.UUID, limit,
 }
 
 // GetFollowing retrieves users that the specified user is following
-func (s *UserService) GetFollowing(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.User, error) {
+func (s *UserService) GetFollowing(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.User, error) {
 	if limit <= 0 || limit > 100 {
 		return nil, fmt.Errorf("invalid limit: %d (must be 1-100)", limit)
 	}
@@ -193,7 +193,7 @@ func (s *UserService) GetFollowing(ctx context.Context, userID uuid.UUID, limit,
 		return nil, fmt.Errorf("invalid offset: %d (must be >= 0)", offset)
 	}
 
-	following, err := s.userRepo.GetFollowing(ctx, userID, limit, offset)
+	following, err := s.userRepo.GetFollowing(ctx, userID, limit, offset, after)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get following: %w", err)
 	}
@@ -256,7 +256,7 @@ func (s *UserService) GetUserBands(ctx context.Context, userID uuid.UUID) ([]*mo
 }
 
 // GetAllUsers gets all users with pagination
-func (s *UserService) GetAllUsers(ctx context.Context, limit, offset int) ([]*models.User, error) {
+func (s *UserService) GetAllUsers(ctx context.Context, limit, offset int, after *pagination.Cursor) ([]*models.User, error) {
 	// Default pagination values
 	if limit <= 0 {
 		limit = 20
@@ -268,7 +268,7 @@ func (s *UserService) GetAllUsers(ctx context.Context, limit, offset int) ([]*mo
 		offset = 0
 	}
 
-	return s.userRepo.GetAll(ctx, limit, offset)
+	return s.userRepo.GetAll(ctx, limit, offset, after)
 }
 
 // Extended adapter for UserService (building on existing adapters from auth.go)
@@ -291,16 +291,16 @@ func (a *UserRepositoryExtendedAdapter) GetNearby(ctx context.Context, lat, lng
 	return a.repo.GetNearby(ctx, lat, lng, radiusKm, limit)
 }
 
-func (a *UserRepositoryExtendedAdapter) GetFollowers(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.User, error) {
-	return a.repo.GetFollowers(ctx, userID, limit, offset)
+func (a *UserRepositoryExtendedAdapter) GetFollowers(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.User, error) {
+	return a.repo.GetFollowers(ctx, userID, limit, offset, after)
 }
 
-func (a *UserRepositoryExtendedAdapter) GetFollowing(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.User, error) {
-	return a.repo.GetFollowing(ctx, userID, limit, offset)
+func (a *UserRepositoryExtendedAdapter) GetFollowing(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.User, error) {
+	return a.repo.GetFollowing(ctx, userID, limit, offset, after)
 }
 
-func (a *UserRepositoryExtendedAdapter) GetAll(ctx context.Context, limit, offset int) ([]*models.User, error) {
-	return a.repo.GetAll(ctx, limit, offset)
+func (a *UserRepositoryExtendedAdapter) GetAll(ctx context.Context, limit, offset int, after *pagination.Cursor) ([]*models.User, error) {
+	return a.repo.GetAll(ctx, limit, offset, after)
 }
 
 // S3ClientAdapter adapts storage.S3Client to S3Client interface
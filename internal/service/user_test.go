@@ -6,10 +6,12 @@ import (
 	"io"
 	"strings"
 	"testing"
+	"time"
 
 	"musicapp/internal/interfaces"
 	"musicapp/internal/logging"
 	"musicapp/internal/models"
+	"musicapp/internal/pagination"
 	"musicapp/internal/storage"
 	"musicapp/pkg/utils"
 
@@ -71,21 +73,21 @@ func (m *ExtendedMockUserRepository) GetNearby(ctx context.Context, lat, lng flo
 	return m.nearbyUsers, nil
 }
 
-func (m *ExtendedMockUserRepository) GetFollowers(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.User, error) {
+func (m *ExtendedMockUserRepository) GetFollowers(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.User, error) {
 	if m.getFollowersError != nil {
 		return nil, m.getFollowersError
 	}
 	return m.followers, nil
 }
 
-func (m *ExtendedMockUserRepository) GetFollowing(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*models.User, error) {
+func (m *ExtendedMockUserRepository) GetFollowing(ctx context.Context, userID uuid.UUID, limit, offset int, after *pagination.Cursor) ([]*models.User, error) {
 	if m.getFollowingError != nil {
 		return nil, m.getFollowingError
 	}
 	return m.following, nil
 }
 
-func (m *ExtendedMockUserRepository) GetAll(ctx context.Context, limit, offset int) ([]*models.User, error) {
+func (m *ExtendedMockUserRepository) GetAll(ctx context.Context, limit, offset int, after *pagination.Cursor) ([]*models.User, error) {
 	return m.allUsers, nil
 }
 
@@ -398,6 +400,25 @@ func TestUserService_GetUser(t *testing.T) {
 }
 
 // Test UploadProfilePicture business logic with the REAL UserService using mocks
+func TestUserService_GetProfile(t *testing.T) {
+	userRepo := NewExtendedMockUserRepository()
+	userID := uuid.New()
+	viewerID := uuid.New()
+	deleteAt := time.Now().Add(30 * 24 * time.Hour)
+	userRepo.usersByID[userID.String()] = &models.User{ID: userID, Username: "leaving", DeletionScheduledAt: &deleteAt}
+
+	userService := NewUserService(userRepo, NewMockCache(), NewMockS3Client(), createTestLogger())
+
+	for _, viewer := range []*uuid.UUID{nil, &viewerID} {
+		if _, err := userService.GetProfile(context.Background(), userID, viewer); err == nil {
+			t.Error("Expected an account scheduled for deletion to be hidden from other viewers")
+		}
+	}
+	if _, err := userService.GetProfile(context.Background(), userID, &userID); err != nil {
+		t.Errorf("Expected the owner to still see their profile, got: %v", err)
+	}
+}
+
 func TestUserService_UploadProfilePicture(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -677,105 +698,6 @@ func TestUserService_GetUserBands(t *testing.T) {
 	}
 }
 
-// Test GetUserPosts business logic with the REAL UserService using mocks
-func TestUserService_GetUserPosts(t *testing.T) {
-	tests := []struct {
-		name           string
-		userID         uuid.UUID
-		limit          int
-		offset         int
-		setupMocks     func(*ExtendedMockUserRepository, *MockCache, *MockS3Client)
-		expectError    bool
-		errorContains  string
-		expectPosts    bool
-		expectedCount  int
-	}{
-		{
-			name:   "successful user posts retrieval",
-			userID: uuid.New(),
-			limit:  20,
-			offset: 0,
-			setupMocks: func(userRepo *ExtendedMockUserRepository, cache *MockCache, s3Client *MockS3Client) {
-				// No setup needed - method returns empty slice
-			},
-			expectError:   false,
-			expectPosts:   true,
-			expectedCount: 0, // Method returns empty slice
-		},
-		{
-			name:          "invalid limit too low",
-			userID:        uuid.New(),
-			limit:         0,
-			offset:        0,
-			setupMocks:    func(userRepo *ExtendedMockUserRepository, cache *MockCache, s3Client *MockS3Client) {},
-			expectError:   true,
-			errorContains: "invalid limit",
-		},
-		{
-			name:          "invalid limit too high",
-			userID:        uuid.New(),
-			limit:         101,
-			offset:        0,
-			setupMocks:    func(userRepo *ExtendedMockUserRepository, cache *MockCache, s3Client *MockS3Client) {},
-			expectError:   true,
-			errorContains: "invalid limit",
-		},
-		{
-			name:          "invalid offset negative",
-			userID:        uuid.New(),
-			limit:         20,
-			offset:        -1,
-			setupMocks:    func(userRepo *ExtendedMockUserRepository, cache *MockCache, s3Client *MockS3Client) {},
-			expectError:   true,
-			errorContains: "invalid offset",
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Setup mocks
-			userRepo := NewExtendedMockUserRepository()
-			cache := NewMockCache()
-			s3Client := NewMockS3Client()
-			
-			if tt.setupMocks != nil {
-				tt.setupMocks(userRepo, cache, s3Client)
-			}
-			
-			// Create REAL UserService with mocks
-			userService := NewUserService(userRepo, cache, s3Client, createTestLogger())
-			
-			// Test GetUserPosts
-			posts, err := userService.GetUserPosts(context.Background(), tt.userID, tt.limit, tt.offset)
-			
-			// Verify results
-			if tt.expectError {
-				if err == nil {
-					t.Error("Expected error but got none")
-				}
-				if tt.errorContains != "" && err != nil {
-					if !strings.Contains(err.Error(), tt.errorContains) {
-						t.Errorf("Expected error to contain '%s', got '%s'", tt.errorContains, err.Error())
-					}
-				}
-				if posts != nil {
-					t.Error("Expected posts to be nil on error")
-				}
-			} else {
-				if err != nil {
-					t.Errorf("Expected no error but got: %v", err)
-				}
-				if tt.expectPosts && posts == nil {
-					t.Error("Expected posts but got nil")
-				}
-				if tt.expectPosts && len(posts) != tt.expectedCount {
-					t.Errorf("Expected %d posts, got %d", tt.expectedCount, len(posts))
-				}
-			}
-		})
-	}
-}
-
 // Test GetAllUsers business logic with the REAL UserService using mocks
 func TestUserService_GetAllUsers(t *testing.T) {
 	tests := []struct {
@@ -871,7 +793,7 @@ func TestUserService_GetAllUsers(t *testing.T) {
 			userService := NewUserService(userRepo, cache, s3Client, createTestLogger())
 			
 			// Test GetAllUsers
-			users, err := userService.GetAllUsers(context.Background(), tt.limit, tt.offset)
+			users, err := userService.GetAllUsers(context.Background(), tt.limit, tt.offset, nil)
 			
 			// Verify results
 			if tt.expectError {
@@ -997,7 +919,7 @@ func TestUserService_GetFollowing(t *testing.T) {
 			userService := NewUserService(userRepo, cache, s3Client, createTestLogger())
 			
 			// Test GetFollowing
-			users, err := userService.GetFollowing(context.Background(), tt.userID, tt.limit, tt.offset)
+			users, err := userService.GetFollowing(context.Background(), tt.userID, tt.limit, tt.offset, nil)
 			
 			// Verify results
 			if tt.expectError {
@@ -1140,7 +1062,7 @@ func TestUserService_GetFollowers(t *testing.T) {
 			userService := NewUserService(userRepo, cache, s3Client, createTestLogger())
 			
 			// Test GetFollowers
-			users, err := userService.GetFollowers(context.Background(), tt.userID, tt.limit, tt.offset)
+			users, err := userService.GetFollowers(context.Background(), tt.userID, tt.limit, tt.offset, nil)
 			
 			// Verify results
 			if tt.expectError {
@@ -13,6 +13,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 )
 
 type S3Client struct {
@@ -90,6 +91,17 @@ func (s *S3Client) UploadFile(ctx context.Context, key string, file io.Reader, c
 	}, nil
 }
 
+// MediaPrefixes are the key prefixes an owner's files are stored under:
+// uploads from UploadAudio and UploadImage, and data export archives
+func MediaPrefixes(ownerID string) []string {
+	return []string{"audio/" + ownerID + "/", "images/" + ownerID + "/", "exports/" + ownerID + "/"}
+}
+
+// ExportKey is where the archive of a user's data export is stored
+func ExportKey(userID, exportID string) string {
+	return fmt.Sprintf("exports/%s/%s.zip", userID, exportID)
+}
+
 // UploadAudio uploads an audio file with optimized settings
 func (s *S3Client) UploadAudio(ctx context.Context, userID, filename string, file io.Reader, size int64) (*UploadResult, error) {
 	// Generate unique key for audio file
@@ -175,6 +187,43 @@ func (s *S3Client) DeleteFile(ctx context.Context, key string) error {
 	return err
 }
 
+// DeletePrefix deletes every file whose key starts with prefix and returns
+// how many were deleted
+func (s *S3Client) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	deleted := 0
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.bucketName),
+		Prefix: aws.String(prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to list files: %w", err)
+		}
+		if len(page.Contents) == 0 {
+			continue
+		}
+
+		// A page holds at most 1000 keys, the most DeleteObjects accepts
+		objects := make([]types.ObjectIdentifier, 0, len(page.Contents))
+		for _, object := range page.Contents {
+			objects = append(objects, types.ObjectIdentifier{Key: object.Key})
+		}
+		output, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(s.bucketName),
+			Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete files: %w", err)
+		}
+		if len(output.Errors) > 0 {
+			return deleted + len(objects) - len(output.Errors), fmt.Errorf("failed to delete %s: %s", aws.ToString(output.Errors[0].Key), aws.ToString(output.Errors[0].Message))
+		}
+		deleted += len(objects)
+	}
+	return deleted, nil
+}
+
 // GetFileInfo gets metadata about a file
 func (s *S3Client) GetFileInfo(ctx context.Context, key string) (*s3.HeadObjectOutput, error) {
 	return s.client.HeadObject(ctx, &s3.HeadObjectInput{
@@ -203,6 +252,25 @@ func (s *S3Client) GeneratePresignedUploadURL(ctx context.Context, key string, c
 	return request.URL, nil
 }
 
+// GeneratePresignedDownloadURL creates a link that downloads a private file
+// until it expires
+func (s *S3Client) GeneratePresignedDownloadURL(ctx context.Context, key string, expiresIn time.Duration) (string, error) {
+	presignClient := s3.NewPresignClient(s.client)
+
+	request, err := presignClient.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucketName),
+		Key:    aws.String(key),
+	}, func(opts *s3.PresignOptions) {
+		opts.Expires = expiresIn
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	return request.URL, nil
+}
+
 // Helper methods
 func (s *S3Client) generateURL(key string) string {
 	if s.cdnURL != "" {
@@ -0,0 +1,17 @@
+-- Threaded replies on comments
+ALTER TABLE comments ADD COLUMN parent_comment_id UUID REFERENCES comments(id) ON DELETE CASCADE;
+ALTER TABLE comments ADD COLUMN depth INT NOT NULL DEFAULT 0;
+
+CREATE INDEX idx_comments_parent ON comments(parent_comment_id, created_at) WHERE parent_comment_id IS NOT NULL;
+
+-- Comment likes table
+CREATE TABLE comment_likes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    comment_id UUID REFERENCES comments(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(user_id, comment_id)
+);
+
+CREATE INDEX idx_comment_likes_comment ON comment_likes(comment_id);
+CREATE INDEX idx_comment_likes_user ON comment_likes(user_id);
@@ -0,0 +1,35 @@
+-- One-to-one conversations between users. The participant pair is stored in
+-- sorted order so each pair maps to exactly one conversation.
+CREATE TABLE conversations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_low_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_high_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    last_message_at TIMESTAMP DEFAULT NOW(),
+    CHECK (user_low_id < user_high_id),
+    UNIQUE(user_low_id, user_high_id)
+);
+
+CREATE INDEX idx_conversations_last_message ON conversations(last_message_at DESC);
+
+-- Per-participant read markers
+CREATE TABLE conversation_participants (
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_read_at TIMESTAMP,
+    joined_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (conversation_id, user_id)
+);
+
+CREATE INDEX idx_conversation_participants_user ON conversation_participants(user_id);
+
+CREATE TABLE direct_messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    conversation_id UUID NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    sender_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_direct_messages_conversation ON direct_messages(conversation_id, created_at DESC);
+CREATE INDEX idx_direct_messages_sender ON direct_messages(sender_id);
@@ -0,0 +1,2 @@
+-- Band admins can designate members who may publish posts as the band
+ALTER TABLE band_members ADD COLUMN can_post_as_band BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,5 @@
+-- Quote posts: a post that reposts another post with the author's own commentary.
+-- Quotes outlive the original; the embed simply disappears when it is deleted.
+ALTER TABLE posts ADD COLUMN quoted_post_id UUID REFERENCES posts(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_posts_quoted_post ON posts(quoted_post_id) WHERE quoted_post_id IS NOT NULL;
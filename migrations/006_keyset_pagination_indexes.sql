@@ -0,0 +1,18 @@
+-- Keyset pagination: list endpoints page on (created_at, id) instead of OFFSET,
+-- so each ordering needs an index covering both columns with the id tie-breaker.
+CREATE INDEX idx_posts_created_id ON posts(created_at DESC, id DESC);
+CREATE INDEX idx_posts_user_created ON posts(user_id, created_at DESC, id DESC) WHERE user_id IS NOT NULL;
+CREATE INDEX idx_posts_band_created ON posts(band_id, created_at DESC, id DESC) WHERE band_id IS NOT NULL;
+
+CREATE INDEX idx_comments_post_created ON comments(post_id, created_at, id) WHERE parent_comment_id IS NULL;
+CREATE INDEX idx_comments_parent_created ON comments(parent_comment_id, created_at, id) WHERE parent_comment_id IS NOT NULL;
+
+CREATE INDEX idx_users_created_id ON users(created_at DESC, id DESC);
+CREATE INDEX idx_bands_created_id ON bands(created_at DESC, id DESC);
+
+CREATE INDEX idx_follows_user_created ON follows(following_user_id, created_at DESC, id DESC) WHERE following_user_id IS NOT NULL;
+CREATE INDEX idx_follows_band_created ON follows(following_band_id, created_at DESC, id DESC) WHERE following_band_id IS NOT NULL;
+CREATE INDEX idx_follows_follower_created ON follows(follower_id, created_at DESC, id DESC);
+
+-- The partial index from 002 becomes redundant with the one above
+DROP INDEX IF EXISTS idx_comments_parent;
@@ -0,0 +1,14 @@
+-- Sessions: one row per signed-in device. The session ID doubles as the refresh
+-- token family and the sid claim of its access tokens, so revoking it logs the device out.
+CREATE TABLE sessions (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    device_name VARCHAR(100),
+    user_agent TEXT,
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP DEFAULT NOW(),
+    last_seen_at TIMESTAMP DEFAULT NOW(),
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_sessions_user_active ON sessions(user_id, last_seen_at DESC) WHERE revoked_at IS NULL;
@@ -0,0 +1,13 @@
+-- Account tokens: single-use, expiring tokens mailed to users, e.g. for password resets.
+-- Only the SHA-256 hash of the token is stored.
+CREATE TABLE account_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    purpose VARCHAR(32) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_account_tokens_user ON account_tokens(user_id, purpose) WHERE used_at IS NULL;
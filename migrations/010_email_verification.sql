@@ -0,0 +1,6 @@
+-- Email verification: new accounts must confirm their address before they can
+-- post, follow or join bands. Accounts created before verification existed are
+-- treated as verified.
+ALTER TABLE users ADD COLUMN email_verified_at TIMESTAMP;
+
+UPDATE users SET email_verified_at = created_at;
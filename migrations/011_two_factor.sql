@@ -0,0 +1,21 @@
+-- Two-factor authentication: one TOTP authenticator per user. The secret is kept
+-- unconfirmed until the user proves their app generates valid codes.
+-- last_used_step stops a code from being replayed within its validity window.
+CREATE TABLE totp_credentials (
+    user_id UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    secret VARCHAR(64) NOT NULL,
+    confirmed_at TIMESTAMP,
+    last_used_step BIGINT,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- One-time recovery codes for when the authenticator is lost. Only the
+-- SHA-256 hash of each code is stored.
+CREATE TABLE recovery_codes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(user_id, code_hash)
+);
@@ -0,0 +1,15 @@
+-- External identity provider accounts linked to users. A provider subject can
+-- sign in to exactly one user, and a user links each provider at most once.
+CREATE TABLE identities (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    provider VARCHAR(50) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(255),
+    created_at TIMESTAMP DEFAULT NOW(),
+    last_login_at TIMESTAMP,
+    UNIQUE(provider, subject),
+    UNIQUE(user_id, provider)
+);
+
+CREATE INDEX idx_identities_user_id ON identities(user_id);
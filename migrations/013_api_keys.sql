@@ -0,0 +1,16 @@
+-- Personal API keys for scripts and integrations. Only the SHA-256 hash of the
+-- key is stored; token_prefix is kept so users can tell their keys apart.
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    token_prefix VARCHAR(16) NOT NULL,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_api_keys_user_active ON api_keys(user_id, created_at DESC) WHERE revoked_at IS NULL;
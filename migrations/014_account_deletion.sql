@@ -0,0 +1,6 @@
+-- Account deletion: a deleted account is kept until deletion_scheduled_at so
+-- the user can change their mind, then removed together with everything that
+-- references it.
+ALTER TABLE users ADD COLUMN deletion_scheduled_at TIMESTAMP;
+
+CREATE INDEX idx_users_deletion_scheduled ON users(deletion_scheduled_at) WHERE deletion_scheduled_at IS NOT NULL;
@@ -0,0 +1,17 @@
+-- Personal data exports: users request a ZIP of their data, which is built in
+-- the background, stored in S3 and downloadable until expires_at.
+CREATE TABLE data_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending', -- pending, processing, completed, failed, expired
+    object_key TEXT,
+    size_bytes BIGINT,
+    error TEXT,
+    created_at TIMESTAMP DEFAULT NOW(),
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    expires_at TIMESTAMP
+);
+
+CREATE INDEX idx_data_exports_user ON data_exports(user_id, created_at DESC);
+CREATE INDEX idx_data_exports_open ON data_exports(status) WHERE status IN ('pending', 'processing', 'completed');
@@ -0,0 +1,27 @@
+-- Platform roles and moderation: every user has a role (user, moderator or
+-- admin) carried in their access tokens, staff can suspend accounts, and
+-- every staff action is recorded in the audit log.
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(16) NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'moderator', 'admin')),
+    ADD COLUMN suspended_at TIMESTAMP,
+    ADD COLUMN suspension_reason TEXT;
+
+CREATE INDEX idx_users_staff ON users(role) WHERE role <> 'user';
+CREATE INDEX idx_users_suspended ON users(suspended_at) WHERE suspended_at IS NOT NULL;
+
+-- Audit entries outlive both the staff member and what they acted on, so
+-- targets are not foreign keys and a deleted actor is kept as NULL
+CREATE TABLE audit_log (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(50) NOT NULL,
+    target_type VARCHAR(16) NOT NULL, -- user, post, comment
+    target_id UUID NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}',
+    ip_address VARCHAR(45),
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_log_created ON audit_log(created_at DESC, id DESC);
+CREATE INDEX idx_audit_log_actor ON audit_log(actor_id, created_at DESC);
+CREATE INDEX idx_audit_log_target ON audit_log(target_type, target_id, created_at DESC);
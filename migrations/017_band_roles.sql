@@ -0,0 +1,22 @@
+-- Band roles: band_members.role was a free-form label, of which only 'Admin'
+-- granted anything. It now holds the member's band role: owner, admin or
+-- member. Every band has exactly one owner.
+UPDATE band_members SET role = CASE WHEN role = 'Admin' THEN 'admin' ELSE 'member' END;
+
+-- The longest-standing admin owns the band; bands left without an admin are
+-- handed to their longest-standing member
+UPDATE band_members bm SET role = 'owner'
+FROM (
+    SELECT DISTINCT ON (band_id) id
+    FROM band_members
+    ORDER BY band_id, (role = 'admin') DESC, joined_at ASC, id ASC
+) first_members
+WHERE bm.id = first_members.id;
+
+ALTER TABLE band_members
+    ALTER COLUMN role SET DEFAULT 'member',
+    ALTER COLUMN role SET NOT NULL,
+    ALTER COLUMN role TYPE VARCHAR(16),
+    ADD CONSTRAINT band_members_role_check CHECK (role IN ('owner', 'admin', 'member'));
+
+CREATE UNIQUE INDEX idx_band_members_owner ON band_members(band_id) WHERE role = 'owner';
@@ -0,0 +1,24 @@
+-- Band membership requests: nobody joins a band directly any more. Band
+-- admins invite users, who accept or decline, and users ask to join, which
+-- band admins approve (accepted) or reject (declined). A request is pending
+-- until it is answered, withdrawn (cancelled) or expires_at has passed.
+CREATE TABLE band_membership_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    band_id UUID NOT NULL REFERENCES bands(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE, -- the invitee, or the user asking to join
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('invitation', 'join_request')),
+    role VARCHAR(16) NOT NULL DEFAULT 'member' CHECK (role IN ('admin', 'member')),
+    message TEXT,
+    status VARCHAR(16) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined', 'cancelled', 'expired')),
+    invited_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    responded_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    responded_at TIMESTAMP,
+    notified_at TIMESTAMP -- when the recipient was told about the request
+);
+
+-- A user has at most one open invitation or join request per band
+CREATE UNIQUE INDEX idx_band_membership_requests_pending ON band_membership_requests(band_id, user_id) WHERE status = 'pending';
+CREATE INDEX idx_band_membership_requests_band ON band_membership_requests(band_id, kind, created_at DESC);
+CREATE INDEX idx_band_membership_requests_user ON band_membership_requests(user_id, kind, created_at DESC);
@@ -0,0 +1,13 @@
+-- A user has at most one data export pending or being built, so concurrent
+-- requests cannot start several. Older duplicates left by such races are
+-- marked failed first.
+UPDATE data_exports SET status = 'failed', error = 'superseded by a newer export', completed_at = NOW()
+WHERE status IN ('pending', 'processing')
+    AND id NOT IN (
+        SELECT DISTINCT ON (user_id) id
+        FROM data_exports
+        WHERE status IN ('pending', 'processing')
+        ORDER BY user_id, created_at DESC, id DESC
+    );
+
+CREATE UNIQUE INDEX idx_data_exports_user_open ON data_exports(user_id) WHERE status IN ('pending', 'processing');
@@ -0,0 +1,5 @@
+-- Listing band invitations and join requests moved from the bands:write
+-- scope to the new bands:read scope. Keys that could list them before keep
+-- that access.
+UPDATE api_keys SET scopes = array_append(scopes, 'bands:read')
+WHERE 'bands:write' = ANY(scopes) AND NOT 'bands:read' = ANY(scopes);
@@ -1,17 +1,238 @@
 package utils
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/argon2"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// HashPassword hashes a password using bcrypt
+// PasswordHasher is a password hashing algorithm. Hashes are stored in PHC
+// string format ($id$params$salt$hash), so each hash records the algorithm
+// and parameters it was made with and can be verified after the defaults
+// change. bcrypt's own $2b$ format is accepted as well.
+type PasswordHasher interface {
+	// IDs are the leading $id$ values of the hashes this hasher verifies
+	IDs() []string
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (bool, error)
+	// NeedsRehash reports whether a hash it verifies was made with weaker
+	// parameters than it would use now
+	NeedsRehash(encoded string) bool
+}
+
+var (
+	passwordHashersMu     sync.RWMutex
+	passwordHashers       = make(map[string]PasswordHasher)
+	defaultPasswordHasher PasswordHasher
+)
+
+func init() {
+	RegisterPasswordHasher(NewBcryptHasher(bcrypt.DefaultCost))
+	SetPasswordHasher(NewArgon2idHasher(DefaultArgon2idParams))
+}
+
+// RegisterPasswordHasher lets CheckPasswordHash verify the hasher's hashes
+func RegisterPasswordHasher(hasher PasswordHasher) {
+	passwordHashersMu.Lock()
+	defer passwordHashersMu.Unlock()
+	for _, id := range hasher.IDs() {
+		passwordHashers[id] = hasher
+	}
+}
+
+// SetPasswordHasher makes HashPassword use hasher for new hashes. Hashes
+// made by other registered hashers still verify, and are reported by
+// PasswordNeedsRehash so they can be upgraded.
+func SetPasswordHasher(hasher PasswordHasher) {
+	RegisterPasswordHasher(hasher)
+	passwordHashersMu.Lock()
+	defer passwordHashersMu.Unlock()
+	defaultPasswordHasher = hasher
+}
+
+// HashPassword hashes a password with the configured hasher, argon2id
+// unless changed with SetPasswordHasher
 func HashPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
-	return string(bytes), err
+	passwordHashersMu.RLock()
+	hasher := defaultPasswordHasher
+	passwordHashersMu.RUnlock()
+	return hasher.Hash(password)
 }
 
-// CheckPasswordHash compares a password with a hash
+// CheckPasswordHash compares a password with a hash made by any registered
+// hasher
 func CheckPasswordHash(password, hash string) bool {
-	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
-	return err == nil
+	hasher := passwordHasherFor(hash)
+	if hasher == nil {
+		return false
+	}
+	ok, err := hasher.Verify(password, hash)
+	return err == nil && ok
+}
+
+// PasswordNeedsRehash reports whether a hash should be replaced with one
+// from the configured hasher, because it uses another algorithm or weaker
+// parameters. Only call it after the password has been verified.
+func PasswordNeedsRehash(hash string) bool {
+	passwordHashersMu.RLock()
+	current := defaultPasswordHasher
+	passwordHashersMu.RUnlock()
+
+	if passwordHasherFor(hash) != current {
+		return true
+	}
+	return current.NeedsRehash(hash)
+}
+
+func passwordHasherFor(hash string) PasswordHasher {
+	parts := strings.SplitN(hash, "$", 3)
+	if len(parts) < 3 || parts[0] != "" {
+		return nil
+	}
+
+	passwordHashersMu.RLock()
+	defer passwordHashersMu.RUnlock()
+	return passwordHashers[parts[1]]
+}
+
+// Argon2idParams are the argon2id cost parameters
+type Argon2idParams struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idParams follow the OWASP recommendation of 19 MiB of memory
+// and two iterations
+var DefaultArgon2idParams = Argon2idParams{
+	Memory:      19 * 1024,
+	Iterations:  2,
+	Parallelism: 1,
+	SaltLength:  16,
+	KeyLength:   32,
+}
+
+type argon2idHasher struct {
+	params Argon2idParams
+}
+
+// NewArgon2idHasher hashes passwords with argon2id, encoded as
+// $argon2id$v=19$m=<memory>,t=<iterations>,p=<parallelism>$<salt>$<hash>
+func NewArgon2idHasher(params Argon2idParams) PasswordHasher {
+	return &argon2idHasher{params: params}
+}
+
+func (h *argon2idHasher) IDs() []string {
+	return []string{"argon2id"}
+}
+
+func (h *argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+func (h *argon2idHasher) Verify(password, encoded string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	candidate := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, uint32(len(key)))
+	return subtle.ConstantTimeCompare(candidate, key) == 1, nil
+}
+
+func (h *argon2idHasher) NeedsRehash(encoded string) bool {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params.Memory < h.params.Memory ||
+		params.Iterations < h.params.Iterations ||
+		params.Parallelism < h.params.Parallelism ||
+		uint32(len(salt)) < h.params.SaltLength ||
+		uint32(len(key)) < h.params.KeyLength
+}
+
+// maxArgon2idMemory bounds the memory a stored hash can make verification
+// use, so a tampered hash cannot exhaust the server
+const maxArgon2idMemory = 1024 * 1024
+
+func decodeArgon2id(encoded string) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", salt, hash
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, fmt.Errorf("not an argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+	if params.Memory == 0 || params.Memory > maxArgon2idMemory || params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, fmt.Errorf("invalid argon2id parameters")
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, fmt.Errorf("invalid argon2id hash")
+	}
+
+	return params, salt, key, nil
+}
+
+type bcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher hashes passwords with bcrypt at the given cost. Passwords
+// hashed before the switch to argon2id are bcrypt hashes.
+func NewBcryptHasher(cost int) PasswordHasher {
+	return &bcryptHasher{cost: cost}
+}
+
+func (h *bcryptHasher) IDs() []string {
+	return []string{"2a", "2b", "2y"}
+}
+
+func (h *bcryptHasher) Hash(password string) (string, error) {
+	bytes, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	return string(bytes), err
+}
+
+func (h *bcryptHasher) Verify(password, encoded string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if err == bcrypt.ErrMismatchedHashAndPassword {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (h *bcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost < h.cost
 }
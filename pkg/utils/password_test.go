@@ -1,9 +1,13 @@
 package utils
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestHashPassword(t *testing.T) {
@@ -183,6 +187,75 @@ func TestPasswordHashRoundTrip(t *testing.T) {
 	}
 }
 
+func TestHashPasswordFormat(t *testing.T) {
+	hash, err := HashPassword("password123")
+	if err != nil {
+		t.Fatalf("HashPassword() unexpected error: %v", err)
+	}
+
+	expectedPrefix := fmt.Sprintf("$argon2id$v=19$m=%d,t=%d,p=%d$",
+		DefaultArgon2idParams.Memory, DefaultArgon2idParams.Iterations, DefaultArgon2idParams.Parallelism)
+	if !strings.HasPrefix(hash, expectedPrefix) {
+		t.Errorf("Expected argon2id PHC string starting with %s, got %s", expectedPrefix, hash)
+	}
+	if PasswordNeedsRehash(hash) {
+		t.Error("Expected a fresh hash not to need rehashing")
+	}
+}
+
+func TestLegacyBcryptHash(t *testing.T) {
+	legacy, err := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+	if err != nil {
+		t.Fatalf("Failed to create bcrypt hash: %v", err)
+	}
+
+	if !CheckPasswordHash("password123", string(legacy)) {
+		t.Error("Expected legacy bcrypt hash to verify")
+	}
+	if CheckPasswordHash("wrongpassword", string(legacy)) {
+		t.Error("Expected wrong password not to verify against bcrypt hash")
+	}
+	if !PasswordNeedsRehash(string(legacy)) {
+		t.Error("Expected bcrypt hash to need rehashing to argon2id")
+	}
+}
+
+func TestPasswordNeedsRehash_Argon2idParams(t *testing.T) {
+	weaker := DefaultArgon2idParams
+	weaker.Memory = 8 * 1024
+	hash, err := NewArgon2idHasher(weaker).Hash("password123")
+	if err != nil {
+		t.Fatalf("Hash() unexpected error: %v", err)
+	}
+
+	if !CheckPasswordHash("password123", hash) {
+		t.Error("Expected hash with older parameters to verify")
+	}
+	if !PasswordNeedsRehash(hash) {
+		t.Error("Expected hash with less memory than the default to need rehashing")
+	}
+}
+
+func TestCheckPasswordHash_InvalidArgon2id(t *testing.T) {
+	hash, _ := HashPassword("password123")
+	parts := strings.Split(hash, "$")
+
+	tests := map[string]string{
+		"unsupported version": strings.Replace(hash, "v=19", "v=16", 1),
+		"excessive memory":    strings.Replace(hash, parts[3], "m=4194304,t=1,p=1", 1),
+		"zero iterations":     strings.Replace(hash, parts[3], "m=19456,t=0,p=1", 1),
+		"truncated":           strings.Join(parts[:5], "$"),
+		"unknown algorithm":   strings.Replace(hash, "$argon2id$", "$scrypt$", 1),
+	}
+	for name, tampered := range tests {
+		t.Run(name, func(t *testing.T) {
+			if CheckPasswordHash("password123", tampered) {
+				t.Errorf("Expected %s hash to be rejected", name)
+			}
+		})
+	}
+}
+
 func BenchmarkHashPassword(b *testing.B) {
 	password := "benchmarkpassword123"
 	
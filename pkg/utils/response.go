@@ -6,10 +6,11 @@ import (
 )
 
 type APIResponse struct {
-	Success bool        `json:"success"`
-	Message string      `json:"message,omitempty"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
+	Success    bool        `json:"success"`
+	Message    string      `json:"message,omitempty"`
+	Data       interface{} `json:"data,omitempty"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+	Error      string      `json:"error,omitempty"`
 }
 
 func WriteJSON(w http.ResponseWriter, status int, data interface{}) {
@@ -27,6 +28,18 @@ func WriteSuccess(w http.ResponseWriter, message string, data interface{}) {
 	WriteJSON(w, http.StatusOK, response)
 }
 
+// WritePaginated writes a page of a list along with the cursor for the next
+// page; nextCursor is omitted when there are no more items
+func WritePaginated(w http.ResponseWriter, message string, data interface{}, nextCursor string) {
+	response := APIResponse{
+		Success:    true,
+		Message:    message,
+		Data:       data,
+		NextCursor: nextCursor,
+	}
+	WriteJSON(w, http.StatusOK, response)
+}
+
 func WriteError(w http.ResponseWriter, status int, message string) {
 	response := APIResponse{
 		Success: false,
@@ -43,3 +56,14 @@ func WriteCreated(w http.ResponseWriter, message string, data interface{}) {
 	}
 	WriteJSON(w, http.StatusCreated, response)
 }
+
+// WriteAccepted acknowledges a request that will be completed in the
+// background
+func WriteAccepted(w http.ResponseWriter, message string, data interface{}) {
+	response := APIResponse{
+		Success: true,
+		Message: message,
+		Data:    data,
+	}
+	WriteJSON(w, http.StatusAccepted, response)
+}
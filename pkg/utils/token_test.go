@@ -0,0 +1,33 @@
+package utils
+
+import "testing"
+
+func TestGenerateSecureToken(t *testing.T) {
+	a, err := GenerateSecureToken(32)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	b, err := GenerateSecureToken(32)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if len(a) != 43 {
+		t.Errorf("Expected 43 characters for 32 bytes, got %d", len(a))
+	}
+	if a == b {
+		t.Error("Expected two generated tokens to differ")
+	}
+}
+
+func TestHashToken(t *testing.T) {
+	if HashToken("token") != HashToken("token") {
+		t.Error("Expected hashing to be deterministic")
+	}
+	if HashToken("token") == HashToken("other") {
+		t.Error("Expected different tokens to hash differently")
+	}
+	if HashToken("token") == "token" {
+		t.Error("Expected hash to differ from the token")
+	}
+}
@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// RFC 6238 appendix B uses the ASCII secret "12345678901234567890"; the
+// expected values are the last six digits of its eight-digit SHA1 codes
+var rfcSecret = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCode(t *testing.T) {
+	tests := []struct {
+		unix     int64
+		expected string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		code, err := TOTPCode(rfcSecret, TOTPStep(time.Unix(tt.unix, 0)))
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if code != tt.expected {
+			t.Errorf("At %d expected code %s, got %s", tt.unix, tt.expected, code)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current, _ := TOTPCode(rfcSecret, TOTPStep(now))
+	previous, _ := TOTPCode(rfcSecret, TOTPStep(now)-1)
+	stale, _ := TOTPCode(rfcSecret, TOTPStep(now)-3)
+
+	if step, ok := ValidateTOTP(rfcSecret, current, now); !ok || step != TOTPStep(now) {
+		t.Errorf("Expected current code to validate at step %d, got %d, %v", TOTPStep(now), step, ok)
+	}
+	if _, ok := ValidateTOTP(rfcSecret, previous, now); !ok {
+		t.Error("Expected code from the previous step to be accepted")
+	}
+	if _, ok := ValidateTOTP(rfcSecret, stale, now); ok {
+		t.Error("Expected stale code to be rejected")
+	}
+	if _, ok := ValidateTOTP(rfcSecret, "12345", now); ok {
+		t.Error("Expected short code to be rejected")
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if _, err := TOTPCode(secret, 1); err != nil {
+		t.Errorf("Expected generated secret to be usable, got: %v", err)
+	}
+
+	uri := TOTPProvisioningURI("MusicApp", "user@example.com", secret)
+	if !strings.HasPrefix(uri, "otpauth://totp/MusicApp:user@example.com?") || !strings.Contains(uri, "secret="+secret) {
+		t.Errorf("Unexpected provisioning URI: %s", uri)
+	}
+}
@@ -12,5 +12,24 @@ echo "PostgreSQL is ready!"
 # Run migrations
 echo "Running database migrations..."
 psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/001_initial_schema.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/002_comment_threads.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/003_direct_messages.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/004_band_post_permissions.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/005_quote_posts.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/006_keyset_pagination_indexes.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/007_refresh_tokens.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/008_sessions.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/009_account_tokens.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/010_email_verification.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/011_two_factor.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/012_identities.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/013_api_keys.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/014_account_deletion.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/015_data_exports.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/016_platform_roles.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/017_band_roles.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/018_band_membership_requests.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/019_data_export_one_open.sql
+psql -h localhost -p 5432 -U dev -d musicapp -f /docker-entrypoint-initdb.d/020_api_key_bands_read.sql
 
 echo "Database initialization complete!"